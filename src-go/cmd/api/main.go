@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Fusos das organizações não dependem do tzdata instalado no servidor.
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,10 +16,13 @@ import (
 	"go-api/internal/api"
 	"go-api/internal/api/routes"
 	"go-api/internal/config"
+	"go-api/internal/core"
 	"go-api/internal/db"
 	"go-api/internal/logging"
+	"go-api/internal/mail"
 	"go-api/internal/middleware"
 	"go-api/internal/models"
+	"go-api/internal/oidc"
 	"go-api/internal/repositories"
 	"go-api/internal/services"
 	"go-api/internal/storage"
@@ -28,13 +33,45 @@ func main() {
 	defer logging.Logger.Sync()
 
 	config.LoadConfig()
+	if err := core.LoadSigningKeys(); err != nil {
+		logging.Logger.Fatal("Failed to load JWT signing keys", zap.Error(err))
+	}
+	if err := core.LoadPasswordDenylist(config.AppConfig.PASSWORD_DENYLIST_FILE); err != nil {
+		logging.Logger.Fatal("Failed to load password denylist", zap.Error(err))
+	}
 
 	gormDB := db.InitDB()
 	db.Migrate(gormDB)
-	redisClient := db.InitRedis()
+	if err := repositories.RegisterTenantGuard(gormDB); err != nil {
+		logging.Logger.Fatal("Failed to register tenant guard", zap.Error(err))
+	}
+	// Com REDIS_ADDR configurado, seguir em memória dividiria bloqueios de login
+	// e estados de SSO entre as instâncias sem ninguém perceber.
+	redisClient, err := db.InitRedis()
+	if err != nil {
+		logging.Logger.Fatal("REDIS_ADDR is set but Redis is unreachable", zap.Error(err))
+	}
+
+	var mailSender mail.Sender
+	if config.AppConfig.SMTP_HOST != "" {
+		mailSender = mail.NewSMTPSender(config.AppConfig.SMTP_HOST, config.AppConfig.SMTP_PORT, config.AppConfig.SMTP_USER, config.AppConfig.SMTP_PASSWORD, config.AppConfig.EMAILS_FROM_EMAIL)
+	} else {
+		mailSender = mail.NewLogSender()
+	}
 
 	// Repositories
-	cacheRepository := repositories.NewRedisCacheRepository(redisClient)
+	// securityStore guarda os contadores de tentativas de login e os estados de
+	// SSO. Em memória ele fica fora do LRU do cache, para que nenhum deles seja
+	// descartado para abrir espaço.
+	var cacheRepository, securityStore repositories.CacheRepository
+	if redisClient != nil {
+		cacheRepository = repositories.NewRedisCacheRepository(redisClient)
+		securityStore = cacheRepository
+	} else {
+		// Bloqueios de login e estados de SSO passam a valer só nesta instância.
+		cacheRepository = repositories.NewMemoryCacheRepository(config.AppConfig.CACHE_MEMORY_MAX_ENTRIES)
+		securityStore = repositories.NewUnboundedMemoryCacheRepository()
+	}
 	userRepository := repositories.NewUserRepository(gormDB)
 	vehicleRepository := repositories.NewVehicleRepository(gormDB)
 	implementRepository := repositories.NewImplementRepository(gormDB)
@@ -48,27 +85,67 @@ func main() {
 	freightOrderRepository := repositories.NewFreightOrderRepository(gormDB)
 	documentRepository := repositories.NewDocumentRepository(gormDB)
 	organizationRepository := repositories.NewOrganizationRepository(gormDB)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(gormDB)
+	accountLockEventRepository := repositories.NewAccountLockEventRepository(gormDB)
+	twoFactorRepository := repositories.NewTwoFactorRepository(gormDB)
+	impersonationSessionRepository := repositories.NewImpersonationSessionRepository(gormDB)
+	apiKeyRepository := repositories.NewAPIKeyRepository(gormDB)
+	userSessionRepository := repositories.NewUserSessionRepository(gormDB)
+	permissionRepository := repositories.NewPermissionRepository(gormDB)
+	ssoConfigRepository := repositories.NewSSOConfigRepository(gormDB)
+	passwordHistoryRepository := repositories.NewPasswordHistoryRepository(gormDB)
+	usageRepository := repositories.NewUsageRepository(gormDB)
+	organizationSettingsRepository := repositories.NewOrganizationSettingsRepository(gormDB)
+	analyticsRepository := repositories.NewAnalyticsRepository(gormDB)
+	odometerReadingRepository := repositories.NewOdometerReadingRepository(gormDB)
+
+	fileStorageService := storage.NewLocalStorageService("static")
 
 	// Services
-	userService := services.NewUserService(userRepository)
-	authService := services.NewAuthService(userRepository)
-	vehicleService := services.NewVehicleService(vehicleRepository, cacheRepository)
+	passwordPolicyService := services.NewPasswordPolicyService(passwordHistoryRepository)
+	organizationSettingsService := services.NewOrganizationSettingsService(organizationSettingsRepository, organizationRepository)
+	emailRequestThrottle := services.NewEmailRequestThrottle(securityStore)
+	passwordResetService := services.NewPasswordResetService(userRepository, refreshTokenRepository, userSessionRepository, passwordPolicyService, emailRequestThrottle, mailSender)
+	quotaService := services.NewQuotaService(organizationRepository, usageRepository, vehicleRepository, userRepository, organizationSettingsService)
+	userService := services.NewUserService(userRepository, organizationRepository, refreshTokenRepository, userSessionRepository, passwordPolicyService, passwordResetService, quotaService, fileStorageService)
+	loginAttemptService := services.NewLoginAttemptService(securityStore, userRepository, accountLockEventRepository)
+	twoFactorService := services.NewTwoFactorService(userRepository, organizationRepository, twoFactorRepository)
+	authService := services.NewAuthService(userRepository, refreshTokenRepository, userSessionRepository, impersonationSessionRepository, loginAttemptService, twoFactorService)
+	permissionService := services.NewPermissionService(permissionRepository)
+	badgeService := services.NewBadgeService(userRepository, organizationRepository, loginAttemptService, authService)
+	ssoService := services.NewSSOService(ssoConfigRepository, userRepository, organizationRepository, securityStore, authService, quotaService, oidc.NewClient(nil))
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, userRepository)
+	vehicleStatusService := services.NewVehicleStatusService(vehicleRepository, organizationSettingsService, cacheRepository)
+	odometerService := services.NewOdometerService(odometerReadingRepository, vehicleRepository, organizationSettingsService, cacheRepository)
+	vehicleService := services.NewVehicleService(vehicleRepository, cacheRepository, quotaService, vehicleStatusService, fileStorageService)
 	implementService := services.NewImplementService(implementRepository)
-	journeyService := services.NewJourneyService(journeyRepository, vehicleRepository)
-	fuelLogService := services.NewFuelLogService(fuelLogRepository)
+	journeyService := services.NewJourneyService(journeyRepository, vehicleRepository, quotaService, organizationSettingsService, vehicleStatusService, odometerService)
+	fuelLogService := services.NewFuelLogService(fuelLogRepository, vehicleRepository, odometerService)
 	maintenanceService := services.NewMaintenanceService(maintenanceRepository)
 	notificationService := services.NewNotificationService(notificationRepository)
 	fineService := services.NewFineService(fineRepository, notificationService)
-	partService := services.NewPartService(partRepository, inventoryTransactionRepository, notificationService)
-	freightOrderService := services.NewFreightOrderService(freightOrderRepository, vehicleRepository, journeyService)
-	fileStorageService := storage.NewLocalStorageService("static")
-	documentService := services.NewDocumentService(documentRepository, fileStorageService)
-	organizationService := services.NewOrganizationService(organizationRepository)
+	partService := services.NewPartService(partRepository, inventoryTransactionRepository, notificationService, fileStorageService)
+	freightOrderService := services.NewFreightOrderService(freightOrderRepository, vehicleRepository, journeyService, quotaService)
+	assetTagService := services.NewAssetTagService(vehicleRepository, implementRepository, partRepository)
+	documentService := services.NewDocumentService(documentRepository, fileStorageService, quotaService, organizationSettingsService)
+	organizationService := services.NewOrganizationService(organizationRepository, userRepository, passwordPolicyService, fileStorageService)
+	analyticsService := services.NewAnalyticsService(analyticsRepository, organizationRepository, fileStorageService)
+	signupService := services.NewSignupService(userRepository, organizationRepository, passwordPolicyService, emailRequestThrottle, mailSender)
 
 	// Handlers
-	userHandler := api.NewUserHandler(userService)
-	authHandler := api.NewAuthHandler(authService)
+	userHandler := api.NewUserHandler(userService, loginAttemptService)
+	authHandler := api.NewAuthHandler(authService, passwordResetService)
+	twoFactorHandler := api.NewTwoFactorHandler(twoFactorService)
+	apiKeyHandler := api.NewAPIKeyHandler(apiKeyService)
+	permissionHandler := api.NewPermissionHandler(permissionService)
+	sessionHandler := api.NewSessionHandler(authService)
+	ssoHandler := api.NewSSOHandler(ssoService)
+	badgeHandler := api.NewBadgeHandler(badgeService)
+	signupHandler := api.NewSignupHandler(signupService)
+	usageHandler := api.NewUsageHandler(quotaService)
+	organizationSettingsHandler := api.NewOrganizationSettingsHandler(organizationSettingsService)
 	vehicleHandler := api.NewVehicleHandler(vehicleService)
+	odometerHandler := api.NewOdometerHandler(odometerService)
 	implementHandler := api.NewImplementHandler(implementService)
 	journeyHandler := api.NewJourneyHandler(journeyService)
 	fuelLogHandler := api.NewFuelLogHandler(fuelLogService)
@@ -76,52 +153,74 @@ func main() {
 	fineHandler := api.NewFineHandler(fineService)
 	partHandler := api.NewPartHandler(partService)
 	freightOrderHandler := api.NewFreightOrderHandler(freightOrderService)
+	assetTagHandler := api.NewAssetTagHandler(assetTagService, permissionService)
 	documentHandler := api.NewDocumentHandler(documentService)
-	adminHandler := api.NewAdminHandler(organizationService, userService, authService)
+	adminHandler := api.NewAdminHandler(organizationService, userService, authService, loginAttemptService)
+	analyticsHandler := api.NewAnalyticsHandler(analyticsService)
+	cacheHandler := api.NewCacheHandler(cacheRepository)
+
+	go services.RunOrganizationPurge(organizationService, time.Duration(config.AppConfig.ORGANIZATION_PURGE_INTERVAL_MINUTES)*time.Minute)
+	go services.RunSignupCleanup(signupService, time.Duration(config.AppConfig.ORGANIZATION_PURGE_INTERVAL_MINUTES)*time.Minute)
 
 	router := gin.Default()
 	router.Use(middleware.LoggingMiddleware())
 	router.Use(middleware.ErrorHandler())
 
 	router.Static("/static", "./static")
+	routes.RegisterWellKnownRoutes(authHandler)(&router.RouterGroup)
 
 	apiV1 := router.Group("/api/v1")
 	{
 		// Public routes
 		routes.RegisterLoginRoutes(authHandler)(apiV1)
+		routes.RegisterSSOLoginRoutes(ssoHandler)(apiV1)
+		routes.RegisterBadgeLoginRoutes(badgeHandler)(apiV1)
+		routes.RegisterSignupRoutes(signupHandler)(apiV1)
 
 		// Authenticated routes
 		authRequired := apiV1.Group("/")
-		authRequired.Use(middleware.AuthMiddleware(userService))
+		authRequired.Use(middleware.AuthMiddleware(userService, authService, apiKeyService, organizationService))
 		{
+			routes.RegisterLogoutRoutes(authHandler)(authRequired)
+			routes.RegisterTwoFactorRoutes(twoFactorHandler)(authRequired)
+
 			// SuperAdmin routes
 			superAdminRoutes := authRequired.Group("/admin")
 			superAdminRoutes.Use(middleware.AuthorizationMiddleware(models.RoleSuperAdmin))
 			{
 				routes.RegisterAdminRoutes(adminHandler)(superAdminRoutes)
+				routes.RegisterAnalyticsRoutes(analyticsHandler)(superAdminRoutes)
+				routes.RegisterCacheRoutes(cacheHandler)(superAdminRoutes)
 			}
 
-			// Manager routes
-			managerRoutes := authRequired.Group("/")
-			managerRoutes.Use(middleware.AuthorizationMiddleware(models.RoleClienteAtivo, models.RoleClienteDemo))
-			{
-				routes.RegisterUserRoutes(userHandler)(managerRoutes)
-				routes.RegisterVehicleRoutes(vehicleHandler)(managerRoutes)
-				routes.RegisterImplementRoutes(implementHandler)(managerRoutes)
-				routes.RegisterPartRoutes(partHandler)(managerRoutes)
-				routes.RegisterDocumentRoutes(documentHandler)(managerRoutes)
-				// Add other manager routes here
-			}
+			// Organization routes: cada rota exige uma permissão, resolvida a
+			// partir do papel do usuário e das personalizações da organização.
+			requirePermission := middleware.NewPermissionGuard(permissionService)
+
+			// Encerrar sessões continua possível mesmo com a demonstração expirada.
+			routes.RegisterSessionRoutes(sessionHandler, requirePermission)(authRequired)
 
-			// Driver and Manager routes
-			driverAndManagerRoutes := authRequired.Group("/")
-			driverAndManagerRoutes.Use(middleware.AuthorizationMiddleware(models.RoleDriver, models.RoleClienteAtivo, models.RoleClienteDemo))
+			orgRoutes := authRequired.Group("/")
+			orgRoutes.Use(middleware.TrialMiddleware(organizationService))
 			{
-				routes.RegisterJourneyRoutes(journeyHandler)(driverAndManagerRoutes)
-				routes.RegisterFuelLogRoutes(fuelLogHandler)(driverAndManagerRoutes)
-				routes.RegisterMaintenanceRoutes(maintenanceHandler)(driverAndManagerRoutes)
-				routes.RegisterFineRoutes(fineHandler)(driverAndManagerRoutes)
-				routes.RegisterFreightOrderRoutes(freightOrderHandler)(driverAndManagerRoutes)
+				routes.RegisterPermissionRoutes(permissionHandler, requirePermission)(orgRoutes)
+				routes.RegisterSSOConfigRoutes(ssoHandler, requirePermission)(orgRoutes)
+				routes.RegisterUsageRoutes(usageHandler, requirePermission)(orgRoutes)
+				routes.RegisterOrganizationSettingsRoutes(organizationSettingsHandler, requirePermission)(orgRoutes)
+				routes.RegisterUserRoutes(userHandler, requirePermission)(orgRoutes)
+				routes.RegisterBadgeRoutes(badgeHandler, requirePermission)(orgRoutes)
+				routes.RegisterVehicleRoutes(vehicleHandler, requirePermission)(orgRoutes)
+				routes.RegisterOdometerRoutes(odometerHandler, requirePermission)(orgRoutes)
+				routes.RegisterImplementRoutes(implementHandler, requirePermission)(orgRoutes)
+				routes.RegisterPartRoutes(partHandler, requirePermission)(orgRoutes)
+				routes.RegisterDocumentRoutes(documentHandler, requirePermission)(orgRoutes)
+				routes.RegisterAPIKeyRoutes(apiKeyHandler, requirePermission)(orgRoutes)
+				routes.RegisterJourneyRoutes(journeyHandler, requirePermission)(orgRoutes)
+				routes.RegisterFuelLogRoutes(fuelLogHandler, requirePermission)(orgRoutes)
+				routes.RegisterMaintenanceRoutes(maintenanceHandler, requirePermission)(orgRoutes)
+				routes.RegisterFineRoutes(fineHandler, requirePermission)(orgRoutes)
+				routes.RegisterFreightOrderRoutes(freightOrderHandler, requirePermission)(orgRoutes)
+				routes.RegisterAssetTagRoutes(assetTagHandler)(orgRoutes)
 			}
 		}
 	}
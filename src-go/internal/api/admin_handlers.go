@@ -1,23 +1,26 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"go-api/internal/models"
 	"go-api/internal/services"
 	"go-api/internal/schemas"
 )
 
 type AdminHandler struct {
-	orgService  services.OrganizationService
-	userService services.UserService
-	authService services.AuthService
+	orgService          services.OrganizationService
+	userService         services.UserService
+	authService         services.AuthService
+	loginAttemptService services.LoginAttemptService
 }
 
-func NewAdminHandler(orgService services.OrganizationService, userService services.UserService, authService services.AuthService) *AdminHandler {
-	return &AdminHandler{orgService: orgService, userService: userService, authService: authService}
+func NewAdminHandler(orgService services.OrganizationService, userService services.UserService, authService services.AuthService, loginAttemptService services.LoginAttemptService) *AdminHandler {
+	return &AdminHandler{orgService: orgService, userService: userService, authService: authService, loginAttemptService: loginAttemptService}
 }
 
 func (h *AdminHandler) GetOrganizations(c *gin.Context) {
@@ -26,6 +29,10 @@ func (h *AdminHandler) GetOrganizations(c *gin.Context) {
 	status := c.Query("status")
 
 	orgs, err := h.orgService.GetOrganizations(skip, limit, &status)
+	if err == services.ErrInvalidOrganizationStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "statuses": models.OrganizationStatuses})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
 		return
@@ -43,12 +50,98 @@ func (h *AdminHandler) UpdateOrganization(c *gin.Context) {
 
 	updatedOrg, err := h.orgService.UpdateOrganization(uint(orgID), orgIn)
 	if err != nil {
+		if err == services.ErrInvalidLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
 		return
 	}
 	c.JSON(http.StatusOK, updatedOrg)
 }
 
+func (h *AdminHandler) CreateOrganization(c *gin.Context) {
+	var orgIn schemas.OrganizationCreate
+	if err := c.ShouldBindJSON(&orgIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, manager, err := h.orgService.CreateOrganization(orgIn)
+	if err != nil {
+		switch {
+		case err == services.ErrEmailAlreadyRegistered:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == services.ErrInvalidSector, errors.Is(err, services.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"organization": org,
+		"manager": schemas.UserPublic{
+			ID:             manager.ID,
+			Email:          manager.Email,
+			FullName:       manager.FullName,
+			IsActive:       manager.IsActive,
+			OrganizationID: manager.OrganizationID,
+		},
+	})
+}
+
+func (h *AdminHandler) SuspendOrganization(c *gin.Context) {
+	orgID, _ := strconv.Atoi(c.Param("id"))
+	var payload schemas.OrganizationSuspend
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, err := h.orgService.SuspendOrganization(uint(orgID), payload.Reason)
+	if err != nil {
+		respondLifecycleError(c, err, "Failed to suspend organization")
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *AdminHandler) ReactivateOrganization(c *gin.Context) {
+	orgID, _ := strconv.Atoi(c.Param("id"))
+
+	org, err := h.orgService.ReactivateOrganization(uint(orgID))
+	if err != nil {
+		respondLifecycleError(c, err, "Failed to reactivate organization")
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization agenda a exclusão; os dados só são apagados depois do
+// prazo de carência e a organização pode ser reativada até lá.
+func (h *AdminHandler) DeleteOrganization(c *gin.Context) {
+	orgID, _ := strconv.Atoi(c.Param("id"))
+
+	org, err := h.orgService.ScheduleDeletion(uint(orgID))
+	if err != nil {
+		respondLifecycleError(c, err, "Failed to schedule organization deletion")
+		return
+	}
+	c.JSON(http.StatusAccepted, org)
+}
+
+func respondLifecycleError(c *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrOrganizationNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case services.ErrOrganizationStatusConflict, services.ErrOrganizationHasSuperAdmin:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 func (h *AdminHandler) GetAllUsers(c *gin.Context) {
 	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
@@ -83,11 +176,49 @@ func (h *AdminHandler) ActivateUser(c *gin.Context) {
 
 func (h *AdminHandler) ImpersonateUser(c *gin.Context) {
 	userID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	token, err := h.authService.Impersonate(uint(userID), currentUser, c.GetString("sessionID"), c.ClientIP())
+	if err != nil {
+		switch err {
+		case services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case services.ErrCannotImpersonateSuperAdmin:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *AdminHandler) GetImpersonationSessions(c *gin.Context) {
+	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	sessions, err := h.authService.GetImpersonationSessions(skip, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch impersonation sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
 
-	token, err := h.authService.Impersonate(uint(userID))
+	err := h.loginAttemptService.UnlockAccount(uint(userID), currentUser)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
 		return
 	}
-	c.JSON(http.StatusOK, schemas.Token{AccessToken: token, TokenType: "bearer"})
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
 }
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/services"
+	"go-api/internal/spreadsheet"
+)
+
+type AnalyticsHandler struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// GetOrganizationAnalytics aceita date_from e date_to (AAAA-MM-DD, UTC,
+// date_to inclusive) e format=csv para exportar.
+func (h *AnalyticsHandler) GetOrganizationAnalytics(c *gin.Context) {
+	dateFrom, dateTo, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetOrganizationAnalytics(dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute organization analytics"})
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	rows := [][]string{{"organization_id", "name", "status", "on_trial", "total_users", "active_users", "vehicles", "journeys", "freight_orders", "storage_files", "storage_bytes", "last_activity_at"}}
+	for _, org := range report.Organizations {
+		lastActivity := ""
+		if org.LastActivityAt != nil {
+			lastActivity = org.LastActivityAt.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(org.OrganizationID), 10), org.Name, org.Status, strconv.FormatBool(org.OnTrial),
+			strconv.FormatInt(org.TotalUsers, 10), strconv.FormatInt(org.ActiveUsers, 10), strconv.FormatInt(org.Vehicles, 10),
+			strconv.FormatInt(org.Journeys, 10), strconv.FormatInt(org.FreightOrders, 10),
+			strconv.FormatInt(org.StorageFiles, 10), strconv.FormatInt(org.StorageBytes, 10), lastActivity,
+		})
+	}
+	writeCSV(c, "organization-analytics.csv", rows)
+}
+
+func (h *AnalyticsHandler) GetConversions(c *gin.Context) {
+	dateFrom, dateTo, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetConversions(dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute conversions"})
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	rows := [][]string{{"period", "demo_signups", "conversions", "cohort_converted", "cohort_conversion_rate"}}
+	for _, p := range report.Periods {
+		rows = append(rows, []string{
+			p.Period, strconv.FormatInt(p.DemoSignups, 10), strconv.FormatInt(p.Conversions, 10),
+			strconv.FormatInt(p.CohortConverted, 10), strconv.FormatFloat(p.CohortConversionRate, 'f', 4, 64),
+		})
+	}
+	writeCSV(c, "conversions.csv", rows)
+}
+
+// parseDateRange lê date_from e date_to; date_to é convertido no início do dia
+// seguinte para ser usado como limite exclusivo.
+func parseDateRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var dateFrom, dateTo *time.Time
+	if val := c.Query("date_from"); val != "" {
+		parsed, err := time.Parse("2006-01-02", val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must use the YYYY-MM-DD format"})
+			return nil, nil, false
+		}
+		dateFrom = &parsed
+	}
+	if val := c.Query("date_to"); val != "" {
+		parsed, err := time.Parse("2006-01-02", val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_to must use the YYYY-MM-DD format"})
+			return nil, nil, false
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		dateTo = &parsed
+	}
+	if dateFrom != nil && dateTo != nil && !dateFrom.Before(*dateTo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return nil, nil, false
+	}
+	return dateFrom, dateTo, true
+}
+
+func writeCSV(c *gin.Context, filename string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	// Nomes de organizações vêm do cadastro público: células que pareçam
+	// fórmulas são escapadas.
+	if err := spreadsheet.WriteCSV(c.Writer, rows); err != nil {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	keys, err := h.service.GetAPIKeys(currentUser.OrganizationID, skip, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch api keys"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var keyIn schemas.APIKeyCreate
+	if err := c.ShouldBindJSON(&keyIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	key, err := h.service.CreateAPIKey(keyIn, currentUser)
+	if err != nil {
+		if err == services.ErrInvalidAPIKeyScope || err == services.ErrInvalidAPIKeyExpiry {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create api key"})
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	keyID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	if err := h.service.RevokeAPIKey(uint(keyID), currentUser.OrganizationID); err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke api key"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
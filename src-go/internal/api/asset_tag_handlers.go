@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+// assetTagReadPermissions é a permissão de leitura de cada tipo, exigida para
+// gerar as etiquetas e para abrir o registro pela leitura do QR.
+var assetTagReadPermissions = map[models.AssetType]models.Permission{
+	models.AssetTypeVehicle:       models.PermissionVehicleRead,
+	models.AssetTypeImplement:     models.PermissionImplementRead,
+	models.AssetTypeInventoryItem: models.PermissionPartRead,
+}
+
+// AssetTagHandler confere as permissões no próprio handler: elas dependem dos
+// tipos pedidos na query ou do tipo da etiqueta lida.
+type AssetTagHandler struct {
+	service     services.AssetTagService
+	permissions services.PermissionService
+}
+
+func NewAssetTagHandler(service services.AssetTagService, permissions services.PermissionService) *AssetTagHandler {
+	return &AssetTagHandler{service: service, permissions: permissions}
+}
+
+// GetTags devolve o conteúdo das etiquetas, para o frontend imprimir no
+// próprio layout.
+func (h *AssetTagHandler) GetTags(c *gin.Context) {
+	tags, ok := h.tags(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetLabelSheet devolve as etiquetas em uma folha A4 pronta para imprimir.
+func (h *AssetTagHandler) GetLabelSheet(c *gin.Context) {
+	tags, ok := h.tags(c)
+	if !ok {
+		return
+	}
+
+	var sheet bytes.Buffer
+	if err := h.service.WriteLabelSheet(&sheet, tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render labels"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="asset-tags.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", sheet.Bytes())
+}
+
+func (h *AssetTagHandler) tags(c *gin.Context) ([]schemas.AssetTagPublic, bool) {
+	var req schemas.AssetTagRequest
+	var err error
+	for key, ids := range map[string]*[]uint{
+		"vehicle_ids":        &req.VehicleIDs,
+		"implement_ids":      &req.ImplementIDs,
+		"inventory_item_ids": &req.InventoryItemIDs,
+	} {
+		if *ids, err = queryIDs(c, key); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+
+	requested := map[models.AssetType]bool{
+		models.AssetTypeVehicle:       len(req.VehicleIDs) > 0,
+		models.AssetTypeImplement:     len(req.ImplementIDs) > 0,
+		models.AssetTypeInventoryItem: len(req.InventoryItemIDs) > 0,
+	}
+	for _, assetType := range []models.AssetType{models.AssetTypeVehicle, models.AssetTypeImplement, models.AssetTypeInventoryItem} {
+		if requested[assetType] && !middleware.CheckPermission(c, h.permissions, assetTagReadPermissions[assetType]) {
+			return nil, false
+		}
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	tags, err := h.service.GetTags(req, currentUser.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoAssetsSelected), errors.Is(err, services.ErrTooManyAssetTags):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAssetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate asset tags"})
+		}
+		return nil, false
+	}
+	return tags, true
+}
+
+// queryIDs lê uma lista de IDs separados por vírgula ("?vehicle_ids=1,2"),
+// aceitando também a chave repetida ("?vehicle_ids=1&vehicle_ids=2").
+func queryIDs(c *gin.Context, key string) ([]uint, error) {
+	var ids []uint
+	for _, value := range c.QueryArray(key) {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(field, 10, 32)
+			if err != nil || id == 0 {
+				return nil, fmt.Errorf("invalid id %q in %s", field, key)
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+// Resolve abre o registro de uma etiqueta lida. Quem pode iniciar jornadas
+// (motoristas) também resolve veículos e implementos, mesmo sem a permissão
+// de leitura do cadastro.
+func (h *AssetTagHandler) Resolve(c *gin.Context) {
+	var resolveIn schemas.AssetTagResolve
+	if err := c.ShouldBindJSON(&resolveIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.ParseTag(resolveIn.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assetType := models.AssetType(tag.Kind)
+	permissions := []models.Permission{assetTagReadPermissions[assetType]}
+	if assetType == models.AssetTypeVehicle || assetType == models.AssetTypeImplement {
+		permissions = append(permissions, models.PermissionJourneyStart)
+	}
+	if !middleware.CheckPermission(c, h.permissions, permissions...) {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	resolved, err := h.service.Resolve(tag, currentUser.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAssetTagOtherOrganization):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAssetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve asset tag"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, resolved)
+}
@@ -1,20 +1,24 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"go-api/internal/core"
 	"go-api/internal/schemas"
 	"go-api/internal/services"
 )
 
 type AuthHandler struct {
-	service services.AuthService
+	service              services.AuthService
+	passwordResetService services.PasswordResetService
 }
 
-func NewAuthHandler(service services.AuthService) *AuthHandler {
-	return &AuthHandler{service: service}
+func NewAuthHandler(service services.AuthService, passwordResetService services.PasswordResetService) *AuthHandler {
+	return &AuthHandler{service: service, passwordResetService: passwordResetService}
 }
 
 type LoginRequest struct {
@@ -29,15 +33,219 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.Login(req.Email, req.Password)
+	token, err := h.service.Login(req.Email, req.Password, clientInfo(c))
 	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
 		if err == services.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
+		if err == services.ErrEmailNotVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
 		return
 	}
 
-	c.JSON(http.StatusOK, schemas.Token{AccessToken: token, TokenType: "bearer"})
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req schemas.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.service.VerifyTwoFactor(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+		if err == services.ErrInvalidMFAToken || err == services.ErrInvalidTwoFactorCode {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify two-factor code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) BeginTwoFactorSetup(c *gin.Context) {
+	var req schemas.TwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := h.service.BeginTwoFactorSetup(req.MFAToken)
+	if err != nil {
+		if err == services.ErrInvalidMFAToken {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *AuthHandler) ActivateTwoFactor(c *gin.Context) {
+	var req schemas.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activation, err := h.service.ActivateTwoFactor(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+		switch err {
+		case services.ErrInvalidMFAToken, services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case services.ErrTwoFactorSetupNotStarted, services.ErrTwoFactorAlreadyEnabled:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate two-factor authentication"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, activation)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req schemas.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		if err == services.ErrInvalidRefreshToken || err == services.ErrRefreshTokenReused {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID := c.GetString("sessionID")
+
+	if err := h.service.Logout(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *AuthHandler) EndImpersonation(c *gin.Context) {
+	token, err := h.service.EndImpersonation(c.GetString("sessionID"))
+	if err != nil {
+		switch err {
+		case services.ErrNotImpersonating:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrSessionRevoked:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end impersonation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// JWKS publica as chaves públicas para que outros serviços validem os tokens.
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, core.PublicJWKS())
+}
+
+func (h *AuthHandler) RequestPasswordRecovery(c *gin.Context) {
+	var req schemas.PasswordRecoveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordResetService.RequestPasswordReset(req.Email, c.ClientIP()); err != nil {
+		if respondEmailRequestThrottled(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request password recovery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If a user with this email exists, a password reset link will be sent."})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req schemas.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.passwordResetService.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		if err == services.ErrInvalidResetToken || errors.Is(err, services.ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+}
+
+// respondLoginThrottled responde 429/423 com Retry-After quando o erro vem do
+// LoginAttemptService.
+func respondLoginThrottled(c *gin.Context, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+	status := http.StatusTooManyRequests
+	if throttled.Locked {
+		status = http.StatusLocked
+	}
+	c.JSON(status, gin.H{"error": throttled.Error()})
+	return true
+}
+
+// respondEmailRequestThrottled responde 429 com Retry-After quando o erro vem
+// do EmailRequestThrottle.
+func respondEmailRequestThrottled(c *gin.Context, err error) bool {
+	var throttled *services.EmailRequestThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": throttled.Error()})
+	return true
+}
+
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Device:    c.GetHeader("X-Device-Name"),
+	}
 }
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type BadgeHandler struct {
+	service services.BadgeService
+}
+
+func NewBadgeHandler(service services.BadgeService) *BadgeHandler {
+	return &BadgeHandler{service: service}
+}
+
+func (h *BadgeHandler) Login(c *gin.Context) {
+	var req schemas.BadgeLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.service.Login(req, clientInfo(c))
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+		if err == services.ErrInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *BadgeHandler) SetPIN(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schemas.BadgePINUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	credentials, err := h.service.SetPIN(uint(userID), currentUser.OrganizationID, req.PIN)
+	if err != nil {
+		switch err {
+		case services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case services.ErrInvalidBadgePIN, services.ErrBadgeNotAllowed:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set badge PIN"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+func (h *BadgeHandler) ClearPIN(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	if err := h.service.ClearPIN(uint(userID), currentUser.OrganizationID); err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear badge PIN"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/repositories"
+)
+
+type CacheHandler struct {
+	cache repositories.CacheRepository
+}
+
+func NewCacheHandler(cache repositories.CacheRepository) *CacheHandler {
+	return &CacheHandler{cache: cache}
+}
+
+// GetStats devolve acertos e falhas do cache desta instância, no total e por
+// tipo de chave.
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
@@ -58,6 +58,9 @@ func (h *DocumentHandler) CreateDocument(c *gin.Context) {
 
 	createdDoc, err := h.service.CreateDocument(docIn, file, currentUser.OrganizationID)
 	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create document"})
 		return
 	}
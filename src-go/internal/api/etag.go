@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/services"
+)
+
+// setETag publica a versão do registro para o cliente devolver no If-Match.
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion lê a versão esperada do If-Match. No PATCH o cabeçalho é
+// obrigatório; no PUT continua opcional para não quebrar clientes antigos.
+// "*" dispensa a checagem. Em caso de erro já responde e retorna false.
+func ifMatchVersion(c *gin.Context) (*uint, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if c.Request.Method == http.MethodPatch {
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+			return nil, false
+		}
+		return nil, true
+	}
+	if header == "*" {
+		return nil, true
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err == nil {
+		var version uint64
+		if version, err = strconv.ParseUint(tag, 10, 64); err == nil {
+			expected := uint(version)
+			return &expected, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+	return nil, false
+}
+
+// respondPatchError trata os erros comuns das atualizações: patch inválido
+// (400) e versão desatualizada (412).
+func respondPatchError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrVersionMismatch):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/services"
+)
+
+// newETagTestRouter responde como um handler de atualização com a versão 3
+// persistida.
+func newETagTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		ifMatch, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+		if ifMatch != nil && *ifMatch != 3 {
+			respondPatchError(c, services.ErrVersionMismatch)
+			return
+		}
+		setETag(c, 4)
+		c.Status(http.StatusOK)
+	}
+	router.PATCH("/vehicles/1", handler)
+	router.PUT("/vehicles/1", handler)
+	return router
+}
+
+func TestIfMatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		method  string
+		ifMatch string
+		want    int
+	}{
+		{"patch without If-Match", http.MethodPatch, "", http.StatusPreconditionRequired},
+		{"put without If-Match", http.MethodPut, "", http.StatusOK},
+		{"current version", http.MethodPatch, `"3"`, http.StatusOK},
+		{"weak tag", http.MethodPatch, `W/"3"`, http.StatusOK},
+		{"wildcard", http.MethodPatch, "*", http.StatusOK},
+		{"stale version", http.MethodPatch, `"2"`, http.StatusPreconditionFailed},
+		{"stale version on put", http.MethodPut, `"2"`, http.StatusPreconditionFailed},
+		{"unquoted tag", http.MethodPatch, "3", http.StatusBadRequest},
+		{"not a version", http.MethodPatch, `"abc"`, http.StatusBadRequest},
+	}
+	router := newETagTestRouter()
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/vehicles/1", nil)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.want, w.Body.String())
+		}
+		if w.Code == http.StatusOK && w.Header().Get("ETag") != `"4"` {
+			t.Errorf("%s: ETag = %q, want \"4\"", tc.name, w.Header().Get("ETag"))
+		}
+	}
+}
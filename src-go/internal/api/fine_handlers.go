@@ -86,6 +86,7 @@ func (h *FineHandler) GetFine(c *gin.Context) {
 		return
 	}
 
+	setETag(c, fine.Version)
 	c.JSON(http.StatusOK, fine)
 }
 
@@ -101,6 +102,10 @@ func (h *FineHandler) UpdateFine(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, exists := c.Get("currentUser")
 	if !exists {
@@ -109,8 +114,11 @@ func (h *FineHandler) UpdateFine(c *gin.Context) {
 	}
 	currentUser := user.(models.User)
 
-	updatedFine, err := h.service.UpdateFine(uint(fineID), fineIn, currentUser)
+	updatedFine, err := h.service.UpdateFine(uint(fineID), fineIn, currentUser, ifMatch)
 	if err != nil {
+		if respondPatchError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fine"})
 		return
 	}
@@ -119,6 +127,7 @@ func (h *FineHandler) UpdateFine(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedFine.Version)
 	c.JSON(http.StatusOK, updatedFine)
 }
 
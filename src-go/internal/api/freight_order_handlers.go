@@ -47,6 +47,9 @@ func (h *FreightOrderHandler) CreateFreightOrder(c *gin.Context) {
 
 	createdOrder, err := h.service.CreateFreightOrder(orderIn, currentUser.OrganizationID)
 	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create freight order"})
 		return
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -54,6 +55,13 @@ func (h *FuelLogHandler) CreateFuelLog(c *gin.Context) {
 
 	createdFuelLog, err := h.service.CreateFuelLog(fuelLogIn, currentUser.(models.User))
 	if err != nil {
+		if errors.Is(err, services.ErrVehicleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+			return
+		}
+		if respondOdometerError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create fuel log"})
 		return
 	}
@@ -86,6 +94,7 @@ func (h *FuelLogHandler) GetFuelLog(c *gin.Context) {
 		return
 	}
 
+	setETag(c, fuelLog.Version)
 	c.JSON(http.StatusOK, fuelLog)
 }
 
@@ -109,9 +118,20 @@ func (h *FuelLogHandler) UpdateFuelLog(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
-	updatedFuelLog, err := h.service.UpdateFuelLog(uint(fuelLogID), orgID, fuelLogIn)
+	updatedFuelLog, err := h.service.UpdateFuelLog(uint(fuelLogID), orgID, fuelLogIn, ifMatch)
 	if err != nil {
+		if errors.Is(err, services.ErrVehicleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+			return
+		}
+		if respondPatchError(c, err) || respondOdometerError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fuel log"})
 		return
 	}
@@ -120,6 +140,7 @@ func (h *FuelLogHandler) UpdateFuelLog(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedFuelLog.Version)
 	c.JSON(http.StatusOK, updatedFuelLog)
 }
 
@@ -93,6 +93,7 @@ func (h *ImplementHandler) GetImplement(c *gin.Context) {
 		return
 	}
 
+	setETag(c, implement.Version)
 	c.JSON(http.StatusOK, schemas.ToImplementPublic(*implement))
 }
 
@@ -108,6 +109,10 @@ func (h *ImplementHandler) UpdateImplement(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, exists := c.Get("currentUser")
 	if !exists {
@@ -117,8 +122,11 @@ func (h *ImplementHandler) UpdateImplement(c *gin.Context) {
 	currentUser := user.(models.User)
 	orgID := currentUser.OrganizationID
 
-	updatedImplement, err := h.service.UpdateImplement(uint(implementID), orgID, implementIn)
+	updatedImplement, err := h.service.UpdateImplement(uint(implementID), orgID, implementIn, ifMatch)
 	if err != nil {
+		if respondPatchError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update implement"})
 		return
 	}
@@ -127,6 +135,7 @@ func (h *ImplementHandler) UpdateImplement(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedImplement.Version)
 	c.JSON(http.StatusOK, schemas.ToImplementPublic(*updatedImplement))
 }
 
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"go-api/internal/config"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+	"go-api/internal/spreadsheet"
+)
+
+// readImportFile lê a planilha enviada no campo "file" (CSV ou XLSX). A
+// primeira linha é o cabeçalho com os nomes dos campos do schema T; cada linha
+// seguinte é convertida e validada pelas mesmas regras de binding do POST.
+// Linhas com erro vão para o relatório; problemas no arquivo em si já são
+// respondidos aqui e ok volta false.
+func readImportFile[T any](c *gin.Context) ([]schemas.ImportRow[T], *schemas.ImportReport, bool) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return nil, nil, false
+	}
+
+	maxBytes := int64(config.AppConfig.IMPORT_MAX_FILE_MB) << 20
+	// Folga para os cabeçalhos do multipart.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File must be at most %d MB", config.AppConfig.IMPORT_MAX_FILE_MB)})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return nil, nil, false
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File must be at most %d MB", config.AppConfig.IMPORT_MAX_FILE_MB)})
+		return nil, nil, false
+	}
+	format, err := spreadsheet.FormatFromFilename(fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return nil, nil, false
+	}
+	defer file.Close()
+	// Cabeçalho mais as linhas permitidas; linhas em branco entre elas contam.
+	lines, err := spreadsheet.Read(file, fileHeader.Size, format, config.AppConfig.IMPORT_MAX_ROWS+1)
+	if errors.Is(err, spreadsheet.ErrTooManyRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The file must have at most %d rows", config.AppConfig.IMPORT_MAX_ROWS)})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	if len(lines) == 0 || spreadsheet.IsBlank(lines[0]) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The first line must be the header"})
+		return nil, nil, false
+	}
+	decoder, err := spreadsheet.NewDecoder(lines[0], new(T))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	report := &schemas.ImportReport{DryRun: dryRun, Errors: []schemas.ImportError{}}
+	var rows []schemas.ImportRow[T]
+	for i, values := range lines[1:] {
+		if spreadsheet.IsBlank(values) {
+			continue
+		}
+		report.Rows++
+		if report.Rows > config.AppConfig.IMPORT_MAX_ROWS {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The file must have at most %d rows", config.AppConfig.IMPORT_MAX_ROWS)})
+			return nil, nil, false
+		}
+
+		line := i + 2
+		var data T
+		rowErrors := decodeImportRow(line, decoder, values, &data)
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+		rows = append(rows, schemas.ImportRow[T]{Line: line, Data: data})
+	}
+	if report.Rows == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The file has no rows to import"})
+		return nil, nil, false
+	}
+	return rows, report, true
+}
+
+func decodeImportRow(line int, decoder *spreadsheet.Decoder, values []string, data any) []schemas.ImportError {
+	var rowErrors []schemas.ImportError
+	failed := map[string]bool{}
+	for _, cellErr := range decoder.Decode(values, data) {
+		failed[cellErr.Column] = true
+		rowErrors = append(rowErrors, schemas.ImportError{Line: line, Column: cellErr.Column, Message: cellErr.Err.Error()})
+	}
+
+	err := binding.Validator.ValidateStruct(data)
+	if err == nil {
+		return rowErrors
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return append(rowErrors, schemas.ImportError{Line: line, Message: err.Error()})
+	}
+	for _, fieldErr := range fieldErrors {
+		column := spreadsheet.ColumnOf(data, fieldErr.StructField())
+		// A célula que não pôde ser convertida já tem o seu erro.
+		if failed[column] {
+			continue
+		}
+		message := fmt.Sprintf("failed the %q validation", fieldErr.Tag())
+		switch fieldErr.Tag() {
+		case "required":
+			message = "is required"
+		case "email":
+			message = "must be a valid email"
+		}
+		rowErrors = append(rowErrors, schemas.ImportError{Line: line, Column: column, Message: message})
+	}
+	return rowErrors
+}
+
+// respondImport responde 422 com o relatório quando alguma linha tem erro
+// (nada foi gravado), 200 no dry run válido e 201 quando as linhas foram criadas.
+func respondImport(c *gin.Context, report *schemas.ImportReport, err error, failure string) {
+	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrEmployeeIDTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+	switch {
+	case len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	case report.DryRun:
+		c.JSON(http.StatusOK, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// exportFormat lê o parâmetro format (csv, o padrão, ou xlsx).
+func exportFormat(c *gin.Context) (spreadsheet.Format, bool) {
+	format, err := spreadsheet.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport grava os registros com o mesmo cabeçalho aceito na importação.
+func writeExport[T any](c *gin.Context, format spreadsheet.Format, name string, records []T) {
+	rows := [][]string{spreadsheet.Columns(new(T))}
+	for i := range records {
+		rows = append(rows, spreadsheet.MarshalRow(&records[i]))
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+	c.Status(http.StatusOK)
+	if err := spreadsheet.Write(c.Writer, format, name, rows); err != nil {
+		c.Error(err)
+	}
+}
@@ -1,9 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -43,15 +43,12 @@ func (h *JourneyHandler) GetJourneys(c *gin.Context) {
 		vehicleID = &id
 	}
 
-	var dateFrom, dateTo *time.Time
-	if val, err := time.Parse("2006-01-02", c.Query("date_from")); err == nil {
-		dateFrom = &val
-	}
-	if val, err := time.Parse("2006-01-02", c.Query("date_to")); err == nil {
-		dateTo = &val
+	dateRange, ok := parseOrganizationDateRange(c)
+	if !ok {
+		return
 	}
 
-	journeys, err := h.service.GetJourneys(orgID, skip, limit, driverID, vehicleID, dateFrom, dateTo)
+	journeys, err := h.service.GetJourneys(orgID, skip, limit, driverID, vehicleID, dateRange)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch journeys"})
 		return
@@ -82,6 +79,9 @@ func (h *JourneyHandler) StartJourney(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if respondQuotaExceeded(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start journey"})
 		return
 	}
@@ -110,8 +110,15 @@ func (h *JourneyHandler) EndJourney(c *gin.Context) {
 		return
 	}
 
-	endedJourney, updatedVehicle, err := h.service.EndJourney(uint(journeyID), orgID, journeyIn.EndMileage, journeyIn.EndEngineHours)
+	endedJourney, updatedVehicle, err := h.service.EndJourney(uint(journeyID), orgID, journeyIn.EndMileage, journeyIn.EndEngineHours, currentUser.ID)
 	if err != nil {
+		if respondOdometerError(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrJourneyAlreadyEnded) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end journey"})
 		return
 	}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type OdometerHandler struct {
+	service services.OdometerService
+}
+
+func NewOdometerHandler(service services.OdometerService) *OdometerHandler {
+	return &OdometerHandler{service: service}
+}
+
+// respondOdometerError trata os erros de validação das leituras, comuns às
+// leituras avulsas, ao fim da jornada e ao abastecimento.
+func respondOdometerError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrInvalidOdometerReading):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOdometerRollback):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOdometerReadingNotFlagged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVersionMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": "Vehicle was modified concurrently, please retry"})
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *OdometerHandler) RecordReading(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+
+	var readingIn schemas.OdometerReadingCreate
+	if err := c.ShouldBindJSON(&readingIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	reading, err := h.service.RecordReading(uint(vehicleID), currentUser.OrganizationID, readingIn, currentUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrVehicleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+			return
+		}
+		if respondOdometerError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record odometer reading"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reading)
+}
+
+// GetTimeline aceita date_from e date_to (YYYY-MM-DD, inclusive) ou fiscal_year
+// no fuso da organização e devolve as leituras em ordem cronológica, com a origem de cada uma.
+func (h *OdometerHandler) GetTimeline(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+	dateRange, ok := parseOrganizationDateRange(c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	timeline, err := h.service.GetTimeline(uint(vehicleID), currentUser.OrganizationID, dateRange)
+	if err != nil {
+		if errors.Is(err, services.ErrVehicleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch odometer readings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// AcceptReading confirma uma leitura sinalizada como salto implausível.
+func (h *OdometerHandler) AcceptReading(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+	readingID, err := strconv.Atoi(c.Param("reading_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reading ID"})
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	reading, err := h.service.AcceptReading(uint(vehicleID), uint(readingID), currentUser.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVehicleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+		case errors.Is(err, services.ErrOdometerReadingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Odometer reading not found"})
+		default:
+			if !respondOdometerError(c, err) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept odometer reading"})
+			}
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reading)
+}
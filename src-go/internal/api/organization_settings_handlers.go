@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type OrganizationSettingsHandler struct {
+	service services.OrganizationSettingsService
+}
+
+func NewOrganizationSettingsHandler(service services.OrganizationSettingsService) *OrganizationSettingsHandler {
+	return &OrganizationSettingsHandler{service: service}
+}
+
+func (h *OrganizationSettingsHandler) GetSettings(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	settings, err := h.service.GetSettings(currentUser.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization settings"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *OrganizationSettingsHandler) UpdateSettings(c *gin.Context) {
+	var settingsIn schemas.OrganizationSettingsUpdate
+	if err := c.ShouldBindJSON(&settingsIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	settings, err := h.service.UpdateSettings(currentUser.OrganizationID, settingsIn)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTimeZone, services.ErrInvalidCurrency, services.ErrInvalidUsageUnit,
+			services.ErrInvalidLocale, services.ErrInvalidFiscalYearStart:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization settings"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// parseOrganizationDateRange lê o período das listagens da organização:
+// date_from/date_to como no parseDateRange ou fiscal_year (o ano civil em que
+// o ano fiscal da organização começa), nunca os dois juntos.
+func parseOrganizationDateRange(c *gin.Context) (schemas.DateRange, bool) {
+	dateFrom, dateTo, ok := parseDateRange(c)
+	if !ok {
+		return schemas.DateRange{}, false
+	}
+	dateRange := schemas.DateRange{From: dateFrom, To: dateTo}
+	if val := c.Query("fiscal_year"); val != "" {
+		year, err := strconv.Atoi(val)
+		if err != nil || year < 1900 || year > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fiscal_year must be a year such as 2025"})
+			return schemas.DateRange{}, false
+		}
+		if dateFrom != nil || dateTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "use either fiscal_year or date_from/date_to"})
+			return schemas.DateRange{}, false
+		}
+		dateRange.FiscalYear = &year
+	}
+	return dateRange, true
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -51,6 +52,7 @@ func (h *PartHandler) GetPart(c *gin.Context) {
 		return
 	}
 
+	setETag(c, part.Version)
 	c.JSON(http.StatusOK, part)
 }
 
@@ -80,12 +82,19 @@ func (h *PartHandler) UpdatePart(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, _ := c.Get("currentUser")
 	currentUser := user.(models.User)
 
-	updatedPart, err := h.service.UpdatePart(uint(partID), partIn, currentUser.OrganizationID)
+	updatedPart, err := h.service.UpdatePart(uint(partID), partIn, currentUser.OrganizationID, ifMatch)
 	if err != nil {
+		if respondPatchError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update part"})
 		return
 	}
@@ -94,9 +103,65 @@ func (h *PartHandler) UpdatePart(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedPart.Version)
 	c.JSON(http.StatusOK, updatedPart)
 }
 
+// UploadPhoto recebe a foto no campo "file" (JPEG, PNG ou GIF) e substitui a
+// anterior; a miniatura para as listagens vai em PhotoThumbnailURL.
+func (h *PartHandler) UploadPhoto(c *gin.Context) {
+	partID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	file, ok := readPhotoFile(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	part, err := h.service.UploadPhoto(uint(partID), currentUser.OrganizationID, file, ifMatch)
+	h.respondPhoto(c, part, err, "Failed to upload part photo")
+}
+
+func (h *PartHandler) DeletePhoto(c *gin.Context) {
+	partID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	part, err := h.service.DeletePhoto(uint(partID), currentUser.OrganizationID, ifMatch)
+	h.respondPhoto(c, part, err, "Failed to delete part photo")
+}
+
+func (h *PartHandler) respondPhoto(c *gin.Context, part *models.Part, err error, failure string) {
+	if err != nil {
+		if respondPhotoError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+	if part == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Part not found"})
+		return
+	}
+
+	setETag(c, part.Version)
+	c.JSON(http.StatusOK, part)
+}
+
 func (h *PartHandler) DeletePart(c *gin.Context) {
 	partID, _ := strconv.Atoi(c.Param("id"))
 	user, _ := c.Get("currentUser")
@@ -123,6 +188,10 @@ func (h *PartHandler) AddInventoryItems(c *gin.Context) {
 	currentUser := user.(models.User)
 
 	err := h.service.AddInventoryItems(uint(partID), payload, currentUser.OrganizationID, currentUser.ID)
+	if errors.Is(err, services.ErrPartNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Part not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add items"})
 		return
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type PermissionHandler struct {
+	service services.PermissionService
+}
+
+func NewPermissionHandler(service services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{service: service}
+}
+
+func (h *PermissionHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, models.AllPermissions)
+}
+
+// GetMyPermissions permite ao frontend esconder ações que o usuário não pode executar.
+func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	permissions, err := h.service.GetRolePermissions(currentUser.OrganizationID, currentUser.Role)
+	if err != nil {
+		if err == services.ErrInvalidRole {
+			c.JSON(http.StatusOK, schemas.RolePermissions{Role: currentUser.Role, Permissions: []models.Permission{}})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+	c.JSON(http.StatusOK, schemas.RolePermissions{Role: currentUser.Role, Permissions: permissions})
+}
+
+func (h *PermissionHandler) GetRolePermissions(c *gin.Context) {
+	role := models.UserRole(c.Param("role"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	permissions, err := h.service.GetRolePermissions(currentUser.OrganizationID, role)
+	if err != nil {
+		if err == services.ErrInvalidRole {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+	c.JSON(http.StatusOK, schemas.RolePermissions{Role: role, Permissions: permissions})
+}
+
+func (h *PermissionHandler) UpdateRolePermissions(c *gin.Context) {
+	role := models.UserRole(c.Param("role"))
+	var permissionsIn schemas.RolePermissionsUpdate
+	if err := c.ShouldBindJSON(&permissionsIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	permissions, err := h.service.SetRolePermissions(currentUser.OrganizationID, role, permissionsIn.Permissions)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidRole, services.ErrInvalidPermission, services.ErrCannotRevokePermissionManage:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permissions"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, schemas.RolePermissions{Role: role, Permissions: permissions})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/config"
+	"go-api/internal/imaging"
+	"go-api/internal/services"
+)
+
+// readPhotoFile lê a imagem enviada no campo "file". O tipo é conferido pelo
+// conteúdo no serviço; aqui só o tamanho. Em caso de erro já responde.
+func readPhotoFile(c *gin.Context) (*multipart.FileHeader, bool) {
+	maxBytes := int64(config.AppConfig.PHOTO_MAX_FILE_MB) << 20
+	tooLarge := fmt.Sprintf("Photo must be at most %d MB", config.AppConfig.PHOTO_MAX_FILE_MB)
+	// Folga para os cabeçalhos do multipart.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return nil, false
+	}
+	if file.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+	return file, true
+}
+
+// respondPhotoError responde 415 para formatos não aceitos, 400 para imagens
+// inválidas e 412 para versão desatualizada.
+func respondPhotoError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPhoto):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return respondPatchError(c, err)
+	}
+	return true
+}
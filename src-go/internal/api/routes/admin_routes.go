@@ -8,10 +8,16 @@ import (
 func RegisterAdminRoutes(handler *api.AdminHandler) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
 		router.GET("/organizations", handler.GetOrganizations)
+		router.POST("/organizations", handler.CreateOrganization)
 		router.PUT("/organizations/:id", handler.UpdateOrganization)
+		router.POST("/organizations/:id/suspend", handler.SuspendOrganization)
+		router.POST("/organizations/:id/reactivate", handler.ReactivateOrganization)
+		router.DELETE("/organizations/:id", handler.DeleteOrganization)
 		router.GET("/users/all", handler.GetAllUsers)
 		router.GET("/users/demo", handler.GetDemoUsers)
 		router.POST("/users/:id/activate", handler.ActivateUser)
 		router.POST("/users/:id/impersonate", handler.ImpersonateUser)
+		router.GET("/impersonations", handler.GetImpersonationSessions)
+		router.POST("/users/:id/unlock", handler.UnlockUser)
 	}
 }
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+)
+
+// RegisterAnalyticsRoutes é registrado no grupo /admin, restrito ao super admin.
+func RegisterAnalyticsRoutes(handler *api.AnalyticsHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/analytics/organizations", handler.GetOrganizationAnalytics)
+		router.GET("/analytics/conversions", handler.GetConversions)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterAPIKeyRoutes(handler *api.APIKeyHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/api-keys", require(models.PermissionAPIKeyManage, models.ScopeNone), handler.GetAPIKeys)
+		router.POST("/api-keys", require(models.PermissionAPIKeyManage, models.ScopeNone), handler.CreateAPIKey)
+		router.DELETE("/api-keys/:id", require(models.PermissionAPIKeyManage, models.ScopeNone), handler.RevokeAPIKey)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+)
+
+// RegisterAssetTagRoutes não usa o PermissionGuard: as permissões dependem dos
+// tipos de registro e são conferidas pelo AssetTagHandler.
+func RegisterAssetTagRoutes(handler *api.AssetTagHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/asset-tags", handler.GetTags)
+		router.GET("/asset-tags/labels", handler.GetLabelSheet)
+		router.POST("/asset-tags/resolve", handler.Resolve)
+	}
+}
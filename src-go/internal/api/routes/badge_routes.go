@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterBadgeLoginRoutes(handler *api.BadgeHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.POST("/login/badge", handler.Login)
+	}
+}
+
+func RegisterBadgeRoutes(handler *api.BadgeHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.PUT("/users/:id/badge-pin", require(models.PermissionUserUpdate, models.ScopeNone), handler.SetPIN)
+		router.DELETE("/users/:id/badge-pin", require(models.PermissionUserUpdate, models.ScopeNone), handler.ClearPIN)
+	}
+}
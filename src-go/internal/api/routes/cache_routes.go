@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+)
+
+// RegisterCacheRoutes é registrado no grupo /admin, restrito ao super admin.
+func RegisterCacheRoutes(handler *api.CacheHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/cache/stats", handler.GetStats)
+	}
+}
@@ -3,12 +3,14 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterDocumentRoutes(handler *api.DocumentHandler) func(router *gin.RouterGroup) {
+func RegisterDocumentRoutes(handler *api.DocumentHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/documents", handler.GetDocuments)
-		router.POST("/documents", handler.CreateDocument)
-		router.DELETE("/documents/:id", handler.DeleteDocument)
+		router.GET("/documents", require(models.PermissionDocumentRead, models.ScopeDocumentsRead), handler.GetDocuments)
+		router.POST("/documents", require(models.PermissionDocumentCreate, models.ScopeDocumentsWrite), handler.CreateDocument)
+		router.DELETE("/documents/:id", require(models.PermissionDocumentDelete, models.ScopeDocumentsWrite), handler.DeleteDocument)
 	}
 }
@@ -3,14 +3,17 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterFineRoutes(handler *api.FineHandler) func(router *gin.RouterGroup) {
+func RegisterFineRoutes(handler *api.FineHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/fines", handler.GetFines)
-		router.POST("/fines", handler.CreateFine)
-		router.GET("/fines/:id", handler.GetFine)
-		router.PUT("/fines/:id", handler.UpdateFine)
-		router.DELETE("/fines/:id", handler.DeleteFine)
+		router.GET("/fines", require(models.PermissionFineRead, models.ScopeFinesRead), handler.GetFines)
+		router.POST("/fines", require(models.PermissionFineCreate, models.ScopeFinesWrite), handler.CreateFine)
+		router.GET("/fines/:id", require(models.PermissionFineRead, models.ScopeFinesRead), handler.GetFine)
+		router.PUT("/fines/:id", require(models.PermissionFineUpdate, models.ScopeFinesWrite), handler.UpdateFine)
+		router.PATCH("/fines/:id", require(models.PermissionFineUpdate, models.ScopeFinesWrite), handler.UpdateFine)
+		router.DELETE("/fines/:id", require(models.PermissionFineDelete, models.ScopeFinesWrite), handler.DeleteFine)
 	}
 }
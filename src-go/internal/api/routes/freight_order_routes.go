@@ -3,17 +3,19 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterFreightOrderRoutes(handler *api.FreightOrderHandler) func(router *gin.RouterGroup) {
+func RegisterFreightOrderRoutes(handler *api.FreightOrderHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/freight-orders", handler.GetFreightOrders)
-		router.POST("/freight-orders", handler.CreateFreightOrder)
-		router.GET("/freight-orders/open", handler.GetOpenFreightOrders)
-		router.GET("/freight-orders/my-pending", handler.GetMyPendingFreightOrders)
-		router.GET("/freight-orders/:id", handler.GetFreightOrderByID)
-		router.PUT("/freight-orders/:id/claim", handler.ClaimFreightOrder)
-		router.POST("/freight-orders/:order_id/start-leg/:stop_point_id", handler.StartJourneyForStop)
-		router.PUT("/freight-orders/:order_id/complete-stop/:stop_point_id", handler.CompleteStopPoint)
+		router.GET("/freight-orders", require(models.PermissionFreightOrderRead, models.ScopeFreightOrdersRead), handler.GetFreightOrders)
+		router.POST("/freight-orders", require(models.PermissionFreightOrderCreate, models.ScopeFreightOrdersWrite), handler.CreateFreightOrder)
+		router.GET("/freight-orders/open", require(models.PermissionFreightOrderRead, models.ScopeFreightOrdersRead), handler.GetOpenFreightOrders)
+		router.GET("/freight-orders/my-pending", require(models.PermissionFreightOrderRead, models.ScopeFreightOrdersRead), handler.GetMyPendingFreightOrders)
+		router.GET("/freight-orders/:id", require(models.PermissionFreightOrderRead, models.ScopeFreightOrdersRead), handler.GetFreightOrderByID)
+		router.PUT("/freight-orders/:id/claim", require(models.PermissionFreightOrderClaim, models.ScopeFreightOrdersWrite), handler.ClaimFreightOrder)
+		router.POST("/freight-orders/:order_id/start-leg/:stop_point_id", require(models.PermissionFreightOrderExecute, models.ScopeFreightOrdersWrite), handler.StartJourneyForStop)
+		router.PUT("/freight-orders/:order_id/complete-stop/:stop_point_id", require(models.PermissionFreightOrderExecute, models.ScopeFreightOrdersWrite), handler.CompleteStopPoint)
 	}
 }
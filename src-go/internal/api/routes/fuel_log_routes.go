@@ -3,14 +3,17 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterFuelLogRoutes(handler *api.FuelLogHandler) func(router *gin.RouterGroup) {
+func RegisterFuelLogRoutes(handler *api.FuelLogHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/fuel-logs", handler.GetFuelLogs)
-		router.POST("/fuel-logs", handler.CreateFuelLog)
-		router.GET("/fuel-logs/:id", handler.GetFuelLog)
-		router.PUT("/fuel-logs/:id", handler.UpdateFuelLog)
-		router.DELETE("/fuel-logs/:id", handler.DeleteFuelLog)
+		router.GET("/fuel-logs", require(models.PermissionFuelLogRead, models.ScopeFuelLogsRead), handler.GetFuelLogs)
+		router.POST("/fuel-logs", require(models.PermissionFuelLogCreate, models.ScopeFuelLogsWrite), handler.CreateFuelLog)
+		router.GET("/fuel-logs/:id", require(models.PermissionFuelLogRead, models.ScopeFuelLogsRead), handler.GetFuelLog)
+		router.PUT("/fuel-logs/:id", require(models.PermissionFuelLogUpdate, models.ScopeFuelLogsWrite), handler.UpdateFuelLog)
+		router.PATCH("/fuel-logs/:id", require(models.PermissionFuelLogUpdate, models.ScopeFuelLogsWrite), handler.UpdateFuelLog)
+		router.DELETE("/fuel-logs/:id", require(models.PermissionFuelLogDelete, models.ScopeFuelLogsWrite), handler.DeleteFuelLog)
 	}
 }
@@ -3,14 +3,17 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterImplementRoutes(handler *api.ImplementHandler) func(router *gin.RouterGroup) {
+func RegisterImplementRoutes(handler *api.ImplementHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.POST("/implements", handler.CreateImplement)
-		router.GET("/implements", handler.GetImplements)
-		router.GET("/implements/:id", handler.GetImplement)
-		router.PUT("/implements/:id", handler.UpdateImplement)
-		router.DELETE("/implements/:id", handler.DeleteImplement)
+		router.POST("/implements", require(models.PermissionImplementCreate, models.ScopeImplementsWrite), handler.CreateImplement)
+		router.GET("/implements", require(models.PermissionImplementRead, models.ScopeImplementsRead), handler.GetImplements)
+		router.GET("/implements/:id", require(models.PermissionImplementRead, models.ScopeImplementsRead), handler.GetImplement)
+		router.PUT("/implements/:id", require(models.PermissionImplementUpdate, models.ScopeImplementsWrite), handler.UpdateImplement)
+		router.PATCH("/implements/:id", require(models.PermissionImplementUpdate, models.ScopeImplementsWrite), handler.UpdateImplement)
+		router.DELETE("/implements/:id", require(models.PermissionImplementDelete, models.ScopeImplementsWrite), handler.DeleteImplement)
 	}
 }
@@ -3,13 +3,15 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterJourneyRoutes(handler *api.JourneyHandler) func(router *gin.RouterGroup) {
+func RegisterJourneyRoutes(handler *api.JourneyHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/journeys", handler.GetJourneys)
-		router.POST("/journeys/start", handler.StartJourney)
-		router.PUT("/journeys/:id/end", handler.EndJourney)
-		router.DELETE("/journeys/:id", handler.DeleteJourney)
+		router.GET("/journeys", require(models.PermissionJourneyRead, models.ScopeJourneysRead), handler.GetJourneys)
+		router.POST("/journeys/start", require(models.PermissionJourneyStart, models.ScopeJourneysWrite), handler.StartJourney)
+		router.PUT("/journeys/:id/end", require(models.PermissionJourneyEnd, models.ScopeJourneysWrite), handler.EndJourney)
+		router.DELETE("/journeys/:id", require(models.PermissionJourneyDelete, models.ScopeJourneysWrite), handler.DeleteJourney)
 	}
 }
@@ -3,10 +3,30 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
 )
 
 func RegisterLoginRoutes(handler *api.AuthHandler) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
 		router.POST("/login/access-token", handler.Login)
+		router.POST("/login/refresh", handler.Refresh)
+		router.POST("/login/2fa/verify", handler.VerifyTwoFactor)
+		router.POST("/login/2fa/setup", handler.BeginTwoFactorSetup)
+		router.POST("/login/2fa/activate", handler.ActivateTwoFactor)
+		router.POST("/login/password-recovery", handler.RequestPasswordRecovery)
+		router.POST("/login/reset-password", handler.ResetPassword)
+	}
+}
+
+func RegisterLogoutRoutes(handler *api.AuthHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.POST("/logout", middleware.SessionOnly(), handler.Logout)
+		router.POST("/impersonation/end", middleware.SessionOnly(), handler.EndImpersonation)
+	}
+}
+
+func RegisterWellKnownRoutes(handler *api.AuthHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/.well-known/jwks.json", handler.JWKS)
 	}
 }
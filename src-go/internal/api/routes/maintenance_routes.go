@@ -3,16 +3,18 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterMaintenanceRoutes(handler *api.MaintenanceHandler) func(router *gin.RouterGroup) {
+func RegisterMaintenanceRoutes(handler *api.MaintenanceHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/maintenance", handler.GetMaintenanceRequests)
-		router.POST("/maintenance", handler.CreateMaintenanceRequest)
-		router.GET("/maintenance/:id", handler.GetMaintenanceRequest)
-		router.PUT("/maintenance/:id/status", handler.UpdateMaintenanceRequestStatus)
-		router.DELETE("/maintenance/:id", handler.DeleteMaintenanceRequest)
-		router.GET("/maintenance/:id/comments", handler.GetMaintenanceComments)
-		router.POST("/maintenance/:id/comments", handler.CreateMaintenanceComment)
+		router.GET("/maintenance", require(models.PermissionMaintenanceRead, models.ScopeMaintenanceRead), handler.GetMaintenanceRequests)
+		router.POST("/maintenance", require(models.PermissionMaintenanceCreate, models.ScopeMaintenanceWrite), handler.CreateMaintenanceRequest)
+		router.GET("/maintenance/:id", require(models.PermissionMaintenanceRead, models.ScopeMaintenanceRead), handler.GetMaintenanceRequest)
+		router.PUT("/maintenance/:id/status", require(models.PermissionMaintenanceApprove, models.ScopeMaintenanceWrite), handler.UpdateMaintenanceRequestStatus)
+		router.DELETE("/maintenance/:id", require(models.PermissionMaintenanceDelete, models.ScopeMaintenanceWrite), handler.DeleteMaintenanceRequest)
+		router.GET("/maintenance/:id/comments", require(models.PermissionMaintenanceRead, models.ScopeMaintenanceRead), handler.GetMaintenanceComments)
+		router.POST("/maintenance/:id/comments", require(models.PermissionMaintenanceComment, models.ScopeMaintenanceWrite), handler.CreateMaintenanceComment)
 	}
 }
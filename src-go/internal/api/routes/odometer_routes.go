@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterOdometerRoutes(handler *api.OdometerHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/vehicles/:id/odometer-readings", require(models.PermissionVehicleRead, models.ScopeReportsRead), handler.GetTimeline)
+		router.POST("/vehicles/:id/odometer-readings", require(models.PermissionVehicleUpdate, models.ScopeTelemetryWrite), handler.RecordReading)
+		router.POST("/vehicles/:id/odometer-readings/:reading_id/accept", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.AcceptReading)
+	}
+}
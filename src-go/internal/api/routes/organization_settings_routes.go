@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterOrganizationSettingsRoutes(handler *api.OrganizationSettingsHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/settings", require(models.PermissionSettingsRead, models.ScopeNone), handler.GetSettings)
+		router.PUT("/settings", require(models.PermissionSettingsUpdate, models.ScopeNone), handler.UpdateSettings)
+	}
+}
@@ -3,18 +3,23 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterPartRoutes(handler *api.PartHandler) func(router *gin.RouterGroup) {
+func RegisterPartRoutes(handler *api.PartHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/parts", handler.GetParts)
-		router.POST("/parts", handler.CreatePart)
-		router.GET("/parts/:id", handler.GetPart)
-		router.PUT("/parts/:id", handler.UpdatePart)
-		router.DELETE("/parts/:id", handler.DeletePart)
-		router.POST("/parts/:id/add-items", handler.AddInventoryItems)
-		router.PUT("/items/:item_id/set-status", handler.SetInventoryItemStatus)
-		router.GET("/parts/:id/items", handler.GetItemsForPart)
-		router.GET("/parts/:id/history", handler.GetPartHistory)
+		router.GET("/parts", require(models.PermissionPartRead, models.ScopePartsRead), handler.GetParts)
+		router.POST("/parts", require(models.PermissionPartCreate, models.ScopePartsWrite), handler.CreatePart)
+		router.GET("/parts/:id", require(models.PermissionPartRead, models.ScopePartsRead), handler.GetPart)
+		router.PUT("/parts/:id", require(models.PermissionPartUpdate, models.ScopePartsWrite), handler.UpdatePart)
+		router.PATCH("/parts/:id", require(models.PermissionPartUpdate, models.ScopePartsWrite), handler.UpdatePart)
+		router.DELETE("/parts/:id", require(models.PermissionPartDelete, models.ScopePartsWrite), handler.DeletePart)
+		router.PUT("/parts/:id/photo", require(models.PermissionPartUpdate, models.ScopePartsWrite), handler.UploadPhoto)
+		router.DELETE("/parts/:id/photo", require(models.PermissionPartUpdate, models.ScopePartsWrite), handler.DeletePhoto)
+		router.POST("/parts/:id/add-items", require(models.PermissionPartManageInventory, models.ScopePartsWrite), handler.AddInventoryItems)
+		router.PUT("/items/:item_id/set-status", require(models.PermissionPartManageInventory, models.ScopePartsWrite), handler.SetInventoryItemStatus)
+		router.GET("/parts/:id/items", require(models.PermissionPartRead, models.ScopePartsRead), handler.GetItemsForPart)
+		router.GET("/parts/:id/history", require(models.PermissionPartRead, models.ScopePartsRead), handler.GetPartHistory)
 	}
 }
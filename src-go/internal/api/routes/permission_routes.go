@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterPermissionRoutes(handler *api.PermissionHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/permissions/me", middleware.SessionOnly(), handler.GetMyPermissions)
+		router.GET("/permissions", require(models.PermissionPermissionManage, models.ScopeNone), handler.GetPermissions)
+		router.GET("/permissions/roles/:role", require(models.PermissionPermissionManage, models.ScopeNone), handler.GetRolePermissions)
+		router.PUT("/permissions/roles/:role", require(models.PermissionPermissionManage, models.ScopeNone), handler.UpdateRolePermissions)
+	}
+}
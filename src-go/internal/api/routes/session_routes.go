@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterSessionRoutes(handler *api.SessionHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/sessions", middleware.SessionOnly(), handler.GetMySessions)
+		router.DELETE("/sessions/:session_id", middleware.SessionOnly(), handler.RevokeMySession)
+		router.GET("/users/:id/sessions", require(models.PermissionSessionManage, models.ScopeNone), handler.GetUserSessions)
+		router.DELETE("/users/:id/sessions", require(models.PermissionSessionManage, models.ScopeNone), handler.RevokeAllUserSessions)
+		router.DELETE("/users/:id/sessions/:session_id", require(models.PermissionSessionManage, models.ScopeNone), handler.RevokeUserSession)
+	}
+}
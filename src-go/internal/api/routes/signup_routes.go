@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+)
+
+func RegisterSignupRoutes(handler *api.SignupHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.POST("/signup", handler.Signup)
+		router.POST("/signup/verify-email", handler.VerifyEmail)
+		router.POST("/signup/resend-verification", handler.ResendVerification)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
+)
+
+func RegisterSSOLoginRoutes(handler *api.SSOHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/login/sso/:organization_id/authorize", handler.BeginLogin)
+		router.POST("/login/sso/callback", handler.Callback)
+	}
+}
+
+func RegisterSSOConfigRoutes(handler *api.SSOHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.GET("/sso/config", require(models.PermissionSSOManage, models.ScopeNone), handler.GetConfig)
+		router.PUT("/sso/config", require(models.PermissionSSOManage, models.ScopeNone), handler.UpdateConfig)
+		router.DELETE("/sso/config", require(models.PermissionSSOManage, models.ScopeNone), handler.DeleteConfig)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-api/internal/api"
+	"go-api/internal/middleware"
+)
+
+func RegisterTwoFactorRoutes(handler *api.TwoFactorHandler) func(router *gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		router.POST("/2fa/setup", middleware.SessionOnly(), handler.BeginSetup)
+		router.POST("/2fa/activate", middleware.SessionOnly(), handler.Activate)
+		router.POST("/2fa/disable", middleware.SessionOnly(), handler.Disable)
+		router.POST("/2fa/recovery-codes", middleware.SessionOnly(), handler.RegenerateRecoveryCodes)
+	}
+}
@@ -3,14 +3,23 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterUserRoutes(handler *api.UserHandler) func(router *gin.RouterGroup) {
+func RegisterUserRoutes(handler *api.UserHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/users", handler.GetUsers)
-		router.POST("/users", handler.CreateUser)
-		router.GET("/users/:id", handler.GetUser)
-		router.PUT("/users/:id", handler.UpdateUser)
-		router.DELETE("/users/:id", handler.DeleteUser)
+		router.GET("/users", require(models.PermissionUserRead, models.ScopeUsersRead), handler.GetUsers)
+		router.POST("/users", require(models.PermissionUserCreate, models.ScopeUsersWrite), handler.CreateUser)
+		router.POST("/users/import", require(models.PermissionUserCreate, models.ScopeUsersWrite), handler.ImportUsers)
+		router.GET("/users/export", require(models.PermissionUserRead, models.ScopeUsersRead), handler.ExportUsers)
+		router.GET("/users/:id", require(models.PermissionUserRead, models.ScopeUsersRead), handler.GetUser)
+		router.PUT("/users/:id", require(models.PermissionUserUpdate, models.ScopeUsersWrite), handler.UpdateUser)
+		router.PATCH("/users/:id", require(models.PermissionUserUpdate, models.ScopeUsersWrite), handler.UpdateUser)
+		router.DELETE("/users/:id", require(models.PermissionUserDelete, models.ScopeUsersWrite), handler.DeleteUser)
+		router.PUT("/users/:id/avatar", require(models.PermissionUserUpdate, models.ScopeUsersWrite), handler.UploadAvatar)
+		router.DELETE("/users/:id/avatar", require(models.PermissionUserUpdate, models.ScopeUsersWrite), handler.DeleteAvatar)
+		router.POST("/users/:id/unlock", require(models.PermissionUserUnlock, models.ScopeNone), handler.UnlockUser)
+		router.GET("/users/:id/lock-events", require(models.PermissionUserRead, models.ScopeUsersRead), handler.GetUserLockEvents)
 	}
 }
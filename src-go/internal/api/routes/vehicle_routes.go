@@ -3,14 +3,23 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"go-api/internal/api"
+	"go-api/internal/middleware"
+	"go-api/internal/models"
 )
 
-func RegisterVehicleRoutes(handler *api.VehicleHandler) func(router *gin.RouterGroup) {
+func RegisterVehicleRoutes(handler *api.VehicleHandler, require middleware.PermissionGuard) func(router *gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
-		router.GET("/vehicles", handler.GetVehicles)
-		router.POST("/vehicles", handler.CreateVehicle)
-		router.GET("/vehicles/:id", handler.GetVehicle)
-		router.PUT("/vehicles/:id", handler.UpdateVehicle)
-		router.DELETE("/vehicles/:id", handler.DeleteVehicle)
+		router.GET("/vehicles", require(models.PermissionVehicleRead, models.ScopeVehiclesRead), handler.GetVehicles)
+		router.POST("/vehicles", require(models.PermissionVehicleCreate, models.ScopeVehiclesWrite), handler.CreateVehicle)
+		router.POST("/vehicles/import", require(models.PermissionVehicleCreate, models.ScopeVehiclesWrite), handler.ImportVehicles)
+		router.GET("/vehicles/export", require(models.PermissionVehicleRead, models.ScopeVehiclesRead), handler.ExportVehicles)
+		router.GET("/vehicles/:id", require(models.PermissionVehicleRead, models.ScopeVehiclesRead), handler.GetVehicle)
+		router.PUT("/vehicles/:id", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.UpdateVehicle)
+		router.PATCH("/vehicles/:id", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.UpdateVehicle)
+		router.DELETE("/vehicles/:id", require(models.PermissionVehicleDelete, models.ScopeVehiclesWrite), handler.DeleteVehicle)
+		router.PUT("/vehicles/:id/photo", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.UploadPhoto)
+		router.DELETE("/vehicles/:id/photo", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.DeletePhoto)
+		router.POST("/vehicles/:id/status", require(models.PermissionVehicleUpdate, models.ScopeVehiclesWrite), handler.ChangeVehicleStatus)
+		router.GET("/vehicles/:id/status-history", require(models.PermissionVehicleRead, models.ScopeReportsRead), handler.GetVehicleStatusHistory)
 	}
 }
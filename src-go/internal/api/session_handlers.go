@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+type SessionHandler struct {
+	authService services.AuthService
+}
+
+func NewSessionHandler(authService services.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+	h.listSessions(c, currentUser.ID, currentUser.OrganizationID)
+}
+
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+	h.revokeSession(c, currentUser.ID, currentUser.OrganizationID)
+}
+
+func (h *SessionHandler) GetUserSessions(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+	h.listSessions(c, uint(userID), currentUser.OrganizationID)
+}
+
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+	h.revokeSession(c, uint(userID), currentUser.OrganizationID)
+}
+
+func (h *SessionHandler) RevokeAllUserSessions(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Param("id"))
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	if err := h.authService.RevokeAllUserSessions(uint(userID), currentUser.OrganizationID); err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *SessionHandler) listSessions(c *gin.Context, userID, orgID uint) {
+	sessions, err := h.authService.GetUserSessions(userID, orgID, c.GetString("sessionID"))
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) revokeSession(c *gin.Context, userID, orgID uint) {
+	sessionID, _ := strconv.Atoi(c.Param("session_id"))
+
+	if err := h.authService.RevokeUserSession(userID, orgID, uint(sessionID)); err != nil {
+		switch err {
+		case services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case services.ErrSessionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		}
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type SignupHandler struct {
+	service services.SignupService
+}
+
+func NewSignupHandler(service services.SignupService) *SignupHandler {
+	return &SignupHandler{service: service}
+}
+
+func (h *SignupHandler) Signup(c *gin.Context) {
+	var signupIn schemas.SignupRequest
+	if err := c.ShouldBindJSON(&signupIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A mesma resposta vale para e-mails novos e já cadastrados.
+	if err := h.service.Signup(signupIn, c.ClientIP()); err != nil {
+		if respondEmailRequestThrottled(c, err) {
+			return
+		}
+		switch {
+		case err == services.ErrInvalidSector, errors.Is(err, services.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign up"})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Check your inbox to confirm your email and activate the account."})
+}
+
+func (h *SignupHandler) VerifyEmail(c *gin.Context) {
+	var req schemas.EmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.VerifyEmail(req.Token); err != nil {
+		if err == services.ErrInvalidVerificationToken {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *SignupHandler) ResendVerification(c *gin.Context) {
+	var req schemas.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ResendVerification(req.Email, c.ClientIP()); err != nil {
+		if respondEmailRequestThrottled(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend verification email"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account is pending verification, a new link will be sent."})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type SSOHandler struct {
+	service services.SSOService
+}
+
+func NewSSOHandler(service services.SSOService) *SSOHandler {
+	return &SSOHandler{service: service}
+}
+
+func (h *SSOHandler) BeginLogin(c *gin.Context) {
+	orgID, err := strconv.Atoi(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	authorization, err := h.service.BeginLogin(uint(orgID))
+	if err != nil {
+		switch err {
+		case services.ErrSSONotConfigured:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrSSOLoginFailed:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, authorization)
+}
+
+func (h *SSOHandler) Callback(c *gin.Context) {
+	var req schemas.SSOCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.service.CompleteLogin(req.Code, req.State, clientInfo(c))
+	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
+		switch err {
+		case services.ErrInvalidSSOState:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrSSOLoginFailed:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case services.ErrSSOUserNotAllowed:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case services.ErrSSONotConfigured:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete single sign-on"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *SSOHandler) GetConfig(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	ssoConfig, err := h.service.GetConfig(currentUser.OrganizationID)
+	if err != nil {
+		if err == services.ErrSSONotConfigured {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch single sign-on configuration"})
+		return
+	}
+	c.JSON(http.StatusOK, ssoConfig)
+}
+
+func (h *SSOHandler) UpdateConfig(c *gin.Context) {
+	var configIn schemas.SSOConfigUpdate
+	if err := c.ShouldBindJSON(&configIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	ssoConfig, err := h.service.UpdateConfig(currentUser.OrganizationID, configIn)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSSOConfig) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update single sign-on configuration"})
+		return
+	}
+	c.JSON(http.StatusOK, ssoConfig)
+}
+
+func (h *SSOHandler) DeleteConfig(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	if err := h.service.DeleteConfig(currentUser.OrganizationID); err != nil {
+		if err == services.ErrSSONotConfigured {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete single sign-on configuration"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
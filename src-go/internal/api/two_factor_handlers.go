@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/schemas"
+	"go-api/internal/services"
+)
+
+type TwoFactorHandler struct {
+	service services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service}
+}
+
+func (h *TwoFactorHandler) BeginSetup(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(models.User)
+
+	setup, err := h.service.BeginSetup(&currentUser)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to start two-factor setup")
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *TwoFactorHandler) Activate(c *gin.Context) {
+	var req schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currentUser := c.MustGet("currentUser").(models.User)
+
+	codes, err := h.service.Activate(&currentUser, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to activate two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currentUser := c.MustGet("currentUser").(models.User)
+
+	if err := h.service.Disable(&currentUser, req.Code); err != nil {
+		respondTwoFactorError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currentUser := c.MustGet("currentUser").(models.User)
+
+	codes, err := h.service.RegenerateRecoveryCodes(&currentUser, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+func respondTwoFactorError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrInvalidTwoFactorCode:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case services.ErrTwoFactorAlreadyEnabled, services.ErrTwoFactorNotEnabled, services.ErrTwoFactorSetupNotStarted:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+type UsageHandler struct {
+	service services.QuotaService
+}
+
+func NewUsageHandler(service services.QuotaService) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	user, _ := c.Get("currentUser")
+	currentUser := user.(models.User)
+
+	report, err := h.service.GetUsage(currentUser.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve usage"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// respondQuotaExceeded responde 402 quando o erro veio do QuotaService e
+// informa qual limite do plano foi atingido.
+func respondQuotaExceeded(c *gin.Context, err error) bool {
+	var exceeded *services.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+	c.JSON(http.StatusPaymentRequired, gin.H{
+		"error":    exceeded.Error(),
+		"resource": exceeded.Resource,
+		"limit":    exceeded.Limit,
+		"monthly":  exceeded.Monthly,
+	})
+	return true
+}
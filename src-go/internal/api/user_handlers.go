@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -12,11 +13,12 @@ import (
 )
 
 type UserHandler struct {
-	service services.UserService
+	service             services.UserService
+	loginAttemptService services.LoginAttemptService
 }
 
-func NewUserHandler(service services.UserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(service services.UserService, loginAttemptService services.LoginAttemptService) *UserHandler {
+	return &UserHandler{service: service, loginAttemptService: loginAttemptService}
 }
 
 func (h *UserHandler) GetUsers(c *gin.Context) {
@@ -57,6 +59,21 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 	createdUser, err := h.service.CreateUser(userIn, orgID)
 	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrEmployeeIDTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrRoleNotAllowed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
@@ -64,6 +81,48 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	c.JSON(http.StatusCreated, createdUser)
 }
 
+// ImportUsers cria usuários em lote a partir de um CSV ou XLSX com as
+// colunas de UserImport. dry_run=true só valida.
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	rows, report, ok := readImportFile[schemas.UserImport](c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	err := h.service.ImportUsers(currentUser.OrganizationID, rows, report)
+	respondImport(c, report, err, "Failed to import users")
+}
+
+// ExportUsers gera a planilha de usuários (format=csv ou xlsx) no formato da
+// importação.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	users, err := h.service.ExportUsers(currentUser.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export users"})
+		return
+	}
+	writeExport(c, format, "users", users)
+}
+
 func (h *UserHandler) GetUser(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -89,6 +148,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
+	setETag(c, foundUser.Version)
 	c.JSON(http.StatusOK, foundUser)
 }
 
@@ -104,6 +164,10 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, exists := c.Get("currentUser")
 	if !exists {
@@ -113,8 +177,15 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	currentUser := user.(models.User)
 	orgID := currentUser.OrganizationID
 
-	updatedUser, err := h.service.UpdateUser(uint(userID), orgID, userIn)
+	updatedUser, err := h.service.UpdateUser(uint(userID), orgID, userIn, ifMatch)
 	if err != nil {
+		if errors.Is(err, services.ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondPatchError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
@@ -123,9 +194,65 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedUser.Version)
 	c.JSON(http.StatusOK, updatedUser)
 }
 
+// UploadAvatar recebe a foto no campo "file" (JPEG, PNG ou GIF) e substitui a
+// anterior; a miniatura para as listagens vai em AvatarThumbnailURL.
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	file, ok := readPhotoFile(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	user, err := h.service.UploadAvatar(uint(userID), currentUser.OrganizationID, file, ifMatch)
+	h.respondAvatar(c, user, err, "Failed to upload avatar")
+}
+
+func (h *UserHandler) DeleteAvatar(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	user, err := h.service.DeleteAvatar(uint(userID), currentUser.OrganizationID, ifMatch)
+	h.respondAvatar(c, user, err, "Failed to delete avatar")
+}
+
+func (h *UserHandler) respondAvatar(c *gin.Context, user *models.User, err error, failure string) {
+	if err != nil {
+		if respondPhotoError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -149,3 +276,60 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	err = h.loginAttemptService.UnlockAccount(uint(userID), currentUser)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+func (h *UserHandler) GetUserLockEvents(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	events, err := h.loginAttemptService.GetLockEvents(uint(userID), currentUser, skip, limit)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lock events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -60,6 +61,9 @@ func (h *VehicleHandler) CreateVehicle(c *gin.Context) {
 
 	createdVehicle, err := h.service.CreateVehicle(vehicleIn, orgID)
 	if err != nil {
+		if respondQuotaExceeded(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create vehicle"})
 		return
 	}
@@ -67,6 +71,47 @@ func (h *VehicleHandler) CreateVehicle(c *gin.Context) {
 	c.JSON(http.StatusCreated, createdVehicle)
 }
 
+// ImportVehicles cria veículos em lote a partir de um CSV ou XLSX com as
+// colunas de VehicleCreate. dry_run=true só valida.
+func (h *VehicleHandler) ImportVehicles(c *gin.Context) {
+	rows, report, ok := readImportFile[schemas.VehicleCreate](c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	err := h.service.ImportVehicles(currentUser.OrganizationID, rows, report)
+	respondImport(c, report, err, "Failed to import vehicles")
+}
+
+// ExportVehicles gera a planilha da frota (format=csv ou xlsx) no formato da importação.
+func (h *VehicleHandler) ExportVehicles(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	vehicles, err := h.service.ExportVehicles(currentUser.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export vehicles"})
+		return
+	}
+	writeExport(c, format, "vehicles", vehicles)
+}
+
 func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 	vehicleID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -92,6 +137,7 @@ func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, vehicle.Version)
 	c.JSON(http.StatusOK, vehicle)
 }
 
@@ -107,6 +153,10 @@ func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, exists := c.Get("currentUser")
 	if !exists {
@@ -116,8 +166,11 @@ func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
 	currentUser := user.(models.User)
 	orgID := currentUser.OrganizationID
 
-	updatedVehicle, err := h.service.UpdateVehicle(uint(vehicleID), orgID, vehicleIn)
+	updatedVehicle, err := h.service.UpdateVehicle(uint(vehicleID), orgID, vehicleIn, ifMatch)
 	if err != nil {
+		if respondPatchError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vehicle"})
 		return
 	}
@@ -126,9 +179,65 @@ func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
 		return
 	}
 
+	setETag(c, updatedVehicle.Version)
 	c.JSON(http.StatusOK, updatedVehicle)
 }
 
+// UploadPhoto recebe a foto no campo "file" (JPEG, PNG ou GIF) e substitui a
+// anterior; a miniatura para as listagens vai em PhotoThumbnailURL.
+func (h *VehicleHandler) UploadPhoto(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	file, ok := readPhotoFile(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	vehicle, err := h.service.UploadPhoto(uint(vehicleID), currentUser.OrganizationID, file, ifMatch)
+	h.respondPhoto(c, vehicle, err, "Failed to upload vehicle photo")
+}
+
+func (h *VehicleHandler) DeletePhoto(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(models.User)
+	vehicle, err := h.service.DeletePhoto(uint(vehicleID), currentUser.OrganizationID, ifMatch)
+	h.respondPhoto(c, vehicle, err, "Failed to delete vehicle photo")
+}
+
+func (h *VehicleHandler) respondPhoto(c *gin.Context, vehicle *models.Vehicle, err error, failure string) {
+	if err != nil {
+		if respondPhotoError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+	if vehicle == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+		return
+	}
+
+	setETag(c, vehicle.Version)
+	c.JSON(http.StatusOK, vehicle)
+}
+
 func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
 	vehicleID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -152,3 +261,83 @@ func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+func (h *VehicleHandler) ChangeVehicleStatus(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+
+	var statusIn schemas.VehicleStatusUpdate
+	if err := c.ShouldBindJSON(&statusIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	updatedVehicle, err := h.service.ChangeStatus(uint(vehicleID), currentUser.OrganizationID, statusIn, currentUser.ID, ifMatch)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidVehicleStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrVersionMismatch):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change vehicle status"})
+		}
+		return
+	}
+	if updatedVehicle == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+		return
+	}
+
+	setETag(c, updatedVehicle.Version)
+	c.JSON(http.StatusOK, updatedVehicle)
+}
+
+// GetVehicleStatusHistory aceita date_from e date_to (YYYY-MM-DD, inclusive) ou
+// fiscal_year no fuso da organização e devolve os períodos de status que cruzam esses dias.
+func (h *VehicleHandler) GetVehicleStatusHistory(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+	dateRange, ok := parseOrganizationDateRange(c)
+	if !ok {
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	currentUser := user.(models.User)
+
+	history, err := h.service.GetStatusHistory(uint(vehicleID), currentUser.OrganizationID, dateRange)
+	if err != nil {
+		if errors.Is(err, services.ErrVehicleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vehicle status history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
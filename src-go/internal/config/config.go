@@ -8,6 +8,14 @@ import (
 	"go-api/internal/logging"
 )
 
+// defaultJWTSecret só serve para desenvolvimento; LoadConfig recusa iniciar com
+// ele quando APP_ENV=production.
+const defaultJWTSecret = "your-secret-key"
+
+// DefaultPasswordBcryptCost é o custo que estava fixo no código antes de
+// PASSWORD_BCRYPT_COST; valores menores só por configuração explícita.
+const DefaultPasswordBcryptCost = 14
+
 type Config struct {
 	DB_DSN        string `mapstructure:"DB_DSN"`
 	JWT_SECRET    string `mapstructure:"JWT_SECRET"`
@@ -15,6 +23,78 @@ type Config struct {
 	REDIS_ADDR    string `mapstructure:"REDIS_ADDR"`
 	REDIS_PASSWORD string `mapstructure:"REDIS_PASSWORD"`
 	REDIS_DB      int    `mapstructure:"REDIS_DB"`
+	APP_ENV       string `mapstructure:"APP_ENV"`
+
+	// Chave RSA (2048 bits ou mais) ou Ed25519; obrigatória em produção.
+	JWT_PRIVATE_KEY_FILE string `mapstructure:"JWT_PRIVATE_KEY_FILE"`
+	JWT_PUBLIC_KEYS_DIR  string `mapstructure:"JWT_PUBLIC_KEYS_DIR"`
+
+	ACCESS_TOKEN_EXPIRE_MINUTES  int `mapstructure:"ACCESS_TOKEN_EXPIRE_MINUTES"`
+	REFRESH_TOKEN_EXPIRE_DAYS    int `mapstructure:"REFRESH_TOKEN_EXPIRE_DAYS"`
+	IMPERSONATION_EXPIRE_MINUTES int `mapstructure:"IMPERSONATION_EXPIRE_MINUTES"`
+
+	SMTP_HOST                           string `mapstructure:"SMTP_HOST"`
+	SMTP_PORT                           int    `mapstructure:"SMTP_PORT"`
+	SMTP_USER                           string `mapstructure:"SMTP_USER"`
+	SMTP_PASSWORD                       string `mapstructure:"SMTP_PASSWORD"`
+	EMAILS_FROM_EMAIL                   string `mapstructure:"EMAILS_FROM_EMAIL"`
+	FRONTEND_URL                        string `mapstructure:"FRONTEND_URL"`
+	RESET_PASSWORD_TOKEN_EXPIRE_MINUTES int    `mapstructure:"RESET_PASSWORD_TOKEN_EXPIRE_MINUTES"`
+	// Validade do link enviado aos usuários importados para criarem a senha.
+	PASSWORD_SETUP_TOKEN_EXPIRE_HOURS int `mapstructure:"PASSWORD_SETUP_TOKEN_EXPIRE_HOURS"`
+	EMAIL_VERIFICATION_EXPIRE_HOURS     int    `mapstructure:"EMAIL_VERIFICATION_EXPIRE_HOURS"`
+	DEMO_TRIAL_DAYS                     int    `mapstructure:"DEMO_TRIAL_DAYS"`
+
+	// Cadastro, reenvio da confirmação e recuperação de senha aceitos por IP e
+	// por endereço de destino em cada janela; zero desliga o limite.
+	EMAIL_REQUEST_MAX_PER_IP      int `mapstructure:"EMAIL_REQUEST_MAX_PER_IP"`
+	EMAIL_REQUEST_MAX_PER_ADDRESS int `mapstructure:"EMAIL_REQUEST_MAX_PER_ADDRESS"`
+	EMAIL_REQUEST_WINDOW_MINUTES  int `mapstructure:"EMAIL_REQUEST_WINDOW_MINUTES"`
+
+	// Página do frontend que recebe o retorno do IdP e repassa code/state à API.
+	OIDC_REDIRECT_URL string `mapstructure:"OIDC_REDIRECT_URL"`
+
+	PASSWORD_BCRYPT_COST   int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PASSWORD_MIN_LENGTH    int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PASSWORD_HISTORY_SIZE  int    `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	PASSWORD_DENYLIST_FILE string `mapstructure:"PASSWORD_DENYLIST_FILE"`
+
+	LOGIN_MAX_FAILED_ATTEMPTS        int `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LOGIN_MAX_FAILED_ATTEMPTS_PER_IP int `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS_PER_IP"`
+	LOGIN_FAILURE_WINDOW_MINUTES     int `mapstructure:"LOGIN_FAILURE_WINDOW_MINUTES"`
+	LOGIN_LOCKOUT_MINUTES            int `mapstructure:"LOGIN_LOCKOUT_MINUTES"`
+
+	// Falhas seguidas no login por crachá até o PIN ser apagado.
+	BADGE_PIN_MAX_FAILED_ATTEMPTS int `mapstructure:"BADGE_PIN_MAX_FAILED_ATTEMPTS"`
+
+	// Dias entre o agendamento da exclusão e a remoção dos dados da organização.
+	ORGANIZATION_DELETION_GRACE_DAYS    int `mapstructure:"ORGANIZATION_DELETION_GRACE_DAYS"`
+	ORGANIZATION_PURGE_INTERVAL_MINUTES int `mapstructure:"ORGANIZATION_PURGE_INTERVAL_MINUTES"`
+
+	// Leituras de hodômetro/horímetro que avançam mais que a taxa máxima pelo
+	// tempo decorrido, mais a tolerância, ficam sinalizadas para revisão.
+	ODOMETER_MAX_KM_PER_HOUR    float64 `mapstructure:"ODOMETER_MAX_KM_PER_HOUR"`
+	ODOMETER_JUMP_TOLERANCE_KM  float64 `mapstructure:"ODOMETER_JUMP_TOLERANCE_KM"`
+	ENGINE_HOURS_JUMP_TOLERANCE float64 `mapstructure:"ENGINE_HOURS_JUMP_TOLERANCE"`
+
+	// Limites das importações em lote (CSV/XLSX) de veículos e usuários.
+	IMPORT_MAX_ROWS    int `mapstructure:"IMPORT_MAX_ROWS"`
+	IMPORT_MAX_FILE_MB int `mapstructure:"IMPORT_MAX_FILE_MB"`
+
+	// Sem REDIS_ADDR o cache fica em memória no próprio processo, limitado a
+	// este número de chaves. Com REDIS_ADDR, o Redis precisa responder na
+	// inicialização.
+	CACHE_MEMORY_MAX_ENTRIES int `mapstructure:"CACHE_MEMORY_MAX_ENTRIES"`
+
+	// Fotos de veículos e peças e avatares: tamanho máximo do arquivo e lado
+	// maior, em pixels, da miniatura usada nas listagens.
+	PHOTO_MAX_FILE_MB    int `mapstructure:"PHOTO_MAX_FILE_MB"`
+	PHOTO_THUMBNAIL_SIZE int `mapstructure:"PHOTO_THUMBNAIL_SIZE"`
+
+	// Chave das etiquetas QR de patrimônio. Vazia, é derivada do JWT_SECRET;
+	// trocá-la (ou trocar o JWT_SECRET nesse caso) invalida as etiquetas impressas.
+	ASSET_TAG_SECRET     string `mapstructure:"ASSET_TAG_SECRET"`
+	ASSET_TAG_MAX_LABELS int    `mapstructure:"ASSET_TAG_MAX_LABELS"`
 }
 
 var AppConfig *Config
@@ -27,11 +107,52 @@ func LoadConfig() {
 
 	// Set default values
 	viper.SetDefault("DB_DSN", "test.db")
-	viper.SetDefault("JWT_SECRET", "your-secret-key")
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)
 	viper.SetDefault("SERVER_PORT", "8080")
-	viper.SetDefault("REDIS_ADDR", "localhost:6379")
+	viper.SetDefault("REDIS_ADDR", "")
 	viper.SetDefault("REDIS_PASSWORD", "")
 	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("APP_ENV", "development")
+	viper.SetDefault("JWT_PRIVATE_KEY_FILE", "")
+	viper.SetDefault("JWT_PUBLIC_KEYS_DIR", "")
+	viper.SetDefault("ACCESS_TOKEN_EXPIRE_MINUTES", 15)
+	viper.SetDefault("REFRESH_TOKEN_EXPIRE_DAYS", 30)
+	viper.SetDefault("IMPERSONATION_EXPIRE_MINUTES", 10)
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USER", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("EMAILS_FROM_EMAIL", "no-reply@trucar.com")
+	viper.SetDefault("FRONTEND_URL", "http://localhost:9000")
+	viper.SetDefault("RESET_PASSWORD_TOKEN_EXPIRE_MINUTES", 60)
+	viper.SetDefault("PASSWORD_SETUP_TOKEN_EXPIRE_HOURS", 72)
+	viper.SetDefault("EMAIL_VERIFICATION_EXPIRE_HOURS", 48)
+	viper.SetDefault("DEMO_TRIAL_DAYS", 14)
+	viper.SetDefault("EMAIL_REQUEST_MAX_PER_IP", 10)
+	viper.SetDefault("EMAIL_REQUEST_MAX_PER_ADDRESS", 3)
+	viper.SetDefault("EMAIL_REQUEST_WINDOW_MINUTES", 60)
+	viper.SetDefault("OIDC_REDIRECT_URL", "http://localhost:9000/auth/sso/callback")
+	viper.SetDefault("PASSWORD_BCRYPT_COST", DefaultPasswordBcryptCost)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 10)
+	viper.SetDefault("PASSWORD_HISTORY_SIZE", 5)
+	viper.SetDefault("PASSWORD_DENYLIST_FILE", "")
+	viper.SetDefault("LOGIN_MAX_FAILED_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 20)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW_MINUTES", 15)
+	viper.SetDefault("LOGIN_LOCKOUT_MINUTES", 15)
+	viper.SetDefault("BADGE_PIN_MAX_FAILED_ATTEMPTS", 5)
+	viper.SetDefault("ORGANIZATION_DELETION_GRACE_DAYS", 30)
+	viper.SetDefault("ORGANIZATION_PURGE_INTERVAL_MINUTES", 60)
+	viper.SetDefault("ODOMETER_MAX_KM_PER_HOUR", 120)
+	viper.SetDefault("ODOMETER_JUMP_TOLERANCE_KM", 1000)
+	viper.SetDefault("ENGINE_HOURS_JUMP_TOLERANCE", 12)
+	viper.SetDefault("IMPORT_MAX_ROWS", 1000)
+	viper.SetDefault("IMPORT_MAX_FILE_MB", 5)
+	viper.SetDefault("CACHE_MEMORY_MAX_ENTRIES", 10000)
+	viper.SetDefault("PHOTO_MAX_FILE_MB", 10)
+	viper.SetDefault("PHOTO_THUMBNAIL_SIZE", 320)
+	viper.SetDefault("ASSET_TAG_SECRET", "")
+	viper.SetDefault("ASSET_TAG_MAX_LABELS", 480)
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -50,7 +171,7 @@ func LoadConfig() {
 		defer f.Close()
 		// Write default values to .env file
 		f.WriteString("DB_DSN=test.db\n")
-		f.WriteString("JWT_SECRET=your-secret-key\n")
+		f.WriteString("JWT_SECRET=" + defaultJWTSecret + "\n")
 		f.WriteString("SERVER_PORT=8080\n")
 	}
 
@@ -58,4 +179,12 @@ func LoadConfig() {
 	if err != nil {
 		logging.Logger.Fatal("Unable to decode into struct", zap.Error(err))
 	}
+
+	if AppConfig.APP_ENV == "production" && AppConfig.JWT_SECRET == defaultJWTSecret {
+		logging.Logger.Fatal("JWT_SECRET is set to its default value; refusing to start in production")
+	}
+	// Sem SMTP os e-mails de senha e de confirmação nunca chegariam ao usuário.
+	if AppConfig.APP_ENV == "production" && AppConfig.SMTP_HOST == "" {
+		logging.Logger.Fatal("SMTP_HOST is not set; refusing to start in production")
+	}
 }
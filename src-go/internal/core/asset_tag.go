@@ -0,0 +1,77 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-api/internal/config"
+)
+
+var ErrInvalidAssetTag = errors.New("invalid or tampered asset tag")
+
+// assetTagSignatureSize é o tamanho, em bytes, da assinatura truncada: 128
+// bits bastam contra falsificação e mantêm o QR pequeno.
+const assetTagSignatureSize = 16
+
+// AssetTag é o conteúdo das etiquetas de patrimônio: "<tipo>.<org>.<id>.<assinatura>".
+// A assinatura impede que alguém gere etiquetas válidas trocando os números;
+// o acesso ao registro continua sendo conferido pela organização do usuário.
+type AssetTag struct {
+	Kind           string
+	OrganizationID uint
+	ID             uint
+}
+
+// assetTagKey usa ASSET_TAG_SECRET ou, sem ele, uma chave derivada do
+// JWT_SECRET. Trocar a chave invalida todas as etiquetas já impressas.
+func assetTagKey() []byte {
+	if secret := config.AppConfig.ASSET_TAG_SECRET; secret != "" {
+		return []byte(secret)
+	}
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.JWT_SECRET))
+	mac.Write([]byte("asset-tag"))
+	return mac.Sum(nil)
+}
+
+func assetTagSignature(body string) []byte {
+	mac := hmac.New(sha256.New, assetTagKey())
+	mac.Write([]byte(body))
+	return mac.Sum(nil)[:assetTagSignatureSize]
+}
+
+func SignAssetTag(tag AssetTag) string {
+	body := fmt.Sprintf("%s.%d.%d", tag.Kind, tag.OrganizationID, tag.ID)
+	return body + "." + base64.RawURLEncoding.EncodeToString(assetTagSignature(body))
+}
+
+// ParseAssetTag confere a assinatura e devolve o tipo, a organização e o ID.
+func ParseAssetTag(payload string) (*AssetTag, error) {
+	idx := strings.LastIndex(payload, ".")
+	if idx < 0 {
+		return nil, ErrInvalidAssetTag
+	}
+	body := payload[:idx]
+	signature, err := base64.RawURLEncoding.DecodeString(payload[idx+1:])
+	if err != nil || !hmac.Equal(signature, assetTagSignature(body)) {
+		return nil, ErrInvalidAssetTag
+	}
+
+	parts := strings.Split(body, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidAssetTag
+	}
+	orgID, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidAssetTag
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidAssetTag
+	}
+	return &AssetTag{Kind: parts[0], OrganizationID: uint(orgID), ID: uint(id)}, nil
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"go-api/internal/config"
+)
+
+// JWK é a representação pública de uma chave publicada em /.well-known/jwks.json.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// minRSAKeyBits é o tamanho mínimo aceito para chaves RSA, de assinatura ou
+// de verificação.
+const minRSAKeyBits = 2048
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+	jwk    JWK
+}
+
+// keyRing guarda a chave de assinatura ativa e todas as chaves públicas aceitas
+// na verificação. Sem JWT_PRIVATE_KEY_FILE, os tokens continuam em HS256.
+type keyRing struct {
+	signingKID    string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.PrivateKey
+	verification  map[string]verificationKey
+}
+
+var keys *keyRing
+
+// LoadSigningKeys lê a chave privada ativa (JWT_PRIVATE_KEY_FILE) e as chaves
+// públicas extras de JWT_PUBLIC_KEYS_DIR. Para rotacionar, publique a chave
+// nova no diretório, aguarde os serviços atualizarem o JWKS e só então troque
+// a chave privada; mantenha a pública antiga até os tokens antigos expirarem.
+// Em produção a chave privada é obrigatória: o HS256 com JWT_SECRET fica só
+// para desenvolvimento.
+func LoadSigningKeys() error {
+	ring := &keyRing{verification: map[string]verificationKey{}}
+	if config.AppConfig.APP_ENV == "production" && config.AppConfig.JWT_PRIVATE_KEY_FILE == "" {
+		return errors.New("JWT_PRIVATE_KEY_FILE is required in production; HS256 tokens are only for development")
+	}
+
+	if path := config.AppConfig.JWT_PRIVATE_KEY_FILE; path != "" {
+		private, err := readPrivateKey(path)
+		if err != nil {
+			return err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return fmt.Errorf("%s: unsupported private key type", path)
+		}
+		vk, err := newVerificationKey(signer.Public())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		ring.signingKID = vk.jwk.Kid
+		ring.signingMethod = vk.method
+		ring.signingKey = private
+		ring.verification[vk.jwk.Kid] = vk
+	}
+
+	if dir := config.AppConfig.JWT_PUBLIC_KEYS_DIR; dir != "" {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			public, err := readPublicKey(path)
+			if err != nil {
+				return err
+			}
+			vk, err := newVerificationKey(public)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			ring.verification[vk.jwk.Kid] = vk
+		}
+	}
+
+	keys = ring
+	return nil
+}
+
+// PublicJWKS retorna as chaves públicas aceitas, começando pela de assinatura.
+func PublicJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if keys == nil {
+		return set
+	}
+	for _, vk := range keys.verification {
+		set.Keys = append(set.Keys, vk.jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if set.Keys[i].Kid == keys.signingKID {
+			return true
+		}
+		if set.Keys[j].Kid == keys.signingKID {
+			return false
+		}
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+func signClaims(claims jwt.Claims) (string, error) {
+	if keys == nil || keys.signingKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.AppConfig.JWT_SECRET))
+	}
+
+	token := jwt.NewWithClaims(keys.signingMethod, claims)
+	token.Header["kid"] = keys.signingKID
+	return token.SignedString(keys.signingKey)
+}
+
+// verificationKeyFunc escolhe a chave pelo "kid" e exige o algoritmo dela,
+// evitando a troca de RS256/EdDSA por HS256 usando a chave pública como segredo.
+func verificationKeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if keys != nil && keys.signingKey != nil {
+			return nil, errors.New("missing key id")
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(config.AppConfig.JWT_SECRET), nil
+	}
+
+	if keys == nil {
+		return nil, errors.New("unknown key id")
+	}
+	vk, ok := keys.verification[kid]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return vk.key, nil
+}
+
+func newVerificationKey(public crypto.PublicKey) (verificationKey, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return verificationKey{}, fmt.Errorf("RSA key has %d bits, at least %d are required", key.N.BitLen(), minRSAKeyBits)
+		}
+		jwk := JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+		jwk.Kid = jwkThumbprint(map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N})
+		return verificationKey{method: jwt.SigningMethodRS256, key: key, jwk: jwk}, nil
+	case ed25519.PublicKey:
+		jwk := JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+		jwk.Kid = jwkThumbprint(map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X})
+		return verificationKey{method: SigningMethodEdDSA, key: key, jwk: jwk}, nil
+	}
+	return verificationKey{}, errors.New("unsupported key type, use RSA or Ed25519")
+}
+
+// jwkThumbprint calcula o kid pela RFC 7638, então o mesmo par de chaves tem
+// sempre o mesmo kid em todas as instâncias.
+func jwkThumbprint(members map[string]string) string {
+	// json.Marshal ordena as chaves do map, como a RFC exige.
+	canonical, _ := json.Marshal(members)
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || !strings.HasSuffix(block.Type, "KEY") {
+		return nil, fmt.Errorf("%s: no PEM key found", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"go-api/internal/config"
+)
+
+// useKeyConfig troca a configuração e o chaveiro pelo teste.
+func useKeyConfig(t *testing.T, cfg config.Config) {
+	t.Helper()
+	previousConfig, previousKeys := config.AppConfig, keys
+	cfg.JWT_SECRET = "test secret"
+	cfg.ACCESS_TOKEN_EXPIRE_MINUTES = 15
+	config.AppConfig = &cfg
+	keys = nil
+	t.Cleanup(func() { config.AppConfig, keys = previousConfig, previousKeys })
+}
+
+// writePEM grava a chave em PKCS#8 (privada) ou PKIX (pública).
+func writePEM(t *testing.T, dir, name string, key interface{}) string {
+	t.Helper()
+	var block *pem.Block
+	switch k := key.(type) {
+	case *rsa.PrivateKey, ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	default:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// tokenHeader devolve o alg e o kid do token sem validá-lo.
+func tokenHeader(t *testing.T, tokenStr string) (string, string) {
+	t.Helper()
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenStr, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return token.Method.Alg(), kid
+}
+
+func TestLoadSigningKeysRequiresPrivateKeyInProduction(t *testing.T) {
+	useKeyConfig(t, config.Config{APP_ENV: "production"})
+	if err := LoadSigningKeys(); err == nil || !strings.Contains(err.Error(), "JWT_PRIVATE_KEY_FILE") {
+		t.Fatalf("production without a private key: got %v", err)
+	}
+
+	useKeyConfig(t, config.Config{APP_ENV: "development"})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := GenerateJWT(1, 1, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alg, kid := tokenHeader(t, token); alg != "HS256" || kid != "" {
+		t.Fatalf("development token: alg=%s kid=%q, want HS256 without kid", alg, kid)
+	}
+	if _, err := ValidateJWT(token); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	useKeyConfig(t, config.Config{APP_ENV: "production", JWT_PRIVATE_KEY_FILE: writePEM(t, dir, "signing.pem", newEd25519Key(t))})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatalf("production with a private key: %v", err)
+	}
+}
+
+func TestLoadSigningKeysRejectsShortRSAKeys(t *testing.T) {
+	dir := t.TempDir()
+	short := newRSAKey(t, 1024)
+
+	useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: writePEM(t, dir, "short.pem", short)})
+	if err := LoadSigningKeys(); err == nil || !strings.Contains(err.Error(), "1024 bits") {
+		t.Fatalf("1024-bit signing key: got %v", err)
+	}
+
+	publicDir := t.TempDir()
+	writePEM(t, publicDir, "old.pem", &short.PublicKey)
+	useKeyConfig(t, config.Config{JWT_PUBLIC_KEYS_DIR: publicDir})
+	if err := LoadSigningKeys(); err == nil || !strings.Contains(err.Error(), "1024 bits") {
+		t.Fatalf("1024-bit verification key: got %v", err)
+	}
+}
+
+func TestSigningKeyTokens(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name string
+		key  interface{}
+		alg  string
+	}{
+		{"rsa", newRSAKey(t, 2048), "RS256"},
+		{"ed25519", newEd25519Key(t), "EdDSA"},
+	}
+	for _, tc := range cases {
+		useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: writePEM(t, dir, tc.name+".pem", tc.key)})
+		if err := LoadSigningKeys(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		token, err := GenerateJWT(7, 3, "session")
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		alg, kid := tokenHeader(t, token)
+		jwks := PublicJWKS()
+		if alg != tc.alg || len(jwks.Keys) != 1 || kid != jwks.Keys[0].Kid || jwks.Keys[0].Alg != tc.alg {
+			t.Fatalf("%s: alg=%s kid=%s, jwks=%+v", tc.name, alg, kid, jwks)
+		}
+		claims, err := ValidateJWT(token)
+		if err != nil || claims.UserID != 7 || claims.OrganizationID != 3 {
+			t.Fatalf("%s: claims=%+v, %v", tc.name, claims, err)
+		}
+	}
+}
+
+// Depois da rotação, tokens da chave antiga (publicada no diretório) seguem
+// válidos e cada um é verificado pela chave do seu kid.
+func TestVerificationSelectsKeyByKid(t *testing.T) {
+	dir, publicDir := t.TempDir(), t.TempDir()
+	oldKey, newKey := newEd25519Key(t), newRSAKey(t, 2048)
+
+	useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: writePEM(t, dir, "old.pem", oldKey)})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := GenerateJWT(1, 1, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, oldKID := tokenHeader(t, oldToken)
+
+	writePEM(t, publicDir, "old.pem", oldKey.Public())
+	useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: writePEM(t, dir, "new.pem", newKey), JWT_PUBLIC_KEYS_DIR: publicDir})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := GenerateJWT(2, 1, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, newKID := tokenHeader(t, newToken)
+
+	jwks := PublicJWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != newKID || jwks.Keys[1].Kid != oldKID {
+		t.Fatalf("jwks = %+v, want the signing key first and the old key after it", jwks)
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := ValidateJWT(token); err != nil {
+			t.Fatalf("token after rotation: %v", err)
+		}
+	}
+
+	// A chave antiga sai do diretório: os tokens dela deixam de valer.
+	useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: filepath.Join(dir, "new.pem")})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(oldToken); err == nil {
+		t.Fatal("token of a removed key accepted")
+	}
+}
+
+// A chave pública não pode virar segredo de HMAC, e sem kid o token é
+// recusado quando há chave de assinatura.
+func TestVerificationPinsAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	key := newRSAKey(t, 2048)
+	useKeyConfig(t, config.Config{JWT_PRIVATE_KEY_FILE: writePEM(t, dir, "signing.pem", key)})
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	kid := PublicJWKS().Keys[0].Kid
+	publicPEM, err := os.ReadFile(writePEM(t, dir, "public.pem", &key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := &Claims{UserID: 1, OrganizationID: 1}
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	confused.Header["kid"] = kid
+	confusedToken, err := confused.SignedString(publicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(confusedToken); err == nil {
+		t.Fatal("HS256 token signed with the public key accepted")
+	}
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConfig.JWT_SECRET))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(legacy); err == nil {
+		t.Fatal("HS256 token without kid accepted while a signing key is configured")
+	}
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	unknown.Header["kid"] = "unknown"
+	unknownToken, err := unknown.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(unknownToken); err == nil {
+		t.Fatal("token with an unknown kid accepted")
+	}
+}
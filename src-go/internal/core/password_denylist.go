@@ -0,0 +1,56 @@
+package core
+
+import (
+	"bufio"
+	_ "embed"
+	"os"
+	"strings"
+)
+
+// commonPasswords é uma lista curta das senhas mais vazadas (inclusive as
+// comuns no Brasil); PASSWORD_DENYLIST_FILE pode complementá-la com uma
+// lista maior, uma senha por linha.
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+var passwordDenylist = parseDenylist(commonPasswords)
+
+func LoadPasswordDenylist(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if entry := normalizeDenylistEntry(scanner.Text()); entry != "" {
+			passwordDenylist[entry] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// IsCommonPassword compara sem diferenciar maiúsculas e minúsculas.
+func IsCommonPassword(password string) bool {
+	_, found := passwordDenylist[normalizeDenylistEntry(password)]
+	return found
+}
+
+func parseDenylist(list string) map[string]struct{} {
+	entries := map[string]struct{}{}
+	for _, line := range strings.Split(list, "\n") {
+		if entry := normalizeDenylistEntry(line); entry != "" {
+			entries[entry] = struct{}{}
+		}
+	}
+	return entries
+}
+
+func normalizeDenylistEntry(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -10,41 +14,136 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Tokens intermediários do login em duas etapas. Access tokens não têm Purpose.
+const (
+	TokenPurposeMFA      = "mfa"
+	TokenPurposeMFASetup = "mfa_setup"
+)
+
+const mfaTokenTTL = 5 * time.Minute
+
+// TokenScopeDriver limita o token às permissões padrão do motorista, seja qual
+// for o papel ou as personalizações da organização.
+const TokenScopeDriver = "driver"
+
+// organizationCodeAlphabet evita caracteres ambíguos (0/O, 1/I) para facilitar
+// a digitação nos tablets.
+const organizationCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ActorClaims identifica o super admin real por trás de um token de
+// personificação (claim "act", como na RFC 8693).
+type ActorClaims struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
+}
+
 type Claims struct {
-	UserID         uint `json:"user_id"`
-	OrganizationID uint `json:"organization_id"`
+	UserID         uint         `json:"user_id"`
+	OrganizationID uint         `json:"organization_id"`
+	SessionID      string       `json:"sid,omitempty"`
+	Purpose        string       `json:"purpose,omitempty"`
+	Scope          string       `json:"scope,omitempty"`
+	Actor          *ActorClaims `json:"act,omitempty"`
 	jwt.StandardClaims
 }
 
+// HashPassword usa o custo configurado em PASSWORD_BCRYPT_COST; é o único
+// ponto do código que gera hashes de senha.
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost())
 	return string(bytes), err
 }
 
+func PasswordCost() int {
+	cost := config.AppConfig.PASSWORD_BCRYPT_COST
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return config.DefaultPasswordBcryptCost
+	}
+	return cost
+}
+
+// PasswordNeedsRehash indica hashes gerados com custo menor que o atual, que
+// são atualizados no próximo login bem-sucedido.
+func PasswordNeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost < PasswordCost()
+}
+
 func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
 
-func GenerateJWT(userID, orgID uint) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// AccessTokenTTL é a validade dos access tokens emitidos por GenerateJWT.
+func AccessTokenTTL() time.Duration {
+	return time.Duration(config.AppConfig.ACCESS_TOKEN_EXPIRE_MINUTES) * time.Minute
+}
+
+// RefreshTokenTTL é a validade dos refresh tokens guardados no servidor.
+func RefreshTokenTTL() time.Duration {
+	return time.Duration(config.AppConfig.REFRESH_TOKEN_EXPIRE_DAYS) * 24 * time.Hour
+}
+
+func GenerateJWT(userID, orgID uint, sessionID string) (string, error) {
+	return GenerateScopedJWT(userID, orgID, sessionID, "")
+}
+
+func GenerateScopedJWT(userID, orgID uint, sessionID, scope string) (string, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL())
 	claims := &Claims{
 		UserID:         userID,
 		OrganizationID: orgID,
+		SessionID:      sessionID,
+		Scope:          scope,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
+			IssuedAt:  time.Now().Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.AppConfig.JWT_SECRET))
+	return signClaims(claims)
+}
+
+// ImpersonationTTL é propositalmente curto: o token de personificação não tem
+// refresh token e o admin precisa iniciar outra personificação ao expirar.
+func ImpersonationTTL() time.Duration {
+	return time.Duration(config.AppConfig.IMPERSONATION_EXPIRE_MINUTES) * time.Minute
+}
+
+func GenerateImpersonationJWT(userID, orgID uint, sessionID string, actor ActorClaims) (string, error) {
+	claims := &Claims{
+		UserID:         userID,
+		OrganizationID: orgID,
+		SessionID:      sessionID,
+		Actor:          &actor,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ImpersonationTTL()).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	return signClaims(claims)
+}
+
+// GenerateMFAToken emite o token que liga a etapa da senha à etapa do código.
+// Ele não tem sessão e por isso é recusado pelo AuthMiddleware.
+func GenerateMFAToken(userID, orgID uint, purpose string) (string, error) {
+	claims := &Claims{
+		UserID:         userID,
+		OrganizationID: orgID,
+		Purpose:        purpose,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(mfaTokenTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	return signClaims(claims)
 }
 
 func ValidateJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.AppConfig.JWT_SECRET), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, claims, verificationKeyFunc)
 
 	if err != nil {
 		if err == jwt.ErrSignatureInvalid {
@@ -59,3 +158,30 @@ func ValidateJWT(tokenStr string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateOpaqueToken gera um segredo aleatório (refresh tokens, links de e-mail, etc.).
+// Apenas o hash retornado por HashToken deve ser persistido.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateOrganizationCode gera o código curto usado no login por crachá.
+func GenerateOrganizationCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = organizationCodeAlphabet[int(b[i])%len(organizationCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -39,13 +39,46 @@ func Migrate(db *gorm.DB) {
 		&models.FreightOrder{},
 		&models.StopPoint{},
 		&models.Document{},
+		&models.RefreshToken{},
+		&models.OrganizationSSOConfig{},
+		&models.PasswordHistory{},
+		&models.OrganizationUsage{},
+		&models.AccountLockEvent{},
+		&models.TwoFactorRecoveryCode{},
+		&models.ImpersonationSession{},
+		&models.APIKey{},
+		&models.OrganizationRolePermission{},
+		&models.UserSession{},
+		&models.OrganizationSettings{},
+		&models.VehicleStatusChange{},
+		&models.OdometerReading{},
 	)
 	if err != nil {
 		logging.Logger.Fatal("Failed to migrate database", zap.Error(err))
 	}
+
+	// Movimentações antigas não tinham organization_id; herdam a do item.
+	if err := db.Exec(`UPDATE inventory_transactions SET organization_id = (
+		SELECT organization_id FROM inventory_items WHERE inventory_items.id = inventory_transactions.item_id
+	) WHERE organization_id = 0`).Error; err != nil {
+		logging.Logger.Fatal("Failed to backfill inventory transaction organizations", zap.Error(err))
+	}
+
+	// A matrícula passou a ser única por organização; o índice global antigo
+	// impediria duas organizações de terem o funcionário 0001.
+	if db.Migrator().HasIndex(&models.User{}, "idx_users_employee_id") {
+		if err := db.Migrator().DropIndex(&models.User{}, "idx_users_employee_id"); err != nil {
+			logging.Logger.Fatal("Failed to drop legacy employee id index", zap.Error(err))
+		}
+	}
 }
 
-func InitRedis() *redis.Client {
+// InitRedis devolve nil, sem erro, quando REDIS_ADDR está vazio; se o Redis não
+// responder, devolve o erro e a API não sobe.
+func InitRedis() (*redis.Client, error) {
+	if config.AppConfig.REDIS_ADDR == "" {
+		return nil, nil
+	}
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     config.AppConfig.REDIS_ADDR,
 		Password: config.AppConfig.REDIS_PASSWORD,
@@ -54,8 +87,9 @@ func InitRedis() *redis.Client {
 
 	_, err := rdb.Ping(context.Background()).Result()
 	if err != nil {
-		logging.Logger.Fatal("Could not connect to Redis", zap.Error(err))
+		rdb.Close()
+		return nil, err
 	}
 
-	return rdb
+	return rdb, nil
 }
@@ -0,0 +1,206 @@
+package imaging
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+// Fotos enviadas pelos usuários: validação pelo conteúdo do arquivo (a
+// extensão e o Content-Type informados pelo cliente não contam) e miniaturas
+// para as listagens, só com a biblioteca padrão.
+
+var ErrUnsupportedFormat = errors.New("unsupported image type (use JPEG, PNG or GIF)")
+var ErrInvalidImage = errors.New("invalid image")
+var ErrTooManyPixels = errors.New("image dimensions are too large")
+
+// MaxPixels limita a imagem decodificada: um arquivo pequeno pode declarar
+// dimensões que ocupariam gigabytes de memória.
+const MaxPixels = 50_000_000
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatGIF  Format = "gif"
+)
+
+func (f Format) Extension() string {
+	if f == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + string(f)
+}
+
+func (f Format) ContentType() string {
+	return "image/" + string(f)
+}
+
+// Image é a foto decodificada. Width e Height já consideram a orientação
+// do EXIF, que EncodeOriginal e Thumbnail aplicam aos pixels.
+type Image struct {
+	Format      Format
+	Width       int
+	Height      int
+	img         image.Image
+	orientation int
+}
+
+// Decode identifica o formato pelos primeiros bytes, confere as dimensões antes
+// de decodificar e decodifica a imagem inteira, para recusar arquivos corrompidos.
+func Decode(r io.ReadSeeker) (*Image, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	var format Format
+	switch http.DetectContentType(header[:n]) {
+	case "image/jpeg":
+		format = FormatJPEG
+	case "image/png":
+		format = FormatPNG
+	case "image/gif":
+		format = FormatGIF
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var img image.Image
+	orientation := 1
+	switch format {
+	case FormatJPEG:
+		orientation = jpegOrientation(r)
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		img, err = jpeg.Decode(bufio.NewReader(r))
+	case FormatPNG:
+		img, err = png.Decode(bufio.NewReader(r))
+	case FormatGIF:
+		img, err = gif.Decode(bufio.NewReader(r))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	decoded := &Image{Format: format, Width: config.Width, Height: config.Height, img: img, orientation: orientation}
+	if swapsAxes(orientation) {
+		decoded.Width, decoded.Height = decoded.Height, decoded.Width
+	}
+	return decoded, nil
+}
+
+// Thumbnail reduz a imagem para caber em size x size, mantendo a proporção.
+// Imagens menores que isso não são ampliadas.
+func (i *Image) Thumbnail(size int) *Image {
+	width, height := i.Width, i.Height
+	if width > size || height > size {
+		if width >= height {
+			height = max(1, (height*size+width/2)/width)
+			width = size
+		} else {
+			width = max(1, (width*size+height/2)/height)
+			height = size
+		}
+	}
+	// A redução é feita antes de girar, sobre os eixos da imagem gravada.
+	scaledW, scaledH := width, height
+	if swapsAxes(i.orientation) {
+		scaledW, scaledH = height, width
+	}
+	thumbnail := orient(downscale(toRGBA(i.img), scaledW, scaledH), i.orientation)
+	return &Image{Format: i.Format, Width: width, Height: height, img: thumbnail, orientation: 1}
+}
+
+// EncodeOriginal regrava a foto no tamanho original, já na posição de exibição
+// e sem os metadados do arquivo enviado: o EXIF das fotos de celular traz o
+// GPS de onde foram tiradas. JPEG continua JPEG; PNG e GIF viram PNG, sem
+// perda (do GIF animado fica só o primeiro quadro, o mesmo que foi validado).
+func (i *Image) EncodeOriginal(w io.Writer) (Format, error) {
+	img := i.img
+	if i.orientation != 1 {
+		img = orient(toRGBA(i.img), i.orientation)
+	}
+	if i.Format == FormatJPEG {
+		return FormatJPEG, jpeg.Encode(w, img, &jpeg.Options{Quality: 92})
+	}
+	return FormatPNG, png.Encode(w, img)
+}
+
+// Encode grava a imagem em JPEG quando ela é opaca e em PNG quando tem
+// transparência, e devolve o formato usado.
+func (i *Image) Encode(w io.Writer) (Format, error) {
+	if opaque, ok := i.img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		return FormatPNG, png.Encode(w, i.img)
+	}
+	return FormatJPEG, jpeg.Encode(w, i.img, &jpeg.Options{Quality: 85})
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// downscale calcula cada pixel como a média da área correspondente da
+// origem, o que evita o serrilhado de simplesmente pular pixels.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcW, srcH := src.Rect.Dx(), src.Rect.Dy()
+	if width == srcW && height == srcH {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for p := 0; p < len(row); p += 4 {
+					r += uint64(row[p])
+					g += uint64(row[p+1])
+					b += uint64(row[p+2])
+					a += uint64(row[p+3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
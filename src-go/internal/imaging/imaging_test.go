@@ -0,0 +1,131 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+const secret = "GPS -23.5505 -46.6333"
+
+// halves pinta a metade esquerda de vermelho e a direita de azul.
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// exifJPEG monta um JPEG com um segmento APP1 com Orientation 6 e um texto
+// que faz as vezes das coordenadas do GPS.
+func exifJPEG(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, halves(16, 8), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00)
+	tiff = append(tiff, 0, 0, 0, 0)
+	payload := append(append([]byte("Exif\x00\x00"), tiff...), secret...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := encoded.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+// textPNG monta um PNG com um chunk tEXt logo depois do IHDR.
+func textPNG(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, halves(16, 8)); err != nil {
+		t.Fatal(err)
+	}
+	body := append([]byte("tEXt"), "Location\x00"+secret...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)-4))
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+
+	data := encoded.Bytes()
+	ihdrEnd := 8 + 8 + 13 + 4
+	return append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+func TestEncodeOriginalDropsMetadata(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []byte
+		format Format
+		width  int
+		height int
+	}{
+		{"jpeg with exif", exifJPEG(t), FormatJPEG, 8, 16},
+		{"png with text", textPNG(t), FormatPNG, 16, 8},
+	}
+	for _, tc := range cases {
+		if !bytes.Contains(tc.data, []byte(secret)) {
+			t.Fatalf("%s: fixture without metadata", tc.name)
+		}
+		img, err := Decode(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var out bytes.Buffer
+		format, err := img.EncodeOriginal(&out)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if format != tc.format {
+			t.Errorf("%s: format = %s, want %s", tc.name, format, tc.format)
+		}
+		if bytes.Contains(out.Bytes(), []byte(secret)) || bytes.Contains(out.Bytes(), []byte("Exif")) {
+			t.Errorf("%s: metadata kept in the stored original", tc.name)
+		}
+
+		stored, _, err := image.Decode(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if b := stored.Bounds(); b.Dx() != tc.width || b.Dy() != tc.height {
+			t.Fatalf("%s: stored %dx%d, want %dx%d", tc.name, b.Dx(), b.Dy(), tc.width, tc.height)
+		}
+	}
+}
+
+// Sem o EXIF, a rotação precisa estar nos pixels: girada 90° no sentido
+// horário, a metade vermelha da esquerda fica em cima.
+func TestEncodeOriginalAppliesOrientation(t *testing.T) {
+	img, err := Decode(bytes.NewReader(exifJPEG(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := img.EncodeOriginal(&out); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := jpeg.Decode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, bottom := stored.At(4, 2), stored.At(4, 13)
+	if r, _, b, _ := top.RGBA(); r < b {
+		t.Errorf("top = %v, want red", top)
+	}
+	if r, _, b, _ := bottom.RGBA(); b < r {
+		t.Errorf("bottom = %v, want blue", bottom)
+	}
+}
@@ -0,0 +1,122 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+// Celulares gravam a foto na orientação do sensor e indicam a rotação na tag
+// Orientation do EXIF. O original e a miniatura são gravados sem EXIF e por
+// isso precisam sair já girados.
+
+const exifOrientationTag = 0x0112
+
+// jpegOrientation lê a tag Orientation (1 a 8) do segmento APP1 do JPEG.
+// Qualquer problema no EXIF vale como 1 (sem rotação): a foto continua válida.
+func jpegOrientation(r io.Reader) int {
+	br := bufio.NewReader(io.LimitReader(r, 256<<10))
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(br, soi); err != nil || soi[0] != 0xFF || soi[1] != 0xD8 {
+		return 1
+	}
+	for {
+		marker := make([]byte, 4)
+		if _, err := io.ReadFull(br, marker); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		// SOS: começam os dados da imagem e não há mais metadados.
+		if marker[1] == 0xDA {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(marker[2:]))
+		if length < 2 {
+			return 1
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return 1
+		}
+		if marker[1] == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+	}
+}
+
+// exifOrientation procura a tag no IFD0 do bloco TIFF do EXIF.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+	return 1
+}
+
+// swapsAxes indica as orientações em que largura e altura se invertem.
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient aplica a transformação que coloca a imagem na posição de exibição.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dstW, dstH := w, h
+	if swapsAxes(orientation) {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // espelhada na horizontal
+				sx, sy = w-1-x, y
+			case 3: // girada 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // espelhada na vertical
+				sx, sy = x, h-1-y
+			case 5: // transposta
+				sx, sy = y, x
+			case 6: // precisa girar 90° no sentido horário
+				sx, sy = y, h-1-x
+			case 7: // transposta pela outra diagonal
+				sx, sy = w-1-y, h-1-x
+			case 8: // precisa girar 90° no sentido anti-horário
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/logging"
+)
+
+type Sender interface {
+	Send(to []string, subject, htmlBody string) error
+}
+
+type smtpSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender envia e-mails por um servidor SMTP. Sem usuário configurado a
+// autenticação é omitida, o que permite apontar para um servidor local de testes
+// (MailHog, smtp4dev etc.).
+func NewSMTPSender(host string, port int, username, password, from string) Sender {
+	return &smtpSender{
+		addr:     fmt.Sprintf("%s:%d", host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *smtpSender) Send(to []string, subject, htmlBody string) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(htmlBody)
+
+	return smtp.SendMail(s.addr, auth, s.from, to, []byte(msg.String()))
+}
+
+type logSender struct{}
+
+// NewLogSender apenas registra os e-mails no log. Usado quando SMTP_HOST não
+// está configurado, o que LoadConfig só permite fora de produção.
+func NewLogSender() Sender {
+	return &logSender{}
+}
+
+// Send não registra o corpo: ele traz os links de redefinição de senha e de
+// confirmação de e-mail, que valem como credenciais para quem lê o log.
+func (s *logSender) Send(to []string, subject, htmlBody string) error {
+	logging.Logger.Info("E-mail not sent (SMTP not configured)",
+		zap.Strings("to", to),
+		zap.String("subject", subject),
+	)
+	return nil
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go-api/internal/logging"
+)
+
+func TestLogSenderOmitsBody(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	previous := logging.Logger
+	logging.Logger = zap.New(core)
+	t.Cleanup(func() { logging.Logger = previous })
+
+	body := `<a href="https://app.trucar.test/#/auth/reset-password?token=segredo">Redefinir</a>`
+	if err := NewLogSender().Send([]string{"ana@example.com"}, "TruCar - Redefinição de Senha", body); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("%d log entries, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["subject"] != "TruCar - Redefinição de Senha" {
+		t.Errorf("subject = %v", fields["subject"])
+	}
+	for name, value := range fields {
+		if s, ok := value.(string); ok && strings.Contains(s, "segredo") {
+			t.Errorf("field %s carries the body: %s", name, s)
+		}
+	}
+}
@@ -7,11 +7,21 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"go-api/internal/core"
+	"go-api/internal/models"
 	"go-api/internal/services"
 )
 
-func AuthMiddleware(userService services.UserService) gin.HandlerFunc {
+// AuthMiddleware aceita um JWT de sessão ou uma chave de API. Com chave, o
+// escopo é conferido depois, por rota: o PermissionGuard exige o escopo
+// declarado e o CheckPermission recusa a chave quando nenhum foi conferido.
+// Rotas sem permissão, que dependem de uma sessão de usuário, usam SessionOnly.
+func AuthMiddleware(userService services.UserService, authService services.AuthService, apiKeyService services.APIKeyService, organizationService services.OrganizationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			authenticateAPIKey(c, apiKeyService, organizationService, rawKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
@@ -25,18 +35,95 @@ func AuthMiddleware(userService services.UserService) gin.HandlerFunc {
 		}
 
 		claims, err := core.ValidateJWT(tokenString)
-		if err != nil {
+		if err != nil || claims.Purpose != "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
+		if err := authService.ValidateSession(claims); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			return
+		}
+
 		user, err := userService.GetUser(claims.UserID, claims.OrganizationID)
 		if err != nil || user == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			return
 		}
+		// O super admin continua entrando pela personificação para atender a organização.
+		if claims.Actor == nil && !organizationActive(c, organizationService, user) {
+			return
+		}
 
 		c.Set("currentUser", *user)
+		c.Set("sessionID", claims.SessionID)
+		if claims.Scope != "" {
+			c.Set("tokenScope", claims.Scope)
+		}
+		if claims.Actor != nil {
+			c.Set("impersonatorID", claims.Actor.UserID)
+		}
+		c.Next()
+	}
+}
+
+// apiKeyFromRequest aceita a chave no header X-API-Key ou como bearer token.
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if strings.HasPrefix(token, services.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+// organizationActive recusa usuários de organizações suspensas ou com
+// exclusão agendada. O super admin nunca é bloqueado.
+func organizationActive(c *gin.Context, organizationService services.OrganizationService, user *models.User) bool {
+	if user.Role == models.RoleSuperAdmin {
+		return true
+	}
+	org, err := organizationService.GetOrganization(user.OrganizationID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load organization"})
+		return false
+	}
+	switch org.Status {
+	case models.OrganizationStatusSuspended:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This organization has been suspended; contact support to restore access", "organization_status": org.Status, "reason": org.SuspensionReason})
+		return false
+	case models.OrganizationStatusPendingDeletion:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This organization is scheduled for deletion; contact support to restore access", "organization_status": org.Status, "deletion_scheduled_at": org.DeletionScheduledAt})
+		return false
+	}
+	return true
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeyService services.APIKeyService, organizationService services.OrganizationService, rawKey string) {
+	key, user, err := apiKeyService.Authenticate(rawKey)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return
+	}
+	if !organizationActive(c, organizationService, user) {
+		return
+	}
+
+	c.Set("currentUser", *user)
+	c.Set("apiKey", *key)
+	c.Next()
+}
+
+// SessionOnly recusa chaves de API em rotas que só fazem sentido para uma
+// sessão de usuário, como logout, 2FA e as próprias sessões.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("apiKey"); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this resource"})
+			return
+		}
 		c.Next()
 	}
 }
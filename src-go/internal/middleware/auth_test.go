@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+// fixedAPIKey autentica qualquer chave como o usuário informado.
+type fixedAPIKey struct {
+	services.APIKeyService
+	user models.User
+}
+
+func (s fixedAPIKey) Authenticate(string) (*models.APIKey, *models.User, error) {
+	user := s.user
+	return &models.APIKey{Scopes: "vehicles:read"}, &user, nil
+}
+
+// fixedOrganization devolve sempre a mesma organização.
+type fixedOrganization struct {
+	services.OrganizationService
+	org models.Organization
+}
+
+func (s fixedOrganization) GetOrganization(uint) (*models.Organization, error) {
+	org := s.org
+	return &org, nil
+}
+
+func TestAuthMiddlewareRefusesInactiveOrganizations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deleteAt := time.Now().Add(24 * time.Hour)
+	reason := "inadimplência"
+	manager := models.User{ID: 1, OrganizationID: 1, Role: models.RoleClienteAtivo}
+	superAdmin := models.User{ID: 2, OrganizationID: 1, Role: models.RoleSuperAdmin}
+
+	cases := []struct {
+		name   string
+		user   models.User
+		org    models.Organization
+		want   int
+		status string
+	}{
+		{"active organization", manager, models.Organization{Status: models.OrganizationStatusActive}, http.StatusOK, ""},
+		{"suspended organization", manager, models.Organization{Status: models.OrganizationStatusSuspended, SuspensionReason: &reason}, http.StatusForbidden, "suspended"},
+		{"organization scheduled for deletion", manager, models.Organization{Status: models.OrganizationStatusPendingDeletion, DeletionScheduledAt: &deleteAt}, http.StatusForbidden, "pending_deletion"},
+		{"super admin of a suspended organization", superAdmin, models.Organization{Status: models.OrganizationStatusSuspended}, http.StatusOK, ""},
+	}
+
+	for _, tc := range cases {
+		router := gin.New()
+		router.Use(AuthMiddleware(nil, nil, fixedAPIKey{user: tc.user}, fixedOrganization{org: tc.org}))
+		router.GET("/vehicles", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		req.Header.Set("X-API-Key", "trucar_key")
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.want, w.Body.String())
+			continue
+		}
+		if tc.status == "" {
+			continue
+		}
+		var body struct {
+			OrganizationStatus string `json:"organization_status"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.OrganizationStatus != tc.status {
+			t.Errorf("%s: organization_status = %q, want %q (%v)", tc.name, body.OrganizationStatus, tc.status, err)
+		}
+	}
+}
@@ -16,6 +16,11 @@ func AuthorizationMiddleware(allowedRoles ...models.UserRole) gin.HandlerFunc {
 			return
 		}
 
+		// Rotas por papel (super admin) não aceitam chaves de API.
+		if _, isAPIKey := c.Get("apiKey"); isAPIKey {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this resource"})
+			return
+		}
 		currentUser := user.(models.User)
 
 		isAllowed := false
@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 
 	"go-api/internal/logging"
+	"go-api/internal/models"
 )
 
 func LoggingMiddleware() gin.HandlerFunc {
@@ -15,12 +16,24 @@ func LoggingMiddleware() gin.HandlerFunc {
 
 		c.Next()
 
-		logging.Logger.Info("request processed",
+		fields := []zap.Field{
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
 			zap.Int("status", c.Writer.Status()),
 			zap.Duration("latency", time.Since(start)),
 			zap.String("ip", c.ClientIP()),
-		)
+		}
+		if user, exists := c.Get("currentUser"); exists {
+			fields = append(fields, zap.Uint("user_id", user.(models.User).ID))
+		}
+
+		// Requisições feitas sob personificação registram as duas identidades.
+		if impersonatorID, exists := c.Get("impersonatorID"); exists {
+			fields = append(fields, zap.Uint("impersonator_id", impersonatorID.(uint)))
+			logging.Logger.Warn("impersonated request processed", fields...)
+			return
+		}
+
+		logging.Logger.Info("request processed", fields...)
 	}
 }
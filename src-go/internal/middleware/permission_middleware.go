@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+// PermissionGuard cria, para cada rota, o middleware que exige uma permissão
+// do usuário e, em requisições com chave de API, o escopo da rota.
+// models.ScopeNone fecha a rota para chaves de API.
+type PermissionGuard func(permission models.Permission, scope models.APIKeyScope) gin.HandlerFunc
+
+func NewPermissionGuard(permissionService services.PermissionService) PermissionGuard {
+	return func(permission models.Permission, scope models.APIKeyScope) gin.HandlerFunc {
+		return PermissionMiddleware(permissionService, permission, scope)
+	}
+}
+
+func PermissionMiddleware(permissionService services.PermissionService, permission models.Permission, scope models.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checkAPIKeyScope(c, scope) && CheckPermission(c, permissionService, permission) {
+			c.Next()
+		}
+	}
+}
+
+// checkAPIKeyScope confere o escopo da rota quando a requisição usa chave de
+// API e marca a requisição para o CheckPermission.
+func checkAPIKeyScope(c *gin.Context, scope models.APIKeyScope) bool {
+	value, ok := c.Get("apiKey")
+	if !ok {
+		return true
+	}
+	if scope == models.ScopeNone {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this resource"})
+		return false
+	}
+	key := value.(models.APIKey)
+	if !key.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key is missing the required scope", "scope": scope})
+		return false
+	}
+	c.Set("apiKeyScope", scope)
+	return true
+}
+
+// CheckPermission é a verificação do PermissionMiddleware para handlers em que
+// a permissão depende do conteúdo da requisição: basta uma das permissões
+// informadas. Quando nenhuma é concedida, a requisição é abortada com o mesmo
+// erro do middleware, referente à primeira.
+func CheckPermission(c *gin.Context, permissionService services.PermissionService, permissions ...models.Permission) bool {
+	user, exists := c.Get("currentUser")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return false
+	}
+	// Uma chave de API só passa pelas rotas que declararam o escopo dela.
+	if _, isAPIKey := c.Get("apiKey"); isAPIKey {
+		if _, granted := c.Get("apiKeyScope"); !granted {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this resource"})
+			return false
+		}
+	}
+
+	// Sessões de crachá ficam no conjunto padrão do motorista, mesmo que a
+	// organização tenha concedido mais permissões ao papel.
+	candidates := permissions
+	if scope, ok := c.Get("tokenScope"); ok && scope == core.TokenScopeDriver {
+		candidates = nil
+		for _, permission := range permissions {
+			if models.IsDriverPermission(permission) {
+				candidates = append(candidates, permission)
+			}
+		}
+		if len(candidates) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This session is restricted to driver permissions", "permission": permissions[0]})
+			return false
+		}
+	}
+
+	for _, permission := range candidates {
+		allowed, err := permissionService.HasPermission(user.(models.User), permission)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return false
+		}
+		if allowed {
+			return true
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource", "permission": permissions[0]})
+	return false
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+// allowAll concede qualquer permissão, para que os testes isolem o escopo da
+// chave de API.
+type allowAll struct {
+	services.PermissionService
+}
+
+func (allowAll) HasPermission(models.User, models.Permission) (bool, error) {
+	return true, nil
+}
+
+// newScopeTestRouter monta as rotas como no main: a autenticação coloca o
+// usuário (e a chave, quando informada) no contexto e cada rota declara o
+// seu escopo.
+func newScopeTestRouter(key *models.APIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("currentUser", models.User{ID: 1, OrganizationID: 1, Role: models.RoleClienteAtivo})
+		if key != nil {
+			c.Set("apiKey", *key)
+		}
+		c.Next()
+	})
+
+	require := NewPermissionGuard(allowAll{})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/vehicles", require(models.PermissionVehicleRead, models.ScopeVehiclesRead), ok)
+	router.POST("/vehicles/:id/odometer-readings", require(models.PermissionVehicleUpdate, models.ScopeTelemetryWrite), ok)
+	router.GET("/usage", require(models.PermissionUsageRead, models.ScopeReportsRead), ok)
+	router.GET("/api-keys", require(models.PermissionAPIKeyManage, models.ScopeNone), ok)
+	router.GET("/asset-tags", func(c *gin.Context) {
+		if CheckPermission(c, allowAll{}, models.PermissionVehicleRead) {
+			c.Status(http.StatusOK)
+		}
+	})
+	router.POST("/logout", SessionOnly(), ok)
+	return router
+}
+
+func TestAPIKeyScopesPerRoute(t *testing.T) {
+	key := &models.APIKey{Scopes: "vehicles:read telemetry:write reports:read"}
+	readOnly := &models.APIKey{Scopes: "vehicles:read"}
+
+	cases := []struct {
+		name   string
+		key    *models.APIKey
+		method string
+		path   string
+		want   int
+	}{
+		{"session passes every guard", nil, http.MethodGet, "/api-keys", http.StatusOK},
+		{"session passes session only routes", nil, http.MethodPost, "/logout", http.StatusOK},
+		{"key with the route scope", key, http.MethodGet, "/vehicles", http.StatusOK},
+		{"telemetry scope records readings", key, http.MethodPost, "/vehicles/1/odometer-readings", http.StatusOK},
+		{"reports scope reads usage", key, http.MethodGet, "/usage", http.StatusOK},
+		{"key missing the telemetry scope", readOnly, http.MethodPost, "/vehicles/1/odometer-readings", http.StatusForbidden},
+		{"key missing the reports scope", readOnly, http.MethodGet, "/usage", http.StatusForbidden},
+		{"route closed to keys", key, http.MethodGet, "/api-keys", http.StatusForbidden},
+		{"handler check without a declared scope", key, http.MethodGet, "/asset-tags", http.StatusForbidden},
+		{"session only route", key, http.MethodPost, "/logout", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		router := newScopeTestRouter(tc.key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api/internal/models"
+	"go-api/internal/services"
+)
+
+// TrialMiddleware deixa somente leitura as organizações cuja demonstração
+// terminou: consultas continuam liberadas, alterações são recusadas.
+func TrialMiddleware(organizationService services.OrganizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		user, exists := c.Get("currentUser")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+			return
+		}
+
+		org, err := organizationService.GetOrganization(user.(models.User).OrganizationID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load organization"})
+			return
+		}
+		if org.TrialExpired(time.Now()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "The demo trial has ended; the account is read-only until it is activated", "trial_ends_at": org.TrialEndsAt})
+			return
+		}
+
+		c.Next()
+	}
+}
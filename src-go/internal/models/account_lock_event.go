@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type AccountLockEventType string
+
+const (
+	AccountLockEventLocked   AccountLockEventType = "locked"
+	AccountLockEventUnlocked AccountLockEventType = "unlocked"
+)
+
+type AccountLockEvent struct {
+	ID             uint                 `gorm:"primaryKey"`
+	UserID         uint                 `gorm:"index;not null"`
+	OrganizationID uint                 `gorm:"index;not null"`
+	Event          AccountLockEventType `gorm:"size:20;not null"`
+	Reason         string               `gorm:"size:255"`
+	IPAddress      *string              `gorm:"size:45"`
+	ActorID        *uint
+	CreatedAt      time.Time
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyScope é o que uma chave de API pode fazer. Cada rota declara o seu
+// escopo junto com a permissão (middleware.PermissionGuard).
+type APIKeyScope string
+
+const (
+	ScopeVehiclesRead       APIKeyScope = "vehicles:read"
+	ScopeVehiclesWrite      APIKeyScope = "vehicles:write"
+	ScopeImplementsRead     APIKeyScope = "implements:read"
+	ScopeImplementsWrite    APIKeyScope = "implements:write"
+	ScopePartsRead          APIKeyScope = "parts:read"
+	ScopePartsWrite         APIKeyScope = "parts:write"
+	ScopeDocumentsRead      APIKeyScope = "documents:read"
+	ScopeDocumentsWrite     APIKeyScope = "documents:write"
+	ScopeUsersRead          APIKeyScope = "users:read"
+	ScopeUsersWrite         APIKeyScope = "users:write"
+	ScopeJourneysRead       APIKeyScope = "journeys:read"
+	ScopeJourneysWrite      APIKeyScope = "journeys:write"
+	ScopeFuelLogsRead       APIKeyScope = "fuel-logs:read"
+	ScopeFuelLogsWrite      APIKeyScope = "fuel-logs:write"
+	ScopeMaintenanceRead    APIKeyScope = "maintenance:read"
+	ScopeMaintenanceWrite   APIKeyScope = "maintenance:write"
+	ScopeFinesRead          APIKeyScope = "fines:read"
+	ScopeFinesWrite         APIKeyScope = "fines:write"
+	ScopeFreightOrdersRead  APIKeyScope = "freight-orders:read"
+	ScopeFreightOrdersWrite APIKeyScope = "freight-orders:write"
+	// Leituras de hodômetro enviadas por rastreadores e integrações de telemetria.
+	ScopeTelemetryWrite APIKeyScope = "telemetry:write"
+	// Relatórios da organização: histórico de status, linha do tempo do
+	// hodômetro e consumo do plano.
+	ScopeReportsRead APIKeyScope = "reports:read"
+
+	// ScopeNone marca as rotas que chaves de API não acessam (credenciais,
+	// permissões, configurações da organização).
+	ScopeNone APIKeyScope = ""
+)
+
+// APIKeyScopes lista os escopos que podem ser concedidos a uma chave.
+var APIKeyScopes = []APIKeyScope{
+	ScopeVehiclesRead, ScopeVehiclesWrite,
+	ScopeImplementsRead, ScopeImplementsWrite,
+	ScopePartsRead, ScopePartsWrite,
+	ScopeDocumentsRead, ScopeDocumentsWrite,
+	ScopeUsersRead, ScopeUsersWrite,
+	ScopeJourneysRead, ScopeJourneysWrite,
+	ScopeFuelLogsRead, ScopeFuelLogsWrite,
+	ScopeMaintenanceRead, ScopeMaintenanceWrite,
+	ScopeFinesRead, ScopeFinesWrite,
+	ScopeFreightOrdersRead, ScopeFreightOrdersWrite,
+	ScopeTelemetryWrite,
+	ScopeReportsRead,
+}
+
+// APIKey é uma credencial de integração (importadores, BI, telemetria) que
+// pertence à organização. Apenas o hash da chave é guardado.
+type APIKey struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"index;not null"`
+	Name           string `gorm:"size:100;not null"`
+	Prefix         string `gorm:"size:16;not null"`
+	KeyHash        string `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes         string `gorm:"size:1024;not null"`
+	CreatedByID    uint   `gorm:"not null"`
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+	RevokedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+// ScopeList devolve os escopos, guardados separados por espaço como no OAuth.
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.ScopeList() {
+		if APIKeyScope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package models
+
+// AssetType identifica o registro de uma etiqueta QR de patrimônio.
+type AssetType string
+
+const (
+	AssetTypeVehicle       AssetType = "vehicle"
+	AssetTypeImplement     AssetType = "implement"
+	AssetTypeInventoryItem AssetType = "inventory_item"
+)
+
+func IsValidAssetType(assetType AssetType) bool {
+	switch assetType {
+	case AssetTypeVehicle, AssetTypeImplement, AssetTypeInventoryItem:
+		return true
+	}
+	return false
+}
@@ -14,6 +14,17 @@ const (
 	FineStatusCanceled FineStatus = "Cancelada"
 )
 
+var FineStatuses = []FineStatus{FineStatusPending, FineStatusPaid, FineStatusAppealed, FineStatusCanceled}
+
+func IsValidFineStatus(status FineStatus) bool {
+	for _, s := range FineStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Fine struct {
 	gorm.Model
 	Description    string     `gorm:"size:255;not null"`
@@ -24,6 +35,7 @@ type Fine struct {
 	VehicleID      uint       `gorm:"not null"`
 	DriverID       *uint
 	OrganizationID uint       `gorm:"not null"`
+	Version        uint       `gorm:"not null;default:1"`
 	Vehicle        Vehicle
 	Driver         *User
 	Organization   Organization
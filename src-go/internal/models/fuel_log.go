@@ -35,6 +35,7 @@ type FuelLog struct {
 	GasStationLongitude   *float64
 	Source                FuelLogSource      `gorm:"type:fuel_log_source;not null;default:'MANUAL'"`
 	OrganizationID        uint               `gorm:"not null"`
+	Version               uint               `gorm:"not null;default:1"`
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
 }
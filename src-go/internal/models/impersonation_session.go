@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ImpersonationSession registra cada personificação feita por um super admin.
+// SessionID é o "sid" do token emitido; ImpersonatorSessionID é a sessão do
+// admin, restaurada quando a personificação termina.
+type ImpersonationSession struct {
+	ID                    uint    `gorm:"primaryKey"`
+	SessionID             string  `gorm:"size:36;uniqueIndex;not null"`
+	ImpersonatorID        uint    `gorm:"index;not null"`
+	ImpersonatorSessionID string  `gorm:"size:36;not null"`
+	TargetUserID          uint    `gorm:"index;not null"`
+	OrganizationID        uint    `gorm:"index;not null"`
+	IPAddress             *string `gorm:"size:45"`
+	ExpiresAt             time.Time
+	EndedAt               *time.Time
+	CreatedAt             time.Time
+}
@@ -12,6 +12,17 @@ const (
 	ImplementStatusMaintenance ImplementStatus = "maintenance"
 )
 
+var ImplementStatuses = []ImplementStatus{ImplementStatusAvailable, ImplementStatusInUse, ImplementStatusMaintenance}
+
+func IsValidImplementStatus(status ImplementStatus) bool {
+	for _, s := range ImplementStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Implement struct {
 	gorm.Model
 	Name           string          `gorm:"size:100;not null"`
@@ -22,5 +33,6 @@ type Implement struct {
 	Year           int             `gorm:"not null"`
 	Identifier     string          `gorm:"size:50"`
 	OrganizationID uint            `gorm:"not null"`
+	Version        uint            `gorm:"not null;default:1"`
 	Organization   Organization
 }
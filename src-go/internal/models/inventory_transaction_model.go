@@ -25,6 +25,7 @@ type InventoryTransaction struct {
 	Notes              *string         `gorm:"type:text"`
 	RelatedVehicleID   *uint
 	RelatedUserID      *uint
+	OrganizationID     uint            `gorm:"index;not null;default:0"`
 	Timestamp          time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP"`
 	Item               InventoryItem
 	Part               *Part
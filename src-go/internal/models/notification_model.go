@@ -38,6 +38,6 @@ type Notification struct {
 	RelatedEntityID   *uint
 	RelatedVehicleID  *uint
 	User              User
-	Vehicle           *Vehicle
+	Vehicle           *Vehicle `gorm:"foreignKey:RelatedVehicleID"`
 	Organization      Organization
 }
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type OdometerReadingSource string
+
+const (
+	OdometerSourceJourney   OdometerReadingSource = "journey"
+	OdometerSourceFuelLog   OdometerReadingSource = "fuel_log"
+	OdometerSourceTelemetry OdometerReadingSource = "telemetry"
+	OdometerSourceManual    OdometerReadingSource = "manual"
+)
+
+type OdometerReadingStatus string
+
+const (
+	OdometerReadingAccepted OdometerReadingStatus = "accepted"
+	// Salto implausível: a leitura fica registrada, mas só passa a valer para o
+	// veículo depois de aceita por um gestor.
+	OdometerReadingFlagged OdometerReadingStatus = "flagged"
+)
+
+// OdometerReading é uma leitura de hodômetro e/ou horímetro do veículo.
+type OdometerReading struct {
+	ID          uint `gorm:"primaryKey"`
+	VehicleID   uint `gorm:"not null;index"`
+	Odometer    *int
+	EngineHours *float64
+	Source      OdometerReadingSource `gorm:"size:20;not null"`
+	// Jornada ou abastecimento que originou a leitura.
+	SourceID       *uint
+	Status         OdometerReadingStatus `gorm:"size:20;not null;default:'accepted';index"`
+	FlagReason     *string               `gorm:"size:255"`
+	RecordedAt     time.Time             `gorm:"not null;index"`
+	RecordedByID   *uint
+	RecordedBy     *User `gorm:"constraint:OnDelete:SET NULL"`
+	OrganizationID uint  `gorm:"not null;index"`
+	CreatedAt      time.Time
+}
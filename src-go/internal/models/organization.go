@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Sector string
 
@@ -13,13 +16,88 @@ const (
 	Outros                  Sector = "Outros"
 )
 
+var Sectors = []Sector{TransporteDeCargas, TransporteDePassageiros, Agronegocio, Construcao, ServicosDeEntrega, Outros}
+
+func IsValidSector(sector Sector) bool {
+	for _, s := range Sectors {
+		if s == sector {
+			return true
+		}
+	}
+	return false
+}
+
+// Ciclo de vida da organização, controlado pelo super admin.
+type OrganizationStatus string
+
+const (
+	OrganizationStatusActive          OrganizationStatus = "active"
+	OrganizationStatusSuspended       OrganizationStatus = "suspended"
+	OrganizationStatusPendingDeletion OrganizationStatus = "pending_deletion"
+)
+
+var OrganizationStatuses = []OrganizationStatus{OrganizationStatusActive, OrganizationStatusSuspended, OrganizationStatusPendingDeletion}
+
+func IsValidOrganizationStatus(status OrganizationStatus) bool {
+	for _, s := range OrganizationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Organization struct {
-	ID           uint   `gorm:"primaryKey"`
-	Name         string `gorm:"size:100;index;not null"`
-	Sector       Sector `gorm:"type:sector;not null"`
-	VehicleLimit int    `gorm:"default:5;not null"`
-	DriverLimit  int    `gorm:"default:10;not null"`
-	Users        []User
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID     uint   `gorm:"primaryKey"`
+	Name   string `gorm:"size:100;index;not null"`
+	Sector Sector `gorm:"type:sector;not null"`
+	// Code identifica a organização no login por crachá dos motoristas.
+	Code *string `gorm:"size:12;uniqueIndex"`
+	// Último número usado em EmployeeID; incrementado a cada usuário criado.
+	EmployeeSequence int `gorm:"default:0;not null" json:"-"`
+	// Limites do plano; 0 significa ilimitado.
+	VehicleLimit             int `gorm:"default:5;not null"`
+	DriverLimit              int `gorm:"default:10;not null"`
+	JourneyMonthlyLimit      int `gorm:"default:0;not null"`
+	FreightOrderMonthlyLimit int `gorm:"default:0;not null"`
+	DocumentMonthlyLimit     int `gorm:"default:0;not null"`
+	// Obriga gestores (cliente_ativo/cliente_demo) a usar 2FA no login.
+	RequireTwoFactorForManagers bool `gorm:"default:false;not null"`
+	// Fim da demonstração das contas criadas pelo cadastro público. Depois
+	// dessa data a organização fica somente leitura até ser ativada.
+	TrialEndsAt *time.Time
+	// Organizações suspensas ou com exclusão agendada não acessam a API; os
+	// dados são apagados depois de DeletionScheduledAt.
+	Status              OrganizationStatus `gorm:"size:20;index;not null;default:'active'"`
+	SuspendedAt         *time.Time
+	SuspensionReason    *string `gorm:"size:255"`
+	DeletionScheduledAt *time.Time
+	Users               []User
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// FormatEmployeeID formata o número sequencial da matrícula (0001, 0002...).
+func FormatEmployeeID(number int) string {
+	return fmt.Sprintf("%04d", number)
+}
+
+func (o *Organization) TrialExpired(now time.Time) bool {
+	return o.TrialEndsAt != nil && now.After(*o.TrialEndsAt)
+}
+
+func (o *Organization) Limit(resource QuotaResource) int {
+	switch resource {
+	case QuotaVehicles:
+		return o.VehicleLimit
+	case QuotaDrivers:
+		return o.DriverLimit
+	case QuotaJourneys:
+		return o.JourneyMonthlyLimit
+	case QuotaFreightOrders:
+		return o.FreightOrderMonthlyLimit
+	case QuotaDocuments:
+		return o.DocumentMonthlyLimit
+	}
+	return 0
 }
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// UsageUnit é como a organização mede o uso da frota: quilômetros rodados ou
+// horas de motor (máquinas agrícolas).
+type UsageUnit string
+
+const (
+	UsageUnitKM          UsageUnit = "km"
+	UsageUnitEngineHours UsageUnit = "engine_hours"
+)
+
+func IsValidUsageUnit(unit UsageUnit) bool {
+	return unit == UsageUnitKM || unit == UsageUnitEngineHours
+}
+
+// OrganizationSettings guarda as preferências regionais da organização. Sem
+// registro salvo valem os padrões de DefaultOrganizationSettings.
+type OrganizationSettings struct {
+	ID             uint      `gorm:"primaryKey"`
+	OrganizationID uint      `gorm:"uniqueIndex;not null"`
+	TimeZone       string    `gorm:"size:64;not null"`              // nome IANA, ex.: America/Sao_Paulo
+	Currency       string    `gorm:"size:3;default:'BRL';not null"` // código ISO 4217, ex.: BRL
+	UsageUnit      UsageUnit `gorm:"size:20;not null"`
+	Locale         string    `gorm:"size:35;default:'pt-BR';not null"` // tag BCP 47, ex.: pt-BR
+	// Mês (1-12) em que começa o ano fiscal; define o período fiscal_year
+	// das listagens por data.
+	FiscalYearStartMonth int `gorm:"default:1;not null"`
+	UpdatedAt            time.Time
+}
+
+func DefaultOrganizationSettings(orgID uint, sector Sector) *OrganizationSettings {
+	unit := UsageUnitKM
+	if sector == Agronegocio {
+		unit = UsageUnitEngineHours
+	}
+	return &OrganizationSettings{
+		OrganizationID:       orgID,
+		TimeZone:             "America/Sao_Paulo",
+		Currency:             "BRL",
+		UsageUnit:            unit,
+		Locale:               "pt-BR",
+		FiscalYearStartMonth: 1,
+	}
+}
+
+// Location devolve o fuso da organização; um nome inválido salvo antes de
+// alguma mudança no tzdata cai para UTC.
+func (s *OrganizationSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// FiscalYearStart é o início, no fuso da organização, do ano fiscal que contém t.
+func (s *OrganizationSettings) FiscalYearStart(t time.Time) time.Time {
+	t = t.In(s.Location())
+	year := t.Year()
+	if int(t.Month()) < s.FiscalYearStartMonth {
+		year--
+	}
+	return s.FiscalYearBeginning(year)
+}
+
+// FiscalYearBeginning é o início, no fuso da organização, do ano fiscal que
+// começa no ano civil year.
+func (s *OrganizationSettings) FiscalYearBeginning(year int) time.Time {
+	return time.Date(year, time.Month(s.FiscalYearStartMonth), 1, 0, 0, 0, 0, s.Location())
+}
+
+// StartOfDay devolve a meia-noite, no fuso da organização, da data civil de t
+// (ano, mês e dia como foram informados, sem conversão de fuso).
+func (s *OrganizationSettings) StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location())
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// OrganizationSSOConfig liga a organização ao provedor OpenID Connect dela.
+// Com Enabled=false o login por senha continua sendo o único disponível.
+type OrganizationSSOConfig struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"uniqueIndex;not null"`
+	Issuer         string `gorm:"size:255;not null"`
+	ClientID       string `gorm:"size:255;not null"`
+	ClientSecret   string `gorm:"size:512" json:"-"`
+	Scopes         string `gorm:"size:255;not null"`
+	// Domínios de e-mail aceitos, separados por espaço. Vazio aceita qualquer um.
+	AllowedDomains string   `gorm:"size:512"`
+	DefaultRole    UserRole `gorm:"type:user_role;not null"`
+	AutoProvision  bool     `gorm:"default:true;not null"`
+	Enabled        bool     `gorm:"default:false;not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+// QuotaResource identifica um recurso limitado pelo plano da organização.
+type QuotaResource string
+
+const (
+	QuotaVehicles      QuotaResource = "vehicles"
+	QuotaDrivers       QuotaResource = "drivers"
+	QuotaJourneys      QuotaResource = "journeys"
+	QuotaFreightOrders QuotaResource = "freight_orders"
+	QuotaDocuments     QuotaResource = "documents"
+)
+
+// MonthlyQuotaResources são contados por mês em OrganizationUsage; os demais
+// são limitados pela quantidade cadastrada.
+var MonthlyQuotaResources = []QuotaResource{QuotaJourneys, QuotaFreightOrders, QuotaDocuments}
+
+// OrganizationUsage é o contador mensal de um recurso (equivalente ao
+// DemoUsage do backend Python). Excluir o registro não devolve a cota.
+type OrganizationUsage struct {
+	ID             uint          `gorm:"primaryKey"`
+	OrganizationID uint          `gorm:"uniqueIndex:idx_usage_org_resource_period;not null"`
+	Resource       QuotaResource `gorm:"size:32;uniqueIndex:idx_usage_org_resource_period;not null"`
+	Period         string        `gorm:"size:7;uniqueIndex:idx_usage_org_resource_period;not null"` // AAAA-MM
+	Count          int           `gorm:"default:0;not null"`
+}
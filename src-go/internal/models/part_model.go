@@ -28,8 +28,11 @@ type Part struct {
 	Location       *string `gorm:"size:100"`
 	Notes          *string `gorm:"type:text"`
 	PhotoURL       *string `gorm:"size:512"`
+	// Preenchida pelo upload da foto; usada nas listagens.
+	PhotoThumbnailURL *string `gorm:"size:512"`
 	LifespanKM     *int
 	OrganizationID uint `gorm:"not null"`
+	Version        uint `gorm:"not null;default:1"`
 	Organization   Organization
 	Items          []InventoryItem
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PasswordHistory guarda hashes de senhas anteriores para impedir que o
+// usuário volte a usar uma delas.
+type PasswordHistory struct {
+	ID             uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"index;not null"`
+	HashedPassword string `gorm:"size:255;not null" json:"-"`
+	CreatedAt      time.Time
+}
+
+// PasswordChange leva o hash substituído até o repositório, que o grava no
+// histórico na mesma transação da nova senha e mantém apenas os Keep mais
+// recentes do usuário.
+type PasswordChange struct {
+	PreviousHash string
+	Keep         int
+}
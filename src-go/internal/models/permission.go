@@ -0,0 +1,124 @@
+package models
+
+type Permission string
+
+const (
+	PermissionVehicleRead   Permission = "vehicle.read"
+	PermissionVehicleCreate Permission = "vehicle.create"
+	PermissionVehicleUpdate Permission = "vehicle.update"
+	PermissionVehicleDelete Permission = "vehicle.delete"
+
+	PermissionImplementRead   Permission = "implement.read"
+	PermissionImplementCreate Permission = "implement.create"
+	PermissionImplementUpdate Permission = "implement.update"
+	PermissionImplementDelete Permission = "implement.delete"
+
+	PermissionPartRead            Permission = "part.read"
+	PermissionPartCreate          Permission = "part.create"
+	PermissionPartUpdate          Permission = "part.update"
+	PermissionPartDelete          Permission = "part.delete"
+	PermissionPartManageInventory Permission = "part.manage_inventory"
+
+	PermissionDocumentRead   Permission = "document.read"
+	PermissionDocumentCreate Permission = "document.create"
+	PermissionDocumentDelete Permission = "document.delete"
+
+	PermissionUserRead   Permission = "user.read"
+	PermissionUserCreate Permission = "user.create"
+	PermissionUserUpdate Permission = "user.update"
+	PermissionUserDelete Permission = "user.delete"
+	PermissionUserUnlock Permission = "user.unlock"
+
+	PermissionSessionManage Permission = "session.manage"
+
+	PermissionJourneyRead   Permission = "journey.read"
+	PermissionJourneyStart  Permission = "journey.start"
+	PermissionJourneyEnd    Permission = "journey.end"
+	PermissionJourneyDelete Permission = "journey.delete"
+
+	PermissionFuelLogRead   Permission = "fuel_log.read"
+	PermissionFuelLogCreate Permission = "fuel_log.create"
+	PermissionFuelLogUpdate Permission = "fuel_log.update"
+	PermissionFuelLogDelete Permission = "fuel_log.delete"
+
+	PermissionMaintenanceRead    Permission = "maintenance.read"
+	PermissionMaintenanceCreate  Permission = "maintenance.create"
+	PermissionMaintenanceApprove Permission = "maintenance.approve"
+	PermissionMaintenanceDelete  Permission = "maintenance.delete"
+	PermissionMaintenanceComment Permission = "maintenance.comment"
+
+	PermissionFineRead   Permission = "fine.read"
+	PermissionFineCreate Permission = "fine.create"
+	PermissionFineUpdate Permission = "fine.update"
+	PermissionFineDelete Permission = "fine.delete"
+
+	PermissionFreightOrderRead    Permission = "freight_order.read"
+	PermissionFreightOrderCreate  Permission = "freight_order.create"
+	PermissionFreightOrderClaim   Permission = "freight_order.claim"
+	PermissionFreightOrderExecute Permission = "freight_order.execute"
+
+	PermissionAPIKeyManage     Permission = "api_key.manage"
+	PermissionPermissionManage Permission = "permission.manage"
+	PermissionSSOManage        Permission = "sso.manage"
+	PermissionUsageRead        Permission = "usage.read"
+
+	PermissionSettingsRead   Permission = "settings.read"
+	PermissionSettingsUpdate Permission = "settings.update"
+)
+
+// AllPermissions é o catálogo completo, na ordem exibida ao gestor.
+var AllPermissions = []Permission{
+	PermissionVehicleRead, PermissionVehicleCreate, PermissionVehicleUpdate, PermissionVehicleDelete,
+	PermissionImplementRead, PermissionImplementCreate, PermissionImplementUpdate, PermissionImplementDelete,
+	PermissionPartRead, PermissionPartCreate, PermissionPartUpdate, PermissionPartDelete, PermissionPartManageInventory,
+	PermissionDocumentRead, PermissionDocumentCreate, PermissionDocumentDelete,
+	PermissionUserRead, PermissionUserCreate, PermissionUserUpdate, PermissionUserDelete, PermissionUserUnlock,
+	PermissionSessionManage,
+	PermissionJourneyRead, PermissionJourneyStart, PermissionJourneyEnd, PermissionJourneyDelete,
+	PermissionFuelLogRead, PermissionFuelLogCreate, PermissionFuelLogUpdate, PermissionFuelLogDelete,
+	PermissionMaintenanceRead, PermissionMaintenanceCreate, PermissionMaintenanceApprove, PermissionMaintenanceDelete, PermissionMaintenanceComment,
+	PermissionFineRead, PermissionFineCreate, PermissionFineUpdate, PermissionFineDelete,
+	PermissionFreightOrderRead, PermissionFreightOrderCreate, PermissionFreightOrderClaim, PermissionFreightOrderExecute,
+	PermissionAPIKeyManage, PermissionPermissionManage, PermissionSSOManage, PermissionUsageRead,
+	PermissionSettingsRead, PermissionSettingsUpdate,
+}
+
+var driverPermissions = []Permission{
+	PermissionJourneyRead, PermissionJourneyStart, PermissionJourneyEnd,
+	PermissionFuelLogRead, PermissionFuelLogCreate,
+	PermissionMaintenanceRead, PermissionMaintenanceCreate, PermissionMaintenanceComment,
+	PermissionFineRead,
+	PermissionFreightOrderRead, PermissionFreightOrderClaim, PermissionFreightOrderExecute,
+	PermissionSettingsRead,
+}
+
+// IsDriverPermission indica se a permissão faz parte do conjunto padrão do
+// motorista, o máximo que uma sessão de crachá pode usar.
+func IsDriverPermission(permission Permission) bool {
+	for _, p := range driverPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions é o conjunto de cada papel antes das personalizações
+// da organização. O super admin usa as rotas /admin e não tem permissões nos
+// recursos das organizações.
+var DefaultRolePermissions = map[UserRole][]Permission{
+	RoleSuperAdmin:   {},
+	RoleClienteAtivo: AllPermissions,
+	RoleClienteDemo:  AllPermissions,
+	RoleDriver:       driverPermissions,
+}
+
+// OrganizationRolePermission guarda uma exceção da organização ao conjunto
+// padrão do papel: Granted=true concede, Granted=false retira.
+type OrganizationRolePermission struct {
+	ID             uint       `gorm:"primaryKey"`
+	OrganizationID uint       `gorm:"uniqueIndex:idx_org_role_permission;not null"`
+	Role           UserRole   `gorm:"uniqueIndex:idx_org_role_permission;size:20;not null"`
+	Permission     Permission `gorm:"uniqueIndex:idx_org_role_permission;size:50;not null"`
+	Granted        bool       `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RefreshToken é guardado apenas como hash. Todos os tokens gerados a partir do
+// mesmo login compartilham o FamilyID, que também é o "sid" do access token.
+type RefreshToken struct {
+	ID             uint      `gorm:"primaryKey"`
+	TokenHash      string    `gorm:"size:64;uniqueIndex;not null"`
+	FamilyID       string    `gorm:"size:36;index;not null"`
+	UserID         uint      `gorm:"index;not null"`
+	OrganizationID uint      `gorm:"not null"`
+	ExpiresAt      time.Time `gorm:"not null"`
+	RevokedAt      *time.Time
+	ReplacedByID   *uint
+	CreatedAt      time.Time
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -14,20 +15,44 @@ const (
 )
 
 type User struct {
-	ID                          uint      `gorm:"primaryKey"`
-	FullName                    string    `gorm:"size:100;index;not null"`
-	Email                       string    `gorm:"size:100;uniqueIndex;not null"`
-	HashedPassword              string    `gorm:"size:255;not null"`
-	EmployeeID                  string    `gorm:"size:50;uniqueIndex;not null"`
-	Role                        UserRole  `gorm:"type:user_role;not null"`
-	IsActive                    bool      `gorm:"default:true"`
-	AvatarURL                   *string   `gorm:"size:512"`
-	NotifyInApp                 bool      `gorm:"default:true;not null"`
-	NotifyByEmail               bool      `gorm:"default:true;not null"`
-	NotificationEmail           *string   `gorm:"size:100"`
-	ResetPasswordToken          *string   `gorm:"size:255;index"`
+	ID                          uint     `gorm:"primaryKey"`
+	FullName                    string   `gorm:"size:100;index;not null"`
+	Email                       string   `gorm:"size:100;uniqueIndex;not null"`
+	HashedPassword              string   `gorm:"size:255;not null"`
+	EmployeeID                  string   `gorm:"size:50;uniqueIndex:idx_users_org_employee;not null"`
+	Role                        UserRole `gorm:"type:user_role;not null"`
+	IsActive                    bool     `gorm:"default:true"`
+	AvatarURL                   *string  `gorm:"size:512"`
+	// Preenchida pelo upload do avatar; usada nas listagens.
+	AvatarThumbnailURL          *string  `gorm:"size:512"`
+	NotifyInApp                 bool     `gorm:"default:true;not null"`
+	NotifyByEmail               bool     `gorm:"default:true;not null"`
+	NotificationEmail           *string  `gorm:"size:100"`
+	ResetPasswordToken          *string  `gorm:"size:255;index"`
 	ResetPasswordTokenExpiresAt *time.Time
-	OrganizationID              uint      `gorm:"not null"`
-	CreatedAt                   time.Time
-	UpdatedAt                   time.Time
+	// Enquanto houver token o e-mail do cadastro ainda não foi confirmado.
+	EmailVerificationToken          *string    `gorm:"size:255;index" json:"-"`
+	EmailVerificationTokenExpiresAt *time.Time `json:"-"`
+	TwoFactorEnabled                bool       `gorm:"default:false;not null"`
+	TwoFactorSecret                 *string    `gorm:"size:64" json:"-"`
+	TwoFactorLastUsedStep           int64      `gorm:"default:0;not null" json:"-"`
+	// PIN do login por crachá (somente motoristas). É apagado depois de
+	// BADGE_PIN_MAX_FAILED_ATTEMPTS falhas seguidas e precisa ser redefinido.
+	BadgePINHash           *string `gorm:"size:255" json:"-"`
+	BadgePINFailedAttempts int     `gorm:"default:0;not null" json:"-"`
+	OrganizationID         uint    `gorm:"uniqueIndex:idx_users_org_employee;not null"`
+	// Quando um cliente_demo foi ativado pelo super admin (conversão da demonstração).
+	ActivatedAt *time.Time
+	// Preenchido pela política de senha e consumido pelo repositório ao
+	// gravar o usuário.
+	PasswordChange *PasswordChange `gorm:"-" json:"-"`
+	Version     uint `gorm:"not null;default:1"`
+	CreatedAt   time.Time
+	UpdatedAt              time.Time
+}
+
+// NormalizeEmail é a forma em que o e-mail é gravado e comparado: sem
+// espaços e em minúsculas, para que o mesmo endereço não vire duas contas.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
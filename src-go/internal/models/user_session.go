@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// UserSession é criada a cada login. SessionID é o "sid" dos access tokens e o
+// FamilyID dos refresh tokens da sessão.
+type UserSession struct {
+	ID             uint   `gorm:"primaryKey"`
+	SessionID      string `gorm:"size:36;uniqueIndex;not null"`
+	UserID         uint   `gorm:"index;not null"`
+	OrganizationID uint   `gorm:"index;not null"`
+	Device         string `gorm:"size:100"`
+	IPAddress      string `gorm:"size:45"`
+	UserAgent      string `gorm:"size:512"`
+	// Scope restringe as permissões dos tokens da sessão (ex.: login por crachá).
+	Scope      string `gorm:"size:20"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
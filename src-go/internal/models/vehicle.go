@@ -10,8 +10,39 @@ const (
 	StatusAvailable   VehicleStatus = "Disponível"
 	StatusInUse       VehicleStatus = "Em uso"
 	StatusMaintenance VehicleStatus = "Em manutenção"
+	StatusRetired     VehicleStatus = "Desativado"
 )
 
+var VehicleStatuses = []VehicleStatus{StatusAvailable, StatusInUse, StatusMaintenance, StatusRetired}
+
+// vehicleStatusTransitions lista, para cada status, os próximos permitidos.
+// "Em uso" só é alcançado (e deixado para "Disponível") pelas jornadas; um
+// veículo pode quebrar no meio da jornada e ir direto para a manutenção.
+var vehicleStatusTransitions = map[VehicleStatus][]VehicleStatus{
+	StatusAvailable:   {StatusInUse, StatusMaintenance, StatusRetired},
+	StatusInUse:       {StatusAvailable, StatusMaintenance},
+	StatusMaintenance: {StatusAvailable, StatusRetired},
+	StatusRetired:     {StatusAvailable},
+}
+
+func CanTransitionVehicleStatus(from, to VehicleStatus) bool {
+	for _, s := range vehicleStatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidVehicleStatus(status VehicleStatus) bool {
+	for _, s := range VehicleStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Vehicle struct {
 	ID                   uint          `gorm:"primaryKey"`
 	Brand                string        `gorm:"size:50;not null"`
@@ -20,6 +51,8 @@ type Vehicle struct {
 	Identifier           *string       `gorm:"size:50"`
 	Year                 int           `gorm:"not null"`
 	PhotoURL             *string       `gorm:"size:512"`
+	// Preenchida pelo upload da foto; usada nas listagens.
+	PhotoThumbnailURL    *string       `gorm:"size:512"`
 	Status               VehicleStatus `gorm:"type:vehicle_status;not null;default:'Disponível'"`
 	CurrentKM            int           `gorm:"not null;default:0"`
 	CurrentEngineHours   *float64
@@ -31,6 +64,8 @@ type Vehicle struct {
 	NextMaintenanceKM    *int
 	MaintenanceNotes     *string `gorm:"type:text"`
 	OrganizationID       uint    `gorm:"not null"`
+	// Incrementada a cada gravação; exposta como ETag para o If-Match.
+	Version              uint    `gorm:"not null;default:1"`
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
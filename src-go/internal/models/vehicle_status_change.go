@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// VehicleStatusChange registra uma transição de status do veículo.
+type VehicleStatusChange struct {
+	ID         uint          `gorm:"primaryKey"`
+	VehicleID  uint          `gorm:"not null;index"`
+	FromStatus VehicleStatus `gorm:"size:30;not null"`
+	ToStatus   VehicleStatus `gorm:"size:30;not null"`
+	Reason     string        `gorm:"type:text"`
+	// Vazio quando a mudança é feita pelo sistema.
+	ActorID        *uint
+	Actor          *User `gorm:"constraint:OnDelete:SET NULL"`
+	JourneyID      *uint
+	OrganizationID uint      `gorm:"not null;index"`
+	ChangedAt      time.Time `gorm:"not null;index"`
+}
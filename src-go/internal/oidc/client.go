@@ -0,0 +1,356 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var ErrInvalidIDToken = errors.New("invalid id token")
+var ErrPrivateAddress = errors.New("identity provider resolves to a private address")
+
+// Provider contém os endpoints publicados pelo IdP em
+// /.well-known/openid-configuration.
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDTokenClaims são as claims do id_token usadas para identificar o usuário.
+type IDTokenClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified *bool
+	Name          string
+}
+
+// Client fala com qualquer IdP compatível com OpenID Connect (Azure AD, Google,
+// Keycloak ou um mock local nos testes).
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient com httpClient nil usa NewPublicHTTPClient: o issuer é informado
+// pelo cliente, então o servidor não pode ser usado para alcançar a rede interna.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = NewPublicHTTPClient()
+	}
+	return &Client{httpClient: httpClient}
+}
+
+// NewPublicHTTPClient só conecta em endereços públicos. A verificação é feita
+// no IP já resolvido, a cada conexão, e vale também para os endpoints do
+// discovery e para redirecionamentos, inclusive se o DNS mudar depois da
+// configuração.
+func NewPublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: refusePrivateAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		carrierGradeNAT.Contains(ip))
+}
+
+// 100.64.0.0/10 (RFC 6598) não entra no IsPrivate, mas também é rede interna.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	var provider Provider
+	if err := c.getJSON(ctx, wellKnown, &provider); err != nil {
+		return nil, err
+	}
+	// OIDC Discovery 4.3: o issuer publicado precisa ser idêntico ao configurado.
+	if provider.Issuer != issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %q, got %q", issuer, provider.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("incomplete openid configuration")
+	}
+	// O client secret e o code vão para o token endpoint; as chaves, do jwks_uri.
+	for _, endpoint := range []string{provider.AuthorizationEndpoint, provider.TokenEndpoint, provider.JWKSURI} {
+		if parsed, err := url.Parse(endpoint); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return nil, fmt.Errorf("openid configuration endpoint %q must use https", endpoint)
+		}
+	}
+	return &provider, nil
+}
+
+// AuthCodeURL monta a URL de autorização com PKCE (S256) e nonce.
+func (p *Provider) AuthCodeURL(clientID, redirectURI, scopes, state, nonce, codeVerifier string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", scopes)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallengeS256(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// Exchange troca o authorization code pelo id_token.
+func (c *Client) Exchange(ctx context.Context, provider *Provider, clientID, clientSecret, redirectURI, code, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", clientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("token endpoint returned %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("token endpoint error: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response without id_token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken valida assinatura (chaves do jwks_uri), issuer, audience,
+// expiração e nonce do id_token.
+func (c *Client) VerifyIDToken(ctx context.Context, provider *Provider, rawIDToken, clientID, nonce string) (*IDTokenClaims, error) {
+	keys, err := c.fetchKeys(ctx, provider.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range keys {
+			if kid != "" && key.kid != kid {
+				continue
+			}
+			if key.alg != "" && key.alg != token.Method.Alg() {
+				continue
+			}
+			switch key.public.(type) {
+			case *rsa.PublicKey:
+				if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+					return key.public, nil
+				}
+			case *ecdsa.PublicKey:
+				if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+					return key.public, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no matching key for kid %q", kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+
+	if iss, _ := claims["iss"].(string); iss != provider.Issuer {
+		return nil, ErrInvalidIDToken
+	}
+	if !audienceContains(claims["aud"], clientID) {
+		return nil, ErrInvalidIDToken
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrInvalidIDToken
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	result := &IDTokenClaims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = &verified
+	case string:
+		// Alguns IdPs (ex.: Cognito) mandam o booleano como string.
+		value := verified == "true"
+		result.EmailVerified = &value
+	}
+	if result.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+	return result, nil
+}
+
+// GenerateCodeVerifier gera o code_verifier do PKCE (RFC 7636, 43 caracteres).
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type verificationKey struct {
+	kid    string
+	alg    string
+	public interface{}
+}
+
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) ([]verificationKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []verificationKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys = append(keys, verificationKey{kid: jwk.Kid, alg: jwk.Alg, public: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}})
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys = append(keys, verificationKey{kid: jwk.Kid, alg: jwk.Alg, public: &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
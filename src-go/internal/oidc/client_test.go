@@ -0,0 +1,44 @@
+package oidc
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":          true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.0.0.5":         false,
+		"172.16.3.4":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	}
+	for address, want := range cases {
+		if got := isPublicIP(net.ParseIP(address)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestPublicHTTPClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	_, err := NewPublicHTTPClient().Get(server.URL)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("got %v, want ErrPrivateAddress", err)
+	}
+}
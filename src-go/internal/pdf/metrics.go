@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"unicode"
+)
+
+// Larguras dos caracteres 32 a 126 (em milésimos do tamanho da fonte), das
+// métricas AFM das fontes padrão.
+var charWidths = [...][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+const ellipsis = "…"
+
+func charWidth(font Font, r rune) int {
+	switch {
+	case r >= 32 && r <= 126:
+		return charWidths[font][r-32]
+	case r == '…':
+		return 1000
+	case unicode.IsUpper(r):
+		// Maiúsculas acentuadas têm a largura da letra base, entre 667 e 778.
+		return 722
+	default:
+		return 556
+	}
+}
+
+// TextWidth devolve a largura do texto em pontos.
+func TextWidth(font Font, size float64, text string) float64 {
+	total := 0
+	for _, r := range text {
+		total += charWidth(font, r)
+	}
+	return float64(total) * size / 1000
+}
+
+// Truncate corta o texto com reticências para caber na largura.
+func Truncate(font Font, size, width float64, text string) string {
+	if TextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(font, size, string(runes)+ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ellipsis
+}
@@ -0,0 +1,158 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Gerador mínimo de PDF para as folhas de etiquetas: retângulos preenchidos e
+// texto nas fontes padrão Helvetica, que todo leitor de PDF já traz, então
+// nenhuma fonte é embutida. Medidas em pontos (1/72 pol.) e coordenadas a
+// partir do canto superior esquerdo da página.
+
+// Tamanho A4 em pontos.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// MM converte milímetros em pontos.
+func MM(mm float64) float64 {
+	return mm * 72 / 25.4
+}
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{Helvetica: "Helvetica", HelveticaBold: "Helvetica-Bold"}
+
+type Document struct {
+	width  float64
+	height float64
+	title  string
+	pages  []*Page
+}
+
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+func (d *Document) AddPage() *Page {
+	page := &Page{height: d.height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Page acumula os operadores de desenho da página.
+type Page struct {
+	height  float64
+	content bytes.Buffer
+}
+
+// FillRect preenche o retângulo de preto.
+func (p *Page) FillRect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(p.height-y-height), num(width), num(height))
+}
+
+// Text escreve uma linha com a base do texto em y. Caracteres fora do
+// Windows-1252 viram "?".
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(p.height-y), escape(winAnsi(text)))
+}
+
+// Write grava o documento completo.
+func (d *Document) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objetos fixos: 1 catálogo, 2 árvore de páginas, 3 informações e 4-5
+	// fontes. Cada página usa dois objetos a partir do 6: a página e o conteúdo.
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (TruCar) >>", escape(winAnsi(d.title))))
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			num(d.width), num(d.height), firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// num grava as medidas com duas casas, precisão de sobra para a impressão.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// winAnsi converte o texto para o Windows-1252 usado pelas fontes padrão.
+// Os acentos do português estão todos no intervalo Latin-1.
+func winAnsi(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsiExtra[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`).Replace(text)
+}
@@ -0,0 +1,218 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parsed é o documento lido pela tabela xref, como faz um leitor de PDF.
+type parsed struct {
+	raw     []byte
+	objects map[int]string
+	trailer string
+}
+
+func parse(t *testing.T, raw []byte) parsed {
+	t.Helper()
+	if !bytes.HasPrefix(raw, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing header: %q", raw[:min(len(raw), 16)])
+	}
+	if !bytes.HasSuffix(raw, []byte("%%EOF\n")) {
+		t.Fatal("missing EOF marker")
+	}
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(raw)
+	if match == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(raw[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the xref table", xref)
+	}
+
+	lines := strings.Split(string(raw[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("invalid xref subsection %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Fatalf("invalid free entry %q", lines[2])
+	}
+
+	doc := parsed{raw: raw, objects: map[int]string{}}
+	for n := 1; n < count; n++ {
+		entry := lines[2+n]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("invalid xref entry %q", entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		if !bytes.HasPrefix(raw[offset:], []byte(header)) {
+			t.Fatalf("xref offset %d of object %d points to %q", offset, n, raw[offset:offset+min(len(raw)-offset, 12)])
+		}
+		body := raw[offset+len(header):]
+		end := bytes.Index(body, []byte("\nendobj\n"))
+		if end < 0 {
+			t.Fatalf("object %d has no endobj", n)
+		}
+		doc.objects[n] = string(body[:end])
+	}
+
+	trailer := strings.Join(lines[2+count:], "\n")
+	if !strings.HasPrefix(trailer, "trailer\n") {
+		t.Fatalf("missing trailer after %d xref entries", count)
+	}
+	if !strings.Contains(trailer, fmt.Sprintf("/Size %d ", count)) {
+		t.Fatalf("trailer %q does not declare /Size %d", trailer, count)
+	}
+	doc.trailer = trailer
+	return doc
+}
+
+// ref devolve o número do objeto referenciado por "/Key N 0 R".
+func (d parsed) ref(t *testing.T, object, key string) int {
+	t.Helper()
+	match := regexp.MustCompile(`/` + key + ` (\d+) 0 R`).FindStringSubmatch(object)
+	if match == nil {
+		t.Fatalf("no /%s reference in %q", key, object)
+	}
+	n, _ := strconv.Atoi(match[1])
+	if _, ok := d.objects[n]; !ok {
+		t.Fatalf("/%s references missing object %d", key, n)
+	}
+	return n
+}
+
+// content confere o /Length do stream e devolve o conteúdo descomprimido.
+func (d parsed) content(t *testing.T, n int) string {
+	t.Helper()
+	object := d.objects[n]
+	match := regexp.MustCompile(`^<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindStringSubmatch(object)
+	if match == nil {
+		t.Fatalf("object %d is not a Flate stream: %q", n, object[:min(len(object), 60)])
+	}
+	length, _ := strconv.Atoi(match[1])
+	stream := object[len(match[0]):]
+	if len(stream) != length+len("\nendstream") || !strings.HasSuffix(stream, "\nendstream") {
+		t.Fatalf("object %d: /Length %d does not match the stream", n, length)
+	}
+	zr, err := zlib.NewReader(strings.NewReader(stream[:length]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func write(t *testing.T, doc *Document) parsed {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return parse(t, buf.Bytes())
+}
+
+func TestWriteStructure(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.SetTitle("Etiquetas (teste)")
+	first := doc.AddPage()
+	first.FillRect(MM(10), MM(20), 30, 40)
+	first.Text(100, 200, HelveticaBold, 10, "Veículo")
+	second := doc.AddPage()
+	second.Text(50, 60, Helvetica, 7.5, `a (b) \ c`)
+
+	out := write(t, doc)
+	catalog := out.objects[out.ref(t, out.trailer, "Root")]
+	if !strings.Contains(catalog, "/Type /Catalog") {
+		t.Fatalf("root is not a catalog: %q", catalog)
+	}
+	info := out.objects[out.ref(t, out.trailer, "Info")]
+	if !strings.Contains(info, `/Title (Etiquetas \(teste\))`) {
+		t.Fatalf("info %q has no escaped title", info)
+	}
+
+	pages := out.objects[out.ref(t, catalog, "Pages")]
+	kids := regexp.MustCompile(`(\d+) 0 R`).FindAllStringSubmatch(regexp.MustCompile(`/Kids \[(.*?)\]`).FindString(pages), -1)
+	if len(kids) != 2 || !strings.Contains(pages, "/Count 2") {
+		t.Fatalf("page tree %q, want 2 kids", pages)
+	}
+
+	var contents []string
+	for _, kid := range kids {
+		n, _ := strconv.Atoi(kid[1])
+		page := out.objects[n]
+		if !strings.Contains(page, "/Type /Page ") || !strings.Contains(page, "/MediaBox [0 0 595.28 841.89]") {
+			t.Fatalf("page object %d: %q", n, page)
+		}
+		for _, font := range []string{"Helvetica", "Helvetica-Bold"} {
+			key := map[string]string{"Helvetica": "F1", "Helvetica-Bold": "F2"}[font]
+			if f := out.objects[out.ref(t, page, key)]; !strings.Contains(f, "/BaseFont /"+font+" ") || !strings.Contains(f, "/WinAnsiEncoding") {
+				t.Fatalf("/%s is %q, want %s", key, f, font)
+			}
+		}
+		contents = append(contents, out.content(t, out.ref(t, page, "Contents")))
+	}
+
+	// A origem do PDF é o canto inferior esquerdo: y = altura - y - h.
+	wantFirst := "28.35 745.2 30 40 re f\nBT /F2 10 Tf 100 641.89 Td (Ve\xedculo) Tj ET\n"
+	if contents[0] != wantFirst {
+		t.Errorf("first page content %q, want %q", contents[0], wantFirst)
+	}
+	wantSecond := "BT /F1 7.5 Tf 50 781.89 Td (a \\(b\\) \\\\ c) Tj ET\n"
+	if contents[1] != wantSecond {
+		t.Errorf("second page content %q, want %q", contents[1], wantSecond)
+	}
+}
+
+func TestWriteWithoutPages(t *testing.T) {
+	out := write(t, New(A4Width, A4Height))
+	pages := out.objects[out.ref(t, out.objects[out.ref(t, out.trailer, "Root")], "Pages")]
+	if !strings.Contains(pages, "/Kids [] /Count 0") {
+		t.Fatalf("page tree %q, want no kids", pages)
+	}
+}
+
+func TestWinAnsi(t *testing.T) {
+	tests := map[string]string{
+		"Patrimônio":     "Patrim\xf4nio",
+		"ÁÉÍÓÚ çã":       "\xc1\xc9\xcd\xd3\xda \xe7\xe3",
+		"€ – … “x”":      "\x80 \x96 \x85 \x93x\x94",
+		"日本 ok":          "?? ok",
+		"plain ASCII 1!": "plain ASCII 1!",
+	}
+	for in, want := range tests {
+		if got := winAnsi(in); got != want {
+			t.Errorf("winAnsi(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := TextWidth(Helvetica, 10, "Hi"); got != 9.44 {
+		t.Fatalf("TextWidth = %v, want 9.44", got)
+	}
+	if got := Truncate(Helvetica, 10, 100, "curto"); got != "curto" {
+		t.Fatalf("short text truncated to %q", got)
+	}
+	long := "Trator John Deere 6125J com carregador frontal"
+	got := Truncate(HelveticaBold, 10, 80, long)
+	if !strings.HasSuffix(got, "…") || !strings.HasPrefix(long, strings.TrimSuffix(got, "…")) {
+		t.Fatalf("Truncate = %q", got)
+	}
+	if TextWidth(HelveticaBold, 10, got) > 80 {
+		t.Fatalf("%q is wider than 80pt", got)
+	}
+	if next := strings.TrimSuffix(got, "…") + string([]rune(long)[len([]rune(got))-1]) + "…"; TextWidth(HelveticaBold, 10, next) <= 80 {
+		t.Fatalf("%q was cut shorter than needed", got)
+	}
+}
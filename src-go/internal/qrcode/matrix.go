@@ -0,0 +1,265 @@
+package qrcode
+
+// matrix é o símbolo em construção. function marca os módulos dos padrões
+// fixos (localizadores, temporização, alinhamento, formato e versão), que não
+// recebem dados nem máscara.
+type matrix struct {
+	version  int
+	size     int
+	dark     [][]bool
+	function [][]bool
+}
+
+func newMatrix(version int) *matrix {
+	size := 17 + 4*version
+	m := &matrix{version: version, size: size, dark: make([][]bool, size), function: make([][]bool, size)}
+	for y := 0; y < size; y++ {
+		m.dark[y] = make([]bool, size)
+		m.function[y] = make([]bool, size)
+	}
+	return m
+}
+
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.dark[y][x] = dark
+	m.function[y][x] = true
+}
+
+func (m *matrix) drawFunctionPatterns() {
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	positions := versions[m.version].alignment
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Os cantos ocupados pelos localizadores ficam sem alinhamento.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	// Reserva as áreas de formato; os bits são gravados depois da máscara.
+	m.drawFormatBits(0)
+	m.drawVersionBits()
+}
+
+// drawFinder desenha o localizador centrado em (cx, cy) com a borda clara.
+func (m *matrix) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= m.size || y < 0 || y >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits grava o nível de correção (M = 00) e a máscara, protegidos
+// por BCH(15,5), nas duas cópias em volta dos localizadores.
+func (m *matrix) drawFormatBits(mask int) {
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(bits, i))
+	}
+	m.setFunction(8, 7, bit(bits, 6))
+	m.setFunction(8, 8, bit(bits, 7))
+	m.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(bits, i))
+	}
+	m.setFunction(8, m.size-8, true)
+}
+
+// drawVersionBits grava a versão, protegida por BCH(18,6), a partir da 7.
+func (m *matrix) drawVersionBits() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, bit(bits, i))
+		m.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords percorre o símbolo em zigue-zague, de duas em duas colunas a
+// partir do canto inferior direito, pulando a coluna de temporização.
+func (m *matrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				m.dark[y][x] = bit(int(codewords[i/8]), 7-i%8)
+				i++
+			}
+		}
+	}
+}
+
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				m.dark[y][x] = !m.dark[y][x]
+			}
+		}
+	}
+}
+
+// finderLike são as sequências 1:1:3:1:1 com 4 módulos claros de um dos
+// lados, que confundem o leitor com um localizador.
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty aplica as quatro regras de avaliação de máscara da especificação.
+func (m *matrix) penalty() int {
+	penalty := 0
+	for i := 0; i < m.size; i++ {
+		penalty += m.linePenalty(func(j int) bool { return m.at(j, i) })
+		penalty += m.linePenalty(func(j int) bool { return m.at(i, j) })
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.dark[y][x] {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size {
+				c := m.dark[y][x]
+				if c == m.dark[y][x+1] && c == m.dark[y+1][x] && c == m.dark[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	percent := dark * 100 / (m.size * m.size)
+	return penalty + abs(percent-50)/5*10
+}
+
+// linePenalty avalia uma linha ou coluna: sequências de 5 ou mais módulos
+// iguais e padrões parecidos com localizadores (fora do símbolo é claro).
+func (m *matrix) linePenalty(at func(int) bool) int {
+	penalty := 0
+	run := 1
+	for j := 1; j <= m.size; j++ {
+		if j < m.size && at(j) == at(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for start := -4; start+len(finderLike[0]) <= m.size+4; start++ {
+		for _, pattern := range finderLike {
+			matches := true
+			for k, dark := range pattern {
+				if at(start+k) != dark {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				penalty += 40
+			}
+		}
+	}
+	return penalty
+}
+
+// at devolve o módulo, considerando claro o que está fora do símbolo.
+func (m *matrix) at(x, y int) bool {
+	if x < 0 || x >= m.size || y < 0 || y >= m.size {
+		return false
+	}
+	return m.dark[y][x]
+}
+
+func bit(value, i int) bool {
+	return (value>>i)&1 == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,182 @@
+package qrcode
+
+import (
+	"errors"
+)
+
+// Códigos QR das etiquetas de patrimônio, só com a biblioteca padrão: modo
+// byte, correção de erros nível M (recupera ~15% do símbolo, o bastante para
+// etiquetas riscadas no pátio) e versões 1 a 10 (até 213 bytes).
+
+var ErrDataTooLong = errors.New("data too long for a QR code")
+
+// MaxVersion limita o tamanho do símbolo: com 57x57 módulos o código ainda é
+// lido com folga em uma etiqueta de 3 cm.
+const MaxVersion = 10
+
+// blockGroup é um grupo de blocos com o mesmo número de codewords de dados.
+type blockGroup struct {
+	blocks    int
+	dataWords int
+}
+
+// versionInfo traz, para o nível M, os codewords de correção por bloco e os
+// grupos de blocos de cada versão (tabela 9 da ISO/IEC 18004).
+type versionInfo struct {
+	ecWords   int
+	groups    []blockGroup
+	alignment []int
+}
+
+var versions = [MaxVersion + 1]versionInfo{
+	1:  {10, []blockGroup{{1, 16}}, nil},
+	2:  {16, []blockGroup{{1, 28}}, []int{6, 18}},
+	3:  {26, []blockGroup{{1, 44}}, []int{6, 22}},
+	4:  {18, []blockGroup{{2, 32}}, []int{6, 26}},
+	5:  {24, []blockGroup{{2, 43}}, []int{6, 30}},
+	6:  {16, []blockGroup{{4, 27}}, []int{6, 34}},
+	7:  {18, []blockGroup{{4, 31}}, []int{6, 22, 38}},
+	8:  {22, []blockGroup{{2, 38}, {2, 39}}, []int{6, 24, 42}},
+	9:  {22, []blockGroup{{3, 36}, {2, 37}}, []int{6, 26, 46}},
+	10: {26, []blockGroup{{4, 43}, {1, 44}}, []int{6, 28, 50}},
+}
+
+func (v versionInfo) dataWords() int {
+	total := 0
+	for _, g := range v.groups {
+		total += g.blocks * g.dataWords
+	}
+	return total
+}
+
+// Code é o símbolo gerado. Dark(x, y) indica os módulos escuros; quem
+// desenha deve deixar uma margem clara de 4 módulos em volta.
+type Code struct {
+	Version int
+	Size    int
+	modules [][]bool
+}
+
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode gera o código na menor versão em que os dados cabem, com a máscara
+// de menor penalidade.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= versions[v].dataWords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrDataTooLong
+	}
+
+	codewords := interleave(versions[version], encodeData(data, version))
+
+	var best *matrix
+	bestPenalty := -1
+	for mask := 0; mask < 8; mask++ {
+		m := build(version, codewords, mask)
+		if penalty := m.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = m, penalty
+		}
+	}
+	return &Code{Version: version, Size: best.size, modules: best.dark}, nil
+}
+
+// build desenha o símbolo completo com a máscara indicada.
+func build(version int, codewords []byte, mask int) *matrix {
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+	m.applyMask(mask)
+	m.drawFormatBits(mask)
+	return m
+}
+
+// countBits é o tamanho do contador de bytes no modo byte.
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// encodeData monta os codewords de dados: modo, contador, bytes, terminador e
+// os bytes de preenchimento alternados 0xEC/0x11.
+func encodeData(data []byte, version int) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := versions[version].dataWords() * 8
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// interleave divide os dados em blocos, calcula a correção de cada um e
+// intercala os codewords na ordem em que são posicionados no símbolo.
+func interleave(info versionInfo, data []byte) []byte {
+	var blocks, ecBlocks [][]byte
+	divisor := rsDivisor(info.ecWords)
+	offset := 0
+	for _, g := range info.groups {
+		for i := 0; i < g.blocks; i++ {
+			block := data[offset : offset+g.dataWords]
+			offset += g.dataWords
+			blocks = append(blocks, block)
+			ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+		}
+	}
+
+	var result []byte
+	longest := info.groups[len(info.groups)-1].dataWords
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.ecWords; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>i)&1 == 1)
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	result := make([]byte, (len(b.bits)+7)/8)
+	for i, bit := range b.bits {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
@@ -0,0 +1,458 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os testes leem os símbolos com um decodificador próprio, escrito a partir da
+// ISO/IEC 18004 sem usar as tabelas nem o desenho do pacote: formato, máscara,
+// leitura em zigue-zague, desintercalação, síndromes de Reed-Solomon e
+// segmento em modo byte.
+
+// refBlocks são, para o nível M, os codewords de correção por bloco e os
+// blocos (quantidade e codewords de dados) de cada versão.
+var refBlocks = map[int]struct {
+	ec     int
+	groups [][2]int
+}{
+	1:  {10, [][2]int{{1, 16}}},
+	2:  {16, [][2]int{{1, 28}}},
+	3:  {26, [][2]int{{1, 44}}},
+	4:  {18, [][2]int{{2, 32}}},
+	5:  {24, [][2]int{{2, 43}}},
+	6:  {16, [][2]int{{4, 27}}},
+	7:  {18, [][2]int{{4, 31}}},
+	8:  {22, [][2]int{{2, 38}, {2, 39}}},
+	9:  {22, [][2]int{{3, 36}, {2, 37}}},
+	10: {26, [][2]int{{4, 43}, {1, 44}}},
+}
+
+// refCapacity é a capacidade em bytes do nível M em cada versão.
+var refCapacity = []int{1: 14, 26, 42, 62, 84, 106, 122, 152, 180, 213}
+
+type symbol struct {
+	size int
+	dark func(x, y int) bool
+}
+
+func decode(s symbol) ([]byte, error) {
+	if (s.size-17)%4 != 0 || s.size < 21 {
+		return nil, fmt.Errorf("invalid size %d", s.size)
+	}
+	version := (s.size - 17) / 4
+	blocks, ok := refBlocks[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+
+	ecLevel, mask, err := readFormat(s)
+	if err != nil {
+		return nil, err
+	}
+	if ecLevel != 0 {
+		return nil, fmt.Errorf("error correction level %d, want M", ecLevel)
+	}
+	if version >= 7 {
+		if err := checkVersionInfo(s, version); err != nil {
+			return nil, err
+		}
+	}
+
+	function := functionModules(version, s.size)
+	var bits []bool
+	upward := true
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for i := 0; i < s.size; i++ {
+			y := i
+			if upward {
+				y = s.size - 1 - i
+			}
+			for x := right; x > right-2; x-- {
+				if !function[y][x] {
+					bits = append(bits, s.dark(x, y) != masked(mask, x, y))
+				}
+			}
+		}
+		upward = !upward
+	}
+
+	var codewords []byte
+	for i := 0; i+8 <= len(bits); i += 8 {
+		var b byte
+		for _, bit := range bits[i : i+8] {
+			b <<= 1
+			if bit {
+				b |= 1
+			}
+		}
+		codewords = append(codewords, b)
+	}
+
+	var dataBlocks, ecBlocks [][]byte
+	for _, g := range blocks.groups {
+		for i := 0; i < g[0]; i++ {
+			dataBlocks = append(dataBlocks, make([]byte, 0, g[1]))
+			ecBlocks = append(ecBlocks, make([]byte, 0, blocks.ec))
+		}
+	}
+	next := 0
+	longest := blocks.groups[len(blocks.groups)-1][1]
+	for i := 0; i < longest; i++ {
+		for b := range dataBlocks {
+			if i < cap(dataBlocks[b]) {
+				dataBlocks[b] = append(dataBlocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	for i := 0; i < blocks.ec; i++ {
+		for b := range ecBlocks {
+			ecBlocks[b] = append(ecBlocks[b], codewords[next])
+			next++
+		}
+	}
+
+	var data []byte
+	for b := range dataBlocks {
+		if !syndromesZero(append(append([]byte{}, dataBlocks[b]...), ecBlocks[b]...), blocks.ec) {
+			return nil, fmt.Errorf("block %d fails the Reed-Solomon check", b)
+		}
+		data = append(data, dataBlocks[b]...)
+	}
+	return parseByteSegment(data, version)
+}
+
+// readFormat lê as duas cópias da informação de formato e exige que ambas
+// sejam palavras BCH válidas e iguais.
+func readFormat(s symbol) (ecLevel, mask int, err error) {
+	var first, second int
+	for i := 0; i < 15; i++ {
+		var x1, y1, x2, y2 int
+		switch {
+		case i < 6:
+			x1, y1 = 8, i
+		case i < 8:
+			x1, y1 = 8, i+1
+		case i == 8:
+			x1, y1 = 7, 8
+		default:
+			x1, y1 = 14-i, 8
+		}
+		if i < 8 {
+			x2, y2 = s.size-1-i, 8
+		} else {
+			x2, y2 = 8, s.size-15+i
+		}
+		if s.dark(x1, y1) {
+			first |= 1 << i
+		}
+		if s.dark(x2, y2) {
+			second |= 1 << i
+		}
+	}
+	if first != second {
+		return 0, 0, errors.New("format copies differ")
+	}
+	for data := 0; data < 32; data++ {
+		if formatWord(data) == first {
+			return data >> 3, data & 7, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid format word %015b", first)
+}
+
+func formatWord(data int) int {
+	return (data<<10 | bchRemainder(data<<10, 0x537, 10)) ^ 0x5412
+}
+
+func checkVersionInfo(s symbol, version int) error {
+	want := version<<12 | bchRemainder(version<<12, 0x1F25, 12)
+	var bottomLeft, topRight int
+	for i := 0; i < 18; i++ {
+		a, b := i/3, s.size-11+i%3
+		if s.dark(a, b) {
+			bottomLeft |= 1 << i
+		}
+		if s.dark(b, a) {
+			topRight |= 1 << i
+		}
+	}
+	if bottomLeft != want || topRight != want {
+		return fmt.Errorf("version info %018b/%018b, want %018b", bottomLeft, topRight, want)
+	}
+	return nil
+}
+
+func bchRemainder(value, poly, degree int) int {
+	for bit := 31; bit >= degree; bit-- {
+		if value>>bit&1 == 1 {
+			value ^= poly << (bit - degree)
+		}
+	}
+	return value
+}
+
+func functionModules(version, size int) [][]bool {
+	function := make([][]bool, size)
+	for y := range function {
+		function[y] = make([]bool, size)
+	}
+	fill := func(x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				function[y][x] = true
+			}
+		}
+	}
+	// Localizadores com separadores e formato, temporização e módulo escuro.
+	fill(0, 0, 9, 9)
+	fill(size-8, 0, 8, 9)
+	fill(0, size-8, 9, 8)
+	fill(6, 0, 1, size)
+	fill(0, 6, size, 1)
+	if version >= 7 {
+		fill(0, size-11, 6, 3)
+		fill(size-11, 0, 3, 6)
+	}
+	if version >= 2 {
+		count := version/7 + 2
+		step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+		centers := make([]int, count)
+		centers[0] = 6
+		for i, c := count-1, size-7; i >= 1; i, c = i-1, c-step {
+			centers[i] = c
+		}
+		for _, cx := range centers {
+			for _, cy := range centers {
+				if (cx == 6 && cy == 6) || (cx == 6 && cy == size-7) || (cx == size-7 && cy == 6) {
+					continue
+				}
+				fill(cx-2, cy-2, 5, 5)
+			}
+		}
+	}
+	return function
+}
+
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// syndromesZero avalia o bloco nas raízes α^0..α^(ec-1) do gerador.
+func syndromesZero(block []byte, ec int) bool {
+	var exp [510]byte
+	var log [256]int
+	value := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(value), byte(value)
+		log[value] = i
+		value <<= 1
+		if value&0x100 != 0 {
+			value ^= 0x11D
+		}
+	}
+	for root := 0; root < ec; root++ {
+		var sum byte
+		for _, c := range block {
+			if sum != 0 {
+				sum = exp[log[sum]+root]
+			}
+			sum ^= c
+		}
+		if sum != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func parseByteSegment(data []byte, version int) ([]byte, error) {
+	pos := 0
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(data[pos/8]>>(7-pos%8)&1)
+			pos++
+		}
+		return v
+	}
+	if mode := read(4); mode != 0b0100 {
+		return nil, fmt.Errorf("mode %04b, want byte", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	length := read(countBits)
+	if pos+8*length > 8*len(data) {
+		return nil, fmt.Errorf("length %d exceeds the symbol", length)
+	}
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = byte(read(8))
+	}
+
+	if terminator := min(4, 8*len(data)-pos); read(terminator) != 0 {
+		return nil, errors.New("missing terminator")
+	}
+	if pos%8 != 0 && read(8-pos%8) != 0 {
+		return nil, errors.New("non-zero bit padding")
+	}
+	for pad := byte(0xEC); pos < 8*len(data); pad ^= 0xEC ^ 0x11 {
+		if b := byte(read(8)); b != pad {
+			return nil, fmt.Errorf("pad codeword %#x, want %#x", b, pad)
+		}
+	}
+	return result, nil
+}
+
+func codeSymbol(c *Code) symbol {
+	return symbol{size: c.Size, dark: c.Dark}
+}
+
+func TestEncodeDecodes(t *testing.T) {
+	for length := 0; length <= refCapacity[MaxVersion]; length++ {
+		data := make([]byte, length)
+		for i := range data {
+			data[i] = byte(i*37 + length)
+		}
+		code, err := Encode(data)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", length, err)
+		}
+		if length > refCapacity[code.Version] || (code.Version > 1 && length <= refCapacity[code.Version-1]) {
+			t.Errorf("%d bytes: version %d is not the smallest that fits", length, code.Version)
+		}
+		if code.Size != 17+4*code.Version {
+			t.Errorf("%d bytes: size %d for version %d", length, code.Size, code.Version)
+		}
+		decoded, err := decode(codeSymbol(code))
+		if err != nil {
+			t.Fatalf("%d bytes (version %d): %v", length, code.Version, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Fatalf("%d bytes: decoded %x, want %x", length, decoded, data)
+		}
+	}
+}
+
+func TestEncodeEveryMaskDecodes(t *testing.T) {
+	data := []byte("https://app.trucar.com.br/scan/vehicle.12.345.Zx9kQm2Lr8TnVb4Yc1Ws6A")
+	for version := 5; version <= MaxVersion; version++ {
+		codewords := interleave(versions[version], encodeData(data, version))
+		for mask := 0; mask < 8; mask++ {
+			m := build(version, codewords, mask)
+			s := symbol{size: m.size, dark: func(x, y int) bool { return m.dark[y][x] }}
+			if _, gotMask, err := readFormat(s); err != nil || gotMask != mask {
+				t.Fatalf("version %d mask %d: format read as mask %d (%v)", version, mask, gotMask, err)
+			}
+			decoded, err := decode(s)
+			if err != nil {
+				t.Fatalf("version %d mask %d: %v", version, mask, err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Fatalf("version %d mask %d: decoded %q", version, mask, decoded)
+			}
+		}
+	}
+}
+
+func TestEncodeRejectsLongData(t *testing.T) {
+	if _, err := Encode(make([]byte, refCapacity[MaxVersion]+1)); !errors.Is(err, ErrDataTooLong) {
+		t.Fatalf("got %v, want ErrDataTooLong", err)
+	}
+}
+
+func TestDecoderDetectsDamage(t *testing.T) {
+	code, err := Encode([]byte("TruCar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O canto inferior direito guarda o início dos dados.
+	damaged := symbol{size: code.Size, dark: func(x, y int) bool {
+		if x == code.Size-1 && y == code.Size-1 {
+			return !code.Dark(x, y)
+		}
+		return code.Dark(x, y)
+	}}
+	if _, err := decode(damaged); err == nil {
+		t.Fatal("decoder accepted a damaged symbol")
+	}
+}
+
+// As referências em testdata foram geradas pelo github.com/skip2/go-qrcode
+// (nível M, sem borda). A escolha da máscara varia entre implementações, então
+// o símbolo é comparado com o nosso desenhado na máscara da referência.
+func TestEncodeMatchesReference(t *testing.T) {
+	tests := []struct {
+		file    string
+		data    string
+		version int
+	}{
+		{"v1.txt", "TruCar", 1},
+		{"v5.txt", "https://app.trucar.com.br/scan/vehicle.12.345.Zx9kQm2Lr8TnVb4Yc1Ws6A", 5},
+		{"v7.txt", "https://app.trucar.com.br/scan/inventory_item.37.1204.Zx9kQm2Lr8TnVb4Yc1Ws6A?ref=etiqueta-de-patrimonio-do-almoxarifado", 7},
+		{"v10.txt", strings.Repeat("etiqueta de patrimônio, ", 8) + "fim", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := strings.Split(strings.TrimSpace(string(raw)), "\n")
+			reference := symbol{size: len(rows), dark: func(x, y int) bool { return rows[y][x] == '#' }}
+
+			decoded, err := decode(reference)
+			if err != nil {
+				t.Fatalf("reference: %v", err)
+			}
+			if string(decoded) != tt.data {
+				t.Fatalf("reference decoded %q, want %q", decoded, tt.data)
+			}
+
+			code, err := Encode([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.Version != tt.version || code.Size != len(rows) {
+				t.Fatalf("version %d size %d, want %d and %d", code.Version, code.Size, tt.version, len(rows))
+			}
+			_, mask, _ := readFormat(reference)
+			m := build(code.Version, interleave(versions[code.Version], encodeData([]byte(tt.data), code.Version)), mask)
+			for y, row := range rows {
+				for x := range row {
+					if m.dark[y][x] != (row[x] == '#') {
+						t.Fatalf("mask %d: module (%d, %d) differs from the reference", mask, x, y)
+					}
+				}
+			}
+		})
+	}
+}
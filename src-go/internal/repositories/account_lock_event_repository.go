@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type AccountLockEventRepository interface {
+	Create(event *models.AccountLockEvent) error
+	FindByUser(userID uint, skip, limit int) ([]models.AccountLockEvent, error)
+}
+
+type accountLockEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountLockEventRepository(db *gorm.DB) AccountLockEventRepository {
+	return &accountLockEventRepository{db: db}
+}
+
+func (r *accountLockEventRepository) Create(event *models.AccountLockEvent) error {
+	return r.db.Scopes(ForOrganization(event.OrganizationID)).Create(event).Error
+}
+
+func (r *accountLockEventRepository) FindByUser(userID uint, skip, limit int) ([]models.AccountLockEvent, error) {
+	var events []models.AccountLockEvent
+	// O usuário já foi validado pelo LoginAttemptService (da organização do
+	// gestor ou qualquer uma para o super admin).
+	if err := r.db.Scopes(AllOrganizations).Where("user_id = ?", userID).Order("created_at DESC").Offset(skip).Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+// AnalyticsRepository agrega os dados de todas as organizações para o painel
+// do super admin. Os mapas são indexados pelo ID da organização; organizações
+// sem registros ficam de fora. from e to são opcionais (to exclusivo).
+type AnalyticsRepository interface {
+	CountUsers() (map[uint]int64, error)
+	CountActiveUsers(from, to *time.Time) (map[uint]int64, error)
+	CountVehicles() (map[uint]int64, error)
+	CountJourneys(from, to *time.Time) (map[uint]int64, error)
+	CountFreightOrders(from, to *time.Time) (map[uint]int64, error)
+	LastActivity() (map[uint]time.Time, error)
+	FileURLs() (map[uint][]string, error)
+	FindDemoAccounts() ([]models.User, error)
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) CountUsers() (map[uint]int64, error) {
+	return countByOrganization(r.db.Model(&models.User{}).Scopes(AllOrganizations), "COUNT(*)")
+}
+
+// CountActiveUsers conta os usuários com alguma sessão em uso no período.
+func (r *analyticsRepository) CountActiveUsers(from, to *time.Time) (map[uint]int64, error) {
+	query := r.db.Model(&models.UserSession{}).Scopes(AllOrganizations)
+	if from != nil {
+		query = query.Where("last_seen_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	return countByOrganization(query, "COUNT(DISTINCT user_id)")
+}
+
+func (r *analyticsRepository) CountVehicles() (map[uint]int64, error) {
+	return countByOrganization(r.db.Model(&models.Vehicle{}).Scopes(AllOrganizations), "COUNT(*)")
+}
+
+func (r *analyticsRepository) CountJourneys(from, to *time.Time) (map[uint]int64, error) {
+	query := withPeriod(r.db.Model(&models.Journey{}).Scopes(AllOrganizations), "start_time", from, to)
+	return countByOrganization(query, "COUNT(*)")
+}
+
+func (r *analyticsRepository) CountFreightOrders(from, to *time.Time) (map[uint]int64, error) {
+	query := withPeriod(r.db.Model(&models.FreightOrder{}).Scopes(AllOrganizations), "created_at", from, to)
+	return countByOrganization(query, "COUNT(*)")
+}
+
+// LastActivity é o momento mais recente entre o uso das sessões, o início das
+// jornadas e a última alteração das ordens de frete. Cada consulta traz a
+// linha mais recente por organização para manter o tipo da coluna (MAX()
+// devolveria texto no SQLite).
+func (r *analyticsRepository) LastActivity() (map[uint]time.Time, error) {
+	last := map[uint]time.Time{}
+	track := func(orgID uint, t time.Time) {
+		if t.After(last[orgID]) {
+			last[orgID] = t
+		}
+	}
+
+	var sessions []models.UserSession
+	if err := r.db.Scopes(AllOrganizations).
+		Where("last_seen_at = (SELECT MAX(s.last_seen_at) FROM user_sessions s WHERE s.organization_id = user_sessions.organization_id)").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		track(session.OrganizationID, session.LastSeenAt)
+	}
+
+	var journeys []models.Journey
+	if err := r.db.Scopes(AllOrganizations).
+		Where("start_time = (SELECT MAX(j.start_time) FROM journeys j WHERE j.organization_id = journeys.organization_id)").
+		Find(&journeys).Error; err != nil {
+		return nil, err
+	}
+	for _, journey := range journeys {
+		track(journey.OrganizationID, journey.StartTime)
+	}
+
+	var orders []models.FreightOrder
+	if err := r.db.Scopes(AllOrganizations).
+		Where("updated_at = (SELECT MAX(f.updated_at) FROM freight_orders f WHERE f.organization_id = freight_orders.organization_id)").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		track(order.OrganizationID, order.UpdatedAt)
+	}
+	return last, nil
+}
+
+func (r *analyticsRepository) FileURLs() (map[uint][]string, error) {
+	return fileURLs(r.db, AllOrganizations)
+}
+
+// FindDemoAccounts traz os gestores que começaram pela demonstração: os que
+// ainda são cliente_demo e os já ativados (ActivatedAt preenchido).
+func (r *analyticsRepository) FindDemoAccounts() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Scopes(AllOrganizations).
+		Where("role = ? OR activated_at IS NOT NULL", models.RoleClienteDemo).
+		Find(&users).Error
+	return users, err
+}
+
+func withPeriod(query *gorm.DB, column string, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		query = query.Where(column+" >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where(column+" < ?", *to)
+	}
+	return query
+}
+
+func countByOrganization(query *gorm.DB, aggregate string) (map[uint]int64, error) {
+	var rows []struct {
+		OrganizationID uint
+		Count          int64
+	}
+	if err := query.Select("organization_id, " + aggregate + " AS count").Group("organization_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.OrganizationID] = row.Count
+	}
+	return counts, nil
+}
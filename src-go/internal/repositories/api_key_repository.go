@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByHash(keyHash string) (*models.APIKey, error)
+	FindByID(keyID, orgID uint) (*models.APIKey, error)
+	FindByOrganization(orgID uint, skip, limit int) ([]models.APIKey, error)
+	Update(key *models.APIKey) error
+	TouchLastUsed(keyID uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Scopes(ForOrganization(key.OrganizationID)).Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	// A chave identifica a organização; a busca pelo hash é global.
+	if err := r.db.Scopes(AllOrganizations).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByID(keyID, orgID uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", keyID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByOrganization(orgID uint, skip, limit int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.Scopes(ForOrganization(orgID)).Order("created_at DESC").Offset(skip).Limit(limit).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(key *models.APIKey) error {
+	return r.db.Scopes(ForOrganization(key.OrganizationID)).Save(key).Error
+}
+
+// TouchLastUsed atualiza só a coluna last_used_at, sem reescrever o registro.
+func (r *apiKeyRepository) TouchLastUsed(keyID uint, usedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).Scopes(AllOrganizations).Where("id = ?", keyID).Update("last_used_at", usedAt).Error
+}
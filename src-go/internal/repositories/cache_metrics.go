@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheStats são os contadores do cache desde o início do processo. Com o
+// Redis cada instância da API conta apenas as próprias leituras.
+type CacheStats struct {
+	Backend     string           `json:"backend"`
+	Hits        int64            `json:"hits"`
+	Misses      int64            `json:"misses"`
+	HitRate     float64          `json:"hit_rate"`
+	Evictions   int64            `json:"evictions"`
+	Invalidated int64            `json:"invalidated"`
+	Entries     *int             `json:"entries,omitempty"`
+	Kinds       []CacheKindStats `json:"kinds"`
+}
+
+// CacheKindStats separa acertos e falhas pelo tipo da chave (o primeiro
+// segmento depois da organização: "vehicle", "vehicles", "login"...).
+type CacheKindStats struct {
+	Kind    string  `json:"kind"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+type cacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cacheMetrics struct {
+	backend       string
+	total         cacheCounters
+	evictions     atomic.Int64
+	invalidations atomic.Int64
+	kinds         sync.Map // kind -> *cacheCounters
+}
+
+func newCacheMetrics(backend string) *cacheMetrics {
+	return &cacheMetrics{backend: backend}
+}
+
+func (m *cacheMetrics) counters(key string) *cacheCounters {
+	kind := cacheKeyKind(key)
+	if c, ok := m.kinds.Load(kind); ok {
+		return c.(*cacheCounters)
+	}
+	c, _ := m.kinds.LoadOrStore(kind, &cacheCounters{})
+	return c.(*cacheCounters)
+}
+
+func (m *cacheMetrics) hit(key string) {
+	m.total.hits.Add(1)
+	m.counters(key).hits.Add(1)
+}
+
+func (m *cacheMetrics) miss(key string) {
+	m.total.misses.Add(1)
+	m.counters(key).misses.Add(1)
+}
+
+func (m *cacheMetrics) evicted(n int64) {
+	m.evictions.Add(n)
+}
+
+func (m *cacheMetrics) invalidated(n int64) {
+	m.invalidations.Add(n)
+}
+
+func (m *cacheMetrics) stats() CacheStats {
+	hits, misses := m.total.hits.Load(), m.total.misses.Load()
+	stats := CacheStats{
+		Backend:     m.backend,
+		Hits:        hits,
+		Misses:      misses,
+		HitRate:     hitRate(hits, misses),
+		Evictions:   m.evictions.Load(),
+		Invalidated: m.invalidations.Load(),
+		Kinds:       []CacheKindStats{},
+	}
+	m.kinds.Range(func(kind, value any) bool {
+		c := value.(*cacheCounters)
+		kindHits, kindMisses := c.hits.Load(), c.misses.Load()
+		stats.Kinds = append(stats.Kinds, CacheKindStats{
+			Kind:    kind.(string),
+			Hits:    kindHits,
+			Misses:  kindMisses,
+			HitRate: hitRate(kindHits, kindMisses),
+		})
+		return true
+	})
+	sort.Slice(stats.Kinds, func(i, j int) bool { return stats.Kinds[i].Kind < stats.Kinds[j].Kind })
+	return stats
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// cacheKeyKind ignora o prefixo da organização, para as métricas não crescerem
+// com o número de tenants.
+func cacheKeyKind(key string) string {
+	if rest, ok := strings.CutPrefix(key, "org:"); ok {
+		if _, after, found := strings.Cut(rest, ":"); found {
+			key = after
+		}
+	}
+	kind, _, _ := strings.Cut(key, ":")
+	return kind
+}
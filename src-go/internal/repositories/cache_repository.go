@@ -3,41 +3,152 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// ErrCacheMiss é devolvido por Get quando a chave não existe ou expirou.
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheRepository guarda valores serializados em JSON. As tags informadas no
+// Set agrupam chaves que precisam ser invalidadas juntas (ex.: todas as
+// listagens de veículos de uma organização) com um único InvalidateTags.
 type CacheRepository interface {
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
 	Get(ctx context.Context, key string, dest interface{}) error
 	Delete(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	InvalidateTags(ctx context.Context, tags ...string) error
+	Stats() CacheStats
+}
+
+// TenantCacheKey monta a chave (ou tag) de um dado de uma organização. Todo
+// dado de organização passa por aqui, para uma chave nunca ser compartilhada
+// entre tenants: TenantCacheKey(1, "vehicle", 5) = "org:1:vehicle:5".
+func TenantCacheKey(orgID uint, parts ...interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "org:%d", orgID)
+	for _, part := range parts {
+		fmt.Fprintf(&b, ":%v", part)
+	}
+	return b.String()
 }
 
 type redisCacheRepository struct {
-	client *redis.Client
+	client  *redis.Client
+	metrics *cacheMetrics
 }
 
 func NewRedisCacheRepository(client *redis.Client) CacheRepository {
-	return &redisCacheRepository{client: client}
+	return &redisCacheRepository{client: client, metrics: newCacheMetrics("redis")}
 }
 
-func (r *redisCacheRepository) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+// Cada tag é um conjunto com as chaves marcadas com ela. O conjunto expira
+// junto com a chave mais duradoura, para não sobrar depois das chaves nem
+// sumir antes delas.
+var redisSetWithTags = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	local current = redis.call('PTTL', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call('PERSIST', KEYS[i])
+	elseif existed == 0 or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+var redisInvalidateTags = redis.NewScript(`
+local removed = 0
+for i = 1, #KEYS do
+	local members = redis.call('SMEMBERS', KEYS[i])
+	for j = 1, #members, 1000 do
+		removed = removed + redis.call('DEL', unpack(members, j, math.min(j + 999, #members)))
+	end
+	redis.call('DEL', KEYS[i])
+end
+return removed
+`)
+
+func redisTagKey(tag string) string {
+	return "tag:" + tag
+}
+
+func (r *redisCacheRepository) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
 	bytes, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return r.client.Set(ctx, key, bytes, expiration).Err()
+	if len(tags) == 0 {
+		return r.client.Set(ctx, key, bytes, expiration).Err()
+	}
+	keys := []string{key}
+	for _, tag := range tags {
+		keys = append(keys, redisTagKey(tag))
+	}
+	return redisSetWithTags.Run(ctx, r.client, keys, bytes, expiration.Milliseconds()).Err()
 }
 
 func (r *redisCacheRepository) Get(ctx context.Context, key string, dest interface{}) error {
 	val, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		r.metrics.miss(key)
+		return ErrCacheMiss
+	}
 	if err != nil {
 		return err
 	}
+	r.metrics.hit(key)
 	return json.Unmarshal([]byte(val), dest)
 }
 
 func (r *redisCacheRepository) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
+
+// Incr incrementa um contador; a expiração é definida apenas na criação da chave,
+// de modo que a janela não é estendida a cada incremento.
+func (r *redisCacheRepository) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	val, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if val == 1 {
+		if err := r.client.Expire(ctx, key, expiration).Err(); err != nil {
+			return val, err
+		}
+	}
+	return val, nil
+}
+
+func (r *redisCacheRepository) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = redisTagKey(tag)
+	}
+	removed, err := redisInvalidateTags.Run(ctx, r.client, keys).Int64()
+	if err != nil {
+		return err
+	}
+	r.metrics.invalidated(removed)
+	return nil
+}
+
+func (r *redisCacheRepository) Stats() CacheStats {
+	return r.metrics.stats()
+}
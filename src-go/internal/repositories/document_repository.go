@@ -11,7 +11,7 @@ import (
 
 type DocumentRepository interface {
 	FindByID(docID, orgID uint) (*models.Document, error)
-	FindByOrganization(orgID uint, skip, limit int, expiringInDays *int) ([]models.Document, error)
+	FindByOrganization(orgID uint, skip, limit int, expiringBy *time.Time) ([]models.Document, error)
 	Create(doc *models.Document) (*models.Document, error)
 	Delete(doc *models.Document) error
 }
@@ -26,7 +26,7 @@ func NewDocumentRepository(db *gorm.DB) DocumentRepository {
 
 func (r *documentRepository) FindByID(docID, orgID uint) (*models.Document, error) {
 	var doc models.Document
-	if err := r.db.Where("id = ? AND organization_id = ?", docID, orgID).First(&doc).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", docID).First(&doc).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -35,13 +35,13 @@ func (r *documentRepository) FindByID(docID, orgID uint) (*models.Document, erro
 	return &doc, nil
 }
 
-func (r *documentRepository) FindByOrganization(orgID uint, skip, limit int, expiringInDays *int) ([]models.Document, error) {
+// expiringBy é a última data de vencimento incluída (coluna date, meia-noite UTC).
+func (r *documentRepository) FindByOrganization(orgID uint, skip, limit int, expiringBy *time.Time) ([]models.Document, error) {
 	var docs []models.Document
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 
-	if expiringInDays != nil {
-		expiryDate := time.Now().AddDate(0, 0, *expiringInDays)
-		query = query.Where("expiry_date <= ?", expiryDate)
+	if expiringBy != nil {
+		query = query.Where("expiry_date <= ?", *expiringBy)
 	}
 
 	if err := query.Offset(skip).Limit(limit).Find(&docs).Error; err != nil {
@@ -51,10 +51,10 @@ func (r *documentRepository) FindByOrganization(orgID uint, skip, limit int, exp
 }
 
 func (r *documentRepository) Create(doc *models.Document) (*models.Document, error) {
-	err := r.db.Create(doc).Error
+	err := r.db.Scopes(ForOrganization(doc.OrganizationID)).Create(doc).Error
 	return doc, err
 }
 
 func (r *documentRepository) Delete(doc *models.Document) error {
-	return r.db.Delete(doc).Error
+	return r.db.Scopes(ForOrganization(doc.OrganizationID)).Delete(doc).Error
 }
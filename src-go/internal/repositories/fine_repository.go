@@ -27,7 +27,7 @@ func NewFineRepository(db *gorm.DB) FineRepository {
 
 func (r *fineRepository) FindByID(fineID, orgID uint) (*models.Fine, error) {
 	var fine models.Fine
-	if err := r.db.Preload("Vehicle").Preload("Driver").Where("id = ? AND organization_id = ?", fineID, orgID).First(&fine).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("Vehicle").Preload("Driver").Where("id = ?", fineID).First(&fine).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -38,7 +38,7 @@ func (r *fineRepository) FindByID(fineID, orgID uint) (*models.Fine, error) {
 
 func (r *fineRepository) FindByOrganization(orgID uint, skip, limit int) ([]models.Fine, error) {
 	var fines []models.Fine
-	if err := r.db.Preload("Vehicle").Preload("Driver").Where("organization_id = ?", orgID).Offset(skip).Limit(limit).Find(&fines).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("Vehicle").Preload("Driver").Offset(skip).Limit(limit).Find(&fines).Error; err != nil {
 		return nil, err
 	}
 	return fines, nil
@@ -46,14 +46,14 @@ func (r *fineRepository) FindByOrganization(orgID uint, skip, limit int) ([]mode
 
 func (r *fineRepository) FindByDriver(driverID, orgID uint, skip, limit int) ([]models.Fine, error) {
 	var fines []models.Fine
-	if err := r.db.Preload("Vehicle").Preload("Driver").Where("driver_id = ? AND organization_id = ?", driverID, orgID).Offset(skip).Limit(limit).Find(&fines).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("Vehicle").Preload("Driver").Where("driver_id = ?", driverID).Offset(skip).Limit(limit).Find(&fines).Error; err != nil {
 		return nil, err
 	}
 	return fines, nil
 }
 
 func (r *fineRepository) Create(fine *models.Fine) (*models.Fine, error) {
-	err := r.db.Create(fine).Error
+	err := r.db.Scopes(ForOrganization(fine.OrganizationID)).Create(fine).Error
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +62,7 @@ func (r *fineRepository) Create(fine *models.Fine) (*models.Fine, error) {
 }
 
 func (r *fineRepository) Update(fine *models.Fine) (*models.Fine, error) {
-	err := r.db.Save(fine).Error
+	err := updateVersioned(r.db.Scopes(ForOrganization(fine.OrganizationID)), fine, &fine.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -70,5 +70,5 @@ func (r *fineRepository) Update(fine *models.Fine) (*models.Fine, error) {
 }
 
 func (r *fineRepository) Delete(fine *models.Fine) error {
-	return r.db.Delete(fine).Error
+	return r.db.Scopes(ForOrganization(fine.OrganizationID)).Delete(fine).Error
 }
@@ -15,7 +15,6 @@ type FreightOrderRepository interface {
 	FindPendingByDriver(driverID, orgID uint) ([]models.FreightOrder, error)
 	CreateWithStops(order *models.FreightOrder) (*models.FreightOrder, error)
 	Update(order *models.FreightOrder) (*models.FreightOrder, error)
-	UpdateStopPoint(stopPoint *models.StopPoint) (*models.StopPoint, error)
 }
 
 type freightOrderRepository struct {
@@ -28,7 +27,7 @@ func NewFreightOrderRepository(db *gorm.DB) FreightOrderRepository {
 
 func (r *freightOrderRepository) FindByID(orderID, orgID uint) (*models.FreightOrder, error) {
 	var order models.FreightOrder
-	if err := r.db.Preload("StopPoints").Preload("Vehicle").Preload("Driver").Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("StopPoints").Preload("Vehicle").Preload("Driver").Where("id = ?", orderID).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -39,7 +38,7 @@ func (r *freightOrderRepository) FindByID(orderID, orgID uint) (*models.FreightO
 
 func (r *freightOrderRepository) FindByOrganization(orgID uint, skip, limit int) ([]models.FreightOrder, error) {
 	var orders []models.FreightOrder
-	if err := r.db.Preload("StopPoints").Where("organization_id = ?", orgID).Offset(skip).Limit(limit).Find(&orders).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("StopPoints").Offset(skip).Limit(limit).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -47,7 +46,7 @@ func (r *freightOrderRepository) FindByOrganization(orgID uint, skip, limit int)
 
 func (r *freightOrderRepository) FindByStatus(orgID uint, status models.FreightStatus) ([]models.FreightOrder, error) {
 	var orders []models.FreightOrder
-	if err := r.db.Preload("StopPoints").Where("organization_id = ? AND status = ?", orgID, status).Find(&orders).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("StopPoints").Where("status = ?", status).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -56,14 +55,14 @@ func (r *freightOrderRepository) FindByStatus(orgID uint, status models.FreightS
 func (r *freightOrderRepository) FindPendingByDriver(driverID, orgID uint) ([]models.FreightOrder, error) {
 	var orders []models.FreightOrder
 	statuses := []models.FreightStatus{models.FreightStatusClaimed, models.FreightStatusInTransit}
-	if err := r.db.Preload("StopPoints").Where("driver_id = ? AND organization_id = ? AND status IN (?)", driverID, orgID, statuses).Find(&orders).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("StopPoints").Where("driver_id = ? AND status IN (?)", driverID, statuses).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
 
 func (r *freightOrderRepository) CreateWithStops(order *models.FreightOrder) (*models.FreightOrder, error) {
-	err := r.db.Create(order).Error
+	err := r.db.Scopes(ForOrganization(order.OrganizationID)).Create(order).Error
 	if err != nil {
 		return nil, err
 	}
@@ -71,14 +70,9 @@ func (r *freightOrderRepository) CreateWithStops(order *models.FreightOrder) (*m
 }
 
 func (r *freightOrderRepository) Update(order *models.FreightOrder) (*models.FreightOrder, error) {
-	err := r.db.Save(order).Error
+	err := r.db.Scopes(ForOrganization(order.OrganizationID)).Save(order).Error
 	if err != nil {
 		return nil, err
 	}
 	return r.FindByID(order.ID, order.OrganizationID)
 }
-
-func (r *freightOrderRepository) UpdateStopPoint(stopPoint *models.StopPoint) (*models.StopPoint, error) {
-	err := r.db.Save(stopPoint).Error
-	return stopPoint, err
-}
@@ -27,7 +27,7 @@ func NewFuelLogRepository(db *gorm.DB) FuelLogRepository {
 
 func (r *fuelLogRepository) FindByID(fuelLogID, orgID uint) (*models.FuelLog, error) {
 	var fuelLog models.FuelLog
-	if err := r.db.Where("id = ? AND organization_id = ?", fuelLogID, orgID).First(&fuelLog).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", fuelLogID).First(&fuelLog).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -38,7 +38,7 @@ func (r *fuelLogRepository) FindByID(fuelLogID, orgID uint) (*models.FuelLog, er
 
 func (r *fuelLogRepository) FindByOrganization(orgID uint, skip, limit int) ([]models.FuelLog, error) {
 	var fuelLogs []models.FuelLog
-	if err := r.db.Where("organization_id = ?", orgID).Offset(skip).Limit(limit).Find(&fuelLogs).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Offset(skip).Limit(limit).Find(&fuelLogs).Error; err != nil {
 		return nil, err
 	}
 	return fuelLogs, nil
@@ -46,20 +46,20 @@ func (r *fuelLogRepository) FindByOrganization(orgID uint, skip, limit int) ([]m
 
 func (r *fuelLogRepository) FindByUser(userID, orgID uint, skip, limit int) ([]models.FuelLog, error) {
 	var fuelLogs []models.FuelLog
-	if err := r.db.Where("user_id = ? AND organization_id = ?", userID, orgID).Offset(skip).Limit(limit).Find(&fuelLogs).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("user_id = ?", userID).Offset(skip).Limit(limit).Find(&fuelLogs).Error; err != nil {
 		return nil, err
 	}
 	return fuelLogs, nil
 }
 
 func (r *fuelLogRepository) Create(fuelLog *models.FuelLog) error {
-	return r.db.Create(fuelLog).Error
+	return r.db.Scopes(ForOrganization(fuelLog.OrganizationID)).Create(fuelLog).Error
 }
 
 func (r *fuelLogRepository) Update(fuelLog *models.FuelLog) error {
-	return r.db.Save(fuelLog).Error
+	return updateVersioned(r.db.Scopes(ForOrganization(fuelLog.OrganizationID)), fuelLog, &fuelLog.Version)
 }
 
 func (r *fuelLogRepository) Delete(fuelLog *models.FuelLog) error {
-	return r.db.Delete(fuelLog).Error
+	return r.db.Scopes(ForOrganization(fuelLog.OrganizationID)).Delete(fuelLog).Error
 }
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type ImpersonationSessionRepository interface {
+	Create(session *models.ImpersonationSession) error
+	FindBySessionID(sessionID string) (*models.ImpersonationSession, error)
+	End(sessionID string) (bool, error)
+	FindAll(skip, limit int) ([]models.ImpersonationSession, error)
+}
+
+// Personificações são usadas só por super admins e pela validação do token;
+// as consultas usam AllOrganizations.
+type impersonationSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationSessionRepository(db *gorm.DB) ImpersonationSessionRepository {
+	return &impersonationSessionRepository{db: db}
+}
+
+func (r *impersonationSessionRepository) Create(session *models.ImpersonationSession) error {
+	return r.db.Scopes(ForOrganization(session.OrganizationID)).Create(session).Error
+}
+
+func (r *impersonationSessionRepository) FindBySessionID(sessionID string) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := r.db.Scopes(AllOrganizations).Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *impersonationSessionRepository) End(sessionID string) (bool, error) {
+	result := r.db.Model(&models.ImpersonationSession{}).Scopes(AllOrganizations).
+		Where("session_id = ? AND ended_at IS NULL", sessionID).
+		Update("ended_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *impersonationSessionRepository) FindAll(skip, limit int) ([]models.ImpersonationSession, error) {
+	var sessions []models.ImpersonationSession
+	if err := r.db.Scopes(AllOrganizations).Order("created_at DESC").Offset(skip).Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
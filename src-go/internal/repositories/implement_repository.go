@@ -10,6 +10,7 @@ import (
 
 type ImplementRepository interface {
 	FindByID(implementID, orgID uint) (*models.Implement, error)
+	FindByIDs(implementIDs []uint, orgID uint) ([]models.Implement, error)
 	FindByOrganization(orgID uint, managementList bool) ([]models.Implement, error)
 	Create(implement *models.Implement) error
 	Update(implement *models.Implement) error
@@ -26,7 +27,7 @@ func NewImplementRepository(db *gorm.DB) ImplementRepository {
 
 func (r *implementRepository) FindByID(implementID, orgID uint) (*models.Implement, error) {
 	var implement models.Implement
-	if err := r.db.Where("id = ? AND organization_id = ?", implementID, orgID).First(&implement).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", implementID).First(&implement).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -35,9 +36,18 @@ func (r *implementRepository) FindByID(implementID, orgID uint) (*models.Impleme
 	return &implement, nil
 }
 
+// FindByIDs ignora os IDs que não existem na organização.
+func (r *implementRepository) FindByIDs(implementIDs []uint, orgID uint) ([]models.Implement, error) {
+	var implements []models.Implement
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id IN ?", implementIDs).Find(&implements).Error; err != nil {
+		return nil, err
+	}
+	return implements, nil
+}
+
 func (r *implementRepository) FindByOrganization(orgID uint, managementList bool) ([]models.Implement, error) {
 	var implements []models.Implement
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 
 	if !managementList {
 		query = query.Where("status = ?", models.ImplementStatusAvailable)
@@ -50,13 +60,13 @@ func (r *implementRepository) FindByOrganization(orgID uint, managementList bool
 }
 
 func (r *implementRepository) Create(implement *models.Implement) error {
-	return r.db.Create(implement).Error
+	return r.db.Scopes(ForOrganization(implement.OrganizationID)).Create(implement).Error
 }
 
 func (r *implementRepository) Update(implement *models.Implement) error {
-	return r.db.Save(implement).Error
+	return updateVersioned(r.db.Scopes(ForOrganization(implement.OrganizationID)), implement, &implement.Version)
 }
 
 func (r *implementRepository) Delete(implement *models.Implement) error {
-	return r.db.Delete(implement).Error
+	return r.db.Scopes(ForOrganization(implement.OrganizationID)).Delete(implement).Error
 }
@@ -8,7 +8,7 @@ import (
 
 type InventoryTransactionRepository interface {
 	Create(transaction *models.InventoryTransaction) error
-	FindByPartID(partID uint, skip, limit int) ([]models.InventoryTransaction, error)
+	FindByPartID(partID, orgID uint, skip, limit int) ([]models.InventoryTransaction, error)
 }
 
 type inventoryTransactionRepository struct {
@@ -20,12 +20,12 @@ func NewInventoryTransactionRepository(db *gorm.DB) InventoryTransactionReposito
 }
 
 func (r *inventoryTransactionRepository) Create(transaction *models.InventoryTransaction) error {
-	return r.db.Create(transaction).Error
+	return r.db.Scopes(ForOrganization(transaction.OrganizationID)).Create(transaction).Error
 }
 
-func (r *inventoryTransactionRepository) FindByPartID(partID uint, skip, limit int) ([]models.InventoryTransaction, error) {
+func (r *inventoryTransactionRepository) FindByPartID(partID, orgID uint, skip, limit int) ([]models.InventoryTransaction, error) {
 	var transactions []models.InventoryTransaction
-	if err := r.db.Where("part_id = ?", partID).Offset(skip).Limit(limit).Find(&transactions).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("part_id = ?", partID).Offset(skip).Limit(limit).Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 	return transactions, nil
@@ -16,10 +16,11 @@ type JourneyRepository interface {
 	FindByOrganization(orgID uint, skip, limit int, driverID, vehicleID *uint, dateFrom, dateTo *time.Time) ([]models.Journey, error)
 	Create(journey *models.Journey) (*models.Journey, error)
 	Update(journey *models.Journey) (*models.Journey, error)
+	End(journey *models.Journey) (bool, error)
+	Reopen(journey *models.Journey) error
 	Delete(journey *models.Journey) error
-	CheckVehicleAvailability(vehicleID uint) (bool, error)
-	UpdateVehicleStatus(vehicleID uint, status models.VehicleStatus) error
-	UpdateVehicleMileage(vehicleID uint, mileage int) error
+	CheckVehicleAvailability(vehicleID, orgID uint) (bool, error)
+	UpdateVehicleMileage(vehicleID, orgID uint, mileage int) error
 }
 
 type journeyRepository struct {
@@ -32,7 +33,7 @@ func NewJourneyRepository(db *gorm.DB) JourneyRepository {
 
 func (r *journeyRepository) FindByID(journeyID, orgID uint) (*models.Journey, error) {
 	var journey models.Journey
-	if err := r.db.Where("id = ? AND organization_id = ?", journeyID, orgID).First(&journey).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", journeyID).First(&journey).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -43,7 +44,7 @@ func (r *journeyRepository) FindByID(journeyID, orgID uint) (*models.Journey, er
 
 func (r *journeyRepository) FindByOrganization(orgID uint, skip, limit int, driverID, vehicleID *uint, dateFrom, dateTo *time.Time) ([]models.Journey, error) {
 	var journeys []models.Journey
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 
 	if driverID != nil {
 		query = query.Where("driver_id = ?", *driverID)
@@ -54,8 +55,9 @@ func (r *journeyRepository) FindByOrganization(orgID uint, skip, limit int, driv
 	if dateFrom != nil {
 		query = query.Where("start_time >= ?", *dateFrom)
 	}
+	// dateTo é exclusivo: o início do dia seguinte ao último dia pedido.
 	if dateTo != nil {
-		query = query.Where("end_time <= ?", *dateTo)
+		query = query.Where("end_time < ?", *dateTo)
 	}
 
 	if err := query.Offset(skip).Limit(limit).Find(&journeys).Error; err != nil {
@@ -65,31 +67,57 @@ func (r *journeyRepository) FindByOrganization(orgID uint, skip, limit int, driv
 }
 
 func (r *journeyRepository) Create(journey *models.Journey) (*models.Journey, error) {
-	err := r.db.Create(journey).Error
+	err := r.db.Scopes(ForOrganization(journey.OrganizationID)).Create(journey).Error
 	return journey, err
 }
 
 func (r *journeyRepository) Update(journey *models.Journey) (*models.Journey, error) {
-	err := r.db.Save(journey).Error
+	err := r.db.Scopes(ForOrganization(journey.OrganizationID)).Save(journey).Error
 	return journey, err
 }
 
+// End grava o encerramento num UPDATE condicionado à jornada ainda estar
+// ativa: de dois encerramentos simultâneos, só um altera a linha. Devolve
+// false quando ela já tinha sido encerrada.
+func (r *journeyRepository) End(journey *models.Journey) (bool, error) {
+	result := r.db.Model(&models.Journey{}).Scopes(ForOrganization(journey.OrganizationID)).
+		Where("id = ? AND is_active = ?", journey.ID, true).
+		Updates(map[string]interface{}{
+			"end_time":         journey.EndTime,
+			"end_mileage":      journey.EndMileage,
+			"end_engine_hours": journey.EndEngineHours,
+			"is_active":        false,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Reopen desfaz o End quando o restante do encerramento falha.
+func (r *journeyRepository) Reopen(journey *models.Journey) error {
+	return r.db.Model(&models.Journey{}).Scopes(ForOrganization(journey.OrganizationID)).
+		Where("id = ?", journey.ID).
+		Updates(map[string]interface{}{
+			"end_time":         nil,
+			"end_mileage":      nil,
+			"end_engine_hours": nil,
+			"is_active":        true,
+		}).Error
+}
+
 func (r *journeyRepository) Delete(journey *models.Journey) error {
-	return r.db.Delete(journey).Error
+	return r.db.Scopes(ForOrganization(journey.OrganizationID)).Delete(journey).Error
 }
 
-func (r *journeyRepository) CheckVehicleAvailability(vehicleID uint) (bool, error) {
+func (r *journeyRepository) CheckVehicleAvailability(vehicleID, orgID uint) (bool, error) {
 	var vehicle models.Vehicle
-	if err := r.db.First(&vehicle, vehicleID).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).First(&vehicle, vehicleID).Error; err != nil {
 		return false, err
 	}
 	return vehicle.Status == models.StatusAvailable, nil
 }
 
-func (r *journeyRepository) UpdateVehicleStatus(vehicleID uint, status models.VehicleStatus) error {
-	return r.db.Model(&models.Vehicle{}).Where("id = ?", vehicleID).Update("status", status).Error
-}
-
-func (r *journeyRepository) UpdateVehicleMileage(vehicleID uint, mileage int) error {
-	return r.db.Model(&models.Vehicle{}).Where("id = ?", vehicleID).Update("current_km", mileage).Error
+func (r *journeyRepository) UpdateVehicleMileage(vehicleID, orgID uint, mileage int) error {
+	return r.db.Model(&models.Vehicle{}).Scopes(ForOrganization(orgID)).Where("id = ?", vehicleID).Update("current_km", mileage).Error
 }
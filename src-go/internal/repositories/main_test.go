@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-api/internal/db"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitLogger()
+	os.Exit(m.Run())
+}
+
+// newTestDB abre um SQLite em arquivo temporário com o schema e o guard de
+// organização, como em produção.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_sync=OFF&_journal=MEMORY&_busy_timeout=5000"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Migrate(gormDB)
+	if err := RegisterTenantGuard(gormDB); err != nil {
+		t.Fatal(err)
+	}
+	return gormDB
+}
+
+func createTestOrganization(t *testing.T, gormDB *gorm.DB, name string) uint {
+	t.Helper()
+	org := &models.Organization{Name: name, Sector: models.TransporteDeCargas}
+	if err := gormDB.Create(org).Error; err != nil {
+		t.Fatal(err)
+	}
+	return org.ID
+}
@@ -28,7 +28,7 @@ func NewMaintenanceRepository(db *gorm.DB) MaintenanceRepository {
 
 func (r *maintenanceRepository) FindByID(reqID, orgID uint) (*models.MaintenanceRequest, error) {
 	var req models.MaintenanceRequest
-	if err := r.db.Where("id = ? AND organization_id = ?", reqID, orgID).First(&req).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", reqID).First(&req).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -39,7 +39,7 @@ func (r *maintenanceRepository) FindByID(reqID, orgID uint) (*models.Maintenance
 
 func (r *maintenanceRepository) FindByOrganization(orgID uint, skip, limit int, search string) ([]models.MaintenanceRequest, error) {
 	var reqs []models.MaintenanceRequest
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 
 	if search != "" {
 		searchQuery := "%" + search + "%"
@@ -53,25 +53,25 @@ func (r *maintenanceRepository) FindByOrganization(orgID uint, skip, limit int,
 }
 
 func (r *maintenanceRepository) Create(req *models.MaintenanceRequest) error {
-	return r.db.Create(req).Error
+	return r.db.Scopes(ForOrganization(req.OrganizationID)).Create(req).Error
 }
 
 func (r *maintenanceRepository) Update(req *models.MaintenanceRequest) error {
-	return r.db.Save(req).Error
+	return r.db.Scopes(ForOrganization(req.OrganizationID)).Save(req).Error
 }
 
 func (r *maintenanceRepository) Delete(req *models.MaintenanceRequest) error {
-	return r.db.Delete(req).Error
+	return r.db.Scopes(ForOrganization(req.OrganizationID)).Delete(req).Error
 }
 
 func (r *maintenanceRepository) FindCommentsByRequestID(reqID, orgID uint) ([]models.MaintenanceComment, error) {
 	var comments []models.MaintenanceComment
-	if err := r.db.Where("request_id = ? AND organization_id = ?", reqID, orgID).Find(&comments).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("request_id = ?", reqID).Find(&comments).Error; err != nil {
 		return nil, err
 	}
 	return comments, nil
 }
 
 func (r *maintenanceRepository) CreateComment(comment *models.MaintenanceComment) error {
-	return r.db.Create(comment).Error
+	return r.db.Scopes(ForOrganization(comment.OrganizationID)).Create(comment).Error
 }
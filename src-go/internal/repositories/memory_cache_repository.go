@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errCacheNotInteger = errors.New("cache value is not an integer")
+
+// memoryCacheRepository é o cache usado quando o Redis não está configurado:
+// um LRU em memória com expiração por chave. Ele vale só para o processo, então
+// bloqueios de login e estados de SSO não são compartilhados entre instâncias.
+type memoryCacheRepository struct {
+	mu sync.Mutex
+	// maxEntries zero desliga o descarte pelo LRU (ver NewUnboundedMemoryCacheRepository).
+	maxEntries int
+	// nextSweep é o tamanho em que as entradas expiradas são varridas quando
+	// não há limite.
+	nextSweep int
+	entries   map[string]*list.Element
+	// recent tem a chave usada mais recentemente na frente.
+	recent  *list.List
+	tags    map[string]map[string]struct{}
+	metrics *cacheMetrics
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero: sem expiração
+	tags      []string
+}
+
+func (e *memoryCacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func NewMemoryCacheRepository(maxEntries int) CacheRepository {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &memoryCacheRepository{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		recent:     list.New(),
+		tags:       map[string]map[string]struct{}{},
+		metrics:    newCacheMetrics("memory"),
+	}
+}
+
+// minSweepEntries é o tamanho a partir do qual o store sem limite começa a
+// varrer as entradas expiradas.
+const minSweepEntries = 1024
+
+// NewUnboundedMemoryCacheRepository guarda dados que não podem ser
+// descartados para abrir espaço, como os contadores de tentativas de login e
+// os estados de SSO: com o LRU, uma enxurrada de chaves novas apagaria um
+// bloqueio em andamento. As entradas só saem quando expiram, por isso todas
+// devem ter expiração.
+func NewUnboundedMemoryCacheRepository() CacheRepository {
+	return &memoryCacheRepository{
+		nextSweep: minSweepEntries,
+		entries:   map[string]*list.Element{},
+		recent:    list.New(),
+		tags:      map[string]map[string]struct{}{},
+		metrics:   newCacheMetrics("memory"),
+	}
+}
+
+func expiresAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(expiration)
+}
+
+func (r *memoryCacheRepository) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(&memoryCacheEntry{key: key, value: bytes, expiresAt: expiresAt(expiration), tags: tags})
+	return nil
+}
+
+func (r *memoryCacheRepository) Get(ctx context.Context, key string, dest interface{}) error {
+	r.mu.Lock()
+	entry := r.lookup(key)
+	r.mu.Unlock()
+
+	if entry == nil {
+		r.metrics.miss(key)
+		return ErrCacheMiss
+	}
+	r.metrics.hit(key)
+	// O valor gravado nunca é alterado, então pode ser lido fora do lock.
+	return json.Unmarshal(entry.value, dest)
+}
+
+func (r *memoryCacheRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if elem, ok := r.entries[key]; ok {
+		r.remove(elem)
+	}
+	return nil
+}
+
+// Incr segue o Redis: a expiração é definida apenas na criação da chave.
+func (r *memoryCacheRepository) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.lookup(key)
+	if entry == nil {
+		r.put(&memoryCacheEntry{key: key, value: []byte("1"), expiresAt: expiresAt(expiration)})
+		return 1, nil
+	}
+	var val int64
+	if err := json.Unmarshal(entry.value, &val); err != nil {
+		return 0, errCacheNotInteger
+	}
+	val++
+	updated := *entry
+	updated.value, _ = json.Marshal(val)
+	r.put(&updated)
+	return val, nil
+}
+
+func (r *memoryCacheRepository) InvalidateTags(ctx context.Context, tags ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var removed int64
+	for _, tag := range tags {
+		for key := range r.tags[tag] {
+			if elem, ok := r.entries[key]; ok {
+				r.remove(elem)
+				removed++
+			}
+		}
+		delete(r.tags, tag)
+	}
+	r.metrics.invalidated(removed)
+	return nil
+}
+
+func (r *memoryCacheRepository) Stats() CacheStats {
+	stats := r.metrics.stats()
+	r.mu.Lock()
+	entries := len(r.entries)
+	r.mu.Unlock()
+	stats.Entries = &entries
+	return stats
+}
+
+// lookup devolve a entrada válida da chave e a marca como usada; entradas
+// expiradas são descartadas aqui. Chamado com o lock.
+func (r *memoryCacheRepository) lookup(key string) *memoryCacheEntry {
+	elem, ok := r.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if entry.expired(time.Now()) {
+		r.remove(elem)
+		return nil
+	}
+	r.recent.MoveToFront(elem)
+	return entry
+}
+
+// put grava a entrada (substituindo a anterior da chave) e descarta as menos
+// usadas além do limite. Chamado com o lock.
+func (r *memoryCacheRepository) put(entry *memoryCacheEntry) {
+	if elem, ok := r.entries[entry.key]; ok {
+		r.remove(elem)
+	}
+	r.entries[entry.key] = r.recent.PushFront(entry)
+	for _, tag := range entry.tags {
+		keys, ok := r.tags[tag]
+		if !ok {
+			keys = map[string]struct{}{}
+			r.tags[tag] = keys
+		}
+		keys[entry.key] = struct{}{}
+	}
+
+	if r.maxEntries == 0 {
+		r.sweep()
+		return
+	}
+	for len(r.entries) > r.maxEntries {
+		r.remove(r.recent.Back())
+		r.metrics.evicted(1)
+	}
+}
+
+// sweep remove as entradas expiradas do store sem limite. A varredura completa
+// só acontece quando o tamanho dobra desde a anterior, o que mantém o custo
+// por gravação constante. Chamado com o lock.
+func (r *memoryCacheRepository) sweep() {
+	if len(r.entries) < r.nextSweep {
+		return
+	}
+	now := time.Now()
+	for elem := r.recent.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*memoryCacheEntry).expired(now) {
+			r.remove(elem)
+		}
+		elem = prev
+	}
+	r.nextSweep = max(minSweepEntries, 2*len(r.entries))
+}
+
+// remove tira a entrada do LRU e das tags. Chamado com o lock.
+func (r *memoryCacheRepository) remove(elem *list.Element) {
+	entry := r.recent.Remove(elem).(*memoryCacheEntry)
+	delete(r.entries, entry.key)
+	for _, tag := range entry.tags {
+		if keys, ok := r.tags[tag]; ok {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(r.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCacheRepository(2)
+	cache.Set(ctx, "a", 1, time.Minute)
+	cache.Set(ctx, "b", 2, time.Minute)
+	var value int
+	cache.Get(ctx, "a", &value)
+	cache.Set(ctx, "c", 3, time.Minute)
+
+	if err := cache.Get(ctx, "b", &value); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("b should have been evicted, got %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if err := cache.Get(ctx, key, &value); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+}
+
+// Uma enxurrada de chaves não pode apagar um contador de tentativas em
+// andamento no store sem limite.
+func TestUnboundedMemoryCacheKeepsCounters(t *testing.T) {
+	ctx := context.Background()
+	store := NewUnboundedMemoryCacheRepository()
+	for i := 0; i < 3; i++ {
+		store.Incr(ctx, "login:fail:user@example.com", time.Minute)
+	}
+	for i := 0; i < 5*minSweepEntries; i++ {
+		store.Incr(ctx, fmt.Sprintf("login:fail:ip:%d", i), time.Minute)
+	}
+
+	count, err := store.Incr(ctx, "login:fail:user@example.com", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("counter is %d, want 4", count)
+	}
+}
+
+func TestUnboundedMemoryCacheSweepsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewUnboundedMemoryCacheRepository().(*memoryCacheRepository)
+	for i := 0; i < minSweepEntries-2; i++ {
+		store.Set(ctx, fmt.Sprintf("sso:state:%d", i), i, time.Millisecond)
+	}
+	store.Set(ctx, "login:lock:user@example.com", 1, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+	store.Set(ctx, "login:lock:other@example.com", 1, time.Minute)
+
+	if entries := len(store.entries); entries != 2 {
+		t.Fatalf("%d entries after the sweep, want 2", entries)
+	}
+	var value int
+	if err := store.Get(ctx, "login:lock:user@example.com", &value); err != nil {
+		t.Fatal(err)
+	}
+}
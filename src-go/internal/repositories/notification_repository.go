@@ -20,5 +20,5 @@ func NewNotificationRepository(db *gorm.DB) NotificationRepository {
 }
 
 func (r *notificationRepository) Create(notification *models.Notification) error {
-	return r.db.Create(notification).Error
+	return r.db.Scopes(ForOrganization(notification.OrganizationID)).Create(notification).Error
 }
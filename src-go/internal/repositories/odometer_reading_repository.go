@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type OdometerReadingRepository interface {
+	FindByID(readingID, vehicleID, orgID uint) (*models.OdometerReading, error)
+	FindByVehicle(vehicleID, orgID uint, dateFrom, dateTo *time.Time) ([]models.OdometerReading, error)
+	FindBySource(source models.OdometerReadingSource, sourceID, orgID uint) (*models.OdometerReading, error)
+	FindAdjacent(vehicleID, orgID uint, at time.Time, column string, excludeID uint) (*models.OdometerReading, *models.OdometerReading, error)
+	Save(reading *models.OdometerReading, vehicle *models.Vehicle, fuelLog *models.FuelLog) error
+}
+
+type odometerReadingRepository struct {
+	db *gorm.DB
+}
+
+func NewOdometerReadingRepository(db *gorm.DB) OdometerReadingRepository {
+	return &odometerReadingRepository{db: db}
+}
+
+func (r *odometerReadingRepository) FindByID(readingID, vehicleID, orgID uint) (*models.OdometerReading, error) {
+	var reading models.OdometerReading
+	err := r.db.Scopes(ForOrganization(orgID)).Where("id = ? AND vehicle_id = ?", readingID, vehicleID).First(&reading).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reading, nil
+}
+
+// FindBySource devolve a leitura registrada pela jornada ou abastecimento.
+func (r *odometerReadingRepository) FindBySource(source models.OdometerReadingSource, sourceID, orgID uint) (*models.OdometerReading, error) {
+	var readings []models.OdometerReading
+	err := r.db.Scopes(ForOrganization(orgID)).Where("source = ? AND source_id = ?", source, sourceID).
+		Order("id").Limit(1).Find(&readings).Error
+	if err != nil || len(readings) == 0 {
+		return nil, err
+	}
+	return &readings[0], nil
+}
+
+func (r *odometerReadingRepository) FindByVehicle(vehicleID, orgID uint, dateFrom, dateTo *time.Time) ([]models.OdometerReading, error) {
+	var readings []models.OdometerReading
+	query := r.db.Scopes(ForOrganization(orgID)).Preload("RecordedBy").Where("vehicle_id = ?", vehicleID)
+	if dateFrom != nil {
+		query = query.Where("recorded_at >= ?", dateFrom.UTC())
+	}
+	if dateTo != nil {
+		query = query.Where("recorded_at < ?", dateTo.UTC())
+	}
+	if err := query.Order("recorded_at, id").Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// FindAdjacent devolve as leituras aceitas imediatamente antes (inclusive no
+// mesmo instante) e depois de at que tenham a coluna informada preenchida.
+func (r *odometerReadingRepository) FindAdjacent(vehicleID, orgID uint, at time.Time, column string, excludeID uint) (*models.OdometerReading, *models.OdometerReading, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(ForOrganization(orgID)).
+			Where("vehicle_id = ? AND status = ? AND id <> ?", vehicleID, models.OdometerReadingAccepted, excludeID).
+			Where(column + " IS NOT NULL")
+	}
+
+	var previous, next []models.OdometerReading
+	if err := r.db.Scopes(scope).Where("recorded_at <= ?", at.UTC()).Order("recorded_at DESC, id DESC").Limit(1).Find(&previous).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.Scopes(scope).Where("recorded_at > ?", at.UTC()).Order("recorded_at, id").Limit(1).Find(&next).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var prev, nxt *models.OdometerReading
+	if len(previous) > 0 {
+		prev = &previous[0]
+	}
+	if len(next) > 0 {
+		nxt = &next[0]
+	}
+	return prev, nxt, nil
+}
+
+// Save grava a leitura e, quando ela passa a ser a mais recente, os valores
+// atuais do veículo na mesma transação. O abastecimento de origem, quando
+// informado, é criado (ou atualizado, se já tem ID) na mesma transação.
+func (r *odometerReadingRepository) Save(reading *models.OdometerReading, vehicle *models.Vehicle, fuelLog *models.FuelLog) error {
+	// As leituras chegam no fuso da organização ou do cliente; gravar em UTC
+	// mantém a ordenação por recorded_at correta.
+	reading.RecordedAt = reading.RecordedAt.UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if fuelLog != nil {
+			if err := saveFuelLog(tx, fuelLog); err != nil {
+				return err
+			}
+			reading.SourceID = &fuelLog.ID
+		}
+		if err := tx.Scopes(ForOrganization(reading.OrganizationID)).Save(reading).Error; err != nil {
+			return err
+		}
+		if vehicle == nil {
+			return nil
+		}
+		return updateVersioned(tx.Scopes(ForOrganization(vehicle.OrganizationID)), vehicle, &vehicle.Version)
+	})
+}
+
+func saveFuelLog(tx *gorm.DB, fuelLog *models.FuelLog) error {
+	scoped := tx.Scopes(ForOrganization(fuelLog.OrganizationID))
+	if fuelLog.ID == 0 {
+		return scoped.Create(fuelLog).Error
+	}
+	return updateVersioned(scoped, fuelLog, &fuelLog.Version)
+}
@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"errors"
+	"time"
+
 	"gorm.io/gorm"
 	"go-api/internal/models"
 )
@@ -9,6 +12,13 @@ type OrganizationRepository interface {
 	FindAll(skip, limit int, status *string) ([]models.Organization, error)
 	Update(org *models.Organization) (*models.Organization, error)
 	FindByID(orgID uint) (*models.Organization, error)
+	FindByCode(code string) (*models.Organization, error)
+	NextEmployeeNumber(orgID uint) (int, error)
+	CreateWithOwner(org *models.Organization, owner *models.User) error
+	FindDueForDeletion(now time.Time) ([]models.Organization, error)
+	Purge(orgID uint, now time.Time) ([]string, bool, error)
+	FindUnverifiedSignups(now time.Time) ([]models.Organization, error)
+	PurgeUnverifiedSignup(orgID uint, now time.Time) (bool, error)
 }
 
 type organizationRepository struct {
@@ -22,10 +32,8 @@ func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
 func (r *organizationRepository) FindAll(skip, limit int, status *string) ([]models.Organization, error) {
 	var orgs []models.Organization
 	query := r.db.Offset(skip).Limit(limit)
-	if status != nil {
-		// Esta lógica assume que o status de uma organização pode ser determinado
-		// a partir de seus usuários. A implementação exata pode precisar ser ajustada
-		// com base na lógica de negócios real.
+	if status != nil && *status != "" {
+		query = query.Where("status = ?", *status)
 	}
 	if err := query.Find(&orgs).Error; err != nil {
 		return nil, err
@@ -45,3 +53,210 @@ func (r *organizationRepository) FindByID(orgID uint) (*models.Organization, err
 	}
 	return &org, nil
 }
+
+func (r *organizationRepository) FindByCode(code string) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.Where("code = ?", code).First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// NextEmployeeNumber reserva o próximo número de matrícula da organização. O
+// UPDATE incrementa no banco, então requisições simultâneas recebem números diferentes.
+func (r *organizationRepository) NextEmployeeNumber(orgID uint) (int, error) {
+	var number int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Organization{}).Where("id = ?", orgID).
+			UpdateColumn("employee_sequence", gorm.Expr("employee_sequence + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Organization{}).Where("id = ?", orgID).
+			Select("employee_sequence").Scan(&number).Error
+	})
+	return number, err
+}
+
+// CreateWithOwner cria a organização e o primeiro usuário dela na mesma transação.
+func (r *organizationRepository) CreateWithOwner(org *models.Organization, owner *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		owner.OrganizationID = org.ID
+		return tx.Scopes(ForOrganization(org.ID)).Create(owner).Error
+	})
+}
+
+func (r *organizationRepository) FindDueForDeletion(now time.Time) ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.db.Where("status = ? AND deletion_scheduled_at <= ?", models.OrganizationStatusPendingDeletion, now).Find(&orgs).Error
+	return orgs, err
+}
+
+// Tabelas da organização na ordem de remoção: dependentes antes das
+// referenciadas, usuários por último.
+var tenantModels = []interface{}{
+	&models.MaintenanceComment{},
+	&models.InventoryTransaction{},
+	&models.InventoryItem{},
+	&models.Part{},
+	&models.FuelLog{},
+	&models.Fine{},
+	&models.Document{},
+	&models.Notification{},
+	&models.VehicleStatusChange{},
+	&models.OdometerReading{},
+	&models.Journey{},
+	&models.FreightOrder{},
+	&models.MaintenanceRequest{},
+	&models.Implement{},
+	&models.Vehicle{},
+	&models.APIKey{},
+	&models.RefreshToken{},
+	&models.UserSession{},
+	&models.ImpersonationSession{},
+	&models.AccountLockEvent{},
+	&models.OrganizationRolePermission{},
+	&models.OrganizationSSOConfig{},
+	&models.OrganizationUsage{},
+	&models.OrganizationSettings{},
+	&models.User{},
+}
+
+// Colunas com arquivos enviados ao storage.
+var tenantFileColumns = []struct {
+	model  interface{}
+	column string
+}{
+	{&models.Document{}, "file_url"},
+	{&models.FuelLog{}, "receipt_photo_url"},
+	{&models.MaintenanceComment{}, "file_url"},
+	{&models.Part{}, "invoice_url"},
+	{&models.Part{}, "photo_url"},
+	{&models.Part{}, "photo_thumbnail_url"},
+	{&models.Vehicle{}, "photo_url"},
+	{&models.Vehicle{}, "photo_thumbnail_url"},
+	{&models.User{}, "avatar_url"},
+	{&models.User{}, "avatar_thumbnail_url"},
+}
+
+// fileURLs lista os arquivos das colunas de tenantFileColumns (inclusive de
+// registros com soft delete), agrupados por organização.
+func fileURLs(db *gorm.DB, scope func(*gorm.DB) *gorm.DB) (map[uint][]string, error) {
+	urls := map[uint][]string{}
+	for _, fc := range tenantFileColumns {
+		var rows []struct {
+			OrganizationID uint
+			URL            string
+		}
+		if err := db.Model(fc.model).Unscoped().Scopes(scope).Select("organization_id, "+fc.column+" AS url").
+			Where(fc.column + " IS NOT NULL AND " + fc.column + " <> ''").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			urls[row.OrganizationID] = append(urls[row.OrganizationID], row.URL)
+		}
+	}
+	return urls, nil
+}
+
+// errPurgeCancelled desfaz a transação de Purge quando a organização deixou de
+// estar com a exclusão vencida.
+var errPurgeCancelled = errors.New("organization is no longer due for deletion")
+
+// unverifiedSignup filtra as organizações do cadastro público (as únicas com
+// demonstração) em que ninguém confirmou o e-mail e os links já venceram.
+func unverifiedSignup(db *gorm.DB, now time.Time) *gorm.DB {
+	users := func() *gorm.DB {
+		return db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).Scopes(AllOrganizations).Select("1").
+			Where("users.organization_id = organizations.id")
+	}
+	return db.Where("organizations.status = ? AND organizations.trial_ends_at IS NOT NULL", models.OrganizationStatusActive).
+		Where("EXISTS (?)", users().Where("email_verification_token_expires_at <= ?", now)).
+		Where("NOT EXISTS (?)", users().Where("email_verification_token IS NULL OR email_verification_token_expires_at > ?", now))
+}
+
+func (r *organizationRepository) FindUnverifiedSignups(now time.Time) ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := unverifiedSignup(r.db, now).Find(&orgs).Error
+	return orgs, err
+}
+
+// PurgeUnverifiedSignup apaga a organização de um cadastro que nunca foi
+// confirmado, se ela ainda estiver nesse estado. Sem login não há arquivos
+// enviados, então nada volta para o storage.
+func (r *organizationRepository) PurgeUnverifiedSignup(orgID uint, now time.Time) (bool, error) {
+	_, purged, err := r.purge(orgID, func(tx *gorm.DB) *gorm.DB {
+		return unverifiedSignup(tx, now).Where("organizations.id = ?", orgID)
+	})
+	return purged, err
+}
+
+// Purge apaga de vez (inclusive registros com soft delete) todos os dados da
+// organização e a própria organização, e devolve os arquivos que ficaram sem
+// dono para o chamador removê-los do storage. A organização é apagada primeiro
+// e só se ainda estiver com a exclusão vencida: se foi reativada depois de
+// FindDueForDeletion, nada é apagado e o retorno é false.
+func (r *organizationRepository) Purge(orgID uint, now time.Time) ([]string, bool, error) {
+	return r.purge(orgID, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ? AND status = ? AND deletion_scheduled_at <= ?", orgID, models.OrganizationStatusPendingDeletion, now)
+	})
+}
+
+// purge apaga a organização filtrada por claim e, se ela ainda existia nessas
+// condições, todos os seus dados, na mesma transação.
+func (r *organizationRepository) purge(orgID uint, claim func(tx *gorm.DB) *gorm.DB) ([]string, bool, error) {
+	var files []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := claim(tx).Delete(&models.Organization{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPurgeCancelled
+		}
+
+		urls, err := fileURLs(tx, ForOrganization(orgID))
+		if err != nil {
+			return err
+		}
+		for _, orgURLs := range urls {
+			files = append(files, orgURLs...)
+		}
+
+		// Tabelas sem organization_id, ligadas pelos pedidos de frete e usuários.
+		freightOrders := tx.Model(&models.FreightOrder{}).Unscoped().Scopes(ForOrganization(orgID)).Select("id")
+		if err := tx.Unscoped().Where("freight_order_id IN (?)", freightOrders).Delete(&models.StopPoint{}).Error; err != nil {
+			return err
+		}
+		users := tx.Model(&models.User{}).Scopes(ForOrganization(orgID)).Select("id")
+		if err := tx.Where("user_id IN (?)", users).Delete(&models.PasswordHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", users).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range tenantModels {
+			if err := tx.Unscoped().Scopes(ForOrganization(orgID)).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errPurgeCancelled) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return files, true, nil
+}
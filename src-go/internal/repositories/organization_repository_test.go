@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+// scheduleDeletion marca a organização para exclusão em deleteAt.
+func scheduleDeletion(t *testing.T, gormDB *gorm.DB, orgID uint, deleteAt time.Time) {
+	t.Helper()
+	if err := gormDB.Model(&models.Organization{}).Where("id = ?", orgID).
+		Updates(map[string]interface{}{"status": models.OrganizationStatusPendingDeletion, "deletion_scheduled_at": deleteAt}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedPurgeData grava na organização um usuário com avatar, um veículo com
+// foto, um documento com soft delete e um pedido de frete com parada.
+func seedPurgeData(t *testing.T, gormDB *gorm.DB, orgID uint, prefix string) {
+	t.Helper()
+	avatar := prefix + "/avatar.png"
+	user := &models.User{Email: prefix + "@example.com", FullName: "Gestor", HashedPassword: "hash", EmployeeID: "0001", Role: models.RoleClienteAtivo, IsActive: true, OrganizationID: orgID, AvatarURL: &avatar}
+	if err := NewUserRepository(gormDB).Create(user, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := gormDB.Create(&models.PasswordHistory{UserID: user.ID, HashedPassword: "hash"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	photo := prefix + "/vehicle.jpg"
+	vehicle := &models.Vehicle{Brand: "Volvo", Model: "FH", Year: 2020, PhotoURL: &photo}
+	removed := &models.Document{DocumentType: models.DocumentTypeCRLV, ExpiryDate: time.Now(), FileURL: prefix + "/removed.pdf"}
+	order := &models.FreightOrder{}
+	for _, record := range []interface{}{vehicle, removed, order} {
+		if err := gormDB.Scopes(ForOrganization(orgID)).Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gormDB.Scopes(ForOrganization(orgID)).Delete(removed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gormDB.Create(&models.StopPoint{FreightOrderID: order.ID, SequenceOrder: 1, Type: models.StopPointTypePickup, Address: "Rua A", ScheduledTime: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func count(t *testing.T, query *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := query.Model(model).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestPurgeDeletesOrganizationData(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewOrganizationRepository(gormDB)
+	doomed, kept := createTestOrganization(t, gormDB, "Doomed"), createTestOrganization(t, gormDB, "Kept")
+	seedPurgeData(t, gormDB, doomed, "doomed")
+	seedPurgeData(t, gormDB, kept, "kept")
+	now := time.Now()
+	scheduleDeletion(t, gormDB, doomed, now.Add(-time.Hour))
+
+	files, purged, err := repo.Purge(doomed, now)
+	if err != nil || !purged {
+		t.Fatalf("purge: %v, %v", purged, err)
+	}
+	sort.Strings(files)
+	want := []string{"doomed/avatar.png", "doomed/removed.pdf", "doomed/vehicle.jpg"}
+	if len(files) != len(want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Fatalf("files = %v, want %v", files, want)
+		}
+	}
+
+	if n := count(t, gormDB, &models.Organization{}); n != 1 {
+		t.Errorf("%d organizations left, want 1", n)
+	}
+	for _, model := range []interface{}{&models.User{}, &models.Vehicle{}, &models.Document{}, &models.FreightOrder{}} {
+		if n := count(t, gormDB.Unscoped().Scopes(ForOrganization(doomed)), model); n != 0 {
+			t.Errorf("%T: %d rows of the purged organization left", model, n)
+		}
+		if n := count(t, gormDB.Unscoped().Scopes(ForOrganization(kept)), model); n == 0 {
+			t.Errorf("%T: rows of the other organization were deleted", model)
+		}
+	}
+	if n := count(t, gormDB, &models.StopPoint{}); n != 1 {
+		t.Errorf("%d stop points left, want the other organization's 1", n)
+	}
+	if n := count(t, gormDB, &models.PasswordHistory{}); n != 1 {
+		t.Errorf("%d password history rows left, want the other organization's 1", n)
+	}
+}
+
+func TestPurgeSkipsOrganizationNoLongerDue(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewOrganizationRepository(gormDB)
+	orgID := createTestOrganization(t, gormDB, "Reactivated")
+	seedPurgeData(t, gormDB, orgID, "reactivated")
+	now := time.Now()
+
+	cases := []struct {
+		name    string
+		prepare func()
+	}{
+		{"reactivated after the lookup", func() {
+			if err := gormDB.Model(&models.Organization{}).Where("id = ?", orgID).
+				Updates(map[string]interface{}{"status": models.OrganizationStatusActive, "deletion_scheduled_at": nil}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"rescheduled to a later date", func() { scheduleDeletion(t, gormDB, orgID, now.Add(time.Hour)) }},
+	}
+	for _, tc := range cases {
+		tc.prepare()
+		files, purged, err := repo.Purge(orgID, now)
+		if err != nil || purged || files != nil {
+			t.Fatalf("%s: purge = %v, %v, %v; want nothing purged", tc.name, files, purged, err)
+		}
+		if n := count(t, gormDB.Scopes(ForOrganization(orgID)), &models.User{}); n != 1 {
+			t.Fatalf("%s: %d users left, want 1", tc.name, n)
+		}
+		if n := count(t, gormDB.Where("id = ?", orgID), &models.Organization{}); n != 1 {
+			t.Fatalf("%s: organization deleted", tc.name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type OrganizationSettingsRepository interface {
+	FindByOrganization(orgID uint) (*models.OrganizationSettings, error)
+	Save(settings *models.OrganizationSettings) error
+}
+
+type organizationSettingsRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationSettingsRepository(db *gorm.DB) OrganizationSettingsRepository {
+	return &organizationSettingsRepository{db: db}
+}
+
+func (r *organizationSettingsRepository) FindByOrganization(orgID uint) (*models.OrganizationSettings, error) {
+	var settings models.OrganizationSettings
+	if err := r.db.Scopes(ForOrganization(orgID)).First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Save cria o registro na primeira alteração; até lá a organização usa os padrões.
+func (r *organizationSettingsRepository) Save(settings *models.OrganizationSettings) error {
+	if settings.ID == 0 {
+		return r.db.Scopes(ForOrganization(settings.OrganizationID)).Create(settings).Error
+	}
+	return r.db.Scopes(ForOrganization(settings.OrganizationID)).Save(settings).Error
+}
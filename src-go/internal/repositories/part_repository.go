@@ -15,9 +15,10 @@ type PartRepository interface {
 	Update(part *models.Part) (*models.Part, error)
 	Delete(part *models.Part) error
 	FindItemByID(itemID, orgID uint) (*models.InventoryItem, error)
+	FindItemsByIDs(itemIDs []uint, orgID uint) ([]models.InventoryItem, error)
 	CreateItem(item *models.InventoryItem) (*models.InventoryItem, error)
 	UpdateItem(item *models.InventoryItem) (*models.InventoryItem, error)
-	FindItemsByPartID(partID uint, status *models.InventoryItemStatus) ([]models.InventoryItem, error)
+	FindItemsByPartID(partID, orgID uint, status *models.InventoryItemStatus) ([]models.InventoryItem, error)
 }
 
 type partRepository struct {
@@ -30,7 +31,7 @@ func NewPartRepository(db *gorm.DB) PartRepository {
 
 func (r *partRepository) FindByID(partID, orgID uint) (*models.Part, error) {
 	var part models.Part
-	if err := r.db.Where("id = ? AND organization_id = ?", partID, orgID).First(&part).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", partID).First(&part).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -41,7 +42,7 @@ func (r *partRepository) FindByID(partID, orgID uint) (*models.Part, error) {
 
 func (r *partRepository) FindByOrganization(orgID uint, search string, skip, limit int) ([]models.Part, error) {
 	var parts []models.Part
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 	if search != "" {
 		searchQuery := "%" + search + "%"
 		query = query.Where("name LIKE ? OR part_number LIKE ? OR brand LIKE ?", searchQuery, searchQuery, searchQuery)
@@ -53,22 +54,22 @@ func (r *partRepository) FindByOrganization(orgID uint, search string, skip, lim
 }
 
 func (r *partRepository) Create(part *models.Part) (*models.Part, error) {
-	err := r.db.Create(part).Error
+	err := r.db.Scopes(ForOrganization(part.OrganizationID)).Create(part).Error
 	return part, err
 }
 
 func (r *partRepository) Update(part *models.Part) (*models.Part, error) {
-	err := r.db.Save(part).Error
+	err := updateVersioned(r.db.Scopes(ForOrganization(part.OrganizationID)), part, &part.Version)
 	return part, err
 }
 
 func (r *partRepository) Delete(part *models.Part) error {
-	return r.db.Delete(part).Error
+	return r.db.Scopes(ForOrganization(part.OrganizationID)).Delete(part).Error
 }
 
 func (r *partRepository) FindItemByID(itemID, orgID uint) (*models.InventoryItem, error) {
 	var item models.InventoryItem
-	if err := r.db.Where("id = ? AND organization_id = ?", itemID, orgID).First(&item).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", itemID).First(&item).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -77,19 +78,29 @@ func (r *partRepository) FindItemByID(itemID, orgID uint) (*models.InventoryItem
 	return &item, nil
 }
 
+// FindItemsByIDs carrega a peça de cada item e ignora os IDs que não existem
+// na organização.
+func (r *partRepository) FindItemsByIDs(itemIDs []uint, orgID uint) ([]models.InventoryItem, error) {
+	var items []models.InventoryItem
+	if err := r.db.Scopes(ForOrganization(orgID)).Preload("Part").Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (r *partRepository) CreateItem(item *models.InventoryItem) (*models.InventoryItem, error) {
-	err := r.db.Create(item).Error
+	err := r.db.Scopes(ForOrganization(item.OrganizationID)).Create(item).Error
 	return item, err
 }
 
 func (r *partRepository) UpdateItem(item *models.InventoryItem) (*models.InventoryItem, error) {
-	err := r.db.Save(item).Error
+	err := r.db.Scopes(ForOrganization(item.OrganizationID)).Save(item).Error
 	return item, err
 }
 
-func (r *partRepository) FindItemsByPartID(partID uint, status *models.InventoryItemStatus) ([]models.InventoryItem, error) {
+func (r *partRepository) FindItemsByPartID(partID, orgID uint, status *models.InventoryItemStatus) ([]models.InventoryItem, error) {
 	var items []models.InventoryItem
-	query := r.db.Where("part_id = ?", partID)
+	query := r.db.Scopes(ForOrganization(orgID)).Where("part_id = ?", partID)
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
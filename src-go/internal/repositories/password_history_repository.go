@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type PasswordHistoryRepository interface {
+	FindRecent(userID uint, limit int) ([]models.PasswordHistory, error)
+}
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+func (r *passwordHistoryRepository) FindRecent(userID uint, limit int) ([]models.PasswordHistory, error) {
+	var entries []models.PasswordHistory
+	if err := r.db.Where("user_id = ?", userID).Order("created_at desc, id desc").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// addPasswordHistory registra o hash substituído e mantém apenas os
+// change.Keep mais recentes do usuário. Roda na transação que grava a senha nova.
+func addPasswordHistory(tx *gorm.DB, userID uint, change *models.PasswordChange) error {
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, HashedPassword: change.PreviousHash}).Error; err != nil {
+		return err
+	}
+	var stale []uint
+	if err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("created_at desc, id desc").Offset(change.Keep).Pluck("id", &stale).Error; err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return tx.Delete(&models.PasswordHistory{}, stale).Error
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type PermissionRepository interface {
+	FindOverrides(orgID uint, role models.UserRole) ([]models.OrganizationRolePermission, error)
+	ReplaceOverrides(orgID uint, role models.UserRole, overrides []models.OrganizationRolePermission) error
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) FindOverrides(orgID uint, role models.UserRole) ([]models.OrganizationRolePermission, error) {
+	var overrides []models.OrganizationRolePermission
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("role = ?", role).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+func (r *permissionRepository) ReplaceOverrides(orgID uint, role models.UserRole, overrides []models.OrganizationRolePermission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(ForOrganization(orgID)).Where("role = ?", role).Delete(&models.OrganizationRolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(overrides) == 0 {
+			return nil
+		}
+		return tx.Scopes(ForOrganization(orgID)).Create(&overrides).Error
+	})
+}
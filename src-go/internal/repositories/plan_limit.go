@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+// ErrLimitReached indica que a gravação passaria do limite de cadastros do plano.
+var ErrLimitReached = errors.New("plan limit reached")
+
+// createWithinLimit grava records só se o total atual (count) somado aos adding
+// novos registros couber em limit; limit <= 0 é ilimitado. A transação começa
+// com um UPDATE na linha da organização, que a trava até o commit: criações
+// simultâneas da mesma organização esperam umas pelas outras e nenhuma conta
+// um total que já mudou.
+func createWithinLimit(db *gorm.DB, orgID uint, limit, adding int, count func(tx *gorm.DB) *gorm.DB, records interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if limit > 0 && adding > 0 {
+			if err := lockOrganization(tx, orgID); err != nil {
+				return err
+			}
+			var current int64
+			if err := count(tx).Count(&current).Error; err != nil {
+				return err
+			}
+			if current+int64(adding) > int64(limit) {
+				return ErrLimitReached
+			}
+		}
+		return tx.Scopes(ForOrganization(orgID)).CreateInBatches(records, 100).Error
+	})
+}
+
+func lockOrganization(tx *gorm.DB, orgID uint) error {
+	result := tx.Model(&models.Organization{}).Where("id = ?", orgID).UpdateColumn("id", gorm.Expr("id"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	Rotate(tokenID, replacedByID uint) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID uint) error
+}
+
+// Refresh tokens são buscados pelo hash ou pela sessão antes de se saber a
+// organização; as consultas usam AllOrganizations e a criação fica escopada.
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Scopes(ForOrganization(token.OrganizationID)).Create(token).Error
+}
+
+func (r *refreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Scopes(AllOrganizations).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate revoga o token e aponta o substituto no mesmo UPDATE, condicionado
+// a ele ainda estar ativo. Retorna false se outra requisição já o usou, o que
+// indica reutilização.
+func (r *refreshTokenRepository) Rotate(tokenID, replacedByID uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).Scopes(AllOrganizations).
+		Where("id = ? AND revoked_at IS NULL", tokenID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": replacedByID})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).Scopes(AllOrganizations).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).Scopes(AllOrganizations).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type SSOConfigRepository interface {
+	FindByOrganization(orgID uint) (*models.OrganizationSSOConfig, error)
+	Save(config *models.OrganizationSSOConfig) error
+	Delete(orgID uint) error
+}
+
+type ssoConfigRepository struct {
+	db *gorm.DB
+}
+
+func NewSSOConfigRepository(db *gorm.DB) SSOConfigRepository {
+	return &ssoConfigRepository{db: db}
+}
+
+func (r *ssoConfigRepository) FindByOrganization(orgID uint) (*models.OrganizationSSOConfig, error) {
+	var config models.OrganizationSSOConfig
+	if err := r.db.Scopes(ForOrganization(orgID)).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *ssoConfigRepository) Save(config *models.OrganizationSSOConfig) error {
+	return r.db.Scopes(ForOrganization(config.OrganizationID)).Save(config).Error
+}
+
+func (r *ssoConfigRepository) Delete(orgID uint) error {
+	return r.db.Scopes(ForOrganization(orgID)).Delete(&models.OrganizationSSOConfig{}).Error
+}
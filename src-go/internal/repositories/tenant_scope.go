@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Isolamento entre organizações: toda leitura ou escrita em uma tabela com a
+// coluna organization_id precisa passar pelo escopo ForOrganization ou, nos
+// casos em que a busca é de fato global (login, tokens, rotas de super admin),
+// pelo AllOrganizations. As callbacks registradas em RegisterTenantGuard
+// recusam qualquer outra consulta nessas tabelas.
+//
+// SQL escrito à mão (Raw/Exec) não passa pelo guard: o gorm não sabe quais
+// tabelas ele usa. Por isso repositórios e serviços não usam Raw nem Exec
+// (TestNoRawSQLOutsideTheGuard verifica); o único caso é a migração em
+// db.Migrate, que roda antes de existir qualquer requisição.
+
+var ErrMissingTenantScope = errors.New("query on a tenant table without an organization scope")
+var ErrCrossTenantWrite = errors.New("record belongs to another organization")
+
+type tenantContextKey struct{}
+
+type tenantScope struct {
+	organizationID uint
+	all            bool
+}
+
+// ForOrganization filtra pela organização e guarda o escopo no contexto da
+// consulta, que também é usado pelos Preloads e pelas associações salvas junto.
+func ForOrganization(orgID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db.Statement.Context = context.WithValue(db.Statement.Context, tenantContextKey{}, tenantScope{organizationID: orgID})
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "organization_id"}, Value: orgID})
+	}
+}
+
+// AllOrganizations libera explicitamente a consulta de todas as organizações.
+func AllOrganizations(db *gorm.DB) *gorm.DB {
+	db.Statement.Context = context.WithValue(db.Statement.Context, tenantContextKey{}, tenantScope{all: true})
+	return db
+}
+
+func RegisterTenantGuard(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", guardTenantRead); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", guardTenantRead); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:begin_transaction").Register("tenant:delete", guardTenantRead); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:begin_transaction").Register("tenant:update", guardTenantUpdate); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:begin_transaction").Register("tenant:create", guardTenantCreate)
+}
+
+// guardTenantRead vale para consultas e deletes: o filtro já vem do escopo.
+func guardTenantRead(db *gorm.DB) {
+	tenantStatement(db)
+}
+
+// guardTenantUpdate também impede que um Save troque o organization_id do registro.
+func guardTenantUpdate(db *gorm.DB) {
+	field, scope, ok := tenantStatement(db)
+	if !ok || scope.all {
+		return
+	}
+	if db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+	if value, isZero := field.ValueOf(db.Statement.Context, db.Statement.ReflectValue); !isZero && value != scope.organizationID {
+		db.AddError(ErrCrossTenantWrite)
+	}
+}
+
+// guardTenantCreate preenche o organization_id vazio com o do escopo e recusa
+// registros de outra organização. Upserts são recusados porque o ON CONFLICT
+// alcançaria a linha de outra organização com o mesmo id (é o que o Save faz
+// quando o UPDATE escopado não encontra o registro).
+func guardTenantCreate(db *gorm.DB) {
+	field, scope, ok := tenantStatement(db)
+	if !ok || scope.all {
+		return
+	}
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && (onConflict.UpdateAll || len(onConflict.DoUpdates) > 0) {
+			db.AddError(ErrCrossTenantWrite)
+			return
+		}
+	}
+
+	ctx := db.Statement.Context
+	check := func(record reflect.Value) {
+		value, isZero := field.ValueOf(ctx, record)
+		if isZero {
+			db.AddError(field.Set(ctx, record, scope.organizationID))
+		} else if value != scope.organizationID {
+			db.AddError(ErrCrossTenantWrite)
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			record := reflect.Indirect(rv.Index(i))
+			if record.Kind() == reflect.Struct {
+				check(record)
+			}
+		}
+	case reflect.Struct:
+		check(rv)
+	}
+}
+
+// tenantStatement indica se a consulta usa uma tabela com organization_id e,
+// nesse caso, exige o escopo no contexto. SQL escrito à mão (Raw/Exec) fica de
+// fora; veja o comentário no início do arquivo.
+func tenantStatement(db *gorm.DB) (*schema.Field, tenantScope, bool) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return nil, tenantScope{}, false
+	}
+	field := stmt.Schema.LookUpField("OrganizationID")
+	if field == nil {
+		return nil, tenantScope{}, false
+	}
+	scope, ok := scopeFromContext(stmt.Context)
+	if !ok {
+		db.AddError(fmt.Errorf("%w: %s", ErrMissingTenantScope, stmt.Table))
+		return nil, tenantScope{}, false
+	}
+	return field, scope, true
+}
+
+func scopeFromContext(ctx context.Context) (tenantScope, bool) {
+	if ctx == nil {
+		return tenantScope{}, false
+	}
+	scope, ok := ctx.Value(tenantContextKey{}).(tenantScope)
+	return scope, ok
+}
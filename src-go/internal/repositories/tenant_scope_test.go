@@ -0,0 +1,561 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+// tenantFixture tem duas organizações: os registros atacados pertencem à a e
+// cada repositório é chamado como se a requisição viesse da b.
+type tenantFixture struct {
+	db        *gorm.DB
+	a, b      uint
+	vehicleID uint
+	userID    uint
+	clientID  uint
+	partID    uint
+	requestID uint
+}
+
+func newTenantFixture(t *testing.T) *tenantFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	f := &tenantFixture{db: gormDB, a: createTestOrganization(t, gormDB, "Org A"), b: createTestOrganization(t, gormDB, "Org B")}
+
+	user := &models.User{Email: "motorista@a.test", FullName: "Motorista A", HashedPassword: "hash", EmployeeID: "0001", Role: models.RoleDriver, IsActive: true, OrganizationID: f.a}
+	if err := NewUserRepository(gormDB).Create(user, 0); err != nil {
+		t.Fatal(err)
+	}
+	vehicle := &models.Vehicle{Brand: "Volvo", Model: "FH", Year: 2020}
+	client := &models.Client{Name: "Cliente A"}
+	part := &models.Part{Name: "Filtro"}
+	f.seed(t, vehicle, client, part)
+	request := &models.MaintenanceRequest{ProblemDescription: "Freio", Category: models.MaintenanceCategoryMechanical, VehicleID: vehicle.ID}
+	f.seed(t, request)
+
+	f.userID, f.vehicleID, f.clientID, f.partID, f.requestID = user.ID, vehicle.ID, client.ID, part.ID, request.ID
+	return f
+}
+
+// seed grava os registros na organização a.
+func (f *tenantFixture) seed(t *testing.T, records ...interface{}) {
+	t.Helper()
+	for _, record := range records {
+		if err := f.db.Scopes(ForOrganization(f.a)).Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// column lê a coluna do registro sem passar pelos repositórios.
+func (f *tenantFixture) column(t *testing.T, model interface{}, id uint, name string) string {
+	t.Helper()
+	var values []string
+	if err := f.db.Scopes(AllOrganizations).Model(model).Where("id = ?", id).Pluck(name, &values).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 {
+		return "<deleted>"
+	}
+	return values[0]
+}
+
+// tenantCase descreve um repositório: o registro criado em seed pertence à
+// organização a e find, list, update e delete recebem a organização de quem
+// faz a chamada. update tenta gravar o registro com a organização informada e
+// alterar column; list, update e delete são opcionais.
+type tenantCase struct {
+	name   string
+	model  func() interface{}
+	column string
+	seed   func(t *testing.T, f *tenantFixture) uint
+	find   func(f *tenantFixture, id, orgID uint) (bool, error)
+	list   func(f *tenantFixture, orgID uint) (int, error)
+	update func(f *tenantFixture, id, orgID uint) error
+	delete func(f *tenantFixture, id, orgID uint) error
+}
+
+func tenantCases() []tenantCase {
+	return []tenantCase{
+		{
+			name:   "vehicles",
+			model:  func() interface{} { return &models.Vehicle{} },
+			column: "brand",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				v := &models.Vehicle{Brand: "Scania", Model: "R450", Year: 2021}
+				f.seed(t, v)
+				return v.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				v, err := NewVehicleRepository(f.db).FindByID(id, orgID)
+				return v != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				vs, err := NewVehicleRepository(f.db).FindByOrganization(orgID, 0, 100, "")
+				return len(vs), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewVehicleRepository(f.db).Update(&models.Vehicle{ID: id, Brand: "Invadido", Model: "R450", Year: 2021, OrganizationID: orgID, Version: 1})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewVehicleRepository(f.db).Delete(&models.Vehicle{ID: id, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "implements",
+			model:  func() interface{} { return &models.Implement{} },
+			column: "name",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				i := &models.Implement{Name: "Grade", Brand: "Tatu", VehicleModel: "GA", Year: 2019}
+				f.seed(t, i)
+				return i.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				i, err := NewImplementRepository(f.db).FindByID(id, orgID)
+				return i != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				is, err := NewImplementRepository(f.db).FindByOrganization(orgID, true)
+				return len(is), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewImplementRepository(f.db).Update(&models.Implement{Model: gorm.Model{ID: id}, Name: "Invadido", Brand: "Tatu", VehicleModel: "GA", Year: 2019, OrganizationID: orgID, Version: 1})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewImplementRepository(f.db).Delete(&models.Implement{Model: gorm.Model{ID: id}, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "parts",
+			model:  func() interface{} { return &models.Part{} },
+			column: "name",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				p := &models.Part{Name: "Pneu"}
+				f.seed(t, p)
+				return p.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				p, err := NewPartRepository(f.db).FindByID(id, orgID)
+				return p != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				ps, err := NewPartRepository(f.db).FindByOrganization(orgID, "", 0, 100)
+				return len(ps), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				_, err := NewPartRepository(f.db).Update(&models.Part{Model: gorm.Model{ID: id}, Name: "Invadido", OrganizationID: orgID, Version: 1})
+				return err
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewPartRepository(f.db).Delete(&models.Part{Model: gorm.Model{ID: id}, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "inventory items",
+			model:  func() interface{} { return &models.InventoryItem{} },
+			column: "status",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				item := &models.InventoryItem{ItemIdentifier: 1, PartID: f.partID}
+				f.seed(t, item)
+				return item.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				item, err := NewPartRepository(f.db).FindItemByID(id, orgID)
+				return item != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				items, err := NewPartRepository(f.db).FindItemsByPartID(f.partID, orgID, nil)
+				return len(items), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				_, err := NewPartRepository(f.db).UpdateItem(&models.InventoryItem{Model: gorm.Model{ID: id}, ItemIdentifier: 1, Status: models.InventoryItemStatusFimDeVida, PartID: f.partID, OrganizationID: orgID})
+				return err
+			},
+		},
+		{
+			name:   "documents",
+			model:  func() interface{} { return &models.Document{} },
+			column: "file_url",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				d := &models.Document{DocumentType: models.DocumentTypeCRLV, ExpiryDate: time.Now(), FileURL: "/static/crlv.pdf", VehicleID: &f.vehicleID}
+				f.seed(t, d)
+				return d.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				d, err := NewDocumentRepository(f.db).FindByID(id, orgID)
+				return d != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				ds, err := NewDocumentRepository(f.db).FindByOrganization(orgID, 0, 100, nil)
+				return len(ds), err
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewDocumentRepository(f.db).Delete(&models.Document{Model: gorm.Model{ID: id}, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "fines",
+			model:  func() interface{} { return &models.Fine{} },
+			column: "description",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				fine := &models.Fine{Description: "Velocidade", Date: time.Now(), Value: 130, VehicleID: f.vehicleID}
+				f.seed(t, fine)
+				return fine.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				fine, err := NewFineRepository(f.db).FindByID(id, orgID)
+				return fine != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				fines, err := NewFineRepository(f.db).FindByOrganization(orgID, 0, 100)
+				return len(fines), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				_, err := NewFineRepository(f.db).Update(&models.Fine{Model: gorm.Model{ID: id}, Description: "Invadido", Date: time.Now(), Value: 1, VehicleID: f.vehicleID, OrganizationID: orgID, Version: 1})
+				return err
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewFineRepository(f.db).Delete(&models.Fine{Model: gorm.Model{ID: id}, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "fuel logs",
+			model:  func() interface{} { return &models.FuelLog{} },
+			column: "liters",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				log := &models.FuelLog{Odometer: 1000, Liters: 50, TotalCost: 300, VehicleID: f.vehicleID, UserID: f.userID}
+				f.seed(t, log)
+				return log.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				log, err := NewFuelLogRepository(f.db).FindByID(id, orgID)
+				return log != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				logs, err := NewFuelLogRepository(f.db).FindByOrganization(orgID, 0, 100)
+				return len(logs), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewFuelLogRepository(f.db).Update(&models.FuelLog{ID: id, Odometer: 1000, Liters: 999, TotalCost: 300, VehicleID: f.vehicleID, UserID: f.userID, OrganizationID: orgID, Version: 1})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewFuelLogRepository(f.db).Delete(&models.FuelLog{ID: id, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "journeys",
+			model:  func() interface{} { return &models.Journey{} },
+			column: "start_mileage",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				j := &models.Journey{StartTime: time.Now(), StartMileage: 1000, TripType: models.JourneyTypeFreeRoam, VehicleID: f.vehicleID, DriverID: f.userID}
+				f.seed(t, j)
+				return j.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				j, err := NewJourneyRepository(f.db).FindByID(id, orgID)
+				return j != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				js, err := NewJourneyRepository(f.db).FindByOrganization(orgID, 0, 100, nil, nil, nil, nil)
+				return len(js), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				_, err := NewJourneyRepository(f.db).Update(&models.Journey{ID: id, StartTime: time.Now(), StartMileage: 999999, TripType: models.JourneyTypeFreeRoam, VehicleID: f.vehicleID, DriverID: f.userID, OrganizationID: orgID})
+				return err
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewJourneyRepository(f.db).Delete(&models.Journey{ID: id, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "maintenance requests",
+			model:  func() interface{} { return &models.MaintenanceRequest{} },
+			column: "problem_description",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				req := &models.MaintenanceRequest{ProblemDescription: "Motor", Category: models.MaintenanceCategoryMechanical, VehicleID: f.vehicleID}
+				f.seed(t, req)
+				return req.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				req, err := NewMaintenanceRepository(f.db).FindByID(id, orgID)
+				return req != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				reqs, err := NewMaintenanceRepository(f.db).FindByOrganization(orgID, 0, 100, "")
+				return len(reqs), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewMaintenanceRepository(f.db).Update(&models.MaintenanceRequest{ID: id, ProblemDescription: "Invadido", Category: models.MaintenanceCategoryOther, VehicleID: f.vehicleID, OrganizationID: orgID})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewMaintenanceRepository(f.db).Delete(&models.MaintenanceRequest{ID: id, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "maintenance comments",
+			model:  func() interface{} { return &models.MaintenanceComment{} },
+			column: "comment_text",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				comment := &models.MaintenanceComment{CommentText: "Peça pedida", RequestID: f.requestID}
+				f.seed(t, comment)
+				return comment.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				comments, err := NewMaintenanceRepository(f.db).FindCommentsByRequestID(f.requestID, orgID)
+				return len(comments) > 0, err
+			},
+		},
+		{
+			name:   "freight orders",
+			model:  func() interface{} { return &models.FreightOrder{} },
+			column: "description",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				description := "Soja"
+				order := &models.FreightOrder{Description: &description, ClientID: f.clientID}
+				f.seed(t, order)
+				return order.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				order, err := NewFreightOrderRepository(f.db).FindByID(id, orgID)
+				return order != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				orders, err := NewFreightOrderRepository(f.db).FindByOrganization(orgID, 0, 100)
+				return len(orders), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				description := "Invadido"
+				_, err := NewFreightOrderRepository(f.db).Update(&models.FreightOrder{Model: gorm.Model{ID: id}, Description: &description, Status: models.FreightStatusOpen, ClientID: f.clientID, OrganizationID: orgID})
+				return err
+			},
+		},
+		{
+			name:   "api keys",
+			model:  func() interface{} { return &models.APIKey{} },
+			column: "name",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				key := &models.APIKey{Name: "ERP", Prefix: "trk_abcd", KeyHash: "hash-a", Scopes: "vehicles:read", CreatedByID: f.userID}
+				f.seed(t, key)
+				return key.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				key, err := NewAPIKeyRepository(f.db).FindByID(id, orgID)
+				return key != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				keys, err := NewAPIKeyRepository(f.db).FindByOrganization(orgID, 0, 100)
+				return len(keys), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewAPIKeyRepository(f.db).Update(&models.APIKey{ID: id, Name: "Invadido", Prefix: "trk_abcd", KeyHash: "hash-b", Scopes: "vehicles:write", CreatedByID: f.userID, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "users",
+			model:  func() interface{} { return &models.User{} },
+			column: "full_name",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				user := &models.User{Email: "gestor@a.test", FullName: "Gestor A", HashedPassword: "hash", EmployeeID: "0002", Role: models.RoleClienteAtivo, IsActive: true, OrganizationID: f.a}
+				if err := NewUserRepository(f.db).Create(user, 0); err != nil {
+					t.Fatal(err)
+				}
+				return user.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				user, err := NewUserRepository(f.db).FindByID(id, orgID)
+				return user != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				users, err := NewUserRepository(f.db).FindByOrganization(orgID, 0, 100)
+				return len(users), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				// As escritas internas só alcançam a linha pelo WHERE da organização;
+				// a edição do cadastro esbarra na versão.
+				repo := NewUserRepository(f.db)
+				if err := repo.UpdateColumns(&models.User{ID: id, FullName: "Invadido", OrganizationID: orgID}, "full_name"); err != nil {
+					return err
+				}
+				return repo.UpdateVersioned(&models.User{ID: id, Email: "gestor@a.test", FullName: "Invadido", HashedPassword: "hash", EmployeeID: "9999", Role: models.RoleClienteAtivo, OrganizationID: orgID, Version: 1})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewUserRepository(f.db).Delete(&models.User{ID: id, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "odometer readings",
+			model:  func() interface{} { return &models.OdometerReading{} },
+			column: "odometer",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				odometer := 1000
+				reading := &models.OdometerReading{VehicleID: f.vehicleID, Odometer: &odometer, Source: models.OdometerSourceManual, RecordedAt: time.Now()}
+				f.seed(t, reading)
+				return reading.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				reading, err := NewOdometerReadingRepository(f.db).FindByID(id, f.vehicleID, orgID)
+				return reading != nil, err
+			},
+			list: func(f *tenantFixture, orgID uint) (int, error) {
+				readings, err := NewOdometerReadingRepository(f.db).FindByVehicle(f.vehicleID, orgID, nil, nil)
+				return len(readings), err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				odometer := 999999
+				return NewOdometerReadingRepository(f.db).Save(&models.OdometerReading{ID: id, VehicleID: f.vehicleID, Odometer: &odometer, Source: models.OdometerSourceManual, RecordedAt: time.Now(), OrganizationID: orgID}, nil, nil)
+			},
+		},
+		{
+			name:   "organization settings",
+			model:  func() interface{} { return &models.OrganizationSettings{} },
+			column: "time_zone",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				settings := &models.OrganizationSettings{TimeZone: "America/Sao_Paulo", UsageUnit: models.UsageUnitKM}
+				f.seed(t, settings)
+				return settings.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				settings, err := NewOrganizationSettingsRepository(f.db).FindByOrganization(orgID)
+				return settings != nil && settings.ID == id, err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewOrganizationSettingsRepository(f.db).Save(&models.OrganizationSettings{ID: id, TimeZone: "UTC", UsageUnit: models.UsageUnitKM, FiscalYearStartMonth: 1, OrganizationID: orgID})
+			},
+		},
+		{
+			name:   "sso config",
+			model:  func() interface{} { return &models.OrganizationSSOConfig{} },
+			column: "issuer",
+			seed: func(t *testing.T, f *tenantFixture) uint {
+				config := &models.OrganizationSSOConfig{Issuer: "https://idp.a.test", ClientID: "a", Scopes: "openid email", DefaultRole: models.RoleDriver}
+				f.seed(t, config)
+				return config.ID
+			},
+			find: func(f *tenantFixture, id, orgID uint) (bool, error) {
+				config, err := NewSSOConfigRepository(f.db).FindByOrganization(orgID)
+				return config != nil && config.ID == id, err
+			},
+			update: func(f *tenantFixture, id, orgID uint) error {
+				return NewSSOConfigRepository(f.db).Save(&models.OrganizationSSOConfig{ID: id, Issuer: "https://idp.invasor.test", ClientID: "b", Scopes: "openid", DefaultRole: models.RoleClienteAtivo, OrganizationID: orgID})
+			},
+			delete: func(f *tenantFixture, id, orgID uint) error {
+				return NewSSOConfigRepository(f.db).Delete(orgID)
+			},
+		},
+	}
+}
+
+// TestRepositoriesIsolateOrganizations tenta, em cada repositório, ler,
+// alterar e apagar um registro da organização a a partir da organização b.
+func TestRepositoriesIsolateOrganizations(t *testing.T) {
+	for _, tc := range tenantCases() {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			f := newTenantFixture(t)
+			id := tc.seed(t, f)
+
+			if found, err := tc.find(f, id, f.a); err != nil || !found {
+				t.Fatalf("find from the owner: found=%v err=%v", found, err)
+			}
+			if found, err := tc.find(f, id, f.b); err != nil || found {
+				t.Errorf("find from another organization: found=%v err=%v", found, err)
+			}
+			if tc.list != nil {
+				if n, err := tc.list(f, f.b); err != nil || n != 0 {
+					t.Errorf("list from another organization: %d records, err=%v", n, err)
+				}
+			}
+
+			// Sem escopo o guard recusa a consulta.
+			if err := f.db.First(tc.model(), id).Error; !errors.Is(err, ErrMissingTenantScope) {
+				t.Errorf("unscoped read: err = %v, want ErrMissingTenantScope", err)
+			}
+
+			before := f.column(t, tc.model(), id, tc.column)
+			if tc.update != nil {
+				err := tc.update(f, id, f.b)
+				if !errors.Is(err, ErrCrossTenantWrite) && !errors.Is(err, ErrVersionConflict) {
+					t.Errorf("update from another organization: err = %v, want ErrCrossTenantWrite or ErrVersionConflict", err)
+				}
+				if after := f.column(t, tc.model(), id, tc.column); after != before {
+					t.Errorf("update from another organization changed %s: %q -> %q", tc.column, before, after)
+				}
+			}
+			if tc.delete != nil {
+				if err := tc.delete(f, id, f.b); err != nil {
+					t.Errorf("delete from another organization: %v", err)
+				}
+				if after := f.column(t, tc.model(), id, tc.column); after != before {
+					t.Errorf("delete from another organization removed the record (%s = %q)", tc.column, after)
+				}
+			}
+		})
+	}
+}
+
+// TestTenantGuardRejectsWritesToAnotherOrganization cobre as escritas que não
+// passam por um repositório específico: criar na organização errada, mover um
+// registro de organização e o upsert que o Save faria.
+func TestTenantGuardRejectsWritesToAnotherOrganization(t *testing.T) {
+	f := newTenantFixture(t)
+
+	vehicle := &models.Vehicle{Brand: "Volvo", Model: "FH", Year: 2020, OrganizationID: f.a}
+	if err := f.db.Scopes(ForOrganization(f.b)).Create(vehicle).Error; !errors.Is(err, ErrCrossTenantWrite) {
+		t.Errorf("create in another organization: err = %v", err)
+	}
+
+	moved := &models.Vehicle{ID: f.vehicleID, Brand: "Volvo", Model: "FH", Year: 2020, OrganizationID: f.b}
+	if err := f.db.Scopes(ForOrganization(f.a)).Save(moved).Error; !errors.Is(err, ErrCrossTenantWrite) {
+		t.Errorf("move to another organization: err = %v", err)
+	}
+	if got := f.column(t, &models.Vehicle{}, f.vehicleID, "organization_id"); got != fmt.Sprint(f.a) {
+		t.Errorf("organization_id = %s, want %d", got, f.a)
+	}
+
+	batch := []models.Vehicle{{Brand: "A", Model: "1", Year: 2020}, {Brand: "B", Model: "2", Year: 2020, OrganizationID: f.a}}
+	if err := f.db.Scopes(ForOrganization(f.b)).Create(&batch).Error; !errors.Is(err, ErrCrossTenantWrite) {
+		t.Errorf("batch with a record of another organization: err = %v", err)
+	}
+}
+
+// TestNoRawSQLOutsideTheGuard falha quando um repositório, serviço ou handler
+// chama Raw ou Exec: esse SQL não passa pelo guard de organização.
+func TestNoRawSQLOutsideTheGuard(t *testing.T) {
+	for _, dir := range []string{".", "../services", "../api"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && (sel.Sel.Name == "Raw" || sel.Sel.Name == "Exec") {
+					t.Errorf("%s: %s bypasses the tenant guard; use the query builder with ForOrganization or AllOrganizations", fset.Position(call.Pos()), sel.Sel.Name)
+				}
+				return true
+			})
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type TwoFactorRepository interface {
+	ReplaceRecoveryCodes(userID uint, codes []models.TwoFactorRecoveryCode) error
+	DeleteRecoveryCodes(userID uint) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	UseTOTPStep(user *models.User, step int64) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, codes []models.TwoFactorRecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) DeleteRecoveryCodes(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error
+}
+
+// UseRecoveryCode consome o código de forma atômica; retorna false se ele não
+// existe ou já foi usado.
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	var code models.TwoFactorRecoveryCode
+	if err := r.db.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	result := r.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseTOTPStep grava o passo do código aceito só se ele for posterior ao último
+// usado: de duas requisições com o mesmo código, só uma altera a linha.
+func (r *twoFactorRepository) UseTOTPStep(user *models.User, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).Scopes(ForOrganization(user.OrganizationID)).
+		Where("id = ? AND two_factor_last_used_step < ?", user.ID, step).
+		UpdateColumn("two_factor_last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
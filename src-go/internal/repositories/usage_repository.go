@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api/internal/models"
+)
+
+type UsageRepository interface {
+	FindByPeriod(orgID uint, period string) ([]models.OrganizationUsage, error)
+	// TryIncrement soma 1 ao contador apenas se ele ainda estiver abaixo do
+	// limite (limit <= 0 é ilimitado). Retorna false quando a cota acabou.
+	TryIncrement(orgID uint, resource models.QuotaResource, period string, limit int) (bool, error)
+	Decrement(orgID uint, resource models.QuotaResource, period string) error
+}
+
+type usageRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+func (r *usageRepository) FindByPeriod(orgID uint, period string) ([]models.OrganizationUsage, error) {
+	var usage []models.OrganizationUsage
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("period = ?", period).Find(&usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *usageRepository) TryIncrement(orgID uint, resource models.QuotaResource, period string, limit int) (bool, error) {
+	counter := &models.OrganizationUsage{OrganizationID: orgID, Resource: resource, Period: period}
+	if err := r.db.Scopes(ForOrganization(orgID)).Clauses(clause.OnConflict{DoNothing: true}).Create(counter).Error; err != nil {
+		return false, err
+	}
+
+	// A condição fica no próprio UPDATE para que requisições simultâneas não
+	// ultrapassem o limite.
+	query := r.db.Model(&models.OrganizationUsage{}).Scopes(ForOrganization(orgID)).
+		Where("resource = ? AND period = ?", resource, period)
+	if limit > 0 {
+		query = query.Where("count < ?", limit)
+	}
+	result := query.UpdateColumn("count", gorm.Expr("count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *usageRepository) Decrement(orgID uint, resource models.QuotaResource, period string) error {
+	return r.db.Model(&models.OrganizationUsage{}).Scopes(ForOrganization(orgID)).
+		Where("resource = ? AND period = ? AND count > 0", resource, period).
+		UpdateColumn("count", gorm.Expr("count - 1")).Error
+}
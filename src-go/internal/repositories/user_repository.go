@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -12,11 +13,24 @@ type UserRepository interface {
 	FindByID(userID, orgID uint) (*models.User, error)
 	FindByIDUnscoped(userID uint) (*models.User, error) // Para Super Admin
 	FindByEmail(email string) (*models.User, error)
+	FindByEmployeeID(orgID uint, employeeID string) (*models.User, error)
+	FindByResetToken(tokenHash string) (*models.User, error)
+	ConsumeResetToken(user *models.User, tokenHash string, now time.Time) (bool, error)
+	FindByEmailVerificationToken(tokenHash string) (*models.User, error)
+	RegisterBadgePINFailure(user *models.User, pinHash string, maxAttempts int) (bool, error)
+	ResetBadgePINFailures(user *models.User) error
 	FindByOrganization(orgID uint, skip, limit int) ([]models.User, error)
 	FindAll(skip, limit int) ([]models.User, error) // Para Super Admin
 	FindByRole(role models.UserRole) ([]models.User, error) // Para Super Admin
-	Create(user *models.User) error
-	Update(user *models.User) error
+	CountByRole(orgID uint, role models.UserRole) (int64, error)
+	// Create e CreateBatch recusam com ErrLimitReached o que passaria de
+	// driverLimit motoristas na organização (driverLimit <= 0 é ilimitado).
+	Create(user *models.User, driverLimit int) error
+	CreateBatch(users []models.User, driverLimit int) error
+	FindExistingEmails(emails []string) ([]string, error)
+	FindExistingEmployeeIDs(orgID uint, employeeIDs []string) ([]string, error)
+	UpdateColumns(user *models.User, columns ...string) error
+	UpdateVersioned(user *models.User) error
 	Delete(user *models.User) error
 }
 
@@ -30,7 +44,7 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 func (r *userRepository) FindByID(userID, orgID uint) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("id = ? AND organization_id = ?", userID, orgID).First(&user).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", userID).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -41,7 +55,124 @@ func (r *userRepository) FindByID(userID, orgID uint) (*models.User, error) {
 
 func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	// O e-mail é único no sistema todo e identifica a organização no login.
+	// LOWER cobre cadastros antigos, gravados antes da normalização.
+	if err := r.db.Scopes(AllOrganizations).Where("LOWER(email) = ?", models.NormalizeEmail(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmployeeID(orgID uint, employeeID string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("employee_id = ?", employeeID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByResetToken(tokenHash string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Scopes(AllOrganizations).Where("reset_password_token = ?", tokenHash).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ConsumeResetToken grava a nova senha e apaga o token em um UPDATE
+// condicionado ao token ainda valer: de duas requisições com o mesmo token,
+// só uma altera a linha. Devolve false quando o token já foi usado ou expirou.
+func (r *userRepository) ConsumeResetToken(user *models.User, tokenHash string, now time.Time) (bool, error) {
+	err := r.withPasswordChange(user, func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Scopes(ForOrganization(user.OrganizationID)).
+			Where("id = ? AND reset_password_token = ? AND reset_password_token_expires_at > ?", user.ID, tokenHash, now).
+			Updates(map[string]interface{}{
+				"hashed_password":                 user.HashedPassword,
+				"reset_password_token":            nil,
+				"reset_password_token_expires_at": nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errResetTokenUsed
+		}
+		return nil
+	})
+	if errors.Is(err, errResetTokenUsed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	user.ResetPasswordToken = nil
+	user.ResetPasswordTokenExpiresAt = nil
+	return true, nil
+}
+
+// errResetTokenUsed desfaz a transação quando outra requisição já consumiu o token.
+var errResetTokenUsed = errors.New("reset token already used")
+
+// withPasswordChange grava o hash anterior no histórico na mesma transação do
+// update, para que a senha nova não fique sem o registro da antiga (nem o
+// contrário). Sem troca de senha, o update roda sozinho.
+func (r *userRepository) withPasswordChange(user *models.User, update func(tx *gorm.DB) error) error {
+	change := user.PasswordChange
+	if change == nil {
+		return update(r.db)
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := addPasswordHistory(tx, user.ID, change); err != nil {
+			return err
+		}
+		return update(tx)
+	})
+	if err != nil {
+		return err
+	}
+	user.PasswordChange = nil
+	return nil
+}
+
+// RegisterBadgePINFailure soma a falha no próprio UPDATE, para que tentativas
+// simultâneas não se percam, e apaga o PIN quando o total chega a
+// maxAttempts. Só conta enquanto o PIN for o que falhou (pinHash): um PIN
+// trocado no meio começa do zero. Devolve true para a requisição que revogou.
+func (r *userRepository) RegisterBadgePINFailure(user *models.User, pinHash string, maxAttempts int) (bool, error) {
+	revoked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		pin := tx.Model(&models.User{}).Scopes(ForOrganization(user.OrganizationID)).
+			Where("id = ? AND badge_pin_hash = ?", user.ID, pinHash)
+		if err := pin.UpdateColumn("badge_pin_failed_attempts", gorm.Expr("badge_pin_failed_attempts + 1")).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.User{}).Scopes(ForOrganization(user.OrganizationID)).
+			Where("id = ? AND badge_pin_hash = ? AND badge_pin_failed_attempts >= ?", user.ID, pinHash, maxAttempts).
+			UpdateColumns(map[string]interface{}{"badge_pin_hash": nil, "badge_pin_failed_attempts": 0})
+		revoked = result.RowsAffected == 1
+		return result.Error
+	})
+	return revoked, err
+}
+
+// ResetBadgePINFailures zera as falhas sem regravar o resto do usuário.
+func (r *userRepository) ResetBadgePINFailures(user *models.User) error {
+	return r.db.Model(&models.User{}).Scopes(ForOrganization(user.OrganizationID)).
+		Where("id = ?", user.ID).UpdateColumn("badge_pin_failed_attempts", 0).Error
+}
+
+func (r *userRepository) FindByEmailVerificationToken(tokenHash string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Scopes(AllOrganizations).Where("email_verification_token = ?", tokenHash).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -52,27 +183,86 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 
 func (r *userRepository) FindByOrganization(orgID uint, skip, limit int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Where("organization_id = ?", orgID).Offset(skip).Limit(limit).Find(&users).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Offset(skip).Limit(limit).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-func (r *userRepository) Create(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(user *models.User, driverLimit int) error {
+	return createWithinLimit(r.db, user.OrganizationID, driverLimit, countDrivers([]models.User{*user}), r.countDrivers(user.OrganizationID), user)
+}
+
+// CreateBatch grava todos os usuários ou nenhum. Todos são da mesma organização.
+func (r *userRepository) CreateBatch(users []models.User, driverLimit int) error {
+	if len(users) == 0 {
+		return nil
+	}
+	orgID := users[0].OrganizationID
+	return createWithinLimit(r.db, orgID, driverLimit, countDrivers(users), r.countDrivers(orgID), &users)
 }
 
-func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) countDrivers(orgID uint) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.User{}).Scopes(ForOrganization(orgID)).Where("role = ?", models.RoleDriver)
+	}
+}
+
+func countDrivers(users []models.User) int {
+	drivers := 0
+	for _, user := range users {
+		if user.Role == models.RoleDriver {
+			drivers++
+		}
+	}
+	return drivers
+}
+
+// FindExistingEmails busca em todas as organizações, como o FindByEmail, e
+// devolve os e-mails já normalizados.
+func (r *userRepository) FindExistingEmails(emails []string) ([]string, error) {
+	var existing []string
+	if len(emails) == 0 {
+		return existing, nil
+	}
+	normalized := make([]string, len(emails))
+	for i, email := range emails {
+		normalized[i] = models.NormalizeEmail(email)
+	}
+	err := r.db.Model(&models.User{}).Scopes(AllOrganizations).Where("LOWER(email) IN ?", normalized).Pluck("LOWER(email)", &existing).Error
+	return existing, err
+}
+
+func (r *userRepository) FindExistingEmployeeIDs(orgID uint, employeeIDs []string) ([]string, error) {
+	var existing []string
+	if len(employeeIDs) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&models.User{}).Scopes(ForOrganization(orgID)).Where("employee_id IN ?", employeeIDs).Pluck("employee_id", &existing).Error
+	return existing, err
+}
+
+// UpdateColumns grava estado interno (senha, tokens, 2FA, PIN) sem mexer na
+// versão, que só muda em edições do cadastro feitas por UpdateVersioned. Só
+// as colunas informadas são escritas, para que uma escrita interna não desfaça
+// uma edição do cadastro feita ao mesmo tempo.
+func (r *userRepository) UpdateColumns(user *models.User, columns ...string) error {
+	return r.db.Model(user).Scopes(ForOrganization(user.OrganizationID)).Select(columns).Updates(user).Error
+}
+
+func (r *userRepository) UpdateVersioned(user *models.User) error {
+	return r.withPasswordChange(user, func(tx *gorm.DB) error {
+		return updateVersioned(tx.Scopes(ForOrganization(user.OrganizationID)), user, &user.Version)
+	})
 }
 
 func (r *userRepository) Delete(user *models.User) error {
-	return r.db.Delete(user).Error
+	return r.db.Scopes(ForOrganization(user.OrganizationID)).Delete(user).Error
 }
 
 func (r *userRepository) FindByIDUnscoped(userID uint) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, userID).Error; err != nil {
+	if err := r.db.Scopes(AllOrganizations).First(&user, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -83,7 +273,7 @@ func (r *userRepository) FindByIDUnscoped(userID uint) (*models.User, error) {
 
 func (r *userRepository) FindAll(skip, limit int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Offset(skip).Limit(limit).Find(&users).Error; err != nil {
+	if err := r.db.Scopes(AllOrganizations).Offset(skip).Limit(limit).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -91,8 +281,16 @@ func (r *userRepository) FindAll(skip, limit int) ([]models.User, error) {
 
 func (r *userRepository) FindByRole(role models.UserRole) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Where("role = ?", role).Find(&users).Error; err != nil {
+	if err := r.db.Scopes(AllOrganizations).Where("role = ?", role).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
+
+func (r *userRepository) CountByRole(orgID uint, role models.UserRole) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).Scopes(ForOrganization(orgID)).Where("role = ?", role).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"testing"
+
+	"go-api/internal/models"
+)
+
+// Uma escrita interna feita com o usuário lido antes de uma edição do
+// cadastro não pode desfazer essa edição.
+func TestUserUpdateColumnsKeepsConcurrentEdits(t *testing.T) {
+	gormDB := newTestDB(t)
+	orgID := createTestOrganization(t, gormDB, "Transportes A")
+	repo := NewUserRepository(gormDB)
+	user := &models.User{Email: "ana@a.test", FullName: "Ana", HashedPassword: "hash-antigo", EmployeeID: "0001", Role: models.RoleDriver, IsActive: true, OrganizationID: orgID}
+	if err := repo.Create(user, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := repo.FindByID(user.ID, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited, err := repo.FindByID(user.ID, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited.FullName = "Ana Souza"
+	edited.IsActive = false
+	if err := repo.UpdateVersioned(edited); err != nil {
+		t.Fatal(err)
+	}
+
+	stale.HashedPassword = "hash-novo"
+	if err := repo.UpdateColumns(stale, "hashed_password"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repo.FindByID(user.ID, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.HashedPassword != "hash-novo" {
+		t.Errorf("hashed_password = %q, want hash-novo", stored.HashedPassword)
+	}
+	if stored.FullName != "Ana Souza" || stored.IsActive || stored.Version != edited.Version {
+		t.Errorf("concurrent edit lost: full_name=%q active=%v version=%d, want Ana Souza false %d", stored.FullName, stored.IsActive, stored.Version, edited.Version)
+	}
+}
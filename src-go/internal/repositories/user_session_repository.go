@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+)
+
+type UserSessionRepository interface {
+	Create(session *models.UserSession) error
+	FindBySessionID(sessionID string) (*models.UserSession, error)
+	FindByID(id, userID uint) (*models.UserSession, error)
+	FindActiveByUser(userID uint) ([]models.UserSession, error)
+	Revoke(sessionID string) error
+	RevokeAllForUser(userID uint) error
+	TouchLastSeen(id uint, seenAt time.Time) error
+}
+
+// Sessões são identificadas pelo "sid" do token ou pelo usuário, ambos únicos
+// entre organizações; as consultas usam AllOrganizations.
+type userSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
+	return &userSessionRepository{db: db}
+}
+
+func (r *userSessionRepository) Create(session *models.UserSession) error {
+	return r.db.Scopes(ForOrganization(session.OrganizationID)).Create(session).Error
+}
+
+func (r *userSessionRepository) FindBySessionID(sessionID string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.Scopes(AllOrganizations).Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userSessionRepository) FindByID(id, userID uint) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.Scopes(AllOrganizations).Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userSessionRepository) FindActiveByUser(userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	if err := r.db.Scopes(AllOrganizations).Where("user_id = ? AND revoked_at IS NULL", userID).Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *userSessionRepository) Revoke(sessionID string) error {
+	return r.db.Model(&models.UserSession{}).Scopes(AllOrganizations).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *userSessionRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.UserSession{}).Scopes(AllOrganizations).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *userSessionRepository) TouchLastSeen(id uint, seenAt time.Time) error {
+	return r.db.Model(&models.UserSession{}).Scopes(AllOrganizations).Where("id = ?", id).Update("last_seen_at", seenAt).Error
+}
@@ -10,10 +10,19 @@ import (
 
 type VehicleRepository interface {
 	FindByID(vehicleID, orgID uint) (*models.Vehicle, error)
+	FindByIDs(vehicleIDs []uint, orgID uint) ([]models.Vehicle, error)
 	FindByOrganization(orgID uint, skip, limit int, search string) ([]models.Vehicle, error)
 	CountByOrganization(orgID uint, search string) (int64, error)
-	Create(vehicle *models.Vehicle) error
+	// Create e CreateBatch recusam com ErrLimitReached o que passaria de
+	// limit veículos na organização (limit <= 0 é ilimitado).
+	Create(vehicle *models.Vehicle, limit int) error
+	CreateBatch(vehicles []models.Vehicle, limit int) error
+	FindExistingLicensePlates(plates []string) ([]string, error)
+	FindExistingTelemetryDevices(deviceIDs []string) ([]string, error)
 	Update(vehicle *models.Vehicle) error
+	UpdateStatus(vehicle *models.Vehicle, change *models.VehicleStatusChange) error
+	FindStatusHistory(vehicleID, orgID uint) ([]models.VehicleStatusChange, error)
+	FindLastStatusChange(vehicleID, orgID uint, toStatus models.VehicleStatus) (*models.VehicleStatusChange, error)
 	Delete(vehicle *models.Vehicle) error
 }
 
@@ -27,7 +36,7 @@ func NewVehicleRepository(db *gorm.DB) VehicleRepository {
 
 func (r *vehicleRepository) FindByID(vehicleID, orgID uint) (*models.Vehicle, error) {
 	var vehicle models.Vehicle
-	if err := r.db.Where("id = ? AND organization_id = ?", vehicleID, orgID).First(&vehicle).Error; err != nil {
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id = ?", vehicleID).First(&vehicle).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -36,9 +45,18 @@ func (r *vehicleRepository) FindByID(vehicleID, orgID uint) (*models.Vehicle, er
 	return &vehicle, nil
 }
 
+// FindByIDs ignora os IDs que não existem na organização.
+func (r *vehicleRepository) FindByIDs(vehicleIDs []uint, orgID uint) ([]models.Vehicle, error) {
+	var vehicles []models.Vehicle
+	if err := r.db.Scopes(ForOrganization(orgID)).Where("id IN ?", vehicleIDs).Find(&vehicles).Error; err != nil {
+		return nil, err
+	}
+	return vehicles, nil
+}
+
 func (r *vehicleRepository) FindByOrganization(orgID uint, skip, limit int, search string) ([]models.Vehicle, error) {
 	var vehicles []models.Vehicle
-	query := r.db.Where("organization_id = ?", orgID)
+	query := r.db.Scopes(ForOrganization(orgID))
 
 	if search != "" {
 		searchQuery := "%" + search + "%"
@@ -53,7 +71,7 @@ func (r *vehicleRepository) FindByOrganization(orgID uint, skip, limit int, sear
 
 func (r *vehicleRepository) CountByOrganization(orgID uint, search string) (int64, error) {
 	var count int64
-	query := r.db.Model(&models.Vehicle{}).Where("organization_id = ?", orgID)
+	query := r.db.Model(&models.Vehicle{}).Scopes(ForOrganization(orgID))
 
 	if search != "" {
 		searchQuery := "%" + search + "%"
@@ -66,14 +84,85 @@ func (r *vehicleRepository) CountByOrganization(orgID uint, search string) (int6
 	return count, nil
 }
 
-func (r *vehicleRepository) Create(vehicle *models.Vehicle) error {
-	return r.db.Create(vehicle).Error
+func (r *vehicleRepository) Create(vehicle *models.Vehicle, limit int) error {
+	return createWithinLimit(r.db, vehicle.OrganizationID, limit, 1, r.countVehicles(vehicle.OrganizationID), vehicle)
+}
+
+// CreateBatch grava todos os veículos ou nenhum. Todos são da mesma organização.
+func (r *vehicleRepository) CreateBatch(vehicles []models.Vehicle, limit int) error {
+	if len(vehicles) == 0 {
+		return nil
+	}
+	orgID := vehicles[0].OrganizationID
+	return createWithinLimit(r.db, orgID, limit, len(vehicles), r.countVehicles(orgID), &vehicles)
+}
+
+func (r *vehicleRepository) countVehicles(orgID uint) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.Vehicle{}).Scopes(ForOrganization(orgID))
+	}
+}
+
+// FindExistingLicensePlates devolve as placas já cadastradas. A placa é única
+// no sistema todo, por isso a busca não se limita à organização.
+func (r *vehicleRepository) FindExistingLicensePlates(plates []string) ([]string, error) {
+	return r.findExisting("license_plate", plates)
+}
+
+// FindExistingTelemetryDevices devolve os rastreadores já vinculados a algum veículo.
+func (r *vehicleRepository) FindExistingTelemetryDevices(deviceIDs []string) ([]string, error) {
+	return r.findExisting("telemetry_device_id", deviceIDs)
+}
+
+func (r *vehicleRepository) findExisting(column string, values []string) ([]string, error) {
+	var existing []string
+	if len(values) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&models.Vehicle{}).Scopes(AllOrganizations).
+		Where(column+" IN ?", values).Pluck(column, &existing).Error
+	return existing, err
 }
 
 func (r *vehicleRepository) Update(vehicle *models.Vehicle) error {
-	return r.db.Save(vehicle).Error
+	return updateVersioned(r.db.Scopes(ForOrganization(vehicle.OrganizationID)), vehicle, &vehicle.Version)
+}
+
+// UpdateStatus grava o veículo e o registro da transição na mesma transação.
+func (r *vehicleRepository) UpdateStatus(vehicle *models.Vehicle, change *models.VehicleStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx.Scopes(ForOrganization(vehicle.OrganizationID)), vehicle, &vehicle.Version); err != nil {
+			return err
+		}
+		return tx.Scopes(ForOrganization(change.OrganizationID)).Create(change).Error
+	})
+}
+
+func (r *vehicleRepository) FindStatusHistory(vehicleID, orgID uint) ([]models.VehicleStatusChange, error) {
+	var changes []models.VehicleStatusChange
+	err := r.db.Scopes(ForOrganization(orgID)).Preload("Actor").
+		Where("vehicle_id = ?", vehicleID).Order("changed_at, id").Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// FindLastStatusChange devolve a transição mais recente do veículo para
+// toStatus, ou nil se ele nunca esteve nesse status.
+func (r *vehicleRepository) FindLastStatusChange(vehicleID, orgID uint, toStatus models.VehicleStatus) (*models.VehicleStatusChange, error) {
+	var change models.VehicleStatusChange
+	err := r.db.Scopes(ForOrganization(orgID)).
+		Where("vehicle_id = ? AND to_status = ?", vehicleID, toStatus).Order("changed_at DESC, id DESC").First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &change, nil
 }
 
 func (r *vehicleRepository) Delete(vehicle *models.Vehicle) error {
-	return r.db.Delete(vehicle).Error
+	return r.db.Scopes(ForOrganization(vehicle.OrganizationID)).Delete(vehicle).Error
 }
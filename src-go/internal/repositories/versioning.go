@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrVersionConflict = errors.New("record version changed since it was read")
+
+// updateVersioned grava todas as colunas do registro somente se a versão no
+// banco ainda for a lida, incrementando-a. Associações pré-carregadas são
+// ignoradas para não sobrescrever chaves estrangeiras alteradas no patch.
+func updateVersioned(db *gorm.DB, model interface{}, version *uint) error {
+	expected := *version
+	*version = expected + 1
+	result := db.Model(model).Where("version = ?", expected).Select("*").Omit(clause.Associations).Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = expected
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package schemas
+
+import "time"
+
+// OrganizationAnalytics resume uma organização para o super admin. Jornadas,
+// ordens de frete e usuários ativos respeitam o período pedido; os demais
+// números são o total atual.
+type OrganizationAnalytics struct {
+	OrganizationID uint       `json:"organization_id"`
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	OnTrial        bool       `json:"on_trial"`
+	TotalUsers     int64      `json:"total_users"`
+	ActiveUsers    int64      `json:"active_users"`
+	Vehicles       int64      `json:"vehicles"`
+	Journeys       int64      `json:"journeys"`
+	FreightOrders  int64      `json:"freight_orders"`
+	StorageFiles   int64      `json:"storage_files"`
+	StorageBytes   int64      `json:"storage_bytes"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+}
+
+// ConversionPoint é um mês da conversão demonstração -> cliente ativo.
+// Conversions conta as ativações feitas no mês; CohortConverted, quantas das
+// demonstrações abertas no mês já foram ativadas.
+type ConversionPoint struct {
+	Period               string  `json:"period"`
+	DemoSignups          int64   `json:"demo_signups"`
+	Conversions          int64   `json:"conversions"`
+	CohortConverted      int64   `json:"cohort_converted"`
+	CohortConversionRate float64 `json:"cohort_conversion_rate"`
+}
+
+type OrganizationAnalyticsReport struct {
+	DateFrom      *time.Time              `json:"date_from"`
+	DateTo        *time.Time              `json:"date_to"`
+	Organizations []OrganizationAnalytics `json:"organizations"`
+}
+
+type ConversionReport struct {
+	DateFrom *time.Time        `json:"date_from"`
+	DateTo   *time.Time        `json:"date_to"`
+	Periods  []ConversionPoint `json:"periods"`
+}
@@ -0,0 +1,26 @@
+package schemas
+
+import "time"
+
+type APIKeyCreate struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyPublic struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreated é a única resposta que contém a chave em texto puro.
+type APIKeyCreated struct {
+	APIKeyPublic
+	Key string `json:"key"`
+}
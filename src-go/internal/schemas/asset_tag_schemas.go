@@ -0,0 +1,34 @@
+package schemas
+
+import "go-api/internal/models"
+
+// AssetTagRequest seleciona os registros das etiquetas, na ordem em que
+// devem sair na folha. Vem da query (vehicle_ids, implement_ids e
+// inventory_item_ids).
+type AssetTagRequest struct {
+	VehicleIDs       []uint
+	ImplementIDs     []uint
+	InventoryItemIDs []uint
+}
+
+// AssetTagPublic é uma etiqueta: o texto impresso ao lado do QR e o conteúdo
+// dele (URL é o que o QR codifica; Payload é o segmento assinado da URL).
+type AssetTagPublic struct {
+	Type    models.AssetType `json:"type"`
+	ID      uint             `json:"id"`
+	Title   string           `json:"title"`
+	Details []string         `json:"details"`
+	Payload string           `json:"payload"`
+	URL     string           `json:"url"`
+}
+
+// AssetTagResolve aceita a URL lida do QR ou só o payload.
+type AssetTagResolve struct {
+	Payload string `json:"payload" binding:"required"`
+}
+
+type AssetTagResolved struct {
+	Type   models.AssetType `json:"type"`
+	ID     uint             `json:"id"`
+	Entity any              `json:"entity"`
+}
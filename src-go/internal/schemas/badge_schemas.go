@@ -0,0 +1,17 @@
+package schemas
+
+type BadgeLoginRequest struct {
+	OrganizationCode string `json:"organization_code" binding:"required"`
+	EmployeeID       string `json:"employee_id" binding:"required"`
+	PIN              string `json:"pin" binding:"required"`
+}
+
+type BadgePINUpdate struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+// BadgeCredentials é o que o gestor repassa ao motorista junto com o PIN.
+type BadgeCredentials struct {
+	OrganizationCode string `json:"organization_code"`
+	EmployeeID       string `json:"employee_id"`
+}
@@ -13,11 +13,11 @@ type FineCreate struct {
 }
 
 type FineUpdate struct {
-	Description    string    `json:"description"`
-	InfractionCode string    `json:"infraction_code"`
-	Date           time.Time `json:"date"`
-	Value          float64   `json:"value"`
-	Status         string    `json:"status"`
-	VehicleID      uint      `json:"vehicle_id"`
-	DriverID       *uint     `json:"driver_id"`
+	Description    PatchField[string]    `json:"description"`
+	InfractionCode PatchField[string]    `json:"infraction_code"`
+	Date           PatchField[time.Time] `json:"date"`
+	Value          PatchField[float64]   `json:"value"`
+	Status         PatchField[string]    `json:"status"`
+	VehicleID      PatchField[uint]      `json:"vehicle_id"`
+	DriverID       PatchField[uint]      `json:"driver_id"`
 }
@@ -10,9 +10,9 @@ type FuelLogCreate struct {
 }
 
 type FuelLogUpdate struct {
-	Odometer        *int     `json:"odometer"`
-	Liters          *float64 `json:"liters"`
-	TotalCost       *float64 `json:"total_cost"`
-	VehicleID       *uint    `json:"vehicle_id"`
-	ReceiptPhotoURL *string  `json:"receipt_photo_url"`
+	Odometer        PatchField[int]     `json:"odometer"`
+	Liters          PatchField[float64] `json:"liters"`
+	TotalCost       PatchField[float64] `json:"total_cost"`
+	VehicleID       PatchField[uint]    `json:"vehicle_id"`
+	ReceiptPhotoURL PatchField[string]  `json:"receipt_photo_url"`
 }
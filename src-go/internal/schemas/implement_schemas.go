@@ -12,13 +12,13 @@ type ImplementCreate struct {
 }
 
 type ImplementUpdate struct {
-	Name       string `json:"name"`
-	Brand      string `json:"brand"`
-	VehicleModel      string `json:"model"`
-	Year       int    `json:"year"`
-	Identifier string `json:"identifier"`
-	Type       string `json:"type"`
-	Status     string `json:"status"`
+	Name         PatchField[string] `json:"name"`
+	Brand        PatchField[string] `json:"brand"`
+	VehicleModel PatchField[string] `json:"model"`
+	Year         PatchField[int]    `json:"year"`
+	Identifier   PatchField[string] `json:"identifier"`
+	Type         PatchField[string] `json:"type"`
+	Status       PatchField[string] `json:"status"`
 }
 
 type ImplementPublic struct {
@@ -31,6 +31,7 @@ type ImplementPublic struct {
 	Type       string `json:"type"`
 	Status     string `json:"status"`
 	OrganizationID uint `json:"organization_id"`
+	Version        uint `json:"version"`
 }
 
 func ToImplementPublic(implement models.Implement) ImplementPublic {
@@ -44,5 +45,6 @@ func ToImplementPublic(implement models.Implement) ImplementPublic {
 		Type:          implement.Type,
 		Status:        string(implement.Status),
 		OrganizationID: implement.OrganizationID,
+		Version:        implement.Version,
 	}
 }
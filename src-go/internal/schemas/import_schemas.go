@@ -0,0 +1,25 @@
+package schemas
+
+// ImportRow é uma linha da planilha já convertida no schema de criação. Line
+// é o número da linha no arquivo (o cabeçalho é a linha 1).
+type ImportRow[T any] struct {
+	Line int
+	Data T
+}
+
+// ImportError aponta o problema de uma linha; Column fica vazio quando o erro
+// não é de uma célula específica (ex.: e-mail repetido no arquivo).
+type ImportError struct {
+	Line    int    `json:"line"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport é o resultado da importação. Com qualquer erro nada é gravado;
+// no dry run a validação é a mesma, mas nada é gravado mesmo sem erros.
+type ImportReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Rows    int           `json:"rows"`
+	Created int           `json:"created"`
+	Errors  []ImportError `json:"errors"`
+}
@@ -0,0 +1,25 @@
+package schemas
+
+import "time"
+
+// OdometerReadingCreate é a leitura informada manualmente ou por uma
+// integração de telemetria (source "telemetry").
+type OdometerReadingCreate struct {
+	Odometer    *int       `json:"odometer"`
+	EngineHours *float64   `json:"engine_hours"`
+	RecordedAt  *time.Time `json:"recorded_at"`
+	Source      string     `json:"source"`
+}
+
+type OdometerReadingPublic struct {
+	ID             uint      `json:"id"`
+	Odometer       *int      `json:"odometer"`
+	EngineHours    *float64  `json:"engine_hours"`
+	Source         string    `json:"source"`
+	SourceID       *uint     `json:"source_id"`
+	Status         string    `json:"status"`
+	FlagReason     *string   `json:"flag_reason"`
+	RecordedAt     time.Time `json:"recorded_at"`
+	RecordedByID   *uint     `json:"recorded_by_id"`
+	RecordedByName *string   `json:"recorded_by_name"`
+}
@@ -1,6 +1,32 @@
 package schemas
 
 type OrganizationUpdate struct {
-	Name   string `json:"name"`
-	Sector string `json:"sector"`
+	Name                        string `json:"name"`
+	Sector                      string `json:"sector"`
+	RequireTwoFactorForManagers *bool  `json:"require_two_factor_for_managers"`
+
+	// Limites do plano (apenas super admin); 0 significa ilimitado.
+	VehicleLimit             *int `json:"vehicle_limit"`
+	DriverLimit              *int `json:"driver_limit"`
+	JourneyMonthlyLimit      *int `json:"journey_monthly_limit"`
+	FreightOrderMonthlyLimit *int `json:"freight_order_monthly_limit"`
+	DocumentMonthlyLimit     *int `json:"document_monthly_limit"`
+}
+
+// OrganizationCreate é usado pelo super admin para abrir uma organização já
+// ativa, junto com o primeiro gestor.
+type OrganizationCreate struct {
+	Name    string                    `json:"name" binding:"required"`
+	Sector  string                    `json:"sector" binding:"required"`
+	Manager OrganizationManagerCreate `json:"manager" binding:"required"`
+}
+
+type OrganizationManagerCreate struct {
+	FullName string `json:"full_name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type OrganizationSuspend struct {
+	Reason string `json:"reason" binding:"max=255"`
 }
@@ -0,0 +1,31 @@
+package schemas
+
+import "time"
+
+type OrganizationSettingsPublic struct {
+	TimeZone               string    `json:"time_zone"`
+	Currency               string    `json:"currency"`
+	UsageUnit              string    `json:"usage_unit"`
+	Locale                 string    `json:"locale"`
+	FiscalYearStartMonth   int       `json:"fiscal_year_start_month"`
+	CurrentFiscalYearStart time.Time `json:"current_fiscal_year_start"`
+}
+
+// OrganizationSettingsUpdate altera apenas os campos enviados.
+type OrganizationSettingsUpdate struct {
+	TimeZone             *string `json:"time_zone"`
+	Currency             *string `json:"currency"`
+	UsageUnit            *string `json:"usage_unit"`
+	Locale               *string `json:"locale"`
+	FiscalYearStartMonth *int    `json:"fiscal_year_start_month"`
+}
+
+// DateRange é o filtro de período das listagens da organização: datas do
+// calendário (To exclusivo) ou um ano fiscal, ambos resolvidos no fuso e no
+// mês de início do ano fiscal da organização.
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+	// FiscalYear é o ano civil em que o ano fiscal começa.
+	FiscalYear *int
+}
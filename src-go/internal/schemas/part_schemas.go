@@ -15,17 +15,16 @@ type PartCreate struct {
 }
 
 type PartUpdate struct {
-	Name         string   `json:"name"`
-	Category     string   `json:"category"`
-	MinimumStock int      `json:"minimum_stock"`
-	PartNumber   *string  `json:"part_number"`
-	Brand        *string  `json:"brand"`
-	Location     *string  `json:"location"`
-	Notes        *string  `json:"notes"`
-	Value        *float64 `json:"value"`
-	SerialNumber *string  `json:"serial_number"`
-	LifespanKM   *int     `json:"lifespan_km"`
-	Condition    *string  `json:"condition"`
+	Name         PatchField[string]  `json:"name"`
+	Category     PatchField[string]  `json:"category"`
+	MinimumStock PatchField[int]     `json:"minimum_stock"`
+	PartNumber   PatchField[string]  `json:"part_number"`
+	Brand        PatchField[string]  `json:"brand"`
+	Location     PatchField[string]  `json:"location"`
+	Notes        PatchField[string]  `json:"notes"`
+	Value        PatchField[float64] `json:"value"`
+	SerialNumber PatchField[string]  `json:"serial_number"`
+	LifespanKM   PatchField[int]     `json:"lifespan_km"`
 }
 
 type AddItemsPayload struct {
@@ -0,0 +1,27 @@
+package schemas
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PatchField é um campo de JSON Merge Patch (RFC 7386). Distingue campo
+// ausente (Set false), null explícito (Null true) e valor informado.
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON só é chamado quando a chave aparece no corpo.
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		var zero T
+		f.Value = zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
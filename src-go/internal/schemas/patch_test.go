@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPatchFieldDistinguishesAbsentNullAndValue(t *testing.T) {
+	var body struct {
+		Absent PatchField[string] `json:"absent"`
+		Null   PatchField[string] `json:"null"`
+		Value  PatchField[string] `json:"value"`
+		Zero   PatchField[int]    `json:"zero"`
+	}
+	if err := json.Unmarshal([]byte(`{"null": null, "value": "ABC1D23", "zero": 0}`), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.Absent.Set || body.Absent.Null {
+		t.Errorf("absent = %+v, want unset", body.Absent)
+	}
+	if !body.Null.Set || !body.Null.Null || body.Null.Value != "" {
+		t.Errorf("null = %+v, want set and null", body.Null)
+	}
+	if !body.Value.Set || body.Value.Null || body.Value.Value != "ABC1D23" {
+		t.Errorf("value = %+v, want set with ABC1D23", body.Value)
+	}
+	if !body.Zero.Set || body.Zero.Null || body.Zero.Value != 0 {
+		t.Errorf("zero = %+v, want set with 0", body.Zero)
+	}
+}
+
+func TestPatchFieldRejectsWrongType(t *testing.T) {
+	var body struct {
+		Year PatchField[int] `json:"year"`
+	}
+	if err := json.Unmarshal([]byte(`{"year": "2020"}`), &body); err == nil {
+		t.Fatal("string accepted for an int field")
+	}
+}
@@ -0,0 +1,12 @@
+package schemas
+
+import "go-api/internal/models"
+
+type RolePermissionsUpdate struct {
+	Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+type RolePermissions struct {
+	Role        models.UserRole     `json:"role"`
+	Permissions []models.Permission `json:"permissions"`
+}
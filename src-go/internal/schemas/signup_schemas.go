@@ -0,0 +1,17 @@
+package schemas
+
+type SignupRequest struct {
+	OrganizationName string `json:"organization_name" binding:"required"`
+	Sector           string `json:"sector" binding:"required"`
+	FullName         string `json:"full_name" binding:"required"`
+	Email            string `json:"email" binding:"required,email"`
+	Password         string `json:"password" binding:"required"`
+}
+
+type EmailVerificationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,37 @@
+package schemas
+
+import "time"
+
+type SSOConfigUpdate struct {
+	Issuer         string   `json:"issuer" binding:"required"`
+	ClientID       string   `json:"client_id" binding:"required"`
+	ClientSecret   *string  `json:"client_secret"`
+	Scopes         []string `json:"scopes"`
+	AllowedDomains []string `json:"allowed_domains"`
+	DefaultRole    string   `json:"default_role" binding:"required"`
+	AutoProvision  *bool    `json:"auto_provision"`
+	Enabled        bool     `json:"enabled"`
+}
+
+// SSOConfigPublic nunca expõe o client secret, apenas se ele foi configurado.
+type SSOConfigPublic struct {
+	Issuer          string    `json:"issuer"`
+	ClientID        string    `json:"client_id"`
+	HasClientSecret bool      `json:"has_client_secret"`
+	Scopes          []string  `json:"scopes"`
+	AllowedDomains  []string  `json:"allowed_domains"`
+	DefaultRole     string    `json:"default_role"`
+	AutoProvision   bool      `json:"auto_provision"`
+	Enabled         bool      `json:"enabled"`
+	RedirectURI     string    `json:"redirect_uri"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type SSOAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type SSOCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -1,6 +1,31 @@
 package schemas
 
+import "time"
+
+// Token também representa o desafio do login em duas etapas: quando
+// MFARequired ou MFASetupRequired vêm preenchidos, AccessToken fica vazio e o
+// cliente deve continuar com MFAToken.
 type Token struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresIn        int    `json:"expires_in,omitempty"`
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	MFASetupRequired bool   `json:"mfa_setup_required,omitempty"`
+	MFAToken         string `json:"mfa_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SessionPublic struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Scope      string    `json:"scope,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
 }
@@ -0,0 +1,30 @@
+package schemas
+
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorSetupRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// TwoFactorActivation é devolvido quando a ativação acontece durante o login
+// obrigatório: traz os códigos de recuperação e os tokens da sessão.
+type TwoFactorActivation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         *Token   `json:"token"`
+}
@@ -0,0 +1,21 @@
+package schemas
+
+import "time"
+
+// ResourceUsage mostra o consumo de um recurso; Limit e Remaining são nulos
+// quando o plano não limita o recurso.
+type ResourceUsage struct {
+	Resource  string `json:"resource"`
+	Used      int64  `json:"used"`
+	Limit     *int   `json:"limit"`
+	Remaining *int64 `json:"remaining"`
+	Monthly   bool   `json:"monthly"`
+}
+
+type UsageReport struct {
+	OrganizationID uint            `json:"organization_id"`
+	Period         string          `json:"period"`
+	TimeZone       string          `json:"time_zone"`
+	TrialEndsAt    *time.Time      `json:"trial_ends_at"`
+	Resources      []ResourceUsage `json:"resources"`
+}
@@ -7,12 +7,24 @@ type UserCreate struct {
 	FullName   string `json:"full_name" binding:"required"`
 	Password   string `json:"password" binding:"required"`
 	Role       models.UserRole `json:"role"`
+	// Opcional; sem ela a matrícula é gerada pela sequência da organização.
+	EmployeeID *string `json:"employee_id"`
+}
+
+// UserImport é a linha da importação (e da exportação) de usuários. Não há
+// coluna de senha: cada usuário importado recebe um link para definir a sua.
+type UserImport struct {
+	Email      string          `json:"email" binding:"required,email"`
+	FullName   string          `json:"full_name" binding:"required"`
+	Role       models.UserRole `json:"role"`
+	EmployeeID *string         `json:"employee_id"`
 }
 
 type UserUpdate struct {
-	FullName string `json:"full_name"`
-	Email    string `json:"email" binding:"email"`
-	IsActive *bool  `json:"is_active"`
+	FullName PatchField[string] `json:"full_name"`
+	Email    PatchField[string] `json:"email"`
+	IsActive PatchField[bool]   `json:"is_active"`
+	Password PatchField[string] `json:"password"`
 }
 
 type UserPublic struct {
@@ -22,3 +34,12 @@ type UserPublic struct {
 	IsActive       bool   `json:"is_active"`
 	OrganizationID uint   `json:"organization_id"`
 }
+
+type PasswordRecoveryRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type PasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
@@ -19,18 +19,38 @@ type VehicleCreate struct {
 	TelemetryDeviceID  *string   `json:"telemetry_device_id"`
 }
 
+// VehicleUpdate é um JSON Merge Patch: campos ausentes ficam como estão e
+// null limpa os campos anuláveis.
 type VehicleUpdate struct {
-	Brand              *string   `json:"brand"`
-	Model              *string   `json:"model"`
-	Year               *int      `json:"year"`
-	LicensePlate       *string   `json:"license_plate"`
-	Identifier         *string   `json:"identifier"`
-	PhotoURL           *string   `json:"photo_url"`
-	Status             *string   `json:"status"`
-	CurrentKM          *int      `json:"current_km"`
-	CurrentEngineHours *float64  `json:"current_engine_hours"`
-	NextMaintenanceDate *time.Time `json:"next_maintenance_date"`
-	NextMaintenanceKM  *int      `json:"next_maintenance_km"`
-	MaintenanceNotes   *string   `json:"maintenance_notes"`
-	TelemetryDeviceID  *string   `json:"telemetry_device_id"`
+	Brand               PatchField[string]    `json:"brand"`
+	Model               PatchField[string]    `json:"model"`
+	Year                PatchField[int]       `json:"year"`
+	LicensePlate        PatchField[string]    `json:"license_plate"`
+	Identifier          PatchField[string]    `json:"identifier"`
+	PhotoURL            PatchField[string]    `json:"photo_url"`
+	Status              PatchField[string]    `json:"status"`
+	CurrentKM           PatchField[int]       `json:"current_km"`
+	CurrentEngineHours  PatchField[float64]   `json:"current_engine_hours"`
+	NextMaintenanceDate PatchField[time.Time] `json:"next_maintenance_date"`
+	NextMaintenanceKM   PatchField[int]       `json:"next_maintenance_km"`
+	MaintenanceNotes    PatchField[string]    `json:"maintenance_notes"`
+	TelemetryDeviceID   PatchField[string]    `json:"telemetry_device_id"`
+}
+
+type VehicleStatusUpdate struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// VehicleStatusPeriod é um intervalo em que o veículo ficou em um status,
+// aberto (Until nulo) enquanto for o status atual.
+type VehicleStatusPeriod struct {
+	Status     string     `json:"status"`
+	FromStatus string     `json:"from_status"`
+	Since      time.Time  `json:"since"`
+	Until      *time.Time `json:"until"`
+	Reason     string     `json:"reason"`
+	ActorID    *uint      `json:"actor_id"`
+	ActorName  *string    `json:"actor_name"`
+	JourneyID  *uint      `json:"journey_id"`
 }
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/logging"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+	"go-api/internal/storage"
+)
+
+// AnalyticsService calcula as métricas da plataforma a partir das tabelas
+// existentes, sem contadores próprios. As datas são em UTC e dateTo é exclusivo.
+type AnalyticsService interface {
+	GetOrganizationAnalytics(dateFrom, dateTo *time.Time) (*schemas.OrganizationAnalyticsReport, error)
+	GetConversions(dateFrom, dateTo *time.Time) (*schemas.ConversionReport, error)
+}
+
+type analyticsService struct {
+	repo    repositories.AnalyticsRepository
+	orgRepo repositories.OrganizationRepository
+	storage storage.FileStorageService
+}
+
+func NewAnalyticsService(repo repositories.AnalyticsRepository, orgRepo repositories.OrganizationRepository, storage storage.FileStorageService) AnalyticsService {
+	return &analyticsService{repo: repo, orgRepo: orgRepo, storage: storage}
+}
+
+func (s *analyticsService) GetOrganizationAnalytics(dateFrom, dateTo *time.Time) (*schemas.OrganizationAnalyticsReport, error) {
+	orgs, err := s.orgRepo.FindAll(0, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.CountUsers()
+	if err != nil {
+		return nil, err
+	}
+	activeUsers, err := s.repo.CountActiveUsers(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	vehicles, err := s.repo.CountVehicles()
+	if err != nil {
+		return nil, err
+	}
+	journeys, err := s.repo.CountJourneys(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	freightOrders, err := s.repo.CountFreightOrders(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	lastActivity, err := s.repo.LastActivity()
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.repo.FileURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &schemas.OrganizationAnalyticsReport{DateFrom: dateFrom, DateTo: dateTo, Organizations: []schemas.OrganizationAnalytics{}}
+	for _, org := range orgs {
+		row := schemas.OrganizationAnalytics{
+			OrganizationID: org.ID,
+			Name:           org.Name,
+			Status:         string(org.Status),
+			OnTrial:        org.TrialEndsAt != nil,
+			TotalUsers:     users[org.ID],
+			ActiveUsers:    activeUsers[org.ID],
+			Vehicles:       vehicles[org.ID],
+			Journeys:       journeys[org.ID],
+			FreightOrders:  freightOrders[org.ID],
+			StorageFiles:   int64(len(files[org.ID])),
+		}
+		for _, file := range files[org.ID] {
+			size, err := s.storage.Size(file)
+			if err != nil {
+				logging.Logger.Warn("Failed to read file size for analytics", zap.Uint("organization_id", org.ID), zap.String("file", file), zap.Error(err))
+				continue
+			}
+			row.StorageBytes += size
+		}
+		if last, ok := lastActivity[org.ID]; ok {
+			row.LastActivityAt = &last
+		}
+		report.Organizations = append(report.Organizations, row)
+	}
+	return report, nil
+}
+
+// GetConversions agrupa por mês (UTC) as demonstrações abertas e as ativações
+// feitas por ActivateUser. Ativações anteriores ao registro de ActivatedAt não
+// aparecem.
+func (s *analyticsService) GetConversions(dateFrom, dateTo *time.Time) (*schemas.ConversionReport, error) {
+	accounts, err := s.repo.FindDemoAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	inRange := func(t time.Time) bool {
+		return (dateFrom == nil || !t.Before(*dateFrom)) && (dateTo == nil || t.Before(*dateTo))
+	}
+	points := map[string]*schemas.ConversionPoint{}
+	point := func(t time.Time) *schemas.ConversionPoint {
+		period := t.UTC().Format("2006-01")
+		if points[period] == nil {
+			points[period] = &schemas.ConversionPoint{Period: period}
+		}
+		return points[period]
+	}
+
+	for _, account := range accounts {
+		if inRange(account.CreatedAt) {
+			p := point(account.CreatedAt)
+			p.DemoSignups++
+			if account.ActivatedAt != nil {
+				p.CohortConverted++
+			}
+		}
+		if account.ActivatedAt != nil && inRange(*account.ActivatedAt) {
+			point(*account.ActivatedAt).Conversions++
+		}
+	}
+
+	report := &schemas.ConversionReport{DateFrom: dateFrom, DateTo: dateTo, Periods: []schemas.ConversionPoint{}}
+	for _, p := range points {
+		if p.DemoSignups > 0 {
+			p.CohortConversionRate = float64(p.CohortConverted) / float64(p.DemoSignups)
+		}
+		report.Periods = append(report.Periods, *p)
+	}
+	sort.Slice(report.Periods, func(i, j int) bool { return report.Periods[i].Period < report.Periods[j].Period })
+	return report, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// APIKeyPrefix identifica as chaves de API no header Authorization, para que o
+// AuthMiddleware não tente interpretá-las como JWT.
+const APIKeyPrefix = "trk_"
+
+// lastUsedResolution evita uma escrita no banco a cada requisição da integração.
+const lastUsedResolution = time.Minute
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+var ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+var ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyService interface {
+	CreateAPIKey(keyIn schemas.APIKeyCreate, creator models.User) (*schemas.APIKeyCreated, error)
+	GetAPIKeys(orgID uint, skip, limit int) ([]schemas.APIKeyPublic, error)
+	RevokeAPIKey(keyID, orgID uint) error
+	Authenticate(rawKey string) (*models.APIKey, *models.User, error)
+}
+
+type apiKeyService struct {
+	repo     repositories.APIKeyRepository
+	userRepo repositories.UserRepository
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository, userRepo repositories.UserRepository) APIKeyService {
+	return &apiKeyService{repo: repo, userRepo: userRepo}
+}
+
+func (s *apiKeyService) CreateAPIKey(keyIn schemas.APIKeyCreate, creator models.User) (*schemas.APIKeyCreated, error) {
+	scopes, err := normalizeScopes(keyIn.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if keyIn.ExpiresAt != nil && !keyIn.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	secret, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	rawKey := APIKeyPrefix + secret
+
+	key := &models.APIKey{
+		OrganizationID: creator.OrganizationID,
+		Name:           keyIn.Name,
+		Prefix:         rawKey[:len(APIKeyPrefix)+8],
+		KeyHash:        core.HashToken(rawKey),
+		Scopes:         strings.Join(scopes, " "),
+		CreatedByID:    creator.ID,
+		ExpiresAt:      keyIn.ExpiresAt,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &schemas.APIKeyCreated{APIKeyPublic: toAPIKeyPublic(key), Key: rawKey}, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(orgID uint, skip, limit int) ([]schemas.APIKeyPublic, error) {
+	keys, err := s.repo.FindByOrganization(orgID, skip, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]schemas.APIKeyPublic, len(keys))
+	for i := range keys {
+		result[i] = toAPIKeyPublic(&keys[i])
+	}
+	return result, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(keyID, orgID uint) error {
+	key, err := s.repo.FindByID(keyID, orgID)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return s.repo.Update(key)
+}
+
+// Authenticate valida a chave e devolve também o usuário que a criou: as
+// requisições da integração são atribuídas a ele, limitadas pelos escopos.
+func (s *apiKeyService) Authenticate(rawKey string) (*models.APIKey, *models.User, error) {
+	key, err := s.repo.FindByHash(core.HashToken(rawKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if key == nil || key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(key.CreatedByID, key.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.repo.TouchLastUsed(key.ID, now); err != nil {
+			logging.Logger.Error("Failed to update api key last use", zap.Error(err), zap.Uint("api_key_id", key.ID))
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, user, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	valid := map[string]bool{}
+	for _, scope := range models.APIKeyScopes {
+		valid[string(scope)] = true
+	}
+
+	seen := map[string]bool{}
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !valid[scope] {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrInvalidAPIKeyScope
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func toAPIKeyPublic(key *models.APIKey) schemas.APIKeyPublic {
+	return schemas.APIKeyPublic{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
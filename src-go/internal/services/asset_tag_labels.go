@@ -0,0 +1,95 @@
+package services
+
+import (
+	"io"
+
+	"go-api/internal/models"
+	"go-api/internal/pdf"
+	"go-api/internal/qrcode"
+	"go-api/internal/schemas"
+)
+
+// Folha A4 de 3 x 8 etiquetas de 70 x 37 mm, o formato adesivo mais comum
+// nas papelarias. O QR ocupa um quadrado de 30 mm à esquerda, já com a
+// margem clara de 4 módulos exigida pelos leitores.
+const (
+	labelColumns   = 3
+	labelRows      = 8
+	labelWidthMM   = 70
+	labelHeightMM  = 37
+	labelPaddingMM = 3.5
+	labelQRSizeMM  = 30
+)
+
+// assetTypeCaptions são as legendas impressas acima do título.
+var assetTypeCaptions = map[models.AssetType]string{
+	models.AssetTypeVehicle:       "VEÍCULO",
+	models.AssetTypeImplement:     "IMPLEMENTO",
+	models.AssetTypeInventoryItem: "ITEM DE ESTOQUE",
+}
+
+func (s *assetTagService) WriteLabelSheet(w io.Writer, tags []schemas.AssetTagPublic) error {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+	doc.SetTitle("TruCar - Etiquetas de patrimônio")
+
+	perPage := labelColumns * labelRows
+	marginTop := (pdf.A4Height - labelRows*pdf.MM(labelHeightMM)) / 2
+	marginLeft := (pdf.A4Width - labelColumns*pdf.MM(labelWidthMM)) / 2
+
+	var page *pdf.Page
+	for i, tag := range tags {
+		if i%perPage == 0 {
+			page = doc.AddPage()
+		}
+		slot := i % perPage
+		x := marginLeft + float64(slot%labelColumns)*pdf.MM(labelWidthMM)
+		y := marginTop + float64(slot/labelColumns)*pdf.MM(labelHeightMM)
+		if err := drawLabel(page, x, y, tag); err != nil {
+			return err
+		}
+	}
+	return doc.Write(w)
+}
+
+func drawLabel(page *pdf.Page, x, y float64, tag schemas.AssetTagPublic) error {
+	code, err := qrcode.Encode([]byte(tag.URL))
+	if err != nil {
+		return err
+	}
+	padding := pdf.MM(labelPaddingMM)
+	qrSize := pdf.MM(labelQRSizeMM)
+	drawQRCode(page, code, x+padding, y+(pdf.MM(labelHeightMM)-qrSize)/2, qrSize)
+
+	textX := x + 2*padding + qrSize
+	textWidth := pdf.MM(labelWidthMM) - qrSize - 3*padding
+	lineY := y + padding + pdf.MM(5)
+	page.Text(textX, lineY, pdf.Helvetica, 6.5, assetTypeCaptions[tag.Type])
+	lineY += pdf.MM(5)
+	page.Text(textX, lineY, pdf.HelveticaBold, 10, pdf.Truncate(pdf.HelveticaBold, 10, textWidth, tag.Title))
+	for _, detail := range tag.Details {
+		lineY += pdf.MM(4)
+		page.Text(textX, lineY, pdf.Helvetica, 7.5, pdf.Truncate(pdf.Helvetica, 7.5, textWidth, detail))
+	}
+	return nil
+}
+
+// drawQRCode desenha cada sequência de módulos escuros de uma linha como um
+// só retângulo, o que reduz o PDF e evita frestas entre módulos vizinhos.
+func drawQRCode(page *pdf.Page, code *qrcode.Code, x, y, size float64) {
+	const quietZone = 4
+	module := size / float64(code.Size+2*quietZone)
+	originX, originY := x+quietZone*module, y+quietZone*module
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; {
+			if !code.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < code.Size && code.Dark(col, row) {
+				col++
+			}
+			page.FillRect(originX+float64(start)*module, originY+float64(row)*module, float64(col-start)*module, module)
+		}
+	}
+}
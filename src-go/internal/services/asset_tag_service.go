@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+var ErrAssetTagOtherOrganization = errors.New("asset tag belongs to another organization")
+var ErrAssetNotFound = errors.New("asset not found")
+var ErrNoAssetsSelected = errors.New("select at least one vehicle, implement or inventory item")
+var ErrTooManyAssetTags = errors.New("too many labels requested")
+
+// AssetTagService gera as etiquetas QR de patrimônio e resolve as lidas no
+// pátio. O QR leva a URL do frontend com o payload assinado; o frontend chama
+// Resolve com ela para abrir o registro ou iniciar a jornada.
+type AssetTagService interface {
+	GetTags(req schemas.AssetTagRequest, orgID uint) ([]schemas.AssetTagPublic, error)
+	WriteLabelSheet(w io.Writer, tags []schemas.AssetTagPublic) error
+	ParseTag(scanned string) (*core.AssetTag, error)
+	Resolve(tag *core.AssetTag, orgID uint) (*schemas.AssetTagResolved, error)
+}
+
+type assetTagService struct {
+	vehicleRepo   repositories.VehicleRepository
+	implementRepo repositories.ImplementRepository
+	partRepo      repositories.PartRepository
+}
+
+func NewAssetTagService(vehicleRepo repositories.VehicleRepository, implementRepo repositories.ImplementRepository, partRepo repositories.PartRepository) AssetTagService {
+	return &assetTagService{vehicleRepo: vehicleRepo, implementRepo: implementRepo, partRepo: partRepo}
+}
+
+func (s *assetTagService) GetTags(req schemas.AssetTagRequest, orgID uint) ([]schemas.AssetTagPublic, error) {
+	vehicleIDs, implementIDs, itemIDs := uniqueIDs(req.VehicleIDs), uniqueIDs(req.ImplementIDs), uniqueIDs(req.InventoryItemIDs)
+	total := len(vehicleIDs) + len(implementIDs) + len(itemIDs)
+	if total == 0 {
+		return nil, ErrNoAssetsSelected
+	}
+	if limit := config.AppConfig.ASSET_TAG_MAX_LABELS; total > limit {
+		return nil, fmt.Errorf("%w (maximum %d)", ErrTooManyAssetTags, limit)
+	}
+
+	tags := make([]schemas.AssetTagPublic, 0, total)
+	if len(vehicleIDs) > 0 {
+		vehicles, err := s.vehicleRepo.FindByIDs(vehicleIDs, orgID)
+		if err != nil {
+			return nil, err
+		}
+		byID := map[uint]models.Vehicle{}
+		for _, v := range vehicles {
+			byID[v.ID] = v
+		}
+		for _, id := range vehicleIDs {
+			vehicle, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: vehicle %d", ErrAssetNotFound, id)
+			}
+			tags = append(tags, vehicleTag(vehicle))
+		}
+	}
+	if len(implementIDs) > 0 {
+		implements, err := s.implementRepo.FindByIDs(implementIDs, orgID)
+		if err != nil {
+			return nil, err
+		}
+		byID := map[uint]models.Implement{}
+		for _, i := range implements {
+			byID[i.ID] = i
+		}
+		for _, id := range implementIDs {
+			implement, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: implement %d", ErrAssetNotFound, id)
+			}
+			tags = append(tags, implementTag(implement))
+		}
+	}
+	if len(itemIDs) > 0 {
+		items, err := s.partRepo.FindItemsByIDs(itemIDs, orgID)
+		if err != nil {
+			return nil, err
+		}
+		byID := map[uint]models.InventoryItem{}
+		for _, i := range items {
+			byID[i.ID] = i
+		}
+		for _, id := range itemIDs {
+			item, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: inventory item %d", ErrAssetNotFound, id)
+			}
+			tags = append(tags, inventoryItemTag(item))
+		}
+	}
+	return tags, nil
+}
+
+// ParseTag aceita a URL lida do QR ou só o payload e confere a assinatura.
+// A organização ainda precisa ser conferida por Resolve.
+func (s *assetTagService) ParseTag(scanned string) (*core.AssetTag, error) {
+	payload := strings.TrimSpace(scanned)
+	if idx := strings.LastIndex(payload, "/scan/"); idx >= 0 {
+		payload = payload[idx+len("/scan/"):]
+	}
+	payload, _, _ = strings.Cut(payload, "?")
+	payload, _, _ = strings.Cut(payload, "#")
+
+	tag, err := core.ParseAssetTag(strings.TrimSuffix(payload, "/"))
+	if err != nil || !models.IsValidAssetType(models.AssetType(tag.Kind)) {
+		return nil, core.ErrInvalidAssetTag
+	}
+	return tag, nil
+}
+
+// Resolve devolve o registro da etiqueta. Etiquetas de outra organização são
+// recusadas mesmo com assinatura válida, e o registro é buscado só na
+// organização do usuário.
+func (s *assetTagService) Resolve(tag *core.AssetTag, orgID uint) (*schemas.AssetTagResolved, error) {
+	if tag.OrganizationID != orgID {
+		return nil, ErrAssetTagOtherOrganization
+	}
+
+	resolved := &schemas.AssetTagResolved{Type: models.AssetType(tag.Kind), ID: tag.ID}
+	switch resolved.Type {
+	case models.AssetTypeVehicle:
+		vehicle, err := s.vehicleRepo.FindByID(tag.ID, orgID)
+		if err != nil || vehicle == nil {
+			return nil, assetLookupError(err)
+		}
+		resolved.Entity = vehicle
+	case models.AssetTypeImplement:
+		implement, err := s.implementRepo.FindByID(tag.ID, orgID)
+		if err != nil || implement == nil {
+			return nil, assetLookupError(err)
+		}
+		resolved.Entity = schemas.ToImplementPublic(*implement)
+	case models.AssetTypeInventoryItem:
+		item, err := s.partRepo.FindItemByID(tag.ID, orgID)
+		if err != nil || item == nil {
+			return nil, assetLookupError(err)
+		}
+		part, err := s.partRepo.FindByID(item.PartID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if part != nil {
+			item.Part = *part
+		}
+		resolved.Entity = item
+	default:
+		return nil, core.ErrInvalidAssetTag
+	}
+	return resolved, nil
+}
+
+// assetLookupError trata o registro apagado depois da impressão da etiqueta.
+func assetLookupError(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrAssetNotFound
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	var result []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func newAssetTag(assetType models.AssetType, orgID, id uint, title string, details ...string) schemas.AssetTagPublic {
+	payload := core.SignAssetTag(core.AssetTag{Kind: string(assetType), OrganizationID: orgID, ID: id})
+	tag := schemas.AssetTagPublic{
+		Type:    assetType,
+		ID:      id,
+		Title:   title,
+		Details: []string{},
+		Payload: payload,
+		URL:     strings.TrimRight(config.AppConfig.FRONTEND_URL, "/") + "/scan/" + payload,
+	}
+	for _, detail := range details {
+		if detail = strings.TrimSpace(detail); detail != "" && detail != title {
+			tag.Details = append(tag.Details, detail)
+		}
+	}
+	return tag
+}
+
+// vehicleTag usa a placa como título, que é o que o pessoal do pátio procura;
+// sem placa (máquinas), o identificador da frota ou a marca e o modelo.
+func vehicleTag(vehicle models.Vehicle) schemas.AssetTagPublic {
+	description := fmt.Sprintf("%s %s %d", vehicle.Brand, vehicle.Model, vehicle.Year)
+	title := vehicle.Brand + " " + vehicle.Model
+	var identifier string
+	if vehicle.Identifier != nil {
+		identifier = *vehicle.Identifier
+	}
+	switch {
+	case vehicle.LicensePlate != nil && *vehicle.LicensePlate != "":
+		title = *vehicle.LicensePlate
+	case identifier != "":
+		title = identifier
+	}
+	return newAssetTag(models.AssetTypeVehicle, vehicle.OrganizationID, vehicle.ID, title, description, identifier)
+}
+
+func implementTag(implement models.Implement) schemas.AssetTagPublic {
+	return newAssetTag(models.AssetTypeImplement, implement.OrganizationID, implement.ID, implement.Name,
+		implement.Brand+" "+implement.VehicleModel, implement.Identifier)
+}
+
+func inventoryItemTag(item models.InventoryItem) schemas.AssetTagPublic {
+	var partNumber string
+	if item.Part.PartNumber != nil && *item.Part.PartNumber != "" {
+		partNumber = "Ref. " + *item.Part.PartNumber
+	}
+	return newAssetTag(models.AssetTypeInventoryItem, item.OrganizationID, item.ID, item.Part.Name,
+		fmt.Sprintf("Item nº %d", item.ItemIdentifier), partNumber)
+}
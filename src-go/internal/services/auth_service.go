@@ -2,65 +2,635 @@ package services
 
 import (
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/models"
 	"go-api/internal/repositories"
+	"go-api/internal/schemas"
 )
 
 var ErrInvalidCredentials = errors.New("invalid credentials")
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+var ErrSessionRevoked = errors.New("session has been revoked")
+var ErrInvalidMFAToken = errors.New("invalid or expired mfa token")
+var ErrCannotImpersonateSuperAdmin = errors.New("cannot impersonate another super admin")
+var ErrNotImpersonating = errors.New("current session is not an impersonation")
+var ErrSessionNotFound = errors.New("session not found")
+var ErrEmailNotVerified = errors.New("email address has not been verified")
+
+// sessionLastSeenResolution limita a atualização de last_seen_at a uma escrita
+// por minuto por sessão.
+const sessionLastSeenResolution = time.Minute
+
+// ClientInfo descreve o dispositivo que está fazendo login.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	Device    string
+}
 
 type AuthService interface {
-	Login(email, password string) (string, error)
-	Impersonate(userID uint) (string, error)
+	Login(email, password string, client ClientInfo) (*schemas.Token, error)
+	Refresh(refreshToken string) (*schemas.Token, error)
+	Logout(sessionID string) error
+	ValidateSession(claims *core.Claims) error
+	Impersonate(userID uint, admin models.User, adminSessionID, clientIP string) (*schemas.Token, error)
+	EndImpersonation(sessionID string) (*schemas.Token, error)
+	GetImpersonationSessions(skip, limit int) ([]models.ImpersonationSession, error)
+	VerifyTwoFactor(mfaToken, code string, client ClientInfo) (*schemas.Token, error)
+	BeginTwoFactorSetup(mfaToken string) (*schemas.TwoFactorSetup, error)
+	ActivateTwoFactor(mfaToken, code string, client ClientInfo) (*schemas.TwoFactorActivation, error)
+	StartSSOSession(user *models.User, client ClientInfo) (*schemas.Token, error)
+	StartBadgeSession(user *models.User, client ClientInfo) (*schemas.Token, error)
+	GetUserSessions(userID, orgID uint, currentSessionID string) ([]schemas.SessionPublic, error)
+	RevokeUserSession(userID, orgID, sessionID uint) error
+	RevokeAllUserSessions(userID, orgID uint) error
 }
 
 type authService struct {
-	userRepo repositories.UserRepository
+	userRepo          repositories.UserRepository
+	refreshTokenRepo  repositories.RefreshTokenRepository
+	sessionRepo       repositories.UserSessionRepository
+	impersonationRepo repositories.ImpersonationSessionRepository
+	loginAttempts     LoginAttemptService
+	twoFactor         TwoFactorService
 }
 
-func NewAuthService(userRepo repositories.UserRepository) AuthService {
-	return &authService{userRepo: userRepo}
+func NewAuthService(userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, sessionRepo repositories.UserSessionRepository, impersonationRepo repositories.ImpersonationSessionRepository, loginAttempts LoginAttemptService, twoFactor TwoFactorService) AuthService {
+	return &authService{userRepo: userRepo, refreshTokenRepo: refreshTokenRepo, sessionRepo: sessionRepo, impersonationRepo: impersonationRepo, loginAttempts: loginAttempts, twoFactor: twoFactor}
 }
 
-func (s *authService) Login(email, password string) (string, error) {
+func (s *authService) Login(email, password string, client ClientInfo) (*schemas.Token, error) {
+	if err := s.loginAttempts.Check(email, client.IP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if user == nil {
-		return "", ErrInvalidCredentials
+
+	if user == nil || !core.CheckPasswordHash(password, user.HashedPassword) {
+		if err := s.loginAttempts.RegisterFailure(email, client.IP, user); err != nil {
+			logging.Logger.Error("Failed to register login failure", zap.Error(err))
+		}
+		return nil, ErrInvalidCredentials
+	}
+	// Conta desativada responde como senha errada, sem revelar que existe.
+	if !user.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	s.upgradePasswordHash(user, password)
+	if user.EmailVerificationToken != nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Com 2FA a senha é só a primeira etapa; os contadores de falha só são
+	// zerados quando o código também for aceito.
+	if challenge, err := s.secondFactorChallenge(user); challenge != nil || err != nil {
+		return challenge, err
 	}
 
-	if !core.CheckPasswordHash(password, user.HashedPassword) {
-		return "", ErrInvalidCredentials
+	if err := s.loginAttempts.RegisterSuccess(email); err != nil {
+		logging.Logger.Error("Failed to reset login failures", zap.Error(err))
 	}
 
-	token, err := core.GenerateJWT(user.ID, user.OrganizationID)
+	return s.startSession(user, client, "")
+}
+
+func (s *authService) VerifyTwoFactor(mfaToken, code string, client ClientInfo) (*schemas.Token, error) {
+	user, err := s.userFromMFAToken(mfaToken, core.TokenPurposeMFA)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := s.loginAttempts.Check(user.Email, client.IP); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactor.Verify(user, code); err != nil {
+		if err != ErrInvalidTwoFactorCode {
+			return nil, err
+		}
+		if err := s.loginAttempts.RegisterFailure(user.Email, client.IP, user); err != nil {
+			logging.Logger.Error("Failed to register login failure", zap.Error(err))
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.loginAttempts.RegisterSuccess(user.Email); err != nil {
+		logging.Logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+
+	return s.startSession(user, client, "")
+}
+
+func (s *authService) BeginTwoFactorSetup(mfaToken string) (*schemas.TwoFactorSetup, error) {
+	user, err := s.userFromMFAToken(mfaToken, core.TokenPurposeMFASetup)
+	if err != nil {
+		return nil, err
+	}
+	return s.twoFactor.BeginSetup(user)
+}
+
+func (s *authService) ActivateTwoFactor(mfaToken, code string, client ClientInfo) (*schemas.TwoFactorActivation, error) {
+	user, err := s.userFromMFAToken(mfaToken, core.TokenPurposeMFASetup)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loginAttempts.Check(user.Email, client.IP); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.twoFactor.Activate(user, code)
+	if err != nil {
+		if err == ErrInvalidTwoFactorCode {
+			if err := s.loginAttempts.RegisterFailure(user.Email, client.IP, user); err != nil {
+				logging.Logger.Error("Failed to register login failure", zap.Error(err))
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.loginAttempts.RegisterSuccess(user.Email); err != nil {
+		logging.Logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+
+	token, err := s.startSession(user, client, "")
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.TwoFactorActivation{RecoveryCodes: recoveryCodes, Token: token}, nil
+}
+
+// StartSSOSession emite o token de um usuário já autenticado pelo IdP da
+// organização. O IdP substitui só a senha: com 2FA ativo, ou obrigatório para
+// gestores, a resposta é o mesmo desafio do login por senha.
+func (s *authService) StartSSOSession(user *models.User, client ClientInfo) (*schemas.Token, error) {
+	if !user.IsActive {
+		return nil, ErrSSOUserNotAllowed
+	}
+	if challenge, err := s.secondFactorChallenge(user); challenge != nil || err != nil {
+		return challenge, err
+	}
+	return s.startSession(user, client, "")
+}
+
+// StartBadgeSession emite o token do login por crachá, limitado às permissões
+// do motorista durante toda a sessão, inclusive após o refresh.
+func (s *authService) StartBadgeSession(user *models.User, client ClientInfo) (*schemas.Token, error) {
+	if !user.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	return s.startSession(user, client, core.TokenScopeDriver)
+}
+
+func (s *authService) Refresh(refreshToken string) (*schemas.Token, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(core.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil && stored.ReplacedByID == nil {
+		// Revogado por logout/encerramento da sessão, não por rotação.
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.RevokedAt != nil {
+		// Um token já rotacionado foi apresentado de novo: alguém copiou o token.
+		// Derruba a família inteira para que nem o atacante nem a vítima continuem logados.
+		logging.Logger.Warn("Refresh token reuse detected",
+			zap.Uint("user_id", stored.UserID),
+			zap.String("family_id", stored.FamilyID),
+		)
+		if err := s.revokeSession(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID, stored.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		if err := s.revokeSession(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.activeSession(stored.FamilyID)
+	if err != nil {
+		if err == ErrSessionRevoked {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	token, newStored, err := s.issueTokenPairWithRecord(user, stored.FamilyID, session.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// A rotação só vale se o token ainda estava ativo no momento do UPDATE.
+	// Nenhuma linha afetada significa que outra requisição usou o mesmo token
+	// depois da leitura acima: é reutilização, e a família inteira (inclusive
+	// o par recém-emitido) é revogada.
+	rotated, err := s.refreshTokenRepo.Rotate(stored.ID, newStored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		logging.Logger.Warn("Refresh token reuse detected",
+			zap.Uint("user_id", stored.UserID),
+			zap.String("family_id", stored.FamilyID),
+		)
+		if err := s.revokeSession(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
 	}
 
 	return token, nil
 }
 
-func (s *authService) Impersonate(userID uint) (string, error) {
+func (s *authService) Logout(sessionID string) error {
+	if _, err := s.impersonationRepo.End(sessionID); err != nil {
+		return err
+	}
+	return s.revokeSession(sessionID)
+}
+
+func (s *authService) ValidateSession(claims *core.Claims) error {
+	if claims.SessionID == "" {
+		return ErrSessionRevoked
+	}
+
+	sessionID := claims.SessionID
+	if claims.Actor != nil {
+		session, err := s.impersonationRepo.FindBySessionID(claims.SessionID)
+		if err != nil {
+			return err
+		}
+		if session == nil || session.EndedAt != nil || time.Now().After(session.ExpiresAt) ||
+			session.ImpersonatorID != claims.Actor.UserID {
+			return ErrSessionRevoked
+		}
+		// Se o admin encerrar a própria sessão, a personificação cai junto.
+		sessionID = session.ImpersonatorSessionID
+	}
+
+	session, err := s.activeSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != claims.UserID && claims.Actor == nil {
+		return ErrSessionRevoked
+	}
+	// A desativação revoga as sessões, mas o access token já emitido só cai
+	// se o usuário for conferido a cada requisição.
+	user, err := s.userRepo.FindByIDUnscoped(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > sessionLastSeenResolution {
+		if err := s.sessionRepo.TouchLastSeen(session.ID, time.Now()); err != nil {
+			logging.Logger.Error("Failed to update session last seen", zap.Error(err), zap.Uint("session_id", session.ID))
+		}
+	}
+	return nil
+}
+
+func (s *authService) GetUserSessions(userID, orgID uint, currentSessionID string) ([]schemas.SessionPublic, error) {
+	if err := s.ensureUserInOrganization(userID, orgID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]schemas.SessionPublic, len(sessions))
+	for i, session := range sessions {
+		result[i] = schemas.SessionPublic{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Scope:      session.Scope,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.SessionID == currentSessionID,
+		}
+	}
+	return result, nil
+}
+
+func (s *authService) RevokeUserSession(userID, orgID, sessionID uint) error {
+	if err := s.ensureUserInOrganization(userID, orgID); err != nil {
+		return err
+	}
+
+	session, err := s.sessionRepo.FindByID(sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	return s.revokeSession(session.SessionID)
+}
+
+func (s *authService) RevokeAllUserSessions(userID, orgID uint) error {
+	if err := s.ensureUserInOrganization(userID, orgID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeAllForUser(userID)
+}
+
+func (s *authService) Impersonate(userID uint, admin models.User, adminSessionID, clientIP string) (*schemas.Token, error) {
 	user, err := s.userRepo.FindByIDUnscoped(userID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if user == nil {
-		return "", ErrInvalidCredentials // Or a more specific error
+		return nil, ErrUserNotFound
+	}
+	if user.Role == models.RoleSuperAdmin {
+		return nil, ErrCannotImpersonateSuperAdmin
+	}
+
+	session := &models.ImpersonationSession{
+		SessionID:             uuid.New().String(),
+		ImpersonatorID:        admin.ID,
+		ImpersonatorSessionID: adminSessionID,
+		TargetUserID:          user.ID,
+		OrganizationID:        user.OrganizationID,
+		IPAddress:             &clientIP,
+		ExpiresAt:             time.Now().Add(core.ImpersonationTTL()),
 	}
+	if err := s.impersonationRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := core.GenerateImpersonationJWT(user.ID, user.OrganizationID, session.SessionID, core.ActorClaims{
+		UserID:    admin.ID,
+		SessionID: adminSessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Logger.Warn("Impersonation started",
+		zap.Uint("impersonator_id", admin.ID),
+		zap.Uint("user_id", user.ID),
+		zap.Uint("organization_id", user.OrganizationID),
+		zap.String("session_id", session.SessionID),
+	)
 
-	// Assuming SuperAdmin role is defined elsewhere
-	// if user.IsSuperAdmin {
-	// 	return "", errors.New("cannot impersonate another super admin")
-	// }
+	return &schemas.Token{
+		AccessToken: accessToken,
+		TokenType:   "bearer",
+		ExpiresIn:   int(core.ImpersonationTTL().Seconds()),
+	}, nil
+}
 
-	token, err := core.GenerateJWT(user.ID, user.OrganizationID)
+// EndImpersonation encerra a personificação e devolve um access token da
+// sessão original do admin. O refresh token do admin continua o mesmo.
+func (s *authService) EndImpersonation(sessionID string) (*schemas.Token, error) {
+	session, err := s.impersonationRepo.FindBySessionID(sessionID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrNotImpersonating
+	}
+	if _, err := s.impersonationRepo.End(sessionID); err != nil {
+		return nil, err
 	}
 
-	return token, nil
+	logging.Logger.Warn("Impersonation ended",
+		zap.Uint("impersonator_id", session.ImpersonatorID),
+		zap.Uint("user_id", session.TargetUserID),
+		zap.String("session_id", session.SessionID),
+	)
+
+	if _, err := s.activeSession(session.ImpersonatorSessionID); err != nil {
+		return nil, err
+	}
+	admin, err := s.userRepo.FindByIDUnscoped(session.ImpersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil || !admin.IsActive {
+		return nil, ErrSessionRevoked
+	}
+
+	accessToken, err := core.GenerateJWT(admin.ID, admin.OrganizationID, session.ImpersonatorSessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.Token{
+		AccessToken: accessToken,
+		TokenType:   "bearer",
+		ExpiresIn:   int(core.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+func (s *authService) GetImpersonationSessions(skip, limit int) ([]models.ImpersonationSession, error) {
+	return s.impersonationRepo.FindAll(skip, limit)
+}
+
+// secondFactorChallenge devolve o desafio de 2FA (verificação ou cadastro
+// obrigatório) ou nil quando o usuário pode receber a sessão.
+func (s *authService) secondFactorChallenge(user *models.User) (*schemas.Token, error) {
+	if user.TwoFactorEnabled {
+		return s.mfaChallenge(user, core.TokenPurposeMFA)
+	}
+	setupRequired, err := s.twoFactor.SetupRequired(user)
+	if err != nil {
+		return nil, err
+	}
+	if setupRequired {
+		return s.mfaChallenge(user, core.TokenPurposeMFASetup)
+	}
+	return nil, nil
+}
+
+func (s *authService) mfaChallenge(user *models.User, purpose string) (*schemas.Token, error) {
+	mfaToken, err := core.GenerateMFAToken(user.ID, user.OrganizationID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.Token{
+		TokenType:        "bearer",
+		MFARequired:      purpose == core.TokenPurposeMFA,
+		MFASetupRequired: purpose == core.TokenPurposeMFASetup,
+		MFAToken:         mfaToken,
+	}, nil
+}
+
+func (s *authService) userFromMFAToken(mfaToken, purpose string) (*models.User, error) {
+	claims, err := core.ValidateJWT(mfaToken)
+	if err != nil || claims.Purpose != purpose {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID, claims.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, ErrInvalidMFAToken
+	}
+	return user, nil
+}
+
+// upgradePasswordHash regrava hashes com custo abaixo de PASSWORD_BCRYPT_COST
+// aproveitando a senha em texto puro, disponível apenas no login.
+func (s *authService) upgradePasswordHash(user *models.User, password string) {
+	if !core.PasswordNeedsRehash(user.HashedPassword) {
+		return
+	}
+	hashedPassword, err := core.HashPassword(password)
+	if err != nil {
+		logging.Logger.Error("Failed to rehash password", zap.Error(err), zap.Uint("user_id", user.ID))
+		return
+	}
+	user.HashedPassword = hashedPassword
+	if err := s.userRepo.UpdateColumns(user, "hashed_password"); err != nil {
+		logging.Logger.Error("Failed to store rehashed password", zap.Error(err), zap.Uint("user_id", user.ID))
+	}
+}
+
+// startSession registra a sessão do dispositivo e emite o primeiro par de tokens.
+func (s *authService) startSession(user *models.User, client ClientInfo, scope string) (*schemas.Token, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		SessionID:      uuid.New().String(),
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Device:         describeDevice(client),
+		IPAddress:      client.IP,
+		UserAgent:      truncate(client.UserAgent, 512),
+		Scope:          scope,
+		LastSeenAt:     now,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	token, _, err := s.issueTokenPairWithRecord(user, session.SessionID, scope)
+	return token, err
+}
+
+// activeSession devolve ErrSessionRevoked para sessões inexistentes ou encerradas.
+func (s *authService) activeSession(sessionID string) (*models.UserSession, error) {
+	session, err := s.sessionRepo.FindBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+	return session, nil
+}
+
+// revokeSession encerra a sessão e todos os refresh tokens dela.
+func (s *authService) revokeSession(sessionID string) error {
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeFamily(sessionID)
+}
+
+func (s *authService) ensureUserInOrganization(userID, orgID uint) error {
+	user, err := s.userRepo.FindByID(userID, orgID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// describeDevice usa o nome enviado pelo app (X-Device-Name) ou, na falta
+// dele, uma descrição aproximada a partir do User-Agent.
+func describeDevice(client ClientInfo) string {
+	if client.Device != "" {
+		return truncate(client.Device, 100)
+	}
+
+	ua := strings.ToLower(client.UserAgent)
+	platforms := []struct{ marker, name string }{
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"linux", "Linux"},
+	}
+	for _, p := range platforms {
+		if strings.Contains(ua, p.marker) {
+			return p.name
+		}
+	}
+	return "Unknown device"
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+func (s *authService) issueTokenPairWithRecord(user *models.User, familyID, scope string) (*schemas.Token, *models.RefreshToken, error) {
+	accessToken, err := core.GenerateScopedJWT(user.ID, user.OrganizationID, familyID, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := &models.RefreshToken{
+		TokenHash:      core.HashToken(refreshToken),
+		FamilyID:       familyID,
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		ExpiresAt:      time.Now().Add(core.RefreshTokenTTL()),
+	}
+	if err := s.refreshTokenRepo.Create(stored); err != nil {
+		return nil, nil, err
+	}
+
+	return &schemas.Token{
+		AccessToken:  accessToken,
+		TokenType:    "bearer",
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(core.AccessTokenTTL().Seconds()),
+	}, stored, nil
 }
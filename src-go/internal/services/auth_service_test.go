@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// staleRefreshTokens devolve, no FindByHash, o registro lido antes de outra
+// requisição rotacionar o token, como acontece com dois refreshes simultâneos.
+type staleRefreshTokens struct {
+	repositories.RefreshTokenRepository
+	stale *models.RefreshToken
+}
+
+func (r *staleRefreshTokens) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	if r.stale != nil {
+		stale := *r.stale
+		return &stale, nil
+	}
+	return r.RefreshTokenRepository.FindByHash(tokenHash)
+}
+
+type authFixture struct {
+	db            *gorm.DB
+	service       AuthService
+	twoFactor     TwoFactorService
+	refreshTokens *staleRefreshTokens
+	sessions      repositories.UserSessionRepository
+	users         UserService
+	userRepo      repositories.UserRepository
+	user          *models.User
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes A")
+	user := createTestUser(t, gormDB, org.ID, "manager@example.com", models.RoleClienteAtivo)
+
+	userRepo := repositories.NewUserRepository(gormDB)
+	orgRepo := repositories.NewOrganizationRepository(gormDB)
+	refreshTokens := &staleRefreshTokens{RefreshTokenRepository: repositories.NewRefreshTokenRepository(gormDB)}
+	sessions := repositories.NewUserSessionRepository(gormDB)
+	loginAttempts := NewLoginAttemptService(repositories.NewMemoryCacheRepository(100), userRepo, repositories.NewAccountLockEventRepository(gormDB))
+	twoFactor := NewTwoFactorService(userRepo, orgRepo, repositories.NewTwoFactorRepository(gormDB))
+	service := NewAuthService(userRepo, refreshTokens, sessions, repositories.NewImpersonationSessionRepository(gormDB), loginAttempts, twoFactor)
+	users := NewUserService(userRepo, orgRepo, refreshTokens, sessions, NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB)), nil, nil, nil)
+	return &authFixture{db: gormDB, service: service, twoFactor: twoFactor, refreshTokens: refreshTokens, sessions: sessions, users: users, userRepo: userRepo, user: user}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	f := newAuthFixture(t)
+	first, err := f.service.StartSSOSession(f.user, ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.service.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	// Reapresentar o token rotacionado derruba a sessão inteira.
+	if _, err := f.service.Refresh(first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("got %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := f.service.Refresh(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("token issued before the reuse: got %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshDetectsConcurrentRotation(t *testing.T) {
+	f := newAuthFixture(t)
+	first, err := f.service.StartSSOSession(f.user, ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := f.refreshTokens.RefreshTokenRepository.FindByHash(core.HashToken(first.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	winner, err := f.service.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A segunda requisição leu o token ainda ativo, antes da rotação acima.
+	f.refreshTokens.stale = stale
+	if _, err := f.service.Refresh(first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("got %v, want ErrRefreshTokenReused", err)
+	}
+	f.refreshTokens.stale = nil
+
+	if _, err := f.service.Refresh(winner.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("family was not revoked: got %v", err)
+	}
+	active, err := f.sessions.FindActiveByUser(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Fatalf("%d sessions still active", len(active))
+	}
+}
+
+func (f *authFixture) deactivate(t *testing.T) {
+	t.Helper()
+	updated, err := f.users.UpdateUser(f.user.ID, f.user.OrganizationID, schemas.UserUpdate{IsActive: schemas.PatchField[bool]{Set: true, Value: false}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*f.user = *updated
+}
+
+func TestInactiveUserCannotStartSession(t *testing.T) {
+	f := newAuthFixture(t)
+	f.deactivate(t)
+
+	if _, err := f.service.Login(f.user.Email, "old password 123", ClientInfo{IP: "10.0.0.1"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("login: got %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := f.service.StartSSOSession(f.user, ClientInfo{}); !errors.Is(err, ErrSSOUserNotAllowed) {
+		t.Fatalf("sso: got %v, want ErrSSOUserNotAllowed", err)
+	}
+	if _, err := f.service.StartBadgeSession(f.user, ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("badge: got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestDeactivationRevokesSessions(t *testing.T) {
+	f := newAuthFixture(t)
+	token, err := f.service.Login(f.user.Email, "old password 123", ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := core.ValidateJWT(token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.ValidateSession(claims); err != nil {
+		t.Fatalf("active user: %v", err)
+	}
+
+	f.deactivate(t)
+
+	if err := f.service.ValidateSession(claims); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("access token: got %v, want ErrSessionRevoked", err)
+	}
+	if _, err := f.service.Refresh(token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refresh: got %v, want ErrInvalidRefreshToken", err)
+	}
+	active, err := f.sessions.FindActiveByUser(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Fatalf("%d sessions still active", len(active))
+	}
+}
+
+func TestValidateSessionRejectsUserDeactivatedElsewhere(t *testing.T) {
+	f := newAuthFixture(t)
+	token, err := f.service.StartSSOSession(f.user, ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := core.ValidateJWT(token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sem passar pelo UpdateUser, a sessão continua aberta; o access token
+	// ainda assim deixa de valer.
+	f.user.IsActive = false
+	if err := f.userRepo.UpdateColumns(f.user, "is_active"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.ValidateSession(claims); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("got %v, want ErrSessionRevoked", err)
+	}
+}
+
+func TestLoginMatchesEmailIgnoringCase(t *testing.T) {
+	f := newAuthFixture(t)
+	token, err := f.service.Login(" Manager@Example.COM", "old password 123", ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken == "" {
+		t.Fatal("no session issued")
+	}
+}
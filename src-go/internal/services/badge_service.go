@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+const (
+	badgePINMinLength = 4
+	badgePINMaxLength = 8
+)
+
+var ErrInvalidBadgePIN = errors.New("pin must have 4 to 8 digits and cannot be a repeated or sequential number")
+var ErrBadgeNotAllowed = errors.New("badge login is only available for drivers")
+
+// BadgeService cuida do login por crachá nos tablets compartilhados do pátio:
+// código da organização + matrícula + PIN numérico.
+type BadgeService interface {
+	Login(loginIn schemas.BadgeLoginRequest, client ClientInfo) (*schemas.Token, error)
+	SetPIN(userID, orgID uint, pin string) (*schemas.BadgeCredentials, error)
+	ClearPIN(userID, orgID uint) error
+}
+
+type badgeService struct {
+	userRepo      repositories.UserRepository
+	orgRepo       repositories.OrganizationRepository
+	loginAttempts LoginAttemptService
+	authService   AuthService
+}
+
+func NewBadgeService(userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository, loginAttempts LoginAttemptService, authService AuthService) BadgeService {
+	return &badgeService{userRepo: userRepo, orgRepo: orgRepo, loginAttempts: loginAttempts, authService: authService}
+}
+
+func (s *badgeService) Login(loginIn schemas.BadgeLoginRequest, client ClientInfo) (*schemas.Token, error) {
+	code := strings.ToUpper(strings.TrimSpace(loginIn.OrganizationCode))
+	employeeID := strings.TrimSpace(loginIn.EmployeeID)
+	identifier := badgeIdentifier(code, employeeID)
+
+	// Os contadores do LoginAttemptService valem por crachá e por tablet; o
+	// limite de falhas do PIN (BADGE_PIN_MAX_FAILED_ATTEMPTS) fica no próprio usuário.
+	if err := s.loginAttempts.CheckBadge(identifier, client); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findBadgeUser(code, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.BadgePINHash == nil || !core.CheckPasswordHash(loginIn.PIN, *user.BadgePINHash) {
+		s.registerFailure(identifier, client, user)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.loginAttempts.RegisterSuccess(identifier); err != nil {
+		logging.Logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+	if user.BadgePINFailedAttempts > 0 {
+		if err := s.userRepo.ResetBadgePINFailures(user); err != nil {
+			return nil, err
+		}
+		user.BadgePINFailedAttempts = 0
+	}
+
+	return s.authService.StartBadgeSession(user, client)
+}
+
+func (s *badgeService) SetPIN(userID, orgID uint, pin string) (*schemas.BadgeCredentials, error) {
+	if err := validateBadgePIN(pin); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Role != models.RoleDriver {
+		return nil, ErrBadgeNotAllowed
+	}
+
+	code, err := s.organizationCode(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPIN, err := core.HashPassword(pin)
+	if err != nil {
+		return nil, err
+	}
+	user.BadgePINHash = &hashedPIN
+	user.BadgePINFailedAttempts = 0
+	if err := s.userRepo.UpdateColumns(user, "badge_pin_hash", "badge_pin_failed_attempts"); err != nil {
+		return nil, err
+	}
+
+	// Um PIN novo também libera o atraso progressivo acumulado pelo crachá.
+	if err := s.loginAttempts.RegisterSuccess(badgeIdentifier(code, user.EmployeeID)); err != nil {
+		logging.Logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+
+	return &schemas.BadgeCredentials{OrganizationCode: code, EmployeeID: user.EmployeeID}, nil
+}
+
+func (s *badgeService) ClearPIN(userID, orgID uint) error {
+	user, err := s.userRepo.FindByID(userID, orgID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.BadgePINHash = nil
+	user.BadgePINFailedAttempts = 0
+	return s.userRepo.UpdateColumns(user, "badge_pin_hash", "badge_pin_failed_attempts")
+}
+
+// findBadgeUser só devolve motoristas ativos; para os demais o login falha
+// como credencial inválida, sem revelar se a matrícula existe.
+func (s *badgeService) findBadgeUser(code, employeeID string) (*models.User, error) {
+	org, err := s.orgRepo.FindByCode(code)
+	if err != nil || org == nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByEmployeeID(org.ID, employeeID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if user.Role != models.RoleDriver || !user.IsActive {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (s *badgeService) registerFailure(identifier string, client ClientInfo, user *models.User) {
+	if err := s.loginAttempts.RegisterBadgeFailure(identifier, client, user); err != nil {
+		logging.Logger.Error("Failed to register login failure", zap.Error(err))
+	}
+	if user == nil || user.BadgePINHash == nil {
+		return
+	}
+
+	revoked, err := s.userRepo.RegisterBadgePINFailure(user, *user.BadgePINHash, config.AppConfig.BADGE_PIN_MAX_FAILED_ATTEMPTS)
+	if err != nil {
+		logging.Logger.Error("Failed to store badge pin failure", zap.Error(err), zap.Uint("user_id", user.ID))
+		return
+	}
+	if revoked {
+		logging.Logger.Warn("Badge PIN revoked after repeated failures",
+			zap.Uint("user_id", user.ID),
+			zap.Uint("organization_id", user.OrganizationID),
+		)
+	}
+}
+
+// organizationCode gera o código na primeira vez que a organização precisa dele.
+func (s *badgeService) organizationCode(orgID uint) (string, error) {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.Code != nil {
+		return *org.Code, nil
+	}
+	code, err := core.GenerateOrganizationCode()
+	if err != nil {
+		return "", err
+	}
+	org.Code = &code
+	if _, err := s.orgRepo.Update(org); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func badgeIdentifier(code, employeeID string) string {
+	return "badge:" + code + ":" + employeeID
+}
+
+// validateBadgePIN recusa PINs fora do tamanho, com letras, repetidos (1111)
+// ou em sequência (1234, 9876).
+func validateBadgePIN(pin string) error {
+	if len(pin) < badgePINMinLength || len(pin) > badgePINMaxLength {
+		return ErrInvalidBadgePIN
+	}
+	repeated, ascending, descending := true, true, true
+	for i := 0; i < len(pin); i++ {
+		if pin[i] < '0' || pin[i] > '9' {
+			return ErrInvalidBadgePIN
+		}
+		if i == 0 {
+			continue
+		}
+		diff := int(pin[i]) - int(pin[i-1])
+		repeated = repeated && diff == 0
+		ascending = ascending && diff == 1
+		descending = descending && diff == -1
+	}
+	if repeated || ascending || descending {
+		return ErrInvalidBadgePIN
+	}
+	return nil
+}
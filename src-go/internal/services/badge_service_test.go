@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// staleBadgeUsers devolve o motorista como foi lido antes das outras
+// tentativas gravarem, como em logins simultâneos no mesmo crachá.
+type staleBadgeUsers struct {
+	repositories.UserRepository
+	stale *models.User
+}
+
+func (r *staleBadgeUsers) FindByEmployeeID(orgID uint, employeeID string) (*models.User, error) {
+	stale := *r.stale
+	return &stale, nil
+}
+
+// unthrottled deixa passar todas as tentativas para o teste chegar ao limite do PIN.
+type unthrottled struct {
+	LoginAttemptService
+}
+
+func (unthrottled) CheckBadge(string, ClientInfo) error                         { return nil }
+func (unthrottled) RegisterBadgeFailure(string, ClientInfo, *models.User) error { return nil }
+func (unthrottled) RegisterSuccess(string) error                                { return nil }
+
+func TestBadgePINFailuresAreCountedAtomically(t *testing.T) {
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Pátio")
+	driver := createTestUser(t, gormDB, org.ID, "motorista@example.com", models.RoleDriver)
+	userRepo := repositories.NewUserRepository(gormDB)
+	if err := gormDB.Model(driver).Scopes(repositories.ForOrganization(org.ID)).Update("employee_id", "MOT-1").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	badges := NewBadgeService(userRepo, repositories.NewOrganizationRepository(gormDB), unthrottled{}, nil)
+	credentials, err := badges.SetPIN(driver.ID, org.ID, "4826")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := userRepo.FindByID(driver.ID, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badges = NewBadgeService(&staleBadgeUsers{UserRepository: userRepo, stale: stale}, repositories.NewOrganizationRepository(gormDB), unthrottled{}, nil)
+
+	login := schemas.BadgeLoginRequest{OrganizationCode: credentials.OrganizationCode, EmployeeID: "MOT-1", PIN: "0000"}
+	for i := 1; i <= 2; i++ {
+		if _, err := badges.Login(login, ClientInfo{IP: "10.0.0.7", Device: "tablet-1"}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: got %v, want ErrInvalidCredentials", i, err)
+		}
+		stored, _ := userRepo.FindByID(driver.ID, org.ID)
+		if stored.BadgePINFailedAttempts != i || stored.BadgePINHash == nil {
+			t.Fatalf("after %d failures the driver has %d attempts stored", i, stored.BadgePINFailedAttempts)
+		}
+	}
+
+	badges.Login(login, ClientInfo{IP: "10.0.0.7", Device: "tablet-1"})
+	stored, _ := userRepo.FindByID(driver.ID, org.ID)
+	if stored.BadgePINHash != nil || stored.BadgePINFailedAttempts != 0 {
+		t.Fatalf("pin still set after the third failure (%d attempts)", stored.BadgePINFailedAttempts)
+	}
+}
+
+// Falhas de senha vindas da rede do pátio não travam o tablet, e falhas de
+// crachá em um tablet não travam o IP nem os outros tablets.
+func TestBadgeThrottleIsSeparateFromPasswordLogin(t *testing.T) {
+	gormDB := newTestDB(t)
+	newService := func() LoginAttemptService {
+		return NewLoginAttemptService(repositories.NewMemoryCacheRepository(1000), repositories.NewUserRepository(gormDB), repositories.NewAccountLockEventRepository(gormDB))
+	}
+	tablet := ClientInfo{IP: "10.0.0.7", Device: "tablet-1"}
+
+	attempts := newService()
+	for i := 0; i < 20; i++ {
+		attempts.RegisterFailure(fmt.Sprintf("user%d@example.com", i), tablet.IP, nil)
+	}
+	if err := attempts.Check("other@example.com", tablet.IP); err == nil {
+		t.Fatal("password login from the locked IP was allowed")
+	}
+	if err := attempts.CheckBadge(badgeIdentifier("ORG1", "MOT-1"), tablet); err != nil {
+		t.Fatalf("badge login blocked by password failures: %v", err)
+	}
+
+	attempts = newService()
+	for i := 0; i < 20; i++ {
+		attempts.RegisterBadgeFailure(badgeIdentifier("ORG1", fmt.Sprintf("MOT-%d", i)), tablet, nil)
+	}
+	var throttled *LoginThrottledError
+	if err := attempts.CheckBadge(badgeIdentifier("ORG1", "MOT-99"), tablet); !errors.As(err, &throttled) || !throttled.Locked {
+		t.Fatalf("tablet was not locked after 20 badge failures: %v", err)
+	}
+	if err := attempts.CheckBadge(badgeIdentifier("ORG1", "MOT-99"), ClientInfo{IP: tablet.IP, Device: "tablet-2"}); err != nil {
+		t.Fatalf("another tablet on the same network was blocked: %v", err)
+	}
+	if err := attempts.Check("other@example.com", tablet.IP); err != nil {
+		t.Fatalf("password login blocked by badge failures: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"mime/multipart"
+	"time"
 
 	"go-api/internal/models"
 	"go-api/internal/repositories"
@@ -16,21 +17,39 @@ type DocumentService interface {
 }
 
 type documentService struct {
-	repo         repositories.DocumentRepository
+	repo           repositories.DocumentRepository
 	storageService storage.FileStorageService
+	quota          QuotaService
+	settings       OrganizationSettingsService
 }
 
-func NewDocumentService(repo repositories.DocumentRepository, storageService storage.FileStorageService) DocumentService {
-	return &documentService{repo: repo, storageService: storageService}
+func NewDocumentService(repo repositories.DocumentRepository, storageService storage.FileStorageService, quota QuotaService, settings OrganizationSettingsService) DocumentService {
+	return &documentService{repo: repo, storageService: storageService, quota: quota, settings: settings}
 }
 
+// GetDocuments com expiringInDays traz os documentos que vencem até N dias
+// depois de hoje, sendo "hoje" a data atual no fuso da organização.
 func (s *documentService) GetDocuments(orgID uint, skip, limit int, expiringInDays *int) ([]models.Document, error) {
-	return s.repo.FindByOrganization(orgID, skip, limit, expiringInDays)
+	var expiringBy *time.Time
+	if expiringInDays != nil {
+		today, err := s.settings.Now(orgID)
+		if err != nil {
+			return nil, err
+		}
+		cutoff := time.Date(today.Year(), today.Month(), today.Day()+*expiringInDays, 0, 0, 0, 0, time.UTC)
+		expiringBy = &cutoff
+	}
+	return s.repo.FindByOrganization(orgID, skip, limit, expiringBy)
 }
 
 func (s *documentService) CreateDocument(docIn schemas.DocumentCreate, file *multipart.FileHeader, orgID uint) (*models.Document, error) {
+	if err := s.quota.Consume(orgID, models.QuotaDocuments); err != nil {
+		return nil, err
+	}
+
 	fileURL, err := s.storageService.Save(file, "documents")
 	if err != nil {
+		s.quota.Release(orgID, models.QuotaDocuments)
 		return nil, err
 	}
 
@@ -44,7 +63,12 @@ func (s *documentService) CreateDocument(docIn schemas.DocumentCreate, file *mul
 		OrganizationID: orgID,
 	}
 
-	return s.repo.Create(doc)
+	created, err := s.repo.Create(doc)
+	if err != nil {
+		s.quota.Release(orgID, models.QuotaDocuments)
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *documentService) DeleteDocument(docID, orgID uint) error {
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-api/internal/config"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// Pedidos públicos que disparam e-mails.
+const (
+	EmailRequestSignup        = "signup"
+	EmailRequestVerification  = "verification"
+	EmailRequestPasswordReset = "password-reset"
+)
+
+// EmailRequestThrottledError é retornado quando o IP ou o endereço de destino
+// já fez pedidos demais na janela atual.
+type EmailRequestThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *EmailRequestThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// EmailRequestThrottle limita, por IP e por endereço de destino, o cadastro,
+// o reenvio da confirmação e a recuperação de senha: sem limite, qualquer um
+// usaria a API para lotar a caixa de entrada de terceiros. Os contadores ficam
+// no CacheRepository, como os do login.
+type EmailRequestThrottle interface {
+	Allow(action, email, clientIP string) error
+}
+
+type emailRequestThrottle struct {
+	cache repositories.CacheRepository
+}
+
+func NewEmailRequestThrottle(cache repositories.CacheRepository) EmailRequestThrottle {
+	return &emailRequestThrottle{cache: cache}
+}
+
+// Allow conta o pedido em janelas fixas; o número da janela faz parte da
+// chave, então o Retry-After é o tempo até a próxima. Limite zero desliga a
+// contagem.
+func (s *emailRequestThrottle) Allow(action, email, clientIP string) error {
+	window := time.Duration(config.AppConfig.EMAIL_REQUEST_WINDOW_MINUTES) * time.Minute
+	if window <= 0 {
+		return nil
+	}
+	now := time.Now()
+	bucket := now.UnixNano() / int64(window)
+	retryAfter := time.Unix(0, (bucket+1)*int64(window)).Sub(now)
+
+	counters := []struct {
+		key   string
+		limit int
+	}{
+		{fmt.Sprintf("email-request:%s:ip:%s:%d", action, clientIP, bucket), config.AppConfig.EMAIL_REQUEST_MAX_PER_IP},
+		{fmt.Sprintf("email-request:%s:address:%s:%d", action, models.NormalizeEmail(email), bucket), config.AppConfig.EMAIL_REQUEST_MAX_PER_ADDRESS},
+	}
+	for _, counter := range counters {
+		if counter.limit <= 0 {
+			continue
+		}
+		count, err := s.cache.Incr(context.Background(), counter.key, window)
+		if err != nil {
+			return err
+		}
+		if count > int64(counter.limit) {
+			return &EmailRequestThrottledError{RetryAfter: retryAfter}
+		}
+	}
+	return nil
+}
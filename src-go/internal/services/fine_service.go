@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"go-api/internal/models"
 	"go-api/internal/repositories"
@@ -11,7 +12,7 @@ type FineService interface {
 	GetFines(user models.User, skip, limit int) ([]models.Fine, error)
 	GetFine(fineID, orgID uint) (*models.Fine, error)
 	CreateFine(fineIn schemas.FineCreate, user models.User) (*models.Fine, error)
-	UpdateFine(fineID uint, fineIn schemas.FineUpdate, user models.User) (*models.Fine, error)
+	UpdateFine(fineID uint, fineIn schemas.FineUpdate, user models.User, ifMatch *uint) (*models.Fine, error)
 	DeleteFine(fineID, orgID uint) error
 }
 
@@ -69,7 +70,7 @@ func (s *fineService) CreateFine(fineIn schemas.FineCreate, user models.User) (*
 	return createdFine, nil
 }
 
-func (s *fineService) UpdateFine(fineID uint, fineIn schemas.FineUpdate, user models.User) (*models.Fine, error) {
+func (s *fineService) UpdateFine(fineID uint, fineIn schemas.FineUpdate, user models.User, ifMatch *uint) (*models.Fine, error) {
 	fine, err := s.fineRepo.FindByID(fineID, user.OrganizationID)
 	if err != nil {
 		return nil, err
@@ -78,29 +79,31 @@ func (s *fineService) UpdateFine(fineID uint, fineIn schemas.FineUpdate, user mo
 		return nil, nil // Not found
 	}
 
-	if fineIn.Description != "" {
-		fine.Description = fineIn.Description
-	}
-	if fineIn.InfractionCode != "" {
-		fine.InfractionCode = fineIn.InfractionCode
-	}
-	if !fineIn.Date.IsZero() {
-		fine.Date = fineIn.Date
-	}
-	if fineIn.Value > 0 {
-		fine.Value = fineIn.Value
-	}
-	if fineIn.Status != "" {
-		fine.Status = models.FineStatus(fineIn.Status)
+	if err := checkVersion(ifMatch, fine.Version); err != nil {
+		return nil, err
 	}
-	if fineIn.VehicleID > 0 {
-		fine.VehicleID = fineIn.VehicleID
+
+	err = errors.Join(
+		patchRequired("description", fineIn.Description, &fine.Description),
+		patchRequired("date", fineIn.Date, &fine.Date),
+		patchRequired("value", fineIn.Value, &fine.Value),
+		patchRequired("status", fineIn.Status, (*string)(&fine.Status)),
+		patchRequired("vehicle_id", fineIn.VehicleID, &fine.VehicleID),
+		patchValue("infraction_code", fineIn.InfractionCode, &fine.InfractionCode),
+	)
+	if err != nil {
+		return nil, err
 	}
-	if fineIn.DriverID != nil {
-		fine.DriverID = fineIn.DriverID
+	if !models.IsValidFineStatus(fine.Status) {
+		return nil, fmt.Errorf("%w: invalid status %q", ErrInvalidPatch, fine.Status)
 	}
+	patchNullable(fineIn.DriverID, &fine.DriverID)
 
-	return s.fineRepo.Update(fine)
+	updatedFine, err := s.fineRepo.Update(fine)
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return updatedFine, nil
 }
 
 func (s *fineService) DeleteFine(fineID, orgID uint) error {
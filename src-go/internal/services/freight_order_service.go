@@ -28,10 +28,11 @@ type freightOrderService struct {
 	freightOrderRepo repositories.FreightOrderRepository
 	vehicleRepo      repositories.VehicleRepository
 	journeyService   JourneyService
+	quota            QuotaService
 }
 
-func NewFreightOrderService(freightOrderRepo repositories.FreightOrderRepository, vehicleRepo repositories.VehicleRepository, journeyService JourneyService) FreightOrderService {
-	return &freightOrderService{freightOrderRepo: freightOrderRepo, vehicleRepo: vehicleRepo, journeyService: journeyService}
+func NewFreightOrderService(freightOrderRepo repositories.FreightOrderRepository, vehicleRepo repositories.VehicleRepository, journeyService JourneyService, quota QuotaService) FreightOrderService {
+	return &freightOrderService{freightOrderRepo: freightOrderRepo, vehicleRepo: vehicleRepo, journeyService: journeyService, quota: quota}
 }
 
 func (s *freightOrderService) GetFreightOrders(user models.User, skip, limit int) ([]models.FreightOrder, error) {
@@ -73,7 +74,16 @@ func (s *freightOrderService) CreateFreightOrder(orderIn schemas.FreightOrderCre
 		OrganizationID:     orgID,
 		StopPoints:         stopPoints,
 	}
-	return s.freightOrderRepo.CreateWithStops(order)
+
+	if err := s.quota.Consume(orgID, models.QuotaFreightOrders); err != nil {
+		return nil, err
+	}
+	created, err := s.freightOrderRepo.CreateWithStops(order)
+	if err != nil {
+		s.quota.Release(orgID, models.QuotaFreightOrders)
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *freightOrderService) ClaimFreightOrder(orderID uint, claimIn schemas.FreightOrderClaim, driver models.User) (*models.FreightOrder, error) {
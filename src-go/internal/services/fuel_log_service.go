@@ -1,6 +1,10 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
@@ -10,16 +14,18 @@ type FuelLogService interface {
 	GetFuelLogs(user models.User, skip, limit int) ([]models.FuelLog, error)
 	GetFuelLog(fuelLogID, orgID uint) (*models.FuelLog, error)
 	CreateFuelLog(fuelLogIn schemas.FuelLogCreate, currentUser models.User) (*models.FuelLog, error)
-	UpdateFuelLog(fuelLogID, orgID uint, fuelLogIn schemas.FuelLogUpdate) (*models.FuelLog, error)
+	UpdateFuelLog(fuelLogID, orgID uint, fuelLogIn schemas.FuelLogUpdate, ifMatch *uint) (*models.FuelLog, error)
 	DeleteFuelLog(fuelLogID, orgID uint) error
 }
 
 type fuelLogService struct {
-	repo repositories.FuelLogRepository
+	repo        repositories.FuelLogRepository
+	vehicleRepo repositories.VehicleRepository
+	odometer    OdometerService
 }
 
-func NewFuelLogService(repo repositories.FuelLogRepository) FuelLogService {
-	return &fuelLogService{repo: repo}
+func NewFuelLogService(repo repositories.FuelLogRepository, vehicleRepo repositories.VehicleRepository, odometer OdometerService) FuelLogService {
+	return &fuelLogService{repo: repo, vehicleRepo: vehicleRepo, odometer: odometer}
 }
 
 func (s *fuelLogService) GetFuelLogs(user models.User, skip, limit int) ([]models.FuelLog, error) {
@@ -39,6 +45,14 @@ func (s *fuelLogService) CreateFuelLog(fuelLogIn schemas.FuelLogCreate, currentU
 		userID = *fuelLogIn.UserID
 	}
 
+	vehicle, err := s.vehicleRepo.FindByID(fuelLogIn.VehicleID, currentUser.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+
 	fuelLog := &models.FuelLog{
 		Odometer:        fuelLogIn.Odometer,
 		Liters:          fuelLogIn.Liters,
@@ -46,14 +60,16 @@ func (s *fuelLogService) CreateFuelLog(fuelLogIn schemas.FuelLogCreate, currentU
 		VehicleID:       fuelLogIn.VehicleID,
 		UserID:          userID,
 		ReceiptPhotoURL: fuelLogIn.ReceiptPhotoURL,
+		Timestamp:       time.Now(),
 		OrganizationID:  currentUser.OrganizationID,
 	}
-
-	err := s.repo.Create(fuelLog)
-	return fuelLog, err
+	if err := s.odometer.RecordFuelLog(vehicle, fuelLog, currentUser.ID); err != nil {
+		return nil, err
+	}
+	return fuelLog, nil
 }
 
-func (s *fuelLogService) UpdateFuelLog(fuelLogID, orgID uint, fuelLogIn schemas.FuelLogUpdate) (*models.FuelLog, error) {
+func (s *fuelLogService) UpdateFuelLog(fuelLogID, orgID uint, fuelLogIn schemas.FuelLogUpdate, ifMatch *uint) (*models.FuelLog, error) {
 	fuelLog, err := s.repo.FindByID(fuelLogID, orgID)
 	if err != nil {
 		return nil, err
@@ -62,24 +78,45 @@ func (s *fuelLogService) UpdateFuelLog(fuelLogID, orgID uint, fuelLogIn schemas.
 		return nil, nil // Or return a not found error
 	}
 
-	if fuelLogIn.Odometer != nil {
-		fuelLog.Odometer = *fuelLogIn.Odometer
-	}
-	if fuelLogIn.Liters != nil {
-		fuelLog.Liters = *fuelLogIn.Liters
+	if err := checkVersion(ifMatch, fuelLog.Version); err != nil {
+		return nil, err
 	}
-	if fuelLogIn.TotalCost != nil {
-		fuelLog.TotalCost = *fuelLogIn.TotalCost
+
+	// O hodômetro faz parte do histórico de leituras do veículo; correções
+	// entram como leitura manual, que passa pela validação de sequência.
+	if fuelLogIn.Odometer.Set && (fuelLogIn.Odometer.Null || fuelLogIn.Odometer.Value != fuelLog.Odometer) {
+		return nil, fmt.Errorf("%w: odometer cannot be changed, record an odometer reading instead", ErrInvalidPatch)
 	}
-	if fuelLogIn.VehicleID != nil {
-		fuelLog.VehicleID = *fuelLogIn.VehicleID
+
+	vehicleID := fuelLog.VehicleID
+	err = errors.Join(
+		patchRequired("liters", fuelLogIn.Liters, &fuelLog.Liters),
+		patchRequired("total_cost", fuelLogIn.TotalCost, &fuelLog.TotalCost),
+		patchRequired("vehicle_id", fuelLogIn.VehicleID, &fuelLog.VehicleID),
+	)
+	if err != nil {
+		return nil, err
 	}
-	if fuelLogIn.ReceiptPhotoURL != nil {
-		fuelLog.ReceiptPhotoURL = fuelLogIn.ReceiptPhotoURL
+	patchNullable(fuelLogIn.ReceiptPhotoURL, &fuelLog.ReceiptPhotoURL)
+
+	if fuelLog.VehicleID != vehicleID {
+		vehicle, err := s.vehicleRepo.FindByID(fuelLog.VehicleID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if vehicle == nil {
+			return nil, ErrVehicleNotFound
+		}
+		if err := s.odometer.MoveFuelLog(vehicle, fuelLog); err != nil {
+			return nil, err
+		}
+		return fuelLog, nil
 	}
 
-	err = s.repo.Update(fuelLog)
-	return fuelLog, err
+	if err := s.repo.Update(fuelLog); err != nil {
+		return nil, versionError(err)
+	}
+	return fuelLog, nil
 }
 
 func (s *fuelLogService) DeleteFuelLog(fuelLogID, orgID uint) error {
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
@@ -10,7 +13,7 @@ type ImplementService interface {
 	GetImplements(orgID uint, managementList bool) ([]models.Implement, error)
 	GetImplement(implementID, orgID uint) (*models.Implement, error)
 	CreateImplement(implementIn schemas.ImplementCreate, orgID uint) (*models.Implement, error)
-	UpdateImplement(implementID, orgID uint, implementIn schemas.ImplementUpdate) (*models.Implement, error)
+	UpdateImplement(implementID, orgID uint, implementIn schemas.ImplementUpdate, ifMatch *uint) (*models.Implement, error)
 	DeleteImplement(implementID, orgID uint) error
 }
 
@@ -44,7 +47,7 @@ func (s *implementService) CreateImplement(implementIn schemas.ImplementCreate,
 	return implement, err
 }
 
-func (s *implementService) UpdateImplement(implementID, orgID uint, implementIn schemas.ImplementUpdate) (*models.Implement, error) {
+func (s *implementService) UpdateImplement(implementID, orgID uint, implementIn schemas.ImplementUpdate, ifMatch *uint) (*models.Implement, error) {
 	implement, err := s.repo.FindByID(implementID, orgID)
 	if err != nil {
 		return nil, err
@@ -53,30 +56,30 @@ func (s *implementService) UpdateImplement(implementID, orgID uint, implementIn
 		return nil, nil // Or return a not found error
 	}
 
-	if implementIn.Name != "" {
-		implement.Name = implementIn.Name
-	}
-	if implementIn.Brand != "" {
-		implement.Brand = implementIn.Brand
-	}
-	if implementIn.VehicleModel != "" {
-		implement.VehicleModel = implementIn.VehicleModel
-	}
-	if implementIn.Year != 0 {
-		implement.Year = implementIn.Year
-	}
-	if implementIn.Identifier != "" {
-		implement.Identifier = implementIn.Identifier
+	if err := checkVersion(ifMatch, implement.Version); err != nil {
+		return nil, err
 	}
-	if implementIn.Type != "" {
-		implement.Type = implementIn.Type
+
+	err = errors.Join(
+		patchRequired("name", implementIn.Name, &implement.Name),
+		patchRequired("brand", implementIn.Brand, &implement.Brand),
+		patchRequired("model", implementIn.VehicleModel, &implement.VehicleModel),
+		patchRequired("year", implementIn.Year, &implement.Year),
+		patchRequired("status", implementIn.Status, (*string)(&implement.Status)),
+		patchValue("identifier", implementIn.Identifier, &implement.Identifier),
+		patchValue("type", implementIn.Type, &implement.Type),
+	)
+	if err != nil {
+		return nil, err
 	}
-	if implementIn.Status != "" {
-		implement.Status = models.ImplementStatus(implementIn.Status)
+	if !models.IsValidImplementStatus(implement.Status) {
+		return nil, fmt.Errorf("%w: invalid status %q", ErrInvalidPatch, implement.Status)
 	}
 
-	err = s.repo.Update(implement)
-	return implement, err
+	if err := s.repo.Update(implement); err != nil {
+		return nil, versionError(err)
+	}
+	return implement, nil
 }
 
 func (s *implementService) DeleteImplement(implementID, orgID uint) error {
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-api/internal/schemas"
+)
+
+// importUniqueness acompanha, durante uma importação, um campo que precisa
+// ser único: repetições dentro do arquivo e valores já cadastrados viram
+// erros das linhas envolvidas.
+type importUniqueness struct {
+	column string
+	label  string
+	lines  map[string][]int
+	order  []string
+}
+
+func newImportUniqueness(column, label string) *importUniqueness {
+	return &importUniqueness{column: column, label: label, lines: map[string][]int{}}
+}
+
+// add ignora valores vazios, que não entram na restrição de unicidade.
+func (u *importUniqueness) add(line int, value *string) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return
+	}
+	if _, ok := u.lines[*value]; !ok {
+		u.order = append(u.order, *value)
+	}
+	u.lines[*value] = append(u.lines[*value], line)
+}
+
+func (u *importUniqueness) has(value string) bool {
+	_, ok := u.lines[value]
+	return ok
+}
+
+func (u *importUniqueness) values() []string {
+	return u.order
+}
+
+func (u *importUniqueness) errors(existing []string) []schemas.ImportError {
+	registered := map[string]bool{}
+	for _, value := range existing {
+		registered[value] = true
+	}
+
+	var errs []schemas.ImportError
+	for _, value := range u.order {
+		lines := u.lines[value]
+		if registered[value] {
+			for _, line := range lines {
+				errs = append(errs, schemas.ImportError{Line: line, Column: u.column, Message: fmt.Sprintf("%s %q is already registered", u.label, value)})
+			}
+			continue
+		}
+		if len(lines) > 1 {
+			repeated := make([]string, len(lines))
+			for i, line := range lines {
+				repeated[i] = strconv.Itoa(line)
+			}
+			for _, line := range lines {
+				errs = append(errs, schemas.ImportError{Line: line, Column: u.column, Message: fmt.Sprintf("%s %q appears more than once in the file (lines %s)", u.label, value, strings.Join(repeated, ", "))})
+			}
+		}
+	}
+	return errs
+}
+
+func sortImportErrors(errs []schemas.ImportError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+}
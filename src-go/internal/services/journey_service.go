@@ -1,30 +1,42 @@
 package services
 
 import (
-	"time"
+	"errors"
 
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
 )
 
+var ErrJourneyAlreadyEnded = errors.New("journey has already ended")
+
 type JourneyService interface {
-	GetJourneys(orgID uint, skip, limit int, driverID, vehicleID *uint, dateFrom, dateTo *time.Time) ([]models.Journey, error)
+	GetJourneys(orgID uint, skip, limit int, driverID, vehicleID *uint, dateRange schemas.DateRange) ([]models.Journey, error)
 	StartJourney(journeyIn schemas.JourneyCreate, driverID, orgID uint) (*models.Journey, error)
-	EndJourney(journeyID, orgID uint, endMileage *int, endEngineHours *float64) (*models.Journey, *models.Vehicle, error)
+	EndJourney(journeyID, orgID uint, endMileage *int, endEngineHours *float64, actorID uint) (*models.Journey, *models.Vehicle, error)
 	DeleteJourney(journeyID, orgID uint) error
 }
 
 type journeyService struct {
-	journeyRepo repositories.JourneyRepository
-	vehicleRepo repositories.VehicleRepository
+	journeyRepo   repositories.JourneyRepository
+	vehicleRepo   repositories.VehicleRepository
+	quota         QuotaService
+	settings      OrganizationSettingsService
+	vehicleStatus VehicleStatusService
+	odometer      OdometerService
 }
 
-func NewJourneyService(journeyRepo repositories.JourneyRepository, vehicleRepo repositories.VehicleRepository) JourneyService {
-	return &journeyService{journeyRepo: journeyRepo, vehicleRepo: vehicleRepo}
+func NewJourneyService(journeyRepo repositories.JourneyRepository, vehicleRepo repositories.VehicleRepository, quota QuotaService, settings OrganizationSettingsService, vehicleStatus VehicleStatusService, odometer OdometerService) JourneyService {
+	return &journeyService{journeyRepo: journeyRepo, vehicleRepo: vehicleRepo, quota: quota, settings: settings, vehicleStatus: vehicleStatus, odometer: odometer}
 }
 
-func (s *journeyService) GetJourneys(orgID uint, skip, limit int, driverID, vehicleID *uint, dateFrom, dateTo *time.Time) ([]models.Journey, error) {
+// GetJourneys filtra pelo período no fuso da organização (ver dateWindow).
+func (s *journeyService) GetJourneys(orgID uint, skip, limit int, driverID, vehicleID *uint, dateRange schemas.DateRange) ([]models.Journey, error) {
+	settings, err := s.settings.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	dateFrom, dateTo := dateWindow(settings, dateRange)
 	return s.journeyRepo.FindByOrganization(orgID, skip, limit, driverID, vehicleID, dateFrom, dateTo)
 }
 
@@ -36,6 +48,13 @@ func (s *journeyService) StartJourney(journeyIn schemas.JourneyCreate, driverID,
 	if vehicle == nil || vehicle.Status != models.StatusAvailable {
 		return nil, repositories.ErrVehicleNotAvailable
 	}
+	now, err := s.settings.Now(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.quota.Consume(orgID, models.QuotaJourneys); err != nil {
+		return nil, err
+	}
 
 	journey := &models.Journey{
 		VehicleID:               journeyIn.VehicleID,
@@ -50,20 +69,36 @@ func (s *journeyService) StartJourney(journeyIn schemas.JourneyCreate, driverID,
 		DestinationCEP:          journeyIn.DestinationCEP,
 		DriverID:                driverID,
 		OrganizationID:          orgID,
-		StartTime: 				 time.Now(),
+		StartTime:               now,
+		StartMileage:            vehicle.CurrentKM,
+		StartEngineHours:        vehicle.CurrentEngineHours,
 	}
 
 	createdJourney, err := s.journeyRepo.Create(journey)
 	if err != nil {
+		s.quota.Release(orgID, models.QuotaJourneys)
 		return nil, err
 	}
 
-	vehicle.Status = models.StatusInUse
-	err = s.vehicleRepo.Update(vehicle)
-	return createdJourney, err
+	err = s.vehicleStatus.ChangeStatus(vehicle, models.VehicleStatusChange{
+		ToStatus:  models.StatusInUse,
+		Reason:    "Jornada iniciada",
+		ActorID:   &driverID,
+		JourneyID: &createdJourney.ID,
+	})
+	if err != nil {
+		// Outra jornada pode ter ocupado o veículo entre a leitura e a gravação.
+		s.journeyRepo.Delete(createdJourney)
+		s.quota.Release(orgID, models.QuotaJourneys)
+		if errors.Is(err, ErrVersionMismatch) {
+			return nil, repositories.ErrVehicleNotAvailable
+		}
+		return nil, err
+	}
+	return createdJourney, nil
 }
 
-func (s *journeyService) EndJourney(journeyID, orgID uint, endMileage *int, endEngineHours *float64) (*models.Journey, *models.Vehicle, error) {
+func (s *journeyService) EndJourney(journeyID, orgID uint, endMileage *int, endEngineHours *float64, actorID uint) (*models.Journey, *models.Vehicle, error) {
 	journey, err := s.journeyRepo.FindByID(journeyID, orgID)
 	if err != nil {
 		return nil, nil, err
@@ -71,33 +106,73 @@ func (s *journeyService) EndJourney(journeyID, orgID uint, endMileage *int, endE
 	if journey == nil {
 		return nil, nil, nil // Or return a not found error
 	}
+	if !journey.IsActive {
+		return nil, nil, ErrJourneyAlreadyEnded
+	}
 
-	now := time.Now()
-	journey.EndTime = &now
-	journey.EndMileage = endMileage
-	journey.EndEngineHours = endEngineHours
-	journey.IsActive = false
+	vehicle, err := s.vehicleRepo.FindByID(journey.VehicleID, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vehicle == nil {
+		return nil, nil, nil // Or return a not found error
+	}
 
-	updatedJourney, err := s.journeyRepo.Update(journey)
+	now, err := s.settings.Now(orgID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	vehicle, err := s.vehicleRepo.FindByID(journey.VehicleID, orgID)
+	// O encerramento é gravado primeiro e só vale para uma requisição: um
+	// segundo encerramento não registra outra leitura nem mexe no veículo.
+	journey.EndTime = &now
+	journey.EndMileage = endMileage
+	journey.EndEngineHours = endEngineHours
+	journey.IsActive = false
+	ended, err := s.journeyRepo.End(journey)
 	if err != nil {
 		return nil, nil, err
 	}
-	if vehicle == nil {
-		return nil, nil, nil // Or return a not found error
+	if !ended {
+		return nil, nil, ErrJourneyAlreadyEnded
 	}
 
-	vehicle.Status = models.StatusAvailable
-	if endMileage != nil {
-		vehicle.CurrentKM = *endMileage
+	// Organizações que medem por horas de motor informam o horímetro no fim da
+	// jornada. Uma leitura fora de ordem é recusada e a jornada volta a ficar
+	// ativa, para que o motorista corrija o valor.
+	if endMileage != nil || endEngineHours != nil {
+		err = s.odometer.Record(vehicle, &models.OdometerReading{
+			VehicleID:      vehicle.ID,
+			Odometer:       endMileage,
+			EngineHours:    endEngineHours,
+			Source:         models.OdometerSourceJourney,
+			SourceID:       &journey.ID,
+			RecordedAt:     now,
+			RecordedByID:   &actorID,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			if reopenErr := s.journeyRepo.Reopen(journey); reopenErr != nil {
+				return nil, nil, errors.Join(err, reopenErr)
+			}
+			return nil, nil, err
+		}
 	}
 
-	err = s.vehicleRepo.Update(vehicle)
-	return updatedJourney, vehicle, err
+	// O veículo pode ter saído de uso durante a jornada (ex.: foi para a
+	// manutenção) ou já estar em outra jornada; nesses casos o status é mantido.
+	if vehicle.Status == models.StatusInUse {
+		err = s.vehicleStatus.ChangeStatus(vehicle, models.VehicleStatusChange{
+			ToStatus:  models.StatusAvailable,
+			Reason:    "Jornada encerrada",
+			ActorID:   &actorID,
+			JourneyID: &journey.ID,
+		})
+		if errors.Is(err, ErrVehicleHeldByAnotherJourney) {
+			err = nil
+		}
+	}
+	return journey, vehicle, err
 }
 
 func (s *journeyService) DeleteJourney(journeyID, orgID uint) error {
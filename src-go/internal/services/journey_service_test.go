@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// staleJourneys devolve, no FindByID, a jornada lida antes de outra
+// requisição encerrá-la, como acontece com dois encerramentos simultâneos.
+type staleJourneys struct {
+	repositories.JourneyRepository
+	stale *models.Journey
+}
+
+func (r *staleJourneys) FindByID(journeyID, orgID uint) (*models.Journey, error) {
+	if r.stale != nil {
+		stale := *r.stale
+		return &stale, nil
+	}
+	return r.JourneyRepository.FindByID(journeyID, orgID)
+}
+
+type journeyFixture struct {
+	db            *gorm.DB
+	orgID         uint
+	driver        *models.User
+	vehicle       *models.Vehicle
+	journeys      *staleJourneys
+	vehicles      repositories.VehicleRepository
+	vehicleStatus VehicleStatusService
+	service       JourneyService
+}
+
+func newJourneyFixture(t *testing.T) *journeyFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Jornada")
+	driver := createTestUser(t, gormDB, org.ID, "motorista@example.com", models.RoleDriver)
+	vehicle := createTestVehicle(t, gormDB, org.ID, 1000)
+
+	orgRepo := repositories.NewOrganizationRepository(gormDB)
+	vehicles := repositories.NewVehicleRepository(gormDB)
+	cache := repositories.NewUnboundedMemoryCacheRepository()
+	settings := NewOrganizationSettingsService(repositories.NewOrganizationSettingsRepository(gormDB), orgRepo)
+	quota := NewQuotaService(orgRepo, repositories.NewUsageRepository(gormDB), vehicles, repositories.NewUserRepository(gormDB), settings)
+	vehicleStatus := NewVehicleStatusService(vehicles, settings, cache)
+	odometer := NewOdometerService(repositories.NewOdometerReadingRepository(gormDB), vehicles, settings, cache)
+	journeys := &staleJourneys{JourneyRepository: repositories.NewJourneyRepository(gormDB)}
+	service := NewJourneyService(journeys, vehicles, quota, settings, vehicleStatus, odometer)
+	return &journeyFixture{db: gormDB, orgID: org.ID, driver: driver, vehicle: vehicle, journeys: journeys, vehicles: vehicles, vehicleStatus: vehicleStatus, service: service}
+}
+
+func (f *journeyFixture) start(t *testing.T) *models.Journey {
+	t.Helper()
+	journey, err := f.service.StartJourney(schemas.JourneyCreate{VehicleID: f.vehicle.ID, TripType: models.JourneyTypeFreeRoam}, f.driver.ID, f.orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return journey
+}
+
+func (f *journeyFixture) reloadVehicle(t *testing.T) *models.Vehicle {
+	t.Helper()
+	vehicle, err := f.vehicles.FindByID(f.vehicle.ID, f.orgID)
+	if err != nil || vehicle == nil {
+		t.Fatalf("reload vehicle: %v, %v", vehicle, err)
+	}
+	return vehicle
+}
+
+func (f *journeyFixture) journeyReadings(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	if err := f.db.Model(&models.OdometerReading{}).Scopes(repositories.ForOrganization(f.orgID)).
+		Where("source = ?", models.OdometerSourceJourney).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func intPtr(value int) *int { return &value }
+
+func TestEndJourneyOnlyOnce(t *testing.T) {
+	f := newJourneyFixture(t)
+	journey := f.start(t)
+
+	ended, vehicle, err := f.service.EndJourney(journey.ID, f.orgID, intPtr(1200), nil, f.driver.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ended.IsActive || ended.EndTime == nil || vehicle.Status != models.StatusAvailable || vehicle.CurrentKM != 1200 {
+		t.Fatalf("ended: active=%v vehicle=%s km=%d", ended.IsActive, vehicle.Status, vehicle.CurrentKM)
+	}
+
+	if _, _, err := f.service.EndJourney(journey.ID, f.orgID, intPtr(1300), nil, f.driver.ID); !errors.Is(err, ErrJourneyAlreadyEnded) {
+		t.Fatalf("second end: got %v, want ErrJourneyAlreadyEnded", err)
+	}
+
+	// A segunda requisição leu a jornada ainda ativa, antes do encerramento acima.
+	f.journeys.stale = journey
+	if _, _, err := f.service.EndJourney(journey.ID, f.orgID, intPtr(1300), nil, f.driver.ID); !errors.Is(err, ErrJourneyAlreadyEnded) {
+		t.Fatalf("concurrent end: got %v, want ErrJourneyAlreadyEnded", err)
+	}
+	f.journeys.stale = nil
+
+	if n := f.journeyReadings(t); n != 1 {
+		t.Errorf("%d journey readings, want 1", n)
+	}
+	if km := f.reloadVehicle(t).CurrentKM; km != 1200 {
+		t.Errorf("current km = %d, want 1200", km)
+	}
+}
+
+func TestEndJourneyKeepsJourneyOpenWhenReadingIsRejected(t *testing.T) {
+	f := newJourneyFixture(t)
+	journey := f.start(t)
+
+	if _, _, err := f.service.EndJourney(journey.ID, f.orgID, intPtr(900), nil, f.driver.ID); !errors.Is(err, ErrOdometerRollback) {
+		t.Fatalf("reading below the current km: got %v, want ErrOdometerRollback", err)
+	}
+	stored, err := f.journeys.FindByID(journey.ID, f.orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.IsActive || stored.EndTime != nil {
+		t.Fatalf("journey closed by a rejected reading: active=%v", stored.IsActive)
+	}
+	if status := f.reloadVehicle(t).Status; status != models.StatusInUse {
+		t.Fatalf("vehicle status = %s, want %s", status, models.StatusInUse)
+	}
+
+	if _, _, err := f.service.EndJourney(journey.ID, f.orgID, intPtr(1100), nil, f.driver.ID); err != nil {
+		t.Fatalf("corrected reading: %v", err)
+	}
+}
+
+// Uma jornada antiga encerrada depois não libera o veículo de outra jornada.
+func TestEndJourneyDoesNotReleaseVehicleOfAnotherJourney(t *testing.T) {
+	f := newJourneyFixture(t)
+	old := f.start(t)
+
+	// O veículo quebrou, voltou da manutenção e saiu em outra jornada.
+	vehicle := f.reloadVehicle(t)
+	if err := f.vehicleStatus.ChangeStatus(vehicle, models.VehicleStatusChange{ToStatus: models.StatusMaintenance, Reason: "Quebra"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.vehicleStatus.ChangeStatus(vehicle, models.VehicleStatusChange{ToStatus: models.StatusAvailable, Reason: "Reparo"}); err != nil {
+		t.Fatal(err)
+	}
+	current := f.start(t)
+
+	if err := f.vehicleStatus.ChangeStatus(f.reloadVehicle(t), models.VehicleStatusChange{ToStatus: models.StatusAvailable, JourneyID: &old.ID}); !errors.Is(err, ErrVehicleHeldByAnotherJourney) {
+		t.Fatalf("release by the old journey: got %v, want ErrVehicleHeldByAnotherJourney", err)
+	}
+
+	if _, _, err := f.service.EndJourney(old.ID, f.orgID, nil, nil, f.driver.ID); err != nil {
+		t.Fatal(err)
+	}
+	if status := f.reloadVehicle(t).Status; status != models.StatusInUse {
+		t.Fatalf("vehicle status = %s after ending the old journey, want %s", status, models.StatusInUse)
+	}
+
+	if _, vehicle, err := f.service.EndJourney(current.ID, f.orgID, nil, nil, f.driver.ID); err != nil || vehicle.Status != models.StatusAvailable {
+		t.Fatalf("ending the current journey: status=%v err=%v", vehicle, err)
+	}
+}
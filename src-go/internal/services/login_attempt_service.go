@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// Limite máximo do atraso progressivo entre tentativas com falha.
+const maxLoginDelay = 30 * time.Second
+
+// LoginThrottledError é retornado quando a tentativa precisa esperar (atraso
+// progressivo) ou quando a conta/IP está bloqueada.
+type LoginThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginAttemptService guarda os contadores no CacheRepository para que o
+// bloqueio valha para todas as instâncias da API.
+type LoginAttemptService interface {
+	Check(email, clientIP string) error
+	RegisterFailure(email, clientIP string, user *models.User) error
+	// CheckBadge e RegisterBadgeFailure contam as falhas por crachá e por
+	// tablet (IP + nome do dispositivo), separadas das do login por senha:
+	// falhas de senha de quem está na mesma rede do pátio não travam o
+	// tablet compartilhado, e um crachá errado não trava o IP para os demais.
+	CheckBadge(identifier string, client ClientInfo) error
+	RegisterBadgeFailure(identifier string, client ClientInfo, user *models.User) error
+	RegisterSuccess(email string) error
+	UnlockAccount(userID uint, actor models.User) error
+	GetLockEvents(userID uint, actor models.User, skip, limit int) ([]models.AccountLockEvent, error)
+}
+
+type loginAttemptService struct {
+	cache     repositories.CacheRepository
+	userRepo  repositories.UserRepository
+	eventRepo repositories.AccountLockEventRepository
+}
+
+func NewLoginAttemptService(cache repositories.CacheRepository, userRepo repositories.UserRepository, eventRepo repositories.AccountLockEventRepository) LoginAttemptService {
+	return &loginAttemptService{cache: cache, userRepo: userRepo, eventRepo: eventRepo}
+}
+
+func accountKey(kind, email string) string {
+	return fmt.Sprintf("login:%s:account:%s", kind, models.NormalizeEmail(email))
+}
+
+// O contador da origem pega quem testa muitas contas do mesmo lugar.
+func ipSource(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func badgeDeviceSource(client ClientInfo) string {
+	return "badge-device:" + client.IP + "|" + strings.TrimSpace(client.Device)
+}
+
+func sourceKey(kind, source string) string {
+	return fmt.Sprintf("login:%s:%s", kind, source)
+}
+
+func (s *loginAttemptService) Check(email, clientIP string) error {
+	return s.check(email, ipSource(clientIP))
+}
+
+func (s *loginAttemptService) CheckBadge(identifier string, client ClientInfo) error {
+	return s.check(identifier, badgeDeviceSource(client))
+}
+
+func (s *loginAttemptService) check(account, source string) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, key := range []string{accountKey("lock", account), sourceKey("lock", source)} {
+		until, err := s.readTimestamp(ctx, key)
+		if err != nil {
+			return err
+		}
+		if now.Unix() < until {
+			return &LoginThrottledError{Locked: true, RetryAfter: time.Unix(until, 0).Sub(now)}
+		}
+	}
+
+	for _, key := range []string{accountKey("next", account), sourceKey("next", source)} {
+		notBefore, err := s.readTimestamp(ctx, key)
+		if err != nil {
+			return err
+		}
+		if now.Unix() < notBefore {
+			return &LoginThrottledError{RetryAfter: time.Unix(notBefore, 0).Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// readTimestamp devolve 0 quando a chave não existe. Qualquer outro erro do
+// cache sobe para o chamador: sem saber se a conta está bloqueada, o login é
+// recusado em vez de liberado.
+func (s *loginAttemptService) readTimestamp(ctx context.Context, key string) (int64, error) {
+	var value int64
+	err := s.cache.Get(ctx, key, &value)
+	if errors.Is(err, repositories.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to read login lock state", zap.Error(err), zap.String("key", key))
+		return 0, fmt.Errorf("read login lock state: %w", err)
+	}
+	return value, nil
+}
+
+func (s *loginAttemptService) RegisterFailure(email, clientIP string, user *models.User) error {
+	return s.registerFailure(email, ipSource(clientIP), clientIP, user)
+}
+
+func (s *loginAttemptService) RegisterBadgeFailure(identifier string, client ClientInfo, user *models.User) error {
+	return s.registerFailure(identifier, badgeDeviceSource(client), client.IP, user)
+}
+
+func (s *loginAttemptService) registerFailure(account, source, clientIP string, user *models.User) error {
+	ctx := context.Background()
+	window := time.Duration(config.AppConfig.LOGIN_FAILURE_WINDOW_MINUTES) * time.Minute
+	lockout := time.Duration(config.AppConfig.LOGIN_LOCKOUT_MINUTES) * time.Minute
+
+	accountFailures, err := s.cache.Incr(ctx, accountKey("fail", account), window)
+	if err != nil {
+		return err
+	}
+	sourceFailures, err := s.cache.Incr(ctx, sourceKey("fail", source), window)
+	if err != nil {
+		return err
+	}
+
+	if accountFailures >= int64(config.AppConfig.LOGIN_MAX_FAILED_ATTEMPTS) {
+		if err := s.lock(ctx, accountKey("lock", account), lockout); err != nil {
+			return err
+		}
+		s.cache.Delete(ctx, accountKey("fail", account))
+		if user != nil {
+			s.recordEvent(user, models.AccountLockEventLocked, "too many failed login attempts", &clientIP, nil)
+		}
+	} else if err := s.delay(ctx, accountKey("next", account), accountFailures, 2); err != nil {
+		return err
+	}
+
+	if sourceFailures >= int64(config.AppConfig.LOGIN_MAX_FAILED_ATTEMPTS_PER_IP) {
+		if err := s.lock(ctx, sourceKey("lock", source), lockout); err != nil {
+			return err
+		}
+		s.cache.Delete(ctx, sourceKey("fail", source))
+		logging.Logger.Warn("Login source locked after repeated login failures", zap.String("source", source))
+	} else if err := s.delay(ctx, sourceKey("next", source), sourceFailures, 5); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RegisterSuccess zera apenas os contadores da conta. Os contadores da origem
+// continuam, senão uma conta válida serviria para "limpar" um ataque em massa.
+func (s *loginAttemptService) RegisterSuccess(email string) error {
+	ctx := context.Background()
+	if err := s.cache.Delete(ctx, accountKey("fail", email)); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, accountKey("next", email))
+}
+
+func (s *loginAttemptService) UnlockAccount(userID uint, actor models.User) error {
+	user, err := s.findManagedUser(userID, actor)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, kind := range []string{"lock", "fail", "next"} {
+		if err := s.cache.Delete(ctx, accountKey(kind, user.Email)); err != nil {
+			return err
+		}
+	}
+
+	actorID := actor.ID
+	s.recordEvent(user, models.AccountLockEventUnlocked, "unlocked by "+string(actor.Role), nil, &actorID)
+	return nil
+}
+
+func (s *loginAttemptService) GetLockEvents(userID uint, actor models.User, skip, limit int) ([]models.AccountLockEvent, error) {
+	user, err := s.findManagedUser(userID, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.eventRepo.FindByUser(user.ID, skip, limit)
+}
+
+// findManagedUser restringe gestores à própria organização; super admins veem todas.
+func (s *loginAttemptService) findManagedUser(userID uint, actor models.User) (*models.User, error) {
+	var user *models.User
+	var err error
+	if actor.Role == models.RoleSuperAdmin {
+		user, err = s.userRepo.FindByIDUnscoped(userID)
+	} else {
+		user, err = s.userRepo.FindByID(userID, actor.OrganizationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *loginAttemptService) lock(ctx context.Context, key string, lockout time.Duration) error {
+	return s.cache.Set(ctx, key, time.Now().Add(lockout).Unix(), lockout)
+}
+
+// delay aplica 1s, 2s, 4s... (até maxLoginDelay) a partir da falha número "from".
+func (s *loginAttemptService) delay(ctx context.Context, key string, failures int64, from int64) error {
+	if failures < from {
+		return nil
+	}
+	wait := time.Second << uint(failures-from)
+	if wait > maxLoginDelay || wait <= 0 {
+		wait = maxLoginDelay
+	}
+	return s.cache.Set(ctx, key, time.Now().Add(wait).Unix(), wait)
+}
+
+func (s *loginAttemptService) recordEvent(user *models.User, eventType models.AccountLockEventType, reason string, clientIP *string, actorID *uint) {
+	event := &models.AccountLockEvent{
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Event:          eventType,
+		Reason:         reason,
+		IPAddress:      clientIP,
+		ActorID:        actorID,
+	}
+	if err := s.eventRepo.Create(event); err != nil {
+		logging.Logger.Error("Failed to record account lock event", zap.Error(err), zap.Uint("user_id", user.ID))
+	}
+}
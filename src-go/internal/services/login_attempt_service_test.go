@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// brokenCache simula o Redis fora do ar nas leituras.
+type brokenCache struct {
+	repositories.CacheRepository
+}
+
+func (c *brokenCache) Get(ctx context.Context, key string, dest interface{}) error {
+	return errors.New("connection refused")
+}
+
+func newLoginAttemptFixture(t *testing.T) (LoginAttemptService, *models.User, *models.User) {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Bloqueio")
+	user := createTestUser(t, gormDB, org.ID, "motorista@example.com", models.RoleDriver)
+	other := createTestOrganization(t, gormDB, "Transportes Vizinha")
+	outsider := createTestUser(t, gormDB, other.ID, "gestor@vizinha.com", models.RoleClienteAtivo)
+	service := NewLoginAttemptService(repositories.NewUnboundedMemoryCacheRepository(), repositories.NewUserRepository(gormDB), repositories.NewAccountLockEventRepository(gormDB))
+	return service, user, outsider
+}
+
+func throttled(t *testing.T, err error) *LoginThrottledError {
+	t.Helper()
+	var throttledErr *LoginThrottledError
+	if !errors.As(err, &throttledErr) {
+		t.Fatalf("got %v, want LoginThrottledError", err)
+	}
+	return throttledErr
+}
+
+func TestLoginFailuresAddProgressiveDelay(t *testing.T) {
+	service, user, _ := newLoginAttemptFixture(t)
+
+	if err := service.RegisterFailure(user.Email, "10.0.0.1", user); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Check(user.Email, "10.0.0.1"); err != nil {
+		t.Fatalf("after the first failure: %v", err)
+	}
+
+	if err := service.RegisterFailure(user.Email, "10.0.0.1", user); err != nil {
+		t.Fatal(err)
+	}
+	delay := throttled(t, service.Check(user.Email, "10.0.0.2"))
+	if delay.Locked || delay.RetryAfter <= 0 || delay.RetryAfter > time.Second {
+		t.Fatalf("after the second failure: %+v, want a delay of up to 1s", delay)
+	}
+
+	// O atraso é da conta: outra conta no mesmo IP segue liberada.
+	if err := service.Check("outro@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("other account: %v", err)
+	}
+
+	if err := service.RegisterSuccess(user.Email); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Check(user.Email, "10.0.0.1"); err != nil {
+		t.Fatalf("after a successful login: %v", err)
+	}
+}
+
+func TestLoginLockoutAndUnlock(t *testing.T) {
+	service, user, outsider := newLoginAttemptFixture(t)
+
+	for i := 0; i < 5; i++ {
+		if err := service.RegisterFailure(user.Email, "10.0.0.1", user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := throttled(t, service.Check(user.Email, "10.0.0.9"))
+	if !lock.Locked || lock.RetryAfter < 14*time.Minute {
+		t.Fatalf("after 5 failures: %+v, want a 15 minute lock", lock)
+	}
+
+	manager := models.User{ID: 999, OrganizationID: user.OrganizationID, Role: models.RoleClienteAtivo}
+	if err := service.UnlockAccount(user.ID, *outsider); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("manager of another organization: got %v, want ErrUserNotFound", err)
+	}
+	if err := service.UnlockAccount(user.ID, manager); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Check(user.Email, "10.0.0.9"); err != nil {
+		t.Fatalf("after unlock: %v", err)
+	}
+
+	events, err := service.GetLockEvents(user.ID, manager, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[models.AccountLockEventType]bool{}
+	for _, event := range events {
+		kinds[event.Event] = true
+	}
+	if len(events) != 2 || !kinds[models.AccountLockEventLocked] || !kinds[models.AccountLockEventUnlocked] {
+		t.Fatalf("events = %+v, want one lock and one unlock", events)
+	}
+}
+
+func TestLoginSourceLockout(t *testing.T) {
+	service, _, _ := newLoginAttemptFixture(t)
+
+	// Uma tentativa por conta: só o contador do IP chega ao limite.
+	for i := 0; i < 20; i++ {
+		if err := service.RegisterFailure(string(rune('a'+i))+"@example.com", "10.0.0.1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lock := throttled(t, service.Check("nova@example.com", "10.0.0.1")); !lock.Locked {
+		t.Fatalf("after 20 failures from the same ip: %+v, want locked", lock)
+	}
+	if err := service.Check("nova@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("other ip: %v", err)
+	}
+}
+
+func TestLoginCheckFailsClosedOnCacheError(t *testing.T) {
+	gormDB := newTestDB(t)
+	cache := &brokenCache{CacheRepository: repositories.NewUnboundedMemoryCacheRepository()}
+	service := NewLoginAttemptService(cache, repositories.NewUserRepository(gormDB), repositories.NewAccountLockEventRepository(gormDB))
+
+	err := service.Check("motorista@example.com", "10.0.0.1")
+	var throttledErr *LoginThrottledError
+	if err == nil || errors.As(err, &throttledErr) {
+		t.Fatalf("got %v, want the cache error", err)
+	}
+	if err := service.CheckBadge("CRACHA-1", ClientInfo{IP: "10.0.0.1", Device: "tablet"}); err == nil {
+		t.Fatal("badge check passed with the cache down")
+	}
+}
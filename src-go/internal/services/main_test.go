@@ -0,0 +1,112 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/db"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitLogger()
+	config.AppConfig = &config.Config{
+		JWT_SECRET:                          "test secret",
+		ACCESS_TOKEN_EXPIRE_MINUTES:         15,
+		REFRESH_TOKEN_EXPIRE_DAYS:           30,
+		FRONTEND_URL:                        "https://app.trucar.test",
+		RESET_PASSWORD_TOKEN_EXPIRE_MINUTES: 60,
+		PASSWORD_SETUP_TOKEN_EXPIRE_HOURS:   72,
+		EMAIL_VERIFICATION_EXPIRE_HOURS:     48,
+		EMAIL_REQUEST_MAX_PER_IP:            10,
+		EMAIL_REQUEST_MAX_PER_ADDRESS:       3,
+		EMAIL_REQUEST_WINDOW_MINUTES:        60,
+		PASSWORD_BCRYPT_COST:                4,
+		PASSWORD_MIN_LENGTH:                 10,
+		PASSWORD_HISTORY_SIZE:               2,
+		LOGIN_MAX_FAILED_ATTEMPTS:           5,
+		LOGIN_MAX_FAILED_ATTEMPTS_PER_IP:    20,
+		LOGIN_FAILURE_WINDOW_MINUTES:        15,
+		LOGIN_LOCKOUT_MINUTES:               15,
+		BADGE_PIN_MAX_FAILED_ATTEMPTS:       3,
+		ODOMETER_MAX_KM_PER_HOUR:            120,
+		ODOMETER_JUMP_TOLERANCE_KM:          1000,
+		ENGINE_HOURS_JUMP_TOLERANCE:         12,
+	}
+	os.Exit(m.Run())
+}
+
+// newTestDB abre um SQLite em arquivo temporário com o schema e o guard de
+// organização, como em produção. Sem fsync a migração leva milissegundos.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_sync=OFF&_journal=MEMORY&_busy_timeout=5000"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Migrate(gormDB)
+	repositories.RegisterTenantGuard(gormDB)
+	return gormDB
+}
+
+func createTestOrganization(t *testing.T, gormDB *gorm.DB, name string) *models.Organization {
+	t.Helper()
+	org := &models.Organization{Name: name, Sector: models.TransporteDeCargas}
+	if err := gormDB.Create(org).Error; err != nil {
+		t.Fatal(err)
+	}
+	return org
+}
+
+func createTestUser(t *testing.T, gormDB *gorm.DB, orgID uint, email string, role models.UserRole) *models.User {
+	t.Helper()
+	hashed, err := core.HashPassword("old password 123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Email: email, FullName: "Ana <Motorista>", HashedPassword: hashed, Role: role, IsActive: true, OrganizationID: orgID}
+	if err := repositories.NewUserRepository(gormDB).Create(user, 0); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func createTestVehicle(t *testing.T, gormDB *gorm.DB, orgID uint, currentKM int) *models.Vehicle {
+	t.Helper()
+	vehicle := &models.Vehicle{Brand: "Volvo", Model: "FH", Year: 2020, Status: models.StatusAvailable, CurrentKM: currentKM, OrganizationID: orgID}
+	if err := repositories.NewVehicleRepository(gormDB).Create(vehicle, 0); err != nil {
+		t.Fatal(err)
+	}
+	return vehicle
+}
+
+// sentMail é um e-mail capturado pelo testMailer.
+type sentMail struct {
+	to      []string
+	subject string
+	body    string
+}
+
+// testMailer substitui o mail.Sender. O envio pode acontecer em goroutine,
+// por isso os e-mails chegam por canal.
+type testMailer struct {
+	sent chan sentMail
+}
+
+func newTestMailer() *testMailer {
+	return &testMailer{sent: make(chan sentMail, 10)}
+}
+
+func (m *testMailer) Send(to []string, subject, htmlBody string) error {
+	m.sent <- sentMail{to: to, subject: subject, body: htmlBody}
+	return nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-api/internal/config"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+var ErrInvalidOdometerReading = errors.New("invalid odometer reading")
+var ErrOdometerRollback = errors.New("odometer reading is out of sequence")
+var ErrOdometerReadingNotFound = errors.New("odometer reading not found")
+var ErrOdometerReadingNotFlagged = errors.New("odometer reading is not flagged")
+
+type OdometerService interface {
+	Record(vehicle *models.Vehicle, reading *models.OdometerReading) error
+	RecordFuelLog(vehicle *models.Vehicle, fuelLog *models.FuelLog, actorID uint) error
+	MoveFuelLog(vehicle *models.Vehicle, fuelLog *models.FuelLog) error
+	RecordReading(vehicleID, orgID uint, readingIn schemas.OdometerReadingCreate, actorID uint) (*schemas.OdometerReadingPublic, error)
+	AcceptReading(vehicleID, readingID, orgID uint) (*schemas.OdometerReadingPublic, error)
+	GetTimeline(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.OdometerReadingPublic, error)
+}
+
+type odometerService struct {
+	repo        repositories.OdometerReadingRepository
+	vehicleRepo repositories.VehicleRepository
+	settings    OrganizationSettingsService
+	cache       vehicleCache
+}
+
+func NewOdometerService(repo repositories.OdometerReadingRepository, vehicleRepo repositories.VehicleRepository, settings OrganizationSettingsService, cache repositories.CacheRepository) OdometerService {
+	return &odometerService{repo: repo, vehicleRepo: vehicleRepo, settings: settings, cache: vehicleCache{cache: cache}}
+}
+
+// readingPoint é um valor de hodômetro ou horímetro em um instante.
+type readingPoint struct {
+	value float64
+	at    time.Time
+}
+
+// readingCheck reúne o resultado da validação de uma leitura.
+type readingCheck struct {
+	flags      []string
+	latestKM   bool
+	latestHour bool
+}
+
+// Record valida e grava a leitura. Leituras aceitas que sejam as mais
+// recentes atualizam CurrentKM/CurrentEngineHours do veículo na mesma gravação.
+func (s *odometerService) Record(vehicle *models.Vehicle, reading *models.OdometerReading) error {
+	result, err := s.check(vehicle, reading, false)
+	if err != nil {
+		return err
+	}
+	return s.save(vehicle, reading, result, nil)
+}
+
+// RecordFuelLog valida o hodômetro do abastecimento e grava os dois na mesma
+// transação, para que não fique abastecimento sem leitura nem o contrário.
+func (s *odometerService) RecordFuelLog(vehicle *models.Vehicle, fuelLog *models.FuelLog, actorID uint) error {
+	odometer := fuelLog.Odometer
+	reading := &models.OdometerReading{
+		VehicleID:      vehicle.ID,
+		Odometer:       &odometer,
+		Source:         models.OdometerSourceFuelLog,
+		RecordedAt:     time.Now(),
+		RecordedByID:   &actorID,
+		OrganizationID: fuelLog.OrganizationID,
+	}
+	result, err := s.check(vehicle, reading, false)
+	if err != nil {
+		return err
+	}
+	return s.save(vehicle, reading, result, fuelLog)
+}
+
+// MoveFuelLog grava o abastecimento que passou para outro veículo e leva a
+// leitura dele junto, validada contra o histórico do novo veículo. O veículo
+// anterior mantém CurrentKM, que só avança com leituras aceitas.
+func (s *odometerService) MoveFuelLog(vehicle *models.Vehicle, fuelLog *models.FuelLog) error {
+	reading, err := s.repo.FindBySource(models.OdometerSourceFuelLog, fuelLog.ID, fuelLog.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if reading == nil {
+		// Abastecimento anterior ao histórico de leituras.
+		odometer := fuelLog.Odometer
+		reading = &models.OdometerReading{
+			Odometer:       &odometer,
+			Source:         models.OdometerSourceFuelLog,
+			RecordedAt:     fuelLog.Timestamp,
+			RecordedByID:   &fuelLog.UserID,
+			OrganizationID: fuelLog.OrganizationID,
+		}
+	}
+	reading.VehicleID = vehicle.ID
+	result, err := s.check(vehicle, reading, false)
+	if err != nil {
+		return err
+	}
+	return s.save(vehicle, reading, result, fuelLog)
+}
+
+func (s *odometerService) RecordReading(vehicleID, orgID uint, readingIn schemas.OdometerReadingCreate, actorID uint) (*schemas.OdometerReadingPublic, error) {
+	vehicle, err := s.vehicleRepo.FindByID(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	source := models.OdometerReadingSource(readingIn.Source)
+	if source == "" {
+		source = models.OdometerSourceManual
+	}
+	if source != models.OdometerSourceManual && source != models.OdometerSourceTelemetry {
+		return nil, fmt.Errorf("%w: source must be manual or telemetry", ErrInvalidOdometerReading)
+	}
+	recordedAt := time.Now()
+	if readingIn.RecordedAt != nil {
+		if readingIn.RecordedAt.After(recordedAt) {
+			return nil, fmt.Errorf("%w: recorded_at is in the future", ErrInvalidOdometerReading)
+		}
+		recordedAt = *readingIn.RecordedAt
+	}
+
+	reading := &models.OdometerReading{
+		VehicleID:      vehicle.ID,
+		Odometer:       readingIn.Odometer,
+		EngineHours:    readingIn.EngineHours,
+		Source:         source,
+		RecordedAt:     recordedAt,
+		RecordedByID:   &actorID,
+		OrganizationID: orgID,
+	}
+	if err := s.Record(vehicle, reading); err != nil {
+		return nil, err
+	}
+	return s.toPublic(*reading, orgID)
+}
+
+// AcceptReading confirma uma leitura sinalizada. O salto deixa de ser
+// considerado, mas a ordem em relação às demais leituras continua valendo.
+func (s *odometerService) AcceptReading(vehicleID, readingID, orgID uint) (*schemas.OdometerReadingPublic, error) {
+	reading, err := s.repo.FindByID(readingID, vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if reading == nil {
+		return nil, ErrOdometerReadingNotFound
+	}
+	if reading.Status != models.OdometerReadingFlagged {
+		return nil, ErrOdometerReadingNotFlagged
+	}
+	vehicle, err := s.vehicleRepo.FindByID(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	flagReason := reading.FlagReason
+	result, err := s.check(vehicle, reading, true)
+	if err != nil {
+		return nil, err
+	}
+	// Mantém o motivo original para o histórico mostrar que houve revisão.
+	reading.FlagReason = flagReason
+	if err := s.save(vehicle, reading, result, nil); err != nil {
+		return nil, err
+	}
+	return s.toPublic(*reading, orgID)
+}
+
+// GetTimeline devolve as leituras do período, interpretado no fuso da organização.
+func (s *odometerService) GetTimeline(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.OdometerReadingPublic, error) {
+	vehicle, err := s.vehicleRepo.FindByID(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	settings, err := s.settings.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	dateFrom, dateTo := dateWindow(settings, dateRange)
+
+	readings, err := s.repo.FindByVehicle(vehicleID, orgID, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	timeline := make([]schemas.OdometerReadingPublic, 0, len(readings))
+	for _, reading := range readings {
+		timeline = append(timeline, toOdometerReadingPublic(reading, settings.Location()))
+	}
+	return timeline, nil
+}
+
+// check compara a leitura com as leituras aceitas vizinhas no tempo: valores
+// fora de ordem são rejeitados e saltos acima da taxa plausível sinalizados
+// (a menos que allowJump, usado na aceitação pelo gestor).
+func (s *odometerService) check(vehicle *models.Vehicle, reading *models.OdometerReading, allowJump bool) (*readingCheck, error) {
+	if reading.Odometer == nil && reading.EngineHours == nil {
+		return nil, fmt.Errorf("%w: odometer or engine_hours is required", ErrInvalidOdometerReading)
+	}
+	if (reading.Odometer != nil && *reading.Odometer < 0) || (reading.EngineHours != nil && *reading.EngineHours < 0) {
+		return nil, fmt.Errorf("%w: values cannot be negative", ErrInvalidOdometerReading)
+	}
+
+	result := &readingCheck{}
+	if reading.Odometer != nil {
+		var baseline *readingPoint
+		if vehicle.CurrentKM > 0 {
+			baseline = &readingPoint{value: float64(vehicle.CurrentKM), at: vehicle.CreatedAt}
+		}
+		latest, flag, err := s.checkSequence(vehicle, reading, "odometer", float64(*reading.Odometer), baseline,
+			func(r models.OdometerReading) float64 { return float64(*r.Odometer) },
+			config.AppConfig.ODOMETER_MAX_KM_PER_HOUR, config.AppConfig.ODOMETER_JUMP_TOLERANCE_KM)
+		if err != nil {
+			return nil, err
+		}
+		result.latestKM = latest
+		if flag != "" {
+			result.flags = append(result.flags, flag)
+		}
+	}
+	if reading.EngineHours != nil {
+		var baseline *readingPoint
+		if vehicle.CurrentEngineHours != nil {
+			baseline = &readingPoint{value: *vehicle.CurrentEngineHours, at: vehicle.CreatedAt}
+		}
+		// O motor não roda mais de uma hora por hora de relógio.
+		latest, flag, err := s.checkSequence(vehicle, reading, "engine_hours", *reading.EngineHours, baseline,
+			func(r models.OdometerReading) float64 { return *r.EngineHours },
+			1, config.AppConfig.ENGINE_HOURS_JUMP_TOLERANCE)
+		if err != nil {
+			return nil, err
+		}
+		result.latestHour = latest
+		if flag != "" {
+			result.flags = append(result.flags, flag)
+		}
+	}
+
+	reading.Status = models.OdometerReadingAccepted
+	reading.FlagReason = nil
+	if len(result.flags) > 0 && !allowJump {
+		reason := strings.Join(result.flags, "; ")
+		reading.Status = models.OdometerReadingFlagged
+		reading.FlagReason = &reason
+	}
+	return result, nil
+}
+
+// checkSequence valida um dos valores da leitura. Sem leituras anteriores, o
+// valor atual do veículo (desde o cadastro) serve de referência.
+func (s *odometerService) checkSequence(vehicle *models.Vehicle, reading *models.OdometerReading, column string, value float64, baseline *readingPoint, valueOf func(models.OdometerReading) float64, maxRate, tolerance float64) (bool, string, error) {
+	prev, next, err := s.repo.FindAdjacent(vehicle.ID, vehicle.OrganizationID, reading.RecordedAt, column, reading.ID)
+	if err != nil {
+		return false, "", err
+	}
+
+	var previous *readingPoint
+	if prev != nil {
+		previous = &readingPoint{value: valueOf(*prev), at: prev.RecordedAt}
+	} else if next == nil && baseline != nil && !reading.RecordedAt.Before(baseline.at) {
+		previous = baseline
+	}
+
+	if previous != nil && value < previous.value {
+		return false, "", fmt.Errorf("%w: %s %.1f is lower than the earlier reading %.1f", ErrOdometerRollback, column, value, previous.value)
+	}
+	if next != nil && value > valueOf(*next) {
+		return false, "", fmt.Errorf("%w: %s %.1f is higher than the later reading %.1f", ErrOdometerRollback, column, value, valueOf(*next))
+	}
+
+	flag := ""
+	if previous != nil {
+		elapsed := reading.RecordedAt.Sub(previous.at).Hours()
+		if allowed := maxRate*elapsed + tolerance; value-previous.value > allowed {
+			flag = fmt.Sprintf("%s jumped %.1f in %.1f hours (max %.1f)", column, value-previous.value, elapsed, allowed)
+		}
+	}
+	return next == nil, flag, nil
+}
+
+// save grava a leitura (com o abastecimento de origem, se houver) e, se ela
+// foi aceita e é a mais recente, os valores atuais do veículo.
+func (s *odometerService) save(vehicle *models.Vehicle, reading *models.OdometerReading, result *readingCheck, fuelLog *models.FuelLog) error {
+	var updated *models.Vehicle
+	if reading.Status == models.OdometerReadingAccepted && (result.latestKM || result.latestHour) {
+		if result.latestKM {
+			vehicle.CurrentKM = *reading.Odometer
+		}
+		if result.latestHour {
+			hours := *reading.EngineHours
+			vehicle.CurrentEngineHours = &hours
+		}
+		updated = vehicle
+	}
+	if err := s.repo.Save(reading, updated, fuelLog); err != nil {
+		return versionError(err)
+	}
+	if updated != nil {
+		s.cache.invalidate(vehicle.OrganizationID, vehicle.ID)
+	}
+	return nil
+}
+
+func (s *odometerService) toPublic(reading models.OdometerReading, orgID uint) (*schemas.OdometerReadingPublic, error) {
+	settings, err := s.settings.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	public := toOdometerReadingPublic(reading, settings.Location())
+	return &public, nil
+}
+
+func toOdometerReadingPublic(reading models.OdometerReading, loc *time.Location) schemas.OdometerReadingPublic {
+	public := schemas.OdometerReadingPublic{
+		ID:           reading.ID,
+		Odometer:     reading.Odometer,
+		EngineHours:  reading.EngineHours,
+		Source:       string(reading.Source),
+		SourceID:     reading.SourceID,
+		Status:       string(reading.Status),
+		FlagReason:   reading.FlagReason,
+		RecordedAt:   reading.RecordedAt.In(loc),
+		RecordedByID: reading.RecordedByID,
+	}
+	if reading.RecordedBy != nil {
+		public.RecordedByName = &reading.RecordedBy.FullName
+	}
+	return public
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+type odometerFixture struct {
+	db       *gorm.DB
+	orgID    uint
+	driver   *models.User
+	vehicles repositories.VehicleRepository
+	readings repositories.OdometerReadingRepository
+	service  OdometerService
+}
+
+func newOdometerFixture(t *testing.T) *odometerFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Hodômetro")
+	driver := createTestUser(t, gormDB, org.ID, "motorista@example.com", models.RoleDriver)
+
+	vehicles := repositories.NewVehicleRepository(gormDB)
+	readings := repositories.NewOdometerReadingRepository(gormDB)
+	settings := NewOrganizationSettingsService(repositories.NewOrganizationSettingsRepository(gormDB), repositories.NewOrganizationRepository(gormDB))
+	service := NewOdometerService(readings, vehicles, settings, repositories.NewUnboundedMemoryCacheRepository())
+	return &odometerFixture{db: gormDB, orgID: org.ID, driver: driver, vehicles: vehicles, readings: readings, service: service}
+}
+
+// record grava uma leitura manual de km feita "ago" atrás.
+func (f *odometerFixture) record(vehicleID uint, km int, ago time.Duration) (*schemas.OdometerReadingPublic, error) {
+	at := time.Now().Add(-ago)
+	return f.service.RecordReading(vehicleID, f.orgID, schemas.OdometerReadingCreate{Odometer: &km, RecordedAt: &at}, f.driver.ID)
+}
+
+func (f *odometerFixture) currentKM(t *testing.T, vehicleID uint) int {
+	t.Helper()
+	vehicle, err := f.vehicles.FindByID(vehicleID, f.orgID)
+	if err != nil || vehicle == nil {
+		t.Fatalf("reload vehicle: %v, %v", vehicle, err)
+	}
+	return vehicle.CurrentKM
+}
+
+func (f *odometerFixture) timeline(t *testing.T, vehicleID uint) []int {
+	t.Helper()
+	readings, err := f.service.GetTimeline(vehicleID, f.orgID, schemas.DateRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make([]int, 0, len(readings))
+	for _, reading := range readings {
+		values = append(values, *reading.Odometer)
+	}
+	return values
+}
+
+func TestOdometerReadingsAreValidatedInTimeOrder(t *testing.T) {
+	f := newOdometerFixture(t)
+	vehicle := createTestVehicle(t, f.db, f.orgID, 0)
+
+	for _, step := range []struct {
+		km  int
+		ago time.Duration
+	}{{1000, 10 * time.Hour}, {1500, 2 * time.Hour}} {
+		if _, err := f.record(vehicle.ID, step.km, step.ago); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Leitura atrasada entre as duas existentes: aceita, mas não é a mais recente.
+	between, err := f.record(vehicle.ID, 1200, 6*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if between.Status != string(models.OdometerReadingAccepted) {
+		t.Fatalf("reading in between: status %s", between.Status)
+	}
+	if km := f.currentKM(t, vehicle.ID); km != 1500 {
+		t.Fatalf("current km = %d, want 1500", km)
+	}
+
+	for _, step := range []struct {
+		name string
+		km   int
+		ago  time.Duration
+	}{
+		{"lower than the earlier reading", 900, 8 * time.Hour},
+		{"higher than the later reading", 1600, 4 * time.Hour},
+		{"lower than the latest reading", 1400, 0},
+	} {
+		if _, err := f.record(vehicle.ID, step.km, step.ago); !errors.Is(err, ErrOdometerRollback) {
+			t.Errorf("%s: got %v, want ErrOdometerRollback", step.name, err)
+		}
+	}
+
+	if got := f.timeline(t, vehicle.ID); len(got) != 3 || got[0] != 1000 || got[1] != 1200 || got[2] != 1500 {
+		t.Fatalf("timeline = %v, want [1000 1200 1500]", got)
+	}
+}
+
+func TestOdometerRejectsReadingBelowVehicleKM(t *testing.T) {
+	f := newOdometerFixture(t)
+	vehicle := createTestVehicle(t, f.db, f.orgID, 5000)
+
+	if _, err := f.record(vehicle.ID, 4000, 0); !errors.Is(err, ErrOdometerRollback) {
+		t.Fatalf("got %v, want ErrOdometerRollback", err)
+	}
+	if _, err := f.record(vehicle.ID, 5200, 0); err != nil {
+		t.Fatal(err)
+	}
+	if km := f.currentKM(t, vehicle.ID); km != 5200 {
+		t.Fatalf("current km = %d, want 5200", km)
+	}
+}
+
+func TestOdometerJumpIsFlaggedUntilAccepted(t *testing.T) {
+	f := newOdometerFixture(t)
+	vehicle := createTestVehicle(t, f.db, f.orgID, 0)
+
+	if _, err := f.record(vehicle.ID, 1000, 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// 2h a 120 km/h mais a tolerância de 1000 km permitem até 1240 km.
+	jump, err := f.record(vehicle.ID, 5000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jump.Status != string(models.OdometerReadingFlagged) || jump.FlagReason == nil {
+		t.Fatalf("jump: status %s, want flagged", jump.Status)
+	}
+	if km := f.currentKM(t, vehicle.ID); km != 1000 {
+		t.Fatalf("current km after the jump = %d, want 1000", km)
+	}
+
+	// A leitura sinalizada não entra na validação das seguintes.
+	if _, err := f.record(vehicle.ID, 1100, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	accepted, err := f.service.AcceptReading(vehicle.ID, jump.ID, f.orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != string(models.OdometerReadingAccepted) || accepted.FlagReason == nil {
+		t.Fatalf("accepted: status %s, flag reason %v, want accepted with the original reason", accepted.Status, accepted.FlagReason)
+	}
+	if km := f.currentKM(t, vehicle.ID); km != 5000 {
+		t.Fatalf("current km after accepting = %d, want 5000", km)
+	}
+
+	if _, err := f.service.AcceptReading(vehicle.ID, jump.ID, f.orgID); !errors.Is(err, ErrOdometerReadingNotFlagged) {
+		t.Fatalf("accept twice: got %v, want ErrOdometerReadingNotFlagged", err)
+	}
+	if _, err := f.service.AcceptReading(vehicle.ID, jump.ID+100, f.orgID); !errors.Is(err, ErrOdometerReadingNotFound) {
+		t.Fatalf("unknown reading: got %v, want ErrOdometerReadingNotFound", err)
+	}
+}
+
+func TestAcceptReadingKeepsSequence(t *testing.T) {
+	f := newOdometerFixture(t)
+	vehicle := createTestVehicle(t, f.db, f.orgID, 0)
+
+	if _, err := f.record(vehicle.ID, 1000, 3*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	jump, err := f.record(vehicle.ID, 5000, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Depois da sinalização chega uma leitura posterior menor que o salto.
+	if _, err := f.record(vehicle.ID, 1200, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.AcceptReading(vehicle.ID, jump.ID, f.orgID); !errors.Is(err, ErrOdometerRollback) {
+		t.Fatalf("got %v, want ErrOdometerRollback", err)
+	}
+}
+
+func TestRecordFuelLogSavesReadingInSameTransaction(t *testing.T) {
+	f := newOdometerFixture(t)
+	vehicle := createTestVehicle(t, f.db, f.orgID, 0)
+	transaction := "TX-1"
+
+	first := &models.FuelLog{Odometer: 1000, Liters: 50, TotalCost: 300, VehicleID: vehicle.ID, UserID: f.driver.ID, ProviderTransactionID: &transaction, OrganizationID: f.orgID}
+	if err := f.service.RecordFuelLog(vehicle, first, f.driver.ID); err != nil {
+		t.Fatal(err)
+	}
+	reading, err := f.readings.FindBySource(models.OdometerSourceFuelLog, first.ID, f.orgID)
+	if err != nil || reading == nil || *reading.Odometer != 1000 {
+		t.Fatalf("fuel log reading = %+v, %v", reading, err)
+	}
+
+	// O abastecimento duplicado falha e leva a leitura junto.
+	vehicle, _ = f.vehicles.FindByID(vehicle.ID, f.orgID)
+	duplicate := &models.FuelLog{Odometer: 1100, Liters: 50, TotalCost: 300, VehicleID: vehicle.ID, UserID: f.driver.ID, ProviderTransactionID: &transaction, OrganizationID: f.orgID}
+	if err := f.service.RecordFuelLog(vehicle, duplicate, f.driver.ID); err == nil {
+		t.Fatal("duplicate fuel log was saved")
+	}
+	if got := f.timeline(t, vehicle.ID); len(got) != 1 {
+		t.Fatalf("timeline = %v, want only the first reading", got)
+	}
+	if km := f.currentKM(t, vehicle.ID); km != 1000 {
+		t.Fatalf("current km = %d, want 1000", km)
+	}
+
+	// Hodômetro fora de ordem não grava o abastecimento.
+	vehicle, _ = f.vehicles.FindByID(vehicle.ID, f.orgID)
+	rollback := &models.FuelLog{Odometer: 900, Liters: 50, TotalCost: 300, VehicleID: vehicle.ID, UserID: f.driver.ID, OrganizationID: f.orgID}
+	if err := f.service.RecordFuelLog(vehicle, rollback, f.driver.ID); !errors.Is(err, ErrOdometerRollback) {
+		t.Fatalf("got %v, want ErrOdometerRollback", err)
+	}
+	if rollback.ID != 0 {
+		t.Fatal("fuel log saved with a rejected reading")
+	}
+}
+
+func TestMoveFuelLogCarriesReading(t *testing.T) {
+	f := newOdometerFixture(t)
+	origin := createTestVehicle(t, f.db, f.orgID, 0)
+	ahead := createTestVehicle(t, f.db, f.orgID, 0)
+	target := createTestVehicle(t, f.db, f.orgID, 0)
+
+	fuelLog := &models.FuelLog{Odometer: 2000, Liters: 50, TotalCost: 300, VehicleID: origin.ID, UserID: f.driver.ID, OrganizationID: f.orgID}
+	if err := f.service.RecordFuelLog(origin, fuelLog, f.driver.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.record(ahead.ID, 3000, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// O histórico do novo veículo vale para a leitura movida.
+	fuelLog.VehicleID = ahead.ID
+	if err := f.service.MoveFuelLog(ahead, fuelLog); !errors.Is(err, ErrOdometerRollback) {
+		t.Fatalf("move behind the vehicle km: got %v, want ErrOdometerRollback", err)
+	}
+
+	fuelLog.VehicleID = target.ID
+	if err := f.service.MoveFuelLog(target, fuelLog); err != nil {
+		t.Fatal(err)
+	}
+	reading, err := f.readings.FindBySource(models.OdometerSourceFuelLog, fuelLog.ID, f.orgID)
+	if err != nil || reading == nil || reading.VehicleID != target.ID {
+		t.Fatalf("moved reading = %+v, %v", reading, err)
+	}
+	if got := f.timeline(t, origin.ID); len(got) != 0 {
+		t.Fatalf("origin timeline = %v, want empty", got)
+	}
+	if km := f.currentKM(t, target.ID); km != 2000 {
+		t.Fatalf("target current km = %d, want 2000", km)
+	}
+	// O veículo anterior mantém o km que já tinha.
+	if km := f.currentKM(t, origin.ID); km != 2000 {
+		t.Fatalf("origin current km = %d, want 2000", km)
+	}
+
+	var stored models.FuelLog
+	if err := f.db.Scopes(repositories.ForOrganization(f.orgID)).First(&stored, fuelLog.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.VehicleID != target.ID {
+		t.Fatalf("fuel log vehicle = %d, want %d", stored.VehicleID, target.ID)
+	}
+}
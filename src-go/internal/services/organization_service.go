@@ -1,28 +1,61 @@
 package services
 
 import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/logging"
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
+	"go-api/internal/storage"
 )
 
+var ErrInvalidLimit = errors.New("plan limits cannot be negative")
+var ErrOrganizationNotFound = errors.New("organization not found")
+var ErrInvalidOrganizationStatus = errors.New("invalid organization status")
+var ErrOrganizationStatusConflict = errors.New("organization is not in a state that allows this change")
+var ErrOrganizationHasSuperAdmin = errors.New("organizations with super admins cannot be suspended or deleted")
+
 type OrganizationService interface {
 	GetOrganizations(skip, limit int, status *string) ([]models.Organization, error)
 	UpdateOrganization(orgID uint, orgIn schemas.OrganizationUpdate) (*models.Organization, error)
+	GetOrganization(orgID uint) (*models.Organization, error)
+	CreateOrganization(orgIn schemas.OrganizationCreate) (*models.Organization, *models.User, error)
+	SuspendOrganization(orgID uint, reason string) (*models.Organization, error)
+	ReactivateOrganization(orgID uint) (*models.Organization, error)
+	ScheduleDeletion(orgID uint) (*models.Organization, error)
+	PurgeDueOrganizations(now time.Time) (int, error)
 }
 
 type organizationService struct {
-	repo repositories.OrganizationRepository
+	repo           repositories.OrganizationRepository
+	userRepo       repositories.UserRepository
+	passwordPolicy PasswordPolicyService
+	storage        storage.FileStorageService
 }
 
-func NewOrganizationService(repo repositories.OrganizationRepository) OrganizationService {
-	return &organizationService{repo: repo}
+func NewOrganizationService(repo repositories.OrganizationRepository, userRepo repositories.UserRepository, passwordPolicy PasswordPolicyService, storage storage.FileStorageService) OrganizationService {
+	return &organizationService{repo: repo, userRepo: userRepo, passwordPolicy: passwordPolicy, storage: storage}
 }
 
 func (s *organizationService) GetOrganizations(skip, limit int, status *string) ([]models.Organization, error) {
+	if status != nil && *status != "" && !models.IsValidOrganizationStatus(models.OrganizationStatus(*status)) {
+		return nil, ErrInvalidOrganizationStatus
+	}
 	return s.repo.FindAll(skip, limit, status)
 }
 
+func (s *organizationService) GetOrganization(orgID uint) (*models.Organization, error) {
+	return s.repo.FindByID(orgID)
+}
+
 func (s *organizationService) UpdateOrganization(orgID uint, orgIn schemas.OrganizationUpdate) (*models.Organization, error) {
 	org, err := s.repo.FindByID(orgID)
 	if err != nil {
@@ -38,6 +71,195 @@ func (s *organizationService) UpdateOrganization(orgID uint, orgIn schemas.Organ
 	if orgIn.Sector != "" {
 		org.Sector = models.Sector(orgIn.Sector)
 	}
+	if orgIn.RequireTwoFactorForManagers != nil {
+		org.RequireTwoFactorForManagers = *orgIn.RequireTwoFactorForManagers
+	}
+
+	limits := []struct {
+		value  *int
+		target *int
+	}{
+		{orgIn.VehicleLimit, &org.VehicleLimit},
+		{orgIn.DriverLimit, &org.DriverLimit},
+		{orgIn.JourneyMonthlyLimit, &org.JourneyMonthlyLimit},
+		{orgIn.FreightOrderMonthlyLimit, &org.FreightOrderMonthlyLimit},
+		{orgIn.DocumentMonthlyLimit, &org.DocumentMonthlyLimit},
+	}
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		if *limit.value < 0 {
+			return nil, ErrInvalidLimit
+		}
+		*limit.target = *limit.value
+	}
+
+	return s.repo.Update(org)
+}
+
+// CreateOrganization abre uma organização ativa (sem demonstração) com o
+// primeiro gestor, que já entra sem confirmar o e-mail.
+func (s *organizationService) CreateOrganization(orgIn schemas.OrganizationCreate) (*models.Organization, *models.User, error) {
+	sector := models.Sector(orgIn.Sector)
+	if !models.IsValidSector(sector) {
+		return nil, nil, ErrInvalidSector
+	}
+
+	email := models.NormalizeEmail(orgIn.Manager.Email)
+	existing, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		return nil, nil, ErrEmailAlreadyRegistered
+	}
+
+	manager := &models.User{
+		FullName:   strings.TrimSpace(orgIn.Manager.FullName),
+		Email:      email,
+		EmployeeID: models.FormatEmployeeID(1),
+		Role:       models.RoleClienteAtivo,
+		IsActive:   true,
+	}
+	if err := s.passwordPolicy.SetPassword(manager, orgIn.Manager.Password); err != nil {
+		return nil, nil, err
+	}
+
+	code, err := core.GenerateOrganizationCode()
+	if err != nil {
+		return nil, nil, err
+	}
+	org := &models.Organization{
+		Name:             strings.TrimSpace(orgIn.Name),
+		Sector:           sector,
+		Code:             &code,
+		EmployeeSequence: 1,
+		Status:           models.OrganizationStatusActive,
+	}
+	if err := s.repo.CreateWithOwner(org, manager); err != nil {
+		return nil, nil, err
+	}
+	return org, manager, nil
+}
+
+func (s *organizationService) SuspendOrganization(orgID uint, reason string) (*models.Organization, error) {
+	org, err := s.findForLifecycleChange(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.Status != models.OrganizationStatusActive {
+		return nil, ErrOrganizationStatusConflict
+	}
+
+	now := time.Now()
+	org.Status = models.OrganizationStatusSuspended
+	org.SuspendedAt = &now
+	org.SuspensionReason = nil
+	if reason = strings.TrimSpace(reason); reason != "" {
+		org.SuspensionReason = &reason
+	}
+	return s.repo.Update(org)
+}
+
+// ReactivateOrganization vale tanto para suspensas quanto para as que têm a
+// exclusão agendada, enquanto os dados ainda não foram apagados.
+func (s *organizationService) ReactivateOrganization(orgID uint) (*models.Organization, error) {
+	org, err := s.findOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.Status == models.OrganizationStatusActive {
+		return nil, ErrOrganizationStatusConflict
+	}
+
+	org.Status = models.OrganizationStatusActive
+	org.SuspendedAt = nil
+	org.SuspensionReason = nil
+	org.DeletionScheduledAt = nil
+	return s.repo.Update(org)
+}
+
+// ScheduleDeletion bloqueia o acesso imediatamente e marca a remoção dos dados
+// para depois do prazo de carência.
+func (s *organizationService) ScheduleDeletion(orgID uint) (*models.Organization, error) {
+	org, err := s.findForLifecycleChange(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.Status == models.OrganizationStatusPendingDeletion {
+		return nil, ErrOrganizationStatusConflict
+	}
 
+	deleteAt := time.Now().AddDate(0, 0, config.AppConfig.ORGANIZATION_DELETION_GRACE_DAYS)
+	org.Status = models.OrganizationStatusPendingDeletion
+	org.DeletionScheduledAt = &deleteAt
 	return s.repo.Update(org)
 }
+
+// PurgeDueOrganizations apaga as organizações cujo prazo de carência acabou.
+// Falhas ao remover arquivos só são registradas: os dados já foram apagados.
+func (s *organizationService) PurgeDueOrganizations(now time.Time) (int, error) {
+	orgs, err := s.repo.FindDueForDeletion(now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, org := range orgs {
+		files, ok, err := s.repo.Purge(org.ID, now)
+		if err != nil {
+			return purged, err
+		}
+		if !ok {
+			// Reativada (ou reagendada) depois da busca.
+			continue
+		}
+		for _, file := range files {
+			if err := s.storage.Delete(file); err != nil {
+				logging.Logger.Warn("Failed to delete file of purged organization", zap.Uint("organization_id", org.ID), zap.String("file", file), zap.Error(err))
+			}
+		}
+		logging.Logger.Info("Organization purged", zap.Uint("organization_id", org.ID), zap.Int("files", len(files)))
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *organizationService) findOrganization(orgID uint) (*models.Organization, error) {
+	org, err := s.repo.FindByID(orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, err
+}
+
+// findForLifecycleChange recusa a organização do próprio super admin, que
+// perderia o acesso (ou os dados) junto com os demais usuários.
+func (s *organizationService) findForLifecycleChange(orgID uint) (*models.Organization, error) {
+	org, err := s.findOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	superAdmins, err := s.userRepo.CountByRole(orgID, models.RoleSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if superAdmins > 0 {
+		return nil, ErrOrganizationHasSuperAdmin
+	}
+	return org, nil
+}
+
+// RunOrganizationPurge executa PurgeDueOrganizations periodicamente; é
+// iniciado em uma goroutine pelo main.
+func RunOrganizationPurge(service OrganizationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := service.PurgeDueOrganizations(time.Now()); err != nil {
+			logging.Logger.Error("Failed to purge organizations scheduled for deletion", zap.Error(err))
+		}
+		<-ticker.C
+	}
+}
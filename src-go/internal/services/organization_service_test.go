@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// recordingStorage guarda os arquivos que o serviço mandou apagar.
+type recordingStorage struct {
+	deleted []string
+}
+
+func (s *recordingStorage) Save(*multipart.FileHeader, string) (string, error) { return "", nil }
+func (s *recordingStorage) SaveContent(io.Reader, string, string) (string, error) {
+	return "", nil
+}
+func (s *recordingStorage) Size(string) (int64, error) { return 0, nil }
+func (s *recordingStorage) Delete(filePath string) error {
+	s.deleted = append(s.deleted, filePath)
+	return nil
+}
+
+// staleOrganizations devolve a lista de exclusões vencidas lida antes de a
+// organização ser reativada, como se a reativação chegasse durante o purge.
+type staleOrganizations struct {
+	repositories.OrganizationRepository
+	due []models.Organization
+}
+
+func (r *staleOrganizations) FindDueForDeletion(time.Time) ([]models.Organization, error) {
+	return r.due, nil
+}
+
+type organizationFixture struct {
+	db      *gorm.DB
+	repo    repositories.OrganizationRepository
+	storage *recordingStorage
+	service OrganizationService
+	org     *models.Organization
+}
+
+func newOrganizationFixture(t *testing.T) *organizationFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Ciclo")
+	manager := createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+	avatar := "avatars/gestor.png"
+	if err := gormDB.Model(manager).Scopes(repositories.ForOrganization(org.ID)).UpdateColumn("avatar_url", avatar).Error; err != nil {
+		t.Fatal(err)
+	}
+	repo := repositories.NewOrganizationRepository(gormDB)
+	storage := &recordingStorage{}
+	service := NewOrganizationService(repo, repositories.NewUserRepository(gormDB),
+		NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB)), storage)
+	return &organizationFixture{db: gormDB, repo: repo, storage: storage, service: service, org: org}
+}
+
+func (f *organizationFixture) status(t *testing.T) models.OrganizationStatus {
+	t.Helper()
+	org, err := f.repo.FindByID(f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return org.Status
+}
+
+func TestSuspendAndReactivateOrganization(t *testing.T) {
+	f := newOrganizationFixture(t)
+
+	if _, err := f.service.ReactivateOrganization(f.org.ID); !errors.Is(err, ErrOrganizationStatusConflict) {
+		t.Fatalf("reactivate active: got %v, want ErrOrganizationStatusConflict", err)
+	}
+	org, err := f.service.SuspendOrganization(f.org.ID, "  inadimplência ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.SuspendedAt == nil || org.SuspensionReason == nil || *org.SuspensionReason != "inadimplência" {
+		t.Fatalf("suspension not recorded: %+v", org)
+	}
+	if f.status(t) != models.OrganizationStatusSuspended {
+		t.Fatalf("status = %s, want suspended", f.status(t))
+	}
+	if _, err := f.service.SuspendOrganization(f.org.ID, ""); !errors.Is(err, ErrOrganizationStatusConflict) {
+		t.Fatalf("suspend twice: got %v, want ErrOrganizationStatusConflict", err)
+	}
+
+	org, err = f.service.ReactivateOrganization(f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.SuspendedAt != nil || org.SuspensionReason != nil || f.status(t) != models.OrganizationStatusActive {
+		t.Fatalf("reactivation left the suspension behind: %+v", org)
+	}
+	if _, err := f.service.SuspendOrganization(999, ""); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("unknown organization: got %v, want ErrOrganizationNotFound", err)
+	}
+
+	platform := createTestOrganization(t, f.db, "Plataforma")
+	createTestUser(t, f.db, platform.ID, "admin@example.com", models.RoleSuperAdmin)
+	if _, err := f.service.SuspendOrganization(platform.ID, ""); !errors.Is(err, ErrOrganizationHasSuperAdmin) {
+		t.Fatalf("organization of a super admin: got %v, want ErrOrganizationHasSuperAdmin", err)
+	}
+	if _, err := f.service.ScheduleDeletion(platform.ID); !errors.Is(err, ErrOrganizationHasSuperAdmin) {
+		t.Fatalf("delete organization of a super admin: got %v, want ErrOrganizationHasSuperAdmin", err)
+	}
+}
+
+func TestPurgeDueOrganizations(t *testing.T) {
+	f := newOrganizationFixture(t)
+	org, err := f.service.ScheduleDeletion(f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.ScheduleDeletion(f.org.ID); !errors.Is(err, ErrOrganizationStatusConflict) {
+		t.Fatalf("schedule twice: got %v, want ErrOrganizationStatusConflict", err)
+	}
+
+	purged, err := f.service.PurgeDueOrganizations(org.DeletionScheduledAt.Add(-time.Minute))
+	if err != nil || purged != 0 {
+		t.Fatalf("before the grace period: purged %d, %v", purged, err)
+	}
+
+	purged, err = f.service.PurgeDueOrganizations(org.DeletionScheduledAt.Add(time.Minute))
+	if err != nil || purged != 1 {
+		t.Fatalf("after the grace period: purged %d, %v", purged, err)
+	}
+	if len(f.storage.deleted) != 1 || f.storage.deleted[0] != "avatars/gestor.png" {
+		t.Errorf("deleted files = %v, want the manager's avatar", f.storage.deleted)
+	}
+	if _, err := f.repo.FindByID(f.org.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("organization still there: %v", err)
+	}
+	var users int64
+	if err := f.db.Model(&models.User{}).Scopes(repositories.AllOrganizations).Count(&users).Error; err != nil || users != 0 {
+		t.Fatalf("%d users left, %v", users, err)
+	}
+}
+
+func TestPurgeSkipsOrganizationReactivatedMeanwhile(t *testing.T) {
+	f := newOrganizationFixture(t)
+	org, err := f.service.ScheduleDeletion(f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := org.DeletionScheduledAt.Add(time.Minute)
+	due, err := f.repo.FindDueForDeletion(now)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("due = %v, %v", due, err)
+	}
+	if _, err := f.service.ReactivateOrganization(f.org.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := &staleOrganizations{OrganizationRepository: f.repo, due: due}
+	service := NewOrganizationService(stale, repositories.NewUserRepository(f.db),
+		NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(f.db)), f.storage)
+	purged, err := service.PurgeDueOrganizations(now)
+	if err != nil || purged != 0 {
+		t.Fatalf("purged %d, %v; want the reactivated organization kept", purged, err)
+	}
+	if len(f.storage.deleted) != 0 {
+		t.Errorf("deleted files = %v, want none", f.storage.deleted)
+	}
+	if f.status(t) != models.OrganizationStatusActive {
+		t.Fatalf("status = %s, want active", f.status(t))
+	}
+}
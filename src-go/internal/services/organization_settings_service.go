@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+var ErrInvalidTimeZone = errors.New("time_zone must be an IANA time zone name such as America/Sao_Paulo")
+var ErrInvalidCurrency = errors.New("currency must be an ISO 4217 code such as BRL")
+var ErrInvalidUsageUnit = errors.New("usage_unit must be km or engine_hours")
+var ErrInvalidLocale = errors.New("locale must be a BCP 47 language tag such as pt-BR")
+var ErrInvalidFiscalYearStart = errors.New("fiscal_year_start_month must be between 1 and 12")
+
+// settingsValidator confere moeda e idioma com as tabelas do validator já
+// usado no binding: a lista ISO 4217 de moedas e o parser de tags BCP 47.
+var settingsValidator = validator.New()
+
+// OrganizationSettingsService expõe as preferências regionais da organização.
+// Os demais serviços usam Now e Settings para calcular janelas de datas no
+// fuso da organização em vez do fuso do servidor.
+type OrganizationSettingsService interface {
+	Settings(orgID uint) (*models.OrganizationSettings, error)
+	GetSettings(orgID uint) (*schemas.OrganizationSettingsPublic, error)
+	UpdateSettings(orgID uint, settingsIn schemas.OrganizationSettingsUpdate) (*schemas.OrganizationSettingsPublic, error)
+	Now(orgID uint) (time.Time, error)
+}
+
+type organizationSettingsService struct {
+	repo    repositories.OrganizationSettingsRepository
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewOrganizationSettingsService(repo repositories.OrganizationSettingsRepository, orgRepo repositories.OrganizationRepository) OrganizationSettingsService {
+	return &organizationSettingsService{repo: repo, orgRepo: orgRepo}
+}
+
+func (s *organizationSettingsService) Settings(orgID uint) (*models.OrganizationSettings, error) {
+	settings, err := s.repo.FindByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return models.DefaultOrganizationSettings(orgID, org.Sector), nil
+}
+
+func (s *organizationSettingsService) GetSettings(orgID uint) (*schemas.OrganizationSettingsPublic, error) {
+	settings, err := s.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return toSettingsPublic(settings), nil
+}
+
+func (s *organizationSettingsService) UpdateSettings(orgID uint, settingsIn schemas.OrganizationSettingsUpdate) (*schemas.OrganizationSettingsPublic, error) {
+	settings, err := s.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settingsIn.TimeZone != nil {
+		name := strings.TrimSpace(*settingsIn.TimeZone)
+		// "Local" dependeria do fuso do servidor, justamente o que se quer evitar.
+		if _, err := time.LoadLocation(name); err != nil || name == "" || name == "Local" {
+			return nil, ErrInvalidTimeZone
+		}
+		settings.TimeZone = name
+	}
+	if settingsIn.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*settingsIn.Currency))
+		if settingsValidator.Var(currency, "required,iso4217") != nil {
+			return nil, ErrInvalidCurrency
+		}
+		settings.Currency = currency
+	}
+	if settingsIn.UsageUnit != nil {
+		unit := models.UsageUnit(*settingsIn.UsageUnit)
+		if !models.IsValidUsageUnit(unit) {
+			return nil, ErrInvalidUsageUnit
+		}
+		settings.UsageUnit = unit
+	}
+	if settingsIn.Locale != nil {
+		locale := strings.TrimSpace(*settingsIn.Locale)
+		// O parser também aceita "pt_BR", que não é BCP 47 e quebraria no front.
+		if strings.Contains(locale, "_") || settingsValidator.Var(locale, "required,bcp47_language_tag") != nil {
+			return nil, ErrInvalidLocale
+		}
+		settings.Locale = locale
+	}
+	if settingsIn.FiscalYearStartMonth != nil {
+		month := *settingsIn.FiscalYearStartMonth
+		if month < 1 || month > 12 {
+			return nil, ErrInvalidFiscalYearStart
+		}
+		settings.FiscalYearStartMonth = month
+	}
+
+	if err := s.repo.Save(settings); err != nil {
+		return nil, err
+	}
+	return toSettingsPublic(settings), nil
+}
+
+// Now devolve o horário atual no fuso da organização.
+func (s *organizationSettingsService) Now(orgID uint) (time.Time, error) {
+	settings, err := s.Settings(orgID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(settings.Location()), nil
+}
+
+func toSettingsPublic(settings *models.OrganizationSettings) *schemas.OrganizationSettingsPublic {
+	return &schemas.OrganizationSettingsPublic{
+		TimeZone:               settings.TimeZone,
+		Currency:               settings.Currency,
+		UsageUnit:              string(settings.UsageUnit),
+		Locale:                 settings.Locale,
+		FiscalYearStartMonth:   settings.FiscalYearStartMonth,
+		CurrentFiscalYearStart: settings.FiscalYearStart(time.Now()),
+	}
+}
+
+// dateWindow converte o DateRange em instantes no fuso da organização: as
+// datas viram a meia-noite local e o ano fiscal vai do mês de início até o
+// mesmo mês do ano seguinte.
+func dateWindow(settings *models.OrganizationSettings, dateRange schemas.DateRange) (from, to *time.Time) {
+	if dateRange.FiscalYear != nil {
+		start := settings.FiscalYearBeginning(*dateRange.FiscalYear)
+		end := start.AddDate(1, 0, 0)
+		return &start, &end
+	}
+	if dateRange.From != nil {
+		start := settings.StartOfDay(*dateRange.From)
+		from = &start
+	}
+	if dateRange.To != nil {
+		end := settings.StartOfDay(*dateRange.To)
+		to = &end
+	}
+	return from, to
+}
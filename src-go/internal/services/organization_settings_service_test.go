@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+func TestDateWindow(t *testing.T) {
+	settings := models.DefaultOrganizationSettings(1, models.TransporteDeCargas)
+	settings.TimeZone = "America/Manaus"
+	settings.FiscalYearStartMonth = 4
+	manaus := settings.Location()
+
+	day := func(year int, month time.Month, d int) *time.Time {
+		date := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	year := 2025
+	tests := []struct {
+		name     string
+		in       schemas.DateRange
+		from, to *time.Time
+	}{
+		{"empty", schemas.DateRange{}, nil, nil},
+		{"dates at local midnight", schemas.DateRange{From: day(2025, 1, 10), To: day(2025, 1, 11)},
+			timePtr(time.Date(2025, 1, 10, 0, 0, 0, 0, manaus)), timePtr(time.Date(2025, 1, 11, 0, 0, 0, 0, manaus))},
+		{"open end", schemas.DateRange{From: day(2025, 1, 10)}, timePtr(time.Date(2025, 1, 10, 0, 0, 0, 0, manaus)), nil},
+		{"fiscal year", schemas.DateRange{FiscalYear: &year},
+			timePtr(time.Date(2025, 4, 1, 0, 0, 0, 0, manaus)), timePtr(time.Date(2026, 4, 1, 0, 0, 0, 0, manaus))},
+	}
+	for _, tt := range tests {
+		from, to := dateWindow(settings, tt.in)
+		if !sameInstant(from, tt.from) || !sameInstant(to, tt.to) {
+			t.Errorf("%s: got [%v, %v), want [%v, %v)", tt.name, from, to, tt.from, tt.to)
+		}
+	}
+
+	// O ano fiscal corrente vira no mês de início, no fuso da organização.
+	march := time.Date(2026, 4, 1, 3, 30, 0, 0, time.UTC) // 31/03 23:30 em Manaus
+	if got := settings.FiscalYearStart(march); !got.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, manaus)) {
+		t.Errorf("FiscalYearStart(%v) = %v", march, got)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func sameInstant(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestSettingsCurrencyAndLocale(t *testing.T) {
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Regionais")
+	service := NewOrganizationSettingsService(repositories.NewOrganizationSettingsRepository(gormDB), repositories.NewOrganizationRepository(gormDB))
+
+	defaults, err := service.GetSettings(org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults.Currency != "BRL" || defaults.Locale != "pt-BR" {
+		t.Errorf("defaults = %s %s, want BRL pt-BR", defaults.Currency, defaults.Locale)
+	}
+
+	str := func(value string) *string { return &value }
+	invalid := []struct {
+		name string
+		in   schemas.OrganizationSettingsUpdate
+		want error
+	}{
+		{"currency that is not in ISO 4217", schemas.OrganizationSettingsUpdate{Currency: str("ABC")}, ErrInvalidCurrency},
+		{"currency name", schemas.OrganizationSettingsUpdate{Currency: str("real")}, ErrInvalidCurrency},
+		{"empty currency", schemas.OrganizationSettingsUpdate{Currency: str(" ")}, ErrInvalidCurrency},
+		{"locale with underscore", schemas.OrganizationSettingsUpdate{Locale: str("pt_BR")}, ErrInvalidLocale},
+		{"locale that is not a tag", schemas.OrganizationSettingsUpdate{Locale: str("portuguese brazil")}, ErrInvalidLocale},
+		{"empty locale", schemas.OrganizationSettingsUpdate{Locale: str("")}, ErrInvalidLocale},
+	}
+	for _, tc := range invalid {
+		if _, err := service.UpdateSettings(org.ID, tc.in); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	updated, err := service.UpdateSettings(org.ID, schemas.OrganizationSettingsUpdate{Currency: str(" usd "), Locale: str("es-419")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Currency != "USD" || updated.Locale != "es-419" {
+		t.Errorf("updated = %s %s, want USD es-419", updated.Currency, updated.Locale)
+	}
+
+	// Os campos não enviados continuam como estavam.
+	updated, err = service.UpdateSettings(org.ID, schemas.OrganizationSettingsUpdate{Locale: str("zh-Hant-TW")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := service.Settings(org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Currency != "USD" || stored.Locale != "zh-Hant-TW" {
+		t.Errorf("stored = %s %s, want USD zh-Hant-TW", stored.Currency, stored.Locale)
+	}
+
+	body, err := json.Marshal(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"currency":"USD"`) || !strings.Contains(string(body), `"locale":"zh-Hant-TW"`) {
+		t.Errorf("response = %s", body)
+	}
+}
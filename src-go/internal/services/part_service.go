@@ -1,17 +1,25 @@
 package services
 
 import (
+	"errors"
+	"mime/multipart"
+
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
+	"go-api/internal/storage"
 )
 
+var ErrPartNotFound = errors.New("part not found")
+
 type PartService interface {
 	GetParts(orgID uint, search string, skip, limit int) ([]models.Part, error)
 	GetPart(partID, orgID uint) (*models.Part, error)
 	CreatePart(partIn schemas.PartCreate, orgID uint, userID uint) (*models.Part, error)
-	UpdatePart(partID uint, partIn schemas.PartUpdate, orgID uint) (*models.Part, error)
+	UpdatePart(partID uint, partIn schemas.PartUpdate, orgID uint, ifMatch *uint) (*models.Part, error)
 	DeletePart(partID, orgID uint) error
+	UploadPhoto(partID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.Part, error)
+	DeletePhoto(partID, orgID uint, ifMatch *uint) (*models.Part, error)
 	AddInventoryItems(partID uint, payload schemas.AddItemsPayload, orgID uint, userID uint) error
 	SetInventoryItemStatus(itemID uint, payload schemas.SetItemStatusPayload, orgID uint, userID uint) (*models.InventoryItem, error)
 	GetItemsForPart(partID uint, status *models.InventoryItemStatus, orgID uint) ([]models.InventoryItem, error)
@@ -22,10 +30,11 @@ type partService struct {
 	partRepo         repositories.PartRepository
 	transactionRepo  repositories.InventoryTransactionRepository
 	notificationService NotificationService
+	photos           photoStore
 }
 
-func NewPartService(partRepo repositories.PartRepository, transactionRepo repositories.InventoryTransactionRepository, notificationService NotificationService) PartService {
-	return &partService{partRepo: partRepo, transactionRepo: transactionRepo, notificationService: notificationService}
+func NewPartService(partRepo repositories.PartRepository, transactionRepo repositories.InventoryTransactionRepository, notificationService NotificationService, storage storage.FileStorageService) PartService {
+	return &partService{partRepo: partRepo, transactionRepo: transactionRepo, notificationService: notificationService, photos: photoStore{storage: storage}}
 }
 
 func (s *partService) GetParts(orgID uint, search string, skip, limit int) ([]models.Part, error) {
@@ -66,7 +75,7 @@ func (s *partService) CreatePart(partIn schemas.PartCreate, orgID uint, userID u
 	return createdPart, err
 }
 
-func (s *partService) UpdatePart(partID uint, partIn schemas.PartUpdate, orgID uint) (*models.Part, error) {
+func (s *partService) UpdatePart(partID uint, partIn schemas.PartUpdate, orgID uint, ifMatch *uint) (*models.Part, error) {
 	part, err := s.partRepo.FindByID(partID, orgID)
 	if err != nil {
 		return nil, err
@@ -74,10 +83,31 @@ func (s *partService) UpdatePart(partID uint, partIn schemas.PartUpdate, orgID u
 	if part == nil {
 		return nil, nil // Not found
 	}
+	if err := checkVersion(ifMatch, part.Version); err != nil {
+		return nil, err
+	}
 
-	// Update fields
-
-	return s.partRepo.Update(part)
+	err = errors.Join(
+		patchRequired("name", partIn.Name, &part.Name),
+		patchRequired("category", partIn.Category, (*string)(&part.Category)),
+		patchValue("minimum_stock", partIn.MinimumStock, &part.MinimumStock),
+	)
+	if err != nil {
+		return nil, err
+	}
+	patchNullable(partIn.PartNumber, &part.PartNumber)
+	patchNullable(partIn.Brand, &part.Brand)
+	patchNullable(partIn.Location, &part.Location)
+	patchNullable(partIn.Notes, &part.Notes)
+	patchNullable(partIn.Value, &part.Value)
+	patchNullable(partIn.SerialNumber, &part.SerialNumber)
+	patchNullable(partIn.LifespanKM, &part.LifespanKM)
+
+	updatedPart, err := s.partRepo.Update(part)
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return updatedPart, nil
 }
 
 func (s *partService) DeletePart(partID, orgID uint) error {
@@ -91,7 +121,58 @@ func (s *partService) DeletePart(partID, orgID uint) error {
 	return s.partRepo.Delete(part)
 }
 
+// UploadPhoto grava a foto e a miniatura e só apaga os arquivos anteriores
+// depois que a peça foi salva com os novos.
+func (s *partService) UploadPhoto(partID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.Part, error) {
+	part, err := s.partRepo.FindByID(partID, orgID)
+	if err != nil || part == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, part.Version); err != nil {
+		return nil, err
+	}
+
+	photo, err := s.photos.save(file, orgID, "parts")
+	if err != nil {
+		return nil, err
+	}
+	return s.replacePhoto(part, photo)
+}
+
+func (s *partService) DeletePhoto(partID, orgID uint, ifMatch *uint) (*models.Part, error) {
+	part, err := s.partRepo.FindByID(partID, orgID)
+	if err != nil || part == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, part.Version); err != nil {
+		return nil, err
+	}
+	return s.replacePhoto(part, nil)
+}
+
+func (s *partService) replacePhoto(part *models.Part, photo *storedPhoto) (*models.Part, error) {
+	oldPhoto, oldThumbnail := part.PhotoURL, part.PhotoThumbnailURL
+	part.PhotoURL, part.PhotoThumbnailURL = nil, nil
+	if photo != nil {
+		part.PhotoURL, part.PhotoThumbnailURL = photo.urls()
+	}
+	if _, err := s.partRepo.Update(part); err != nil {
+		s.photos.remove(part.OrganizationID, part.PhotoURL, part.PhotoThumbnailURL)
+		return nil, versionError(err)
+	}
+	s.photos.remove(part.OrganizationID, oldPhoto, oldThumbnail)
+	return part, nil
+}
+
 func (s *partService) AddInventoryItems(partID uint, payload schemas.AddItemsPayload, orgID uint, userID uint) error {
+	part, err := s.partRepo.FindByID(partID, orgID)
+	if err != nil {
+		return err
+	}
+	if part == nil {
+		return ErrPartNotFound
+	}
+
 	for i := 0; i < payload.Quantity; i++ {
 		item := &models.InventoryItem{
 			PartID:         partID,
@@ -108,6 +189,7 @@ func (s *partService) AddInventoryItems(partID uint, payload schemas.AddItemsPay
 			UserID:          &userID,
 			TransactionType: models.TransactionTypeEntrada,
 			Notes:           &payload.Notes,
+			OrganizationID:  orgID,
 		}
 		err = s.transactionRepo.Create(transaction)
 		if err != nil {
@@ -141,6 +223,7 @@ func (s *partService) SetInventoryItemStatus(itemID uint, payload schemas.SetIte
 		TransactionType:  models.TransactionType(payload.NewStatus),
 		Notes:            payload.Notes,
 		RelatedVehicleID: payload.RelatedVehicleID,
+		OrganizationID:   orgID,
 	}
 	err = s.transactionRepo.Create(transaction)
 
@@ -150,9 +233,9 @@ func (s *partService) SetInventoryItemStatus(itemID uint, payload schemas.SetIte
 }
 
 func (s *partService) GetItemsForPart(partID uint, status *models.InventoryItemStatus, orgID uint) ([]models.InventoryItem, error) {
-	return s.partRepo.FindItemsByPartID(partID, status)
+	return s.partRepo.FindItemsByPartID(partID, orgID, status)
 }
 
 func (s *partService) GetPartHistory(partID, orgID uint, skip, limit int) ([]models.InventoryTransaction, error) {
-	return s.transactionRepo.FindByPartID(partID, skip, limit)
+	return s.transactionRepo.FindByPartID(partID, orgID, skip, limit)
 }
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// bcrypt ignora tudo após o 72º byte; senhas maiores dariam uma falsa
+// sensação de segurança.
+const maxPasswordBytes = 72
+
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// PasswordPolicyService é o único caminho para definir uma senha: criação,
+// edição e redefinição passam pelas mesmas regras e pelo mesmo custo de hash.
+type PasswordPolicyService interface {
+	Validate(user *models.User, password string) error
+	SetPassword(user *models.User, password string) error
+}
+
+type passwordPolicyService struct {
+	historyRepo repositories.PasswordHistoryRepository
+}
+
+func NewPasswordPolicyService(historyRepo repositories.PasswordHistoryRepository) PasswordPolicyService {
+	return &passwordPolicyService{historyRepo: historyRepo}
+}
+
+// Validate recebe user nil na criação, quando ainda não há histórico.
+func (s *passwordPolicyService) Validate(user *models.User, password string) error {
+	minLength := config.AppConfig.PASSWORD_MIN_LENGTH
+	if utf8.RuneCountInString(password) < minLength {
+		return fmt.Errorf("%w: must have at least %d characters", ErrWeakPassword, minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: must have at most %d bytes", ErrWeakPassword, maxPasswordBytes)
+	}
+	if core.IsCommonPassword(password) {
+		return fmt.Errorf("%w: password is too common", ErrWeakPassword)
+	}
+
+	if user == nil || user.ID == 0 || config.AppConfig.PASSWORD_HISTORY_SIZE <= 0 {
+		return nil
+	}
+	if core.CheckPasswordHash(password, user.HashedPassword) {
+		return fmt.Errorf("%w: password was used recently", ErrWeakPassword)
+	}
+	history, err := s.historyRepo.FindRecent(user.ID, config.AppConfig.PASSWORD_HISTORY_SIZE)
+	if err != nil {
+		return err
+	}
+	for _, entry := range history {
+		if core.CheckPasswordHash(password, entry.HashedPassword) {
+			return fmt.Errorf("%w: password was used recently", ErrWeakPassword)
+		}
+	}
+	return nil
+}
+
+// SetPassword valida e troca o hash em user; quem chama persiste o usuário, e
+// o repositório grava a senha anterior no histórico na mesma transação.
+func (s *passwordPolicyService) SetPassword(user *models.User, password string) error {
+	if err := s.Validate(user, password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := core.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if user.ID != 0 && user.HashedPassword != "" && config.AppConfig.PASSWORD_HISTORY_SIZE > 0 {
+		user.PasswordChange = &models.PasswordChange{PreviousHash: user.HashedPassword, Keep: config.AppConfig.PASSWORD_HISTORY_SIZE}
+	}
+	user.HashedPassword = hashedPassword
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"go-api/internal/config"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+func passwordHistoryCount(t *testing.T, gormDB *gorm.DB, userID uint) int64 {
+	t.Helper()
+	var count int64
+	if err := gormDB.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	policy := NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(newTestDB(t)))
+
+	for _, tc := range []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"shorter than the minimum", "curta 123", false},
+		// O mínimo conta caracteres, não bytes.
+		{"short with accents", "ãçéíõúâêô", false},
+		{"minimum length", "cavalo 123", true},
+		{"72 bytes", strings.Repeat("a", 71) + "b", true},
+		{"over 72 bytes", strings.Repeat("a", 72) + "b", false},
+		{"multibyte over 72 bytes", strings.Repeat("ç", 37), false},
+		{"common password", "1234567890", false},
+		{"common password in another case", "QWERTYUIOP", false},
+	} {
+		err := policy.Validate(nil, tc.password)
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("%s: got %v, want ErrWeakPassword", tc.name, err)
+		}
+	}
+}
+
+func TestSetPasswordKeepsRecentHistory(t *testing.T) {
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Senha")
+	user := createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+	userRepo := repositories.NewUserRepository(gormDB)
+	policy := NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB))
+
+	for _, password := range []string{"primeira senha nova", "segunda senha nova", "terceira senha nova"} {
+		if err := policy.SetPassword(user, password); err != nil {
+			t.Fatal(err)
+		}
+		if err := userRepo.UpdateVersioned(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := passwordHistoryCount(t, gormDB, user.ID); n != 2 {
+		t.Fatalf("%d history entries, want PASSWORD_HISTORY_SIZE (2)", n)
+	}
+	for _, reused := range []string{"terceira senha nova", "segunda senha nova", "primeira senha nova"} {
+		if err := policy.Validate(user, reused); !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("reusing %q: got %v, want ErrWeakPassword", reused, err)
+		}
+	}
+	// A senha original já saiu do histórico.
+	if err := policy.Validate(user, "old password 123"); err != nil {
+		t.Errorf("password older than the history: %v", err)
+	}
+}
+
+func TestPasswordHistoryIsSavedWithThePassword(t *testing.T) {
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Senha")
+	user := createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+	userRepo := repositories.NewUserRepository(gormDB)
+	policy := NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB))
+
+	// Outra requisição alterou o usuário: a troca de senha perde o conflito.
+	stale := *user
+	if err := policy.SetPassword(&stale, "senha que perdeu"); err != nil {
+		t.Fatal(err)
+	}
+	user.FullName = "Outro Nome"
+	if err := userRepo.UpdateVersioned(user); err != nil {
+		t.Fatal(err)
+	}
+	if err := userRepo.UpdateVersioned(&stale); !errors.Is(err, repositories.ErrVersionConflict) {
+		t.Fatalf("got %v, want ErrVersionConflict", err)
+	}
+	if n := passwordHistoryCount(t, gormDB, user.ID); n != 0 {
+		t.Fatalf("%d history entries after the failed update, want 0", n)
+	}
+
+	// Token de redefinição já consumido: nem senha nem histórico.
+	if err := policy.SetPassword(user, "senha do link usado"); err != nil {
+		t.Fatal(err)
+	}
+	consumed, err := userRepo.ConsumeResetToken(user, "token-usado", time.Now())
+	if err != nil || consumed {
+		t.Fatalf("consume used token: %v, %v", consumed, err)
+	}
+	if n := passwordHistoryCount(t, gormDB, user.ID); n != 0 {
+		t.Fatalf("%d history entries after the rejected reset, want 0", n)
+	}
+}
+
+func TestLoginRehashesWeakPasswordHash(t *testing.T) {
+	f := newAuthFixture(t)
+	cost := config.AppConfig.PASSWORD_BCRYPT_COST
+	config.AppConfig.PASSWORD_BCRYPT_COST = cost + 1
+	t.Cleanup(func() { config.AppConfig.PASSWORD_BCRYPT_COST = cost })
+
+	if _, err := f.service.Login(f.user.Email, "wrong password 123", ClientInfo{IP: "10.0.0.1"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: got %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := f.service.Login(f.user.Email, "old password 123", ClientInfo{IP: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := f.userRepo.FindByID(f.user.ID, f.user.OrganizationID)
+	if err != nil || user == nil {
+		t.Fatalf("reload user: %v, %v", user, err)
+	}
+	if got, _ := bcrypt.Cost([]byte(user.HashedPassword)); got != cost+1 {
+		t.Fatalf("hash cost = %d, want %d", got, cost+1)
+	}
+	if user.HashedPassword == f.user.HashedPassword {
+		t.Fatal("hash was not rewritten")
+	}
+	// Mesma senha com custo maior não entra no histórico.
+	if n := passwordHistoryCount(t, f.db, user.ID); n != 0 {
+		t.Fatalf("%d history entries after the rehash, want 0", n)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/mail"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+var ErrInvalidResetToken = errors.New("password reset token is invalid or has expired")
+
+type PasswordResetService interface {
+	RequestPasswordReset(email, clientIP string) error
+	ResetPassword(token, newPassword string) error
+	// PrepareSetup deixa o usuário ainda não gravado sem senha utilizável e
+	// com um token de definição de senha, válido por PASSWORD_SETUP_TOKEN_EXPIRE_HOURS;
+	// SendSetupLinks envia os links depois que os usuários forem criados.
+	PrepareSetup(user *models.User) (string, error)
+	SendSetupLinks(users []models.User, tokens []string)
+}
+
+type passwordResetService struct {
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	sessionRepo      repositories.UserSessionRepository
+	passwordPolicy   PasswordPolicyService
+	throttle         EmailRequestThrottle
+	mailer           mail.Sender
+}
+
+func NewPasswordResetService(userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, sessionRepo repositories.UserSessionRepository, passwordPolicy PasswordPolicyService, throttle EmailRequestThrottle, mailer mail.Sender) PasswordResetService {
+	return &passwordResetService{userRepo: userRepo, refreshTokenRepo: refreshTokenRepo, sessionRepo: sessionRepo, passwordPolicy: passwordPolicy, throttle: throttle, mailer: mailer}
+}
+
+// RequestPasswordReset não informa se o e-mail existe; o chamador sempre responde igual.
+func (s *passwordResetService) RequestPasswordReset(email, clientIP string) error {
+	if err := s.throttle.Allow(EmailRequestPasswordReset, email, clientIP); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+
+	token, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	tokenHash := core.HashToken(token)
+	expiresAt := time.Now().Add(time.Duration(config.AppConfig.RESET_PASSWORD_TOKEN_EXPIRE_MINUTES) * time.Minute)
+
+	user.ResetPasswordToken = &tokenHash
+	user.ResetPasswordTokenExpiresAt = &expiresAt
+	if err := s.userRepo.UpdateColumns(user, "reset_password_token", "reset_password_token_expires_at"); err != nil {
+		return err
+	}
+
+	resetURL := fmt.Sprintf("%s/#/auth/reset-password?token=%s", config.AppConfig.FRONTEND_URL, token)
+	to := user.Email
+	if user.NotificationEmail != nil && *user.NotificationEmail != "" {
+		to = *user.NotificationEmail
+	}
+
+	// O envio acontece em segundo plano para que o tempo de resposta não revele
+	// se a conta existe.
+	go func() {
+		if err := s.mailer.Send([]string{to}, "TruCar - Redefinição de Senha", passwordResetEmailBody(user.FullName, resetURL)); err != nil {
+			logging.Logger.Error("Failed to send password reset email", zap.Error(err), zap.Uint("user_id", user.ID))
+		}
+	}()
+
+	return nil
+}
+
+func (s *passwordResetService) PrepareSetup(user *models.User) (string, error) {
+	token, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	tokenHash := core.HashToken(token)
+	expiresAt := time.Now().Add(time.Duration(config.AppConfig.PASSWORD_SETUP_TOKEN_EXPIRE_HOURS) * time.Hour)
+	// Nenhum hash bcrypt é vazio, então o login recusa qualquer senha até a
+	// definição pelo link.
+	user.HashedPassword = ""
+	user.ResetPasswordToken = &tokenHash
+	user.ResetPasswordTokenExpiresAt = &expiresAt
+	return token, nil
+}
+
+// SendSetupLinks envia os e-mails um a um, em segundo plano, para não abrir
+// uma conexão SMTP por usuário de uma importação grande.
+func (s *passwordResetService) SendSetupLinks(users []models.User, tokens []string) {
+	go func() {
+		for i := range users {
+			user := &users[i]
+			setupURL := fmt.Sprintf("%s/#/auth/reset-password?token=%s", config.AppConfig.FRONTEND_URL, tokens[i])
+			if err := s.mailer.Send([]string{user.Email}, "TruCar - Crie sua senha", passwordSetupEmailBody(user.FullName, setupURL)); err != nil {
+				logging.Logger.Error("Failed to send password setup email", zap.Error(err), zap.Uint("user_id", user.ID))
+			}
+		}
+	}()
+}
+
+func (s *passwordResetService) ResetPassword(token, newPassword string) error {
+	tokenHash := core.HashToken(token)
+	user, err := s.userRepo.FindByResetToken(tokenHash)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return ErrInvalidResetToken
+	}
+	now := time.Now()
+	if user.ResetPasswordTokenExpiresAt == nil || now.After(*user.ResetPasswordTokenExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.passwordPolicy.SetPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	// A leitura acima pode ter sido feita por duas requisições ao mesmo tempo;
+	// o UPDATE condicional garante que o token troque a senha uma única vez.
+	consumed, err := s.userRepo.ConsumeResetToken(user, tokenHash, now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+
+	// Quem pediu a redefinição pode ter perdido o aparelho: encerra as sessões abertas.
+	if err := s.sessionRepo.RevokeAllForUser(user.ID); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeAllForUser(user.ID)
+}
+
+func passwordResetEmailBody(userName, resetURL string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="pt-BR">
+<body style="font-family: sans-serif; color: #4A5568;">
+	<p>Olá, %s,</p>
+	<p>Recebemos uma solicitação para redefinir a sua senha. Se não foi você quem solicitou, ignore este e-mail.</p>
+	<p>Para criar uma nova senha, acesse o link abaixo. Ele é válido por %d minutos e só pode ser usado uma vez.</p>
+	<p><a href="%s">Redefinir minha senha</a></p>
+	<p style="font-size: 12px;">Se o link não funcionar, copie e cole no navegador:<br>%s</p>
+</body>
+</html>`, html.EscapeString(userName), config.AppConfig.RESET_PASSWORD_TOKEN_EXPIRE_MINUTES, resetURL, resetURL)
+}
+
+func passwordSetupEmailBody(userName, setupURL string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="pt-BR">
+<body style="font-family: sans-serif; color: #4A5568;">
+	<p>Olá, %s,</p>
+	<p>Uma conta no TruCar foi criada para você. Para acessá-la, crie a sua senha pelo link abaixo. Ele é válido por %d horas e só pode ser usado uma vez.</p>
+	<p><a href="%s">Criar minha senha</a></p>
+	<p style="font-size: 12px;">Se o link não funcionar, copie e cole no navegador:<br>%s</p>
+</body>
+</html>`, html.EscapeString(userName), config.AppConfig.PASSWORD_SETUP_TOKEN_EXPIRE_HOURS, setupURL, setupURL)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+type passwordResetFixture struct {
+	db       *gorm.DB
+	service  PasswordResetService
+	userRepo repositories.UserRepository
+	mailer   *testMailer
+	user     *models.User
+}
+
+func newPasswordResetFixture(t *testing.T) *passwordResetFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes A")
+
+	user := createTestUser(t, gormDB, org.ID, "driver@example.com", models.RoleDriver)
+	userRepo := repositories.NewUserRepository(gormDB)
+
+	mailer := newTestMailer()
+	service := NewPasswordResetService(userRepo, repositories.NewRefreshTokenRepository(gormDB), repositories.NewUserSessionRepository(gormDB),
+		NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB)), NewEmailRequestThrottle(repositories.NewUnboundedMemoryCacheRepository()), mailer)
+	return &passwordResetFixture{db: gormDB, service: service, userRepo: userRepo, mailer: mailer, user: user}
+}
+
+var resetLinkToken = regexp.MustCompile(`reset-password\?token=([A-Za-z0-9_-]+)`)
+
+// requestToken pede a redefinição e extrai o token do e-mail enviado.
+func (f *passwordResetFixture) requestToken(t *testing.T) string {
+	t.Helper()
+	if err := f.service.RequestPasswordReset(f.user.Email, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case mail := <-f.mailer.sent:
+		if len(mail.to) != 1 || mail.to[0] != f.user.Email {
+			t.Fatalf("email sent to %v, want %s", mail.to, f.user.Email)
+		}
+		if !strings.Contains(mail.body, "Ana &lt;Motorista&gt;") {
+			t.Fatalf("user name is not escaped in %q", mail.body)
+		}
+		match := resetLinkToken.FindStringSubmatch(mail.body)
+		if match == nil {
+			t.Fatalf("no reset link in %q", mail.body)
+		}
+		return match[1]
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email sent")
+	}
+	return ""
+}
+
+func (f *passwordResetFixture) reload(t *testing.T) *models.User {
+	t.Helper()
+	user, err := f.userRepo.FindByIDUnscoped(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestRequestPasswordResetUnknownEmail(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	if err := f.service.RequestPasswordReset("nobody@example.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case mail := <-f.mailer.sent:
+		t.Fatalf("email sent for an unknown address: %v", mail.to)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	stored := f.reload(t)
+	if stored.ResetPasswordToken == nil || *stored.ResetPasswordToken == token {
+		t.Fatal("the reset token must be stored hashed")
+	}
+
+	session := &models.UserSession{SessionID: "session-1", UserID: f.user.ID, OrganizationID: f.user.OrganizationID, LastSeenAt: time.Now()}
+	if err := f.db.Scopes(repositories.ForOrganization(f.user.OrganizationID)).Create(session).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.service.ResetPassword(token, "new password 456"); err != nil {
+		t.Fatal(err)
+	}
+	updated := f.reload(t)
+	if !core.CheckPasswordHash("new password 456", updated.HashedPassword) {
+		t.Fatal("password was not changed")
+	}
+	if updated.ResetPasswordToken != nil || updated.ResetPasswordTokenExpiresAt != nil {
+		t.Fatal("reset token was not cleared")
+	}
+	var revoked models.UserSession
+	if err := f.db.Scopes(repositories.ForOrganization(f.user.OrganizationID)).First(&revoked, session.ID).Error; err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("session was not revoked (%v)", err)
+	}
+
+	if err := f.service.ResetPassword(token, "another password 789"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("reusing the token: got %v, want ErrInvalidResetToken", err)
+	}
+}
+
+func TestResetPasswordRejectsExpiredToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+	if err := f.db.Model(&models.User{}).Scopes(repositories.AllOrganizations).Where("id = ?", f.user.ID).
+		Update("reset_password_token_expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.service.ResetPassword(token, "new password 456"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("got %v, want ErrInvalidResetToken", err)
+	}
+	if !core.CheckPasswordHash("old password 123", f.reload(t).HashedPassword) {
+		t.Fatal("password changed with an expired token")
+	}
+}
+
+func TestResetPasswordKeepsTokenWhenPasswordIsRejected(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	if err := f.service.ResetPassword(token, "short"); err == nil || errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("got %v, want a password policy error", err)
+	}
+	if err := f.service.ResetPassword(token, "new password 456"); err != nil {
+		t.Fatalf("token should still be valid: %v", err)
+	}
+}
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	// Duas requisições que leram o usuário antes de qualquer uma gravar.
+	first, _ := f.userRepo.FindByResetToken(core.HashToken(token))
+	second, _ := f.userRepo.FindByResetToken(core.HashToken(token))
+	now := time.Now()
+	if ok, err := f.userRepo.ConsumeResetToken(first, core.HashToken(token), now); err != nil || !ok {
+		t.Fatalf("first consume: %v, %v", ok, err)
+	}
+	if ok, err := f.userRepo.ConsumeResetToken(second, core.HashToken(token), now); err != nil || ok {
+		t.Fatalf("second consume: %v, %v", ok, err)
+	}
+
+	if err := f.service.ResetPassword(token, "new password 456"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("got %v, want ErrInvalidResetToken", err)
+	}
+}
+
+func TestRequestPasswordResetIsThrottled(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	for i := 0; i < 3; i++ {
+		if err := f.service.RequestPasswordReset(f.user.Email, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		<-f.mailer.sent
+	}
+
+	var throttled *EmailRequestThrottledError
+	if err := f.service.RequestPasswordReset(f.user.Email, "10.0.0.2"); !errors.As(err, &throttled) {
+		t.Fatalf("fourth request: got %v, want EmailRequestThrottledError", err)
+	}
+	select {
+	case mail := <-f.mailer.sent:
+		t.Fatalf("email sent past the limit: %v", mail.to)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
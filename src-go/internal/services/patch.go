@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+var ErrInvalidPatch = errors.New("invalid patch")
+var ErrVersionMismatch = errors.New("resource was modified by another request")
+
+// checkVersion compara a versão do If-Match (nil quando o cliente não enviou)
+// com a versão persistida.
+func checkVersion(expected *uint, current uint) error {
+	if expected != nil && *expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// versionError traduz o conflito detectado na gravação, quando outra
+// requisição alterou o registro entre a leitura e o UPDATE.
+func versionError(err error) error {
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
+// patchRequired aplica campos obrigatórios: null e valor zero são rejeitados,
+// como o binding:"required" faz na criação.
+func patchRequired[T comparable](name string, f schemas.PatchField[T], dst *T) error {
+	if !f.Set {
+		return nil
+	}
+	var zero T
+	if f.Null || f.Value == zero {
+		return fmt.Errorf("%w: %s is required", ErrInvalidPatch, name)
+	}
+	*dst = f.Value
+	return nil
+}
+
+// patchValue aplica campos não anuláveis que aceitam valor zero.
+func patchValue[T any](name string, f schemas.PatchField[T], dst *T) error {
+	if !f.Set {
+		return nil
+	}
+	if f.Null {
+		return fmt.Errorf("%w: %s cannot be null", ErrInvalidPatch, name)
+	}
+	*dst = f.Value
+	return nil
+}
+
+// patchNullable aplica campos anuláveis: null limpa a coluna.
+func patchNullable[T any](f schemas.PatchField[T], dst **T) {
+	if !f.Set {
+		return
+	}
+	if f.Null {
+		*dst = nil
+		return
+	}
+	value := f.Value
+	*dst = &value
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+func TestPatchHelpers(t *testing.T) {
+	absent := schemas.PatchField[string]{}
+	null := schemas.PatchField[string]{Set: true, Null: true}
+	empty := schemas.PatchField[string]{Set: true}
+	value := schemas.PatchField[string]{Set: true, Value: "novo"}
+
+	t.Run("required", func(t *testing.T) {
+		dst := "atual"
+		if err := patchRequired("name", absent, &dst); err != nil || dst != "atual" {
+			t.Errorf("absent: dst=%q err=%v, want unchanged", dst, err)
+		}
+		if err := patchRequired("name", null, &dst); !errors.Is(err, ErrInvalidPatch) || dst != "atual" {
+			t.Errorf("null: dst=%q err=%v, want ErrInvalidPatch", dst, err)
+		}
+		if err := patchRequired("name", empty, &dst); !errors.Is(err, ErrInvalidPatch) || dst != "atual" {
+			t.Errorf("empty: dst=%q err=%v, want ErrInvalidPatch", dst, err)
+		}
+		if err := patchRequired("name", value, &dst); err != nil || dst != "novo" {
+			t.Errorf("value: dst=%q err=%v, want novo", dst, err)
+		}
+	})
+
+	t.Run("value", func(t *testing.T) {
+		dst := "atual"
+		if err := patchValue("name", null, &dst); !errors.Is(err, ErrInvalidPatch) || dst != "atual" {
+			t.Errorf("null: dst=%q err=%v, want ErrInvalidPatch", dst, err)
+		}
+		if err := patchValue("name", empty, &dst); err != nil || dst != "" {
+			t.Errorf("empty: dst=%q err=%v, want the zero value", dst, err)
+		}
+	})
+
+	t.Run("nullable", func(t *testing.T) {
+		current := "atual"
+		dst := &current
+		patchNullable(absent, &dst)
+		if dst == nil || *dst != "atual" {
+			t.Errorf("absent changed the field to %v", dst)
+		}
+		patchNullable(value, &dst)
+		if dst == nil || *dst != "novo" {
+			t.Errorf("value: got %v, want novo", dst)
+		}
+		patchNullable(null, &dst)
+		if dst != nil {
+			t.Errorf("null: got %q, want nil", *dst)
+		}
+	})
+}
+
+func TestCheckVersion(t *testing.T) {
+	current, stale := uint(3), uint(2)
+	if err := checkVersion(nil, current); err != nil {
+		t.Errorf("without If-Match: %v", err)
+	}
+	if err := checkVersion(&current, current); err != nil {
+		t.Errorf("matching version: %v", err)
+	}
+	if err := checkVersion(&stale, current); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("stale version: got %v, want ErrVersionMismatch", err)
+	}
+	if err := versionError(repositories.ErrVersionConflict); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("conflict on write: got %v, want ErrVersionMismatch", err)
+	}
+}
+
+func TestUpdateUserPatchSemantics(t *testing.T) {
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Patch")
+	user := createTestUser(t, gormDB, org.ID, "ana@example.com", models.RoleDriver)
+	userRepo := repositories.NewUserRepository(gormDB)
+	service := NewUserService(userRepo, repositories.NewOrganizationRepository(gormDB), repositories.NewRefreshTokenRepository(gormDB), repositories.NewUserSessionRepository(gormDB), NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB)), nil, nil, nil)
+
+	// Campos ausentes ficam como estão.
+	version := user.Version
+	updated, err := service.UpdateUser(user.ID, org.ID, schemas.UserUpdate{FullName: schemas.PatchField[string]{Set: true, Value: "Ana Souza"}}, &version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.FullName != "Ana Souza" || updated.Email != "ana@example.com" || !updated.IsActive {
+		t.Errorf("updated = %q %q active=%v", updated.FullName, updated.Email, updated.IsActive)
+	}
+	if updated.Version != version+1 {
+		t.Errorf("version = %d, want %d", updated.Version, version+1)
+	}
+
+	// O If-Match com a versão anterior é recusado e nada muda.
+	_, err = service.UpdateUser(user.ID, org.ID, schemas.UserUpdate{FullName: schemas.PatchField[string]{Set: true, Value: "Outro"}}, &version)
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("stale If-Match: got %v, want ErrVersionMismatch", err)
+	}
+
+	// null em campo obrigatório é um patch inválido.
+	_, err = service.UpdateUser(user.ID, org.ID, schemas.UserUpdate{Email: schemas.PatchField[string]{Set: true, Null: true}}, nil)
+	if !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("null email: got %v, want ErrInvalidPatch", err)
+	}
+
+	stored, err := userRepo.FindByID(user.ID, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FullName != "Ana Souza" || stored.Email != "ana@example.com" || stored.Version != version+1 {
+		t.Errorf("stored = %q %q v%d", stored.FullName, stored.Email, stored.Version)
+	}
+}
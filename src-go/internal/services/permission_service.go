@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+var ErrInvalidPermission = errors.New("invalid permission")
+var ErrInvalidRole = errors.New("permissions of this role cannot be customized")
+var ErrCannotRevokePermissionManage = errors.New("managers must keep the permission.manage permission")
+
+type PermissionService interface {
+	HasPermission(user models.User, permission models.Permission) (bool, error)
+	GetRolePermissions(orgID uint, role models.UserRole) ([]models.Permission, error)
+	SetRolePermissions(orgID uint, role models.UserRole, permissions []models.Permission) ([]models.Permission, error)
+}
+
+type permissionService struct {
+	repo repositories.PermissionRepository
+}
+
+func NewPermissionService(repo repositories.PermissionRepository) PermissionService {
+	return &permissionService{repo: repo}
+}
+
+func (s *permissionService) HasPermission(user models.User, permission models.Permission) (bool, error) {
+	permissions, err := s.effectivePermissions(user.OrganizationID, user.Role)
+	if err != nil {
+		return false, err
+	}
+	return permissions[permission], nil
+}
+
+func (s *permissionService) GetRolePermissions(orgID uint, role models.UserRole) ([]models.Permission, error) {
+	if !isCustomizableRole(role) {
+		return nil, ErrInvalidRole
+	}
+	permissions, err := s.effectivePermissions(orgID, role)
+	if err != nil {
+		return nil, err
+	}
+	return sortedPermissions(permissions), nil
+}
+
+// SetRolePermissions recebe o conjunto final desejado e guarda apenas a
+// diferença em relação ao padrão do papel, para que novas permissões padrão
+// cheguem às organizações que não mexeram nelas.
+func (s *permissionService) SetRolePermissions(orgID uint, role models.UserRole, permissions []models.Permission) ([]models.Permission, error) {
+	if !isCustomizableRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	known := map[models.Permission]bool{}
+	for _, p := range models.AllPermissions {
+		known[p] = true
+	}
+	wanted := map[models.Permission]bool{}
+	for _, p := range permissions {
+		if !known[p] {
+			return nil, ErrInvalidPermission
+		}
+		wanted[p] = true
+	}
+	// Sem isso um gestor poderia tirar de todos (inclusive de si) o acesso a esta tela.
+	if role != models.RoleDriver && !wanted[models.PermissionPermissionManage] {
+		return nil, ErrCannotRevokePermissionManage
+	}
+
+	defaults := map[models.Permission]bool{}
+	for _, p := range models.DefaultRolePermissions[role] {
+		defaults[p] = true
+	}
+
+	var overrides []models.OrganizationRolePermission
+	for _, p := range models.AllPermissions {
+		if wanted[p] != defaults[p] {
+			overrides = append(overrides, models.OrganizationRolePermission{
+				OrganizationID: orgID,
+				Role:           role,
+				Permission:     p,
+				Granted:        wanted[p],
+			})
+		}
+	}
+	if err := s.repo.ReplaceOverrides(orgID, role, overrides); err != nil {
+		return nil, err
+	}
+
+	return s.GetRolePermissions(orgID, role)
+}
+
+func (s *permissionService) effectivePermissions(orgID uint, role models.UserRole) (map[models.Permission]bool, error) {
+	permissions := map[models.Permission]bool{}
+	for _, p := range models.DefaultRolePermissions[role] {
+		permissions[p] = true
+	}
+	if !isCustomizableRole(role) {
+		return permissions, nil
+	}
+
+	overrides, err := s.repo.FindOverrides(orgID, role)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		permissions[o.Permission] = o.Granted
+	}
+	return permissions, nil
+}
+
+func isCustomizableRole(role models.UserRole) bool {
+	return role == models.RoleClienteAtivo || role == models.RoleClienteDemo || role == models.RoleDriver
+}
+
+func sortedPermissions(set map[models.Permission]bool) []models.Permission {
+	result := []models.Permission{}
+	for _, p := range models.AllPermissions {
+		if set[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
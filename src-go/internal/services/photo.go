@@ -0,0 +1,98 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/imaging"
+	"go-api/internal/logging"
+	"go-api/internal/storage"
+)
+
+var ErrInvalidPhoto = errors.New("invalid photo")
+
+// photoStore grava fotos enviadas (veículos, peças e avatares) e as miniaturas
+// das listagens. Os arquivos de cada organização ficam em photos/<org>/<tipo>.
+type photoStore struct {
+	storage storage.FileStorageService
+}
+
+type storedPhoto struct {
+	url          string
+	thumbnailURL string
+}
+
+func photoSubpath(orgID uint, kind string) string {
+	return fmt.Sprintf("photos/%d/%s", orgID, kind)
+}
+
+// save valida o arquivo pelo conteúdo e grava o original, regravado sem os
+// metadados do arquivo enviado, e a miniatura.
+func (p photoStore) save(file *multipart.FileHeader, orgID uint, kind string) (*storedPhoto, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	img, err := imaging.Decode(src)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrInvalidImage) || errors.Is(err, imaging.ErrTooManyPixels) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPhoto, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var original, thumbnail bytes.Buffer
+	originalFormat, err := img.EncodeOriginal(&original)
+	if err != nil {
+		return nil, err
+	}
+	thumbnailFormat, err := img.Thumbnail(config.AppConfig.PHOTO_THUMBNAIL_SIZE).Encode(&thumbnail)
+	if err != nil {
+		return nil, err
+	}
+
+	subpath := photoSubpath(orgID, kind)
+	photo := &storedPhoto{}
+	if photo.url, err = p.storage.SaveContent(&original, subpath, originalFormat.Extension()); err != nil {
+		return nil, err
+	}
+	if photo.thumbnailURL, err = p.storage.SaveContent(&thumbnail, subpath+"/thumbnails", thumbnailFormat.Extension()); err != nil {
+		p.remove(orgID, &photo.url)
+		return nil, err
+	}
+	return photo, nil
+}
+
+// remove apaga os arquivos informados que foram gravados por save para a
+// organização. URLs externas, ou preenchidas à mão apontando para outros
+// arquivos, não são tocadas. Falhas só são registradas: o registro já foi gravado.
+func (p photoStore) remove(orgID uint, urls ...*string) {
+	prefix := "/static/" + photoSubpath(orgID, "")
+	for _, url := range urls {
+		if url == nil || !strings.HasPrefix(*url, prefix) || strings.Contains(*url, "..") {
+			continue
+		}
+		if err := p.storage.Delete(*url); err != nil {
+			logging.Logger.Warn("Failed to delete photo", zap.Uint("organization_id", orgID), zap.String("file", *url), zap.Error(err))
+		}
+	}
+}
+
+func (s *storedPhoto) urls() (*string, *string) {
+	return &s.url, &s.thumbnailURL
+}
+
+func samePhotoURL(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
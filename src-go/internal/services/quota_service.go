@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// QuotaExceededError é retornado quando o plano da organização não permite
+// criar mais um recurso.
+type QuotaExceededError struct {
+	Resource models.QuotaResource
+	Limit    int
+	Monthly  bool
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.Monthly {
+		return fmt.Sprintf("monthly %s limit of %d reached for this plan", e.Resource, e.Limit)
+	}
+	return fmt.Sprintf("%s limit of %d reached for this plan", e.Resource, e.Limit)
+}
+
+// QuotaService aplica os limites do plano. Veículos e motoristas são
+// limitados pelo total cadastrado (CreateWithinLimit); jornadas, ordens de
+// frete e documentos pela quantidade criada no mês (Consume/Release).
+type QuotaService interface {
+	CreateWithinLimit(orgID uint, resource models.QuotaResource, create func(limit int) error) error
+	CheckCapacity(orgID uint, resource models.QuotaResource, additional int) error
+	Consume(orgID uint, resource models.QuotaResource) error
+	Release(orgID uint, resource models.QuotaResource)
+	GetUsage(orgID uint) (*schemas.UsageReport, error)
+}
+
+type quotaService struct {
+	orgRepo     repositories.OrganizationRepository
+	usageRepo   repositories.UsageRepository
+	vehicleRepo repositories.VehicleRepository
+	userRepo    repositories.UserRepository
+	settings    OrganizationSettingsService
+}
+
+func NewQuotaService(orgRepo repositories.OrganizationRepository, usageRepo repositories.UsageRepository, vehicleRepo repositories.VehicleRepository, userRepo repositories.UserRepository, settings OrganizationSettingsService) QuotaService {
+	return &quotaService{orgRepo: orgRepo, usageRepo: usageRepo, vehicleRepo: vehicleRepo, userRepo: userRepo, settings: settings}
+}
+
+// CreateWithinLimit chama create com o limite do plano para o recurso. O
+// repositório conta e grava na mesma transação e devolve ErrLimitReached, que
+// vira QuotaExceededError.
+func (s *quotaService) CreateWithinLimit(orgID uint, resource models.QuotaResource, create func(limit int) error) error {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return err
+	}
+	limit := org.Limit(resource)
+	if err := create(limit); err != nil {
+		if errors.Is(err, repositories.ErrLimitReached) {
+			return &QuotaExceededError{Resource: resource, Limit: limit}
+		}
+		return err
+	}
+	return nil
+}
+
+// CheckCapacity verifica se cabem mais additional registros de uma vez, para
+// que uma importação (inclusive o dry run) falhe antes de validar as linhas.
+// É só um aviso antecipado: quem garante o limite é o CreateWithinLimit.
+func (s *quotaService) CheckCapacity(orgID uint, resource models.QuotaResource, additional int) error {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return err
+	}
+	limit := org.Limit(resource)
+	if limit <= 0 {
+		return nil
+	}
+
+	current, err := s.countTotal(orgID, resource)
+	if err != nil {
+		return err
+	}
+	if current+int64(additional) > int64(limit) {
+		return &QuotaExceededError{Resource: resource, Limit: limit}
+	}
+	return nil
+}
+
+func (s *quotaService) Consume(orgID uint, resource models.QuotaResource) error {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return err
+	}
+	limit := org.Limit(resource)
+	period, err := s.currentPeriod(orgID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.usageRepo.TryIncrement(orgID, resource, period, limit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &QuotaExceededError{Resource: resource, Limit: limit, Monthly: true}
+	}
+	return nil
+}
+
+// Release devolve a cota consumida quando a criação do recurso falha depois do Consume.
+func (s *quotaService) Release(orgID uint, resource models.QuotaResource) {
+	period, err := s.currentPeriod(orgID)
+	if err == nil {
+		err = s.usageRepo.Decrement(orgID, resource, period)
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to release quota", zap.Error(err), zap.Uint("organization_id", orgID), zap.String("resource", string(resource)))
+	}
+}
+
+func (s *quotaService) GetUsage(orgID uint) (*schemas.UsageReport, error) {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := s.settings.Now(orgID)
+	if err != nil {
+		return nil, err
+	}
+	period := usagePeriod(now)
+	counters, err := s.usageRepo.FindByPeriod(orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	monthly := map[models.QuotaResource]int64{}
+	for _, counter := range counters {
+		monthly[counter.Resource] = int64(counter.Count)
+	}
+
+	report := &schemas.UsageReport{OrganizationID: org.ID, Period: period, TimeZone: now.Location().String(), TrialEndsAt: org.TrialEndsAt}
+	for _, resource := range []models.QuotaResource{models.QuotaVehicles, models.QuotaDrivers} {
+		used, err := s.countTotal(orgID, resource)
+		if err != nil {
+			return nil, err
+		}
+		report.Resources = append(report.Resources, resourceUsage(resource, used, org.Limit(resource), false))
+	}
+	for _, resource := range models.MonthlyQuotaResources {
+		report.Resources = append(report.Resources, resourceUsage(resource, monthly[resource], org.Limit(resource), true))
+	}
+	return report, nil
+}
+
+func (s *quotaService) countTotal(orgID uint, resource models.QuotaResource) (int64, error) {
+	switch resource {
+	case models.QuotaVehicles:
+		return s.vehicleRepo.CountByOrganization(orgID, "")
+	case models.QuotaDrivers:
+		return s.userRepo.CountByRole(orgID, models.RoleDriver)
+	}
+	return 0, fmt.Errorf("resource %s is not limited by total", resource)
+}
+
+func resourceUsage(resource models.QuotaResource, used int64, limit int, monthly bool) schemas.ResourceUsage {
+	usage := schemas.ResourceUsage{Resource: string(resource), Used: used, Monthly: monthly}
+	if limit > 0 {
+		remaining := int64(limit) - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		usage.Limit = &limit
+		usage.Remaining = &remaining
+	}
+	return usage
+}
+
+// currentPeriod usa o fuso da organização: a cota mensal vira junto com o
+// calendário do cliente, não com o do servidor.
+func (s *quotaService) currentPeriod(orgID uint) (string, error) {
+	now, err := s.settings.Now(orgID)
+	if err != nil {
+		return "", err
+	}
+	return usagePeriod(now), nil
+}
+
+func usagePeriod(now time.Time) string {
+	return now.Format("2006-01")
+}
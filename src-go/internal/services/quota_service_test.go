@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+func newQuotaFixture(t *testing.T, vehicleLimit, driverLimit int) (*gorm.DB, *models.Organization, QuotaService) {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Limite")
+	org.VehicleLimit = vehicleLimit
+	org.DriverLimit = driverLimit
+	if err := gormDB.Save(org).Error; err != nil {
+		t.Fatal(err)
+	}
+	quota := NewQuotaService(repositories.NewOrganizationRepository(gormDB), repositories.NewUsageRepository(gormDB),
+		repositories.NewVehicleRepository(gormDB), repositories.NewUserRepository(gormDB), nil)
+	return gormDB, org, quota
+}
+
+// Criações simultâneas não podem passar juntas do limite: a contagem e o
+// INSERT acontecem na mesma transação, com a organização travada.
+func TestCreateWithinLimitIsAtomic(t *testing.T) {
+	gormDB, org, quota := newQuotaFixture(t, 3, 0)
+	vehicles := repositories.NewVehicleRepository(gormDB)
+	// Alarga a janela entre a contagem e o INSERT.
+	gormDB.Callback().Create().Before("gorm:create").Register("test:slow_create", func(*gorm.DB) {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			vehicle := &models.Vehicle{Brand: "Volvo", Model: "FH", Year: 2022, OrganizationID: org.ID}
+			errs[i] = quota.CreateWithinLimit(org.ID, models.QuotaVehicles, func(limit int) error {
+				return vehicles.Create(vehicle, limit)
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		var exceeded *QuotaExceededError
+		switch {
+		case err == nil:
+			created++
+		case errors.As(err, &exceeded):
+			if exceeded.Resource != models.QuotaVehicles || exceeded.Limit != 3 || exceeded.Monthly {
+				t.Fatalf("unexpected quota error %+v", exceeded)
+			}
+		default:
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	count, err := vehicles.CountByOrganization(org.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 3 || count != 3 {
+		t.Fatalf("%d creations succeeded and %d vehicles stored, want 3", created, count)
+	}
+}
+
+// Um lote que passaria do limite não grava nenhuma linha, e só motoristas
+// contam no limite de motoristas.
+func TestCreateBatchWithinDriverLimit(t *testing.T) {
+	gormDB, org, quota := newQuotaFixture(t, 0, 2)
+	users := repositories.NewUserRepository(gormDB)
+	createTestUser(t, gormDB, org.ID, "motorista@example.com", models.RoleDriver)
+
+	batch := func(roles ...models.UserRole) []models.User {
+		var out []models.User
+		for i, role := range roles {
+			out = append(out, models.User{
+				Email: fmt.Sprintf("%s-%d-%d@example.com", role, len(roles), i), FullName: "Importado", EmployeeID: fmt.Sprintf("IMP-%d-%d", len(roles), i),
+				HashedPassword: "x", Role: role, IsActive: true, OrganizationID: org.ID,
+			})
+		}
+		return out
+	}
+
+	err := quota.CreateWithinLimit(org.ID, models.QuotaDrivers, func(limit int) error {
+		return users.CreateBatch(batch(models.RoleDriver, models.RoleDriver, models.RoleClienteAtivo), limit)
+	})
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("expected a quota error, got %v", err)
+	}
+	var stored int64
+	gormDB.Model(&models.User{}).Scopes(repositories.ForOrganization(org.ID)).Count(&stored)
+	if stored != 1 {
+		t.Fatalf("%d users stored after the rejected batch, want 1", stored)
+	}
+
+	err = quota.CreateWithinLimit(org.ID, models.QuotaDrivers, func(limit int) error {
+		return users.CreateBatch(batch(models.RoleDriver, models.RoleClienteAtivo, models.RoleClienteAtivo, models.RoleClienteAtivo), limit)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drivers, err := users.CountByRole(org.ID, models.RoleDriver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drivers != 2 {
+		t.Fatalf("%d drivers, want 2", drivers)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/mail"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// Limites das organizações de demonstração, os mesmos do backend Python.
+const (
+	demoVehicleLimit             = 3
+	demoDriverLimit              = 3
+	demoJourneyMonthlyLimit      = 50
+	demoFreightOrderMonthlyLimit = 5
+	demoDocumentMonthlyLimit     = 10
+)
+
+var ErrEmailAlreadyRegistered = errors.New("email is already registered")
+var ErrInvalidSector = errors.New("invalid sector")
+var ErrInvalidVerificationToken = errors.New("email verification token is invalid or has expired")
+
+// SignupService cria contas de demonstração pelo cadastro público: uma
+// organização nova e o primeiro gestor (cliente_demo), que só consegue entrar
+// depois de confirmar o e-mail. Cadastros não confirmados são apagados
+// quando o link vence.
+type SignupService interface {
+	Signup(signupIn schemas.SignupRequest, clientIP string) error
+	VerifyEmail(token string) error
+	ResendVerification(email, clientIP string) error
+	DeleteExpiredSignups(now time.Time) (int, error)
+}
+
+type signupService struct {
+	userRepo       repositories.UserRepository
+	orgRepo        repositories.OrganizationRepository
+	passwordPolicy PasswordPolicyService
+	throttle       EmailRequestThrottle
+	mailer         mail.Sender
+}
+
+func NewSignupService(userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository, passwordPolicy PasswordPolicyService, throttle EmailRequestThrottle, mailer mail.Sender) SignupService {
+	return &signupService{userRepo: userRepo, orgRepo: orgRepo, passwordPolicy: passwordPolicy, throttle: throttle, mailer: mailer}
+}
+
+// Signup não informa se o e-mail já tem conta: o chamador sempre responde
+// igual e o dono do endereço recebe um aviso em vez do link de confirmação.
+// A senha é validada e o hash calculado antes da busca, para que o tempo de
+// resposta também não denuncie o cadastro.
+func (s *signupService) Signup(signupIn schemas.SignupRequest, clientIP string) error {
+	if err := s.throttle.Allow(EmailRequestSignup, signupIn.Email, clientIP); err != nil {
+		return err
+	}
+	sector := models.Sector(signupIn.Sector)
+	if !models.IsValidSector(sector) {
+		return ErrInvalidSector
+	}
+
+	email := models.NormalizeEmail(signupIn.Email)
+	user := &models.User{
+		FullName:   strings.TrimSpace(signupIn.FullName),
+		Email:      email,
+		EmployeeID: models.FormatEmployeeID(1),
+		Role:       models.RoleClienteDemo,
+		IsActive:   true,
+	}
+	if err := s.passwordPolicy.SetPassword(user, signupIn.Password); err != nil {
+		return err
+	}
+
+	existing, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		s.sendAccountExistsEmail(existing)
+		return nil
+	}
+
+	token, err := setEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+
+	code, err := core.GenerateOrganizationCode()
+	if err != nil {
+		return err
+	}
+
+	trialEndsAt := time.Now().AddDate(0, 0, config.AppConfig.DEMO_TRIAL_DAYS)
+	org := &models.Organization{
+		Name:             strings.TrimSpace(signupIn.OrganizationName),
+		Sector:           sector,
+		Code:             &code,
+		EmployeeSequence: 1,
+		TrialEndsAt:      &trialEndsAt,
+
+		VehicleLimit:             demoVehicleLimit,
+		DriverLimit:              demoDriverLimit,
+		JourneyMonthlyLimit:      demoJourneyMonthlyLimit,
+		FreightOrderMonthlyLimit: demoFreightOrderMonthlyLimit,
+		DocumentMonthlyLimit:     demoDocumentMonthlyLimit,
+	}
+	if err := s.orgRepo.CreateWithOwner(org, user); err != nil {
+		return err
+	}
+
+	logging.Logger.Info("Demo organization created through signup",
+		zap.Uint("organization_id", org.ID),
+		zap.Uint("user_id", user.ID),
+	)
+	s.sendVerificationEmail(user, token)
+	return nil
+}
+
+func (s *signupService) VerifyEmail(token string) error {
+	user, err := s.userRepo.FindByEmailVerificationToken(core.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if user == nil || user.EmailVerificationTokenExpiresAt == nil || time.Now().After(*user.EmailVerificationTokenExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	user.EmailVerificationToken = nil
+	user.EmailVerificationTokenExpiresAt = nil
+	return s.userRepo.UpdateColumns(user, "email_verification_token", "email_verification_token_expires_at")
+}
+
+// ResendVerification não informa se o e-mail existe ou já foi confirmado.
+func (s *signupService) ResendVerification(email, clientIP string) error {
+	if err := s.throttle.Allow(EmailRequestVerification, email, clientIP); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByEmail(models.NormalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive || user.EmailVerificationToken == nil {
+		return nil
+	}
+
+	token, err := setEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateColumns(user, "email_verification_token", "email_verification_token_expires_at"); err != nil {
+		return err
+	}
+	s.sendVerificationEmail(user, token)
+	return nil
+}
+
+// DeleteExpiredSignups apaga as organizações criadas pelo cadastro público em
+// que o gestor não confirmou o e-mail antes de o link vencer.
+func (s *signupService) DeleteExpiredSignups(now time.Time) (int, error) {
+	orgs, err := s.orgRepo.FindUnverifiedSignups(now)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, org := range orgs {
+		// Quem confirmou ou pediu outro link depois da busca fica.
+		ok, err := s.orgRepo.PurgeUnverifiedSignup(org.ID, now)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			logging.Logger.Info("Unverified signup deleted", zap.Uint("organization_id", org.ID))
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// RunSignupCleanup executa DeleteExpiredSignups periodicamente; é iniciado em
+// uma goroutine pelo main.
+func RunSignupCleanup(service SignupService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := service.DeleteExpiredSignups(time.Now()); err != nil {
+			logging.Logger.Error("Failed to delete unverified signups", zap.Error(err))
+		}
+		<-ticker.C
+	}
+}
+
+func setEmailVerificationToken(user *models.User) (string, error) {
+	token, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	tokenHash := core.HashToken(token)
+	expiresAt := time.Now().Add(time.Duration(config.AppConfig.EMAIL_VERIFICATION_EXPIRE_HOURS) * time.Hour)
+	user.EmailVerificationToken = &tokenHash
+	user.EmailVerificationTokenExpiresAt = &expiresAt
+	return token, nil
+}
+
+func (s *signupService) sendVerificationEmail(user *models.User, token string) {
+	verifyURL := fmt.Sprintf("%s/#/auth/verify-email?token=%s", config.AppConfig.FRONTEND_URL, token)
+	go func() {
+		if err := s.mailer.Send([]string{user.Email}, "TruCar - Confirme seu e-mail", emailVerificationBody(user.FullName, verifyURL)); err != nil {
+			logging.Logger.Error("Failed to send email verification", zap.Error(err), zap.Uint("user_id", user.ID))
+		}
+	}()
+}
+
+// sendAccountExistsEmail avisa o dono do endereço de que alguém tentou
+// cadastrá-lo de novo, com os caminhos para entrar ou trocar a senha.
+func (s *signupService) sendAccountExistsEmail(user *models.User) {
+	loginURL := fmt.Sprintf("%s/#/auth/login", config.AppConfig.FRONTEND_URL)
+	forgotURL := fmt.Sprintf("%s/#/auth/forgot-password", config.AppConfig.FRONTEND_URL)
+	go func() {
+		if err := s.mailer.Send([]string{user.Email}, "TruCar - Você já tem uma conta", accountExistsBody(user.FullName, loginURL, forgotURL)); err != nil {
+			logging.Logger.Error("Failed to send account exists email", zap.Error(err), zap.Uint("user_id", user.ID))
+		}
+	}()
+}
+
+func accountExistsBody(userName, loginURL, forgotURL string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="pt-BR">
+<body style="font-family: sans-serif; color: #4A5568;">
+	<p>Olá, %s,</p>
+	<p>Recebemos um pedido de cadastro no TruCar com este e-mail, mas ele já está ligado a uma conta. Se não foi você, ignore esta mensagem: nada foi alterado.</p>
+	<p><a href="%s">Entrar no TruCar</a></p>
+	<p>Não lembra a senha? <a href="%s">Redefina pelo link de recuperação</a>.</p>
+</body>
+</html>`, html.EscapeString(userName), loginURL, forgotURL)
+}
+
+func emailVerificationBody(userName, verifyURL string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="pt-BR">
+<body style="font-family: sans-serif; color: #4A5568;">
+	<p>Olá, %s,</p>
+	<p>Obrigado por testar o TruCar! Para ativar a sua conta de demonstração, confirme o seu e-mail pelo link abaixo.</p>
+	<p><a href="%s">Confirmar meu e-mail</a></p>
+	<p>O link é válido por %d horas. A demonstração dura %d dias; depois disso os dados continuam disponíveis apenas para consulta até a ativação do plano.</p>
+	<p style="font-size: 12px;">Se o link não funcionar, copie e cole no navegador:<br>%s</p>
+</body>
+</html>`, html.EscapeString(userName), verifyURL, config.AppConfig.EMAIL_VERIFICATION_EXPIRE_HOURS, config.AppConfig.DEMO_TRIAL_DAYS, verifyURL)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+func newSignupFixture(t *testing.T) (*gorm.DB, SignupService, *testMailer) {
+	t.Helper()
+	gormDB := newTestDB(t)
+	mailer := newTestMailer()
+	service := NewSignupService(repositories.NewUserRepository(gormDB), repositories.NewOrganizationRepository(gormDB),
+		NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB)), NewEmailRequestThrottle(repositories.NewUnboundedMemoryCacheRepository()), mailer)
+	return gormDB, service, mailer
+}
+
+func receiveMail(t *testing.T, mailer *testMailer) sentMail {
+	t.Helper()
+	select {
+	case mail := <-mailer.sent:
+		return mail
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email was sent")
+	}
+	return sentMail{}
+}
+
+func signupRequest(email string) schemas.SignupRequest {
+	return schemas.SignupRequest{OrganizationName: "Transportes Novos", Sector: string(models.TransporteDeCargas), FullName: "Bia <Gestora>", Email: email, Password: "senha longa 2024"}
+}
+
+func TestSignupCreatesDemoOrganization(t *testing.T) {
+	gormDB, service, mailer := newSignupFixture(t)
+	if err := service.Signup(signupRequest(" Nova@Example.com "), "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := receiveMail(t, mailer)
+	if len(mail.to) != 1 || mail.to[0] != "nova@example.com" || !strings.Contains(mail.body, "verify-email?token=") {
+		t.Fatalf("unexpected verification email to %v: %q", mail.to, mail.body)
+	}
+	var orgs int64
+	gormDB.Model(&models.Organization{}).Count(&orgs)
+	if orgs != 1 {
+		t.Fatalf("%d organizations, want 1", orgs)
+	}
+}
+
+// Um e-mail já cadastrado recebe a mesma resposta de um novo; o dono do
+// endereço é avisado e nada é criado.
+func TestSignupWithRegisteredEmailNotifiesOwner(t *testing.T) {
+	gormDB, service, mailer := newSignupFixture(t)
+	org := createTestOrganization(t, gormDB, "Transportes A")
+	owner := createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+
+	if err := service.Signup(signupRequest("GESTOR@example.com"), "10.0.0.1"); err != nil {
+		t.Fatalf("signup with a registered email returned %v", err)
+	}
+
+	mail := receiveMail(t, mailer)
+	if len(mail.to) != 1 || mail.to[0] != owner.Email {
+		t.Fatalf("email sent to %v, want %s", mail.to, owner.Email)
+	}
+	if strings.Contains(mail.body, "verify-email") || !strings.Contains(mail.body, "forgot-password") || !strings.Contains(mail.body, "Ana &lt;Motorista&gt;") {
+		t.Fatalf("unexpected account exists email %q", mail.body)
+	}
+	var orgs int64
+	gormDB.Model(&models.Organization{}).Count(&orgs)
+	if orgs != 1 {
+		t.Fatalf("%d organizations, want only the existing one", orgs)
+	}
+}
+
+// A senha é recusada antes da busca pelo e-mail, então o erro também não
+// depende de o endereço existir.
+func TestSignupRejectsWeakPasswordForAnyEmail(t *testing.T) {
+	gormDB, service, _ := newSignupFixture(t)
+	org := createTestOrganization(t, gormDB, "Transportes A")
+	createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+
+	for _, email := range []string{"gestor@example.com", "nova@example.com"} {
+		request := signupRequest(email)
+		request.Password = "curta"
+		if err := service.Signup(request, "10.0.0.1"); !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("%s: got %v, want ErrWeakPassword", email, err)
+		}
+	}
+}
+
+// O limite vale antes de qualquer validação; a senha curta só serve para que
+// os pedidos aceitos não criem contas.
+func TestSignupIsThrottledPerAddressAndIP(t *testing.T) {
+	_, service, _ := newSignupFixture(t)
+	attempt := func(email, ip string) error {
+		request := signupRequest(email)
+		request.Password = "curta"
+		return service.Signup(request, ip)
+	}
+	var throttled *EmailRequestThrottledError
+
+	for i := 0; i < 3; i++ {
+		if err := attempt("alvo@example.com", "10.0.0.1"); !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("attempt %d: got %v, want ErrWeakPassword", i+1, err)
+		}
+	}
+	if err := attempt("ALVO@example.com", "10.0.0.2"); !errors.As(err, &throttled) || throttled.RetryAfter <= 0 {
+		t.Fatalf("same address from another ip: got %v, want EmailRequestThrottledError", err)
+	}
+
+	for i := 0; i < 7; i++ {
+		if err := attempt(fmt.Sprintf("outro%d@example.com", i), "10.0.0.1"); !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("address %d: got %v, want ErrWeakPassword", i, err)
+		}
+	}
+	if err := attempt("mais@example.com", "10.0.0.1"); !errors.As(err, &throttled) {
+		t.Fatalf("eleventh request from the ip: got %v, want EmailRequestThrottledError", err)
+	}
+	if err := attempt("mais@example.com", "10.0.0.3"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("another ip: got %v, want ErrWeakPassword", err)
+	}
+}
+
+func TestDeleteExpiredSignups(t *testing.T) {
+	gormDB, service, mailer := newSignupFixture(t)
+	for _, email := range []string{"expirado@example.com", "confirmado@example.com", "recente@example.com"} {
+		if err := service.Signup(signupRequest(email), "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		receiveMail(t, mailer)
+	}
+	// Organização criada pelo super admin: sem demonstração nem confirmação.
+	createTestOrganization(t, gormDB, "Transportes Antigos")
+
+	past := time.Now().Add(-time.Hour)
+	if err := gormDB.Model(&models.User{}).Scopes(repositories.AllOrganizations).Where("email = ?", "expirado@example.com").UpdateColumn("email_verification_token_expires_at", past).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gormDB.Model(&models.User{}).Scopes(repositories.AllOrganizations).Where("email = ?", "confirmado@example.com").
+		Updates(map[string]interface{}{"email_verification_token": nil, "email_verification_token_expires_at": past}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := service.DeleteExpiredSignups(time.Now())
+	if err != nil || deleted != 1 {
+		t.Fatalf("deleted %d, %v; want 1", deleted, err)
+	}
+	var emails []string
+	if err := gormDB.Model(&models.User{}).Scopes(repositories.AllOrganizations).Order("email").Pluck("email", &emails).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(emails, ",") != "confirmado@example.com,recente@example.com" {
+		t.Fatalf("users left: %v", emails)
+	}
+	var orgs int64
+	gormDB.Model(&models.Organization{}).Count(&orgs)
+	if orgs != 3 {
+		t.Fatalf("%d organizations, want 3", orgs)
+	}
+
+	// Depois do prazo, o cadastro recente também sai.
+	deleted, err = service.DeleteExpiredSignups(time.Now().Add(49 * time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("deleted %d, %v; want the recent signup", deleted, err)
+	}
+}
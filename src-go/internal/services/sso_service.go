@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/config"
+	"go-api/internal/core"
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/oidc"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// ssoStateTTL é o tempo que o usuário tem para concluir o login no IdP.
+const ssoStateTTL = 10 * time.Minute
+
+const defaultSSOScopes = "openid email profile"
+
+// ssoUnusablePassword não é um hash bcrypt válido: usuários criados pelo SSO
+// não conseguem entrar por senha até definirem uma pela recuperação de senha.
+const ssoUnusablePassword = "!sso"
+
+var ErrSSONotConfigured = errors.New("single sign-on is not configured for this organization")
+var ErrInvalidSSOConfig = errors.New("invalid single sign-on configuration")
+var ErrInvalidSSOState = errors.New("invalid or expired sso state")
+var ErrSSOLoginFailed = errors.New("single sign-on login failed")
+var ErrSSOUserNotAllowed = errors.New("this account cannot sign in through single sign-on")
+
+// ssoState é guardado no cache entre o redirecionamento e o callback; o
+// code_verifier do PKCE nunca sai do servidor.
+type ssoState struct {
+	OrganizationID uint   `json:"organization_id"`
+	Nonce          string `json:"nonce"`
+	CodeVerifier   string `json:"code_verifier"`
+}
+
+type SSOService interface {
+	GetConfig(orgID uint) (*schemas.SSOConfigPublic, error)
+	UpdateConfig(orgID uint, configIn schemas.SSOConfigUpdate) (*schemas.SSOConfigPublic, error)
+	DeleteConfig(orgID uint) error
+	BeginLogin(orgID uint) (*schemas.SSOAuthorization, error)
+	CompleteLogin(code, state string, client ClientInfo) (*schemas.Token, error)
+}
+
+type ssoService struct {
+	repo        repositories.SSOConfigRepository
+	userRepo    repositories.UserRepository
+	orgRepo     repositories.OrganizationRepository
+	cache       repositories.CacheRepository
+	authService AuthService
+	quota       QuotaService
+	client      *oidc.Client
+}
+
+func NewSSOService(repo repositories.SSOConfigRepository, userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository, cache repositories.CacheRepository, authService AuthService, quota QuotaService, client *oidc.Client) SSOService {
+	return &ssoService{repo: repo, userRepo: userRepo, orgRepo: orgRepo, cache: cache, authService: authService, quota: quota, client: client}
+}
+
+func (s *ssoService) GetConfig(orgID uint) (*schemas.SSOConfigPublic, error) {
+	ssoConfig, err := s.repo.FindByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if ssoConfig == nil {
+		return nil, ErrSSONotConfigured
+	}
+	return toSSOConfigPublic(ssoConfig), nil
+}
+
+// UpdateConfig consulta o discovery do IdP antes de salvar, para que um issuer
+// digitado errado seja recusado aqui e não no primeiro login.
+func (s *ssoService) UpdateConfig(orgID uint, configIn schemas.SSOConfigUpdate) (*schemas.SSOConfigPublic, error) {
+	issuer := strings.TrimSpace(configIn.Issuer)
+	if err := validateIssuer(issuer); err != nil {
+		return nil, err
+	}
+	role := models.UserRole(configIn.DefaultRole)
+	if !isCustomizableRole(role) {
+		return nil, fmt.Errorf("%w: default_role must be driver, cliente_ativo or cliente_demo", ErrInvalidSSOConfig)
+	}
+
+	scopes := strings.Join(configIn.Scopes, " ")
+	if len(configIn.Scopes) == 0 {
+		scopes = defaultSSOScopes
+	} else if !containsString(configIn.Scopes, "openid") {
+		scopes = "openid " + scopes
+	}
+
+	domains := make([]string, 0, len(configIn.AllowedDomains))
+	for _, domain := range configIn.AllowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	ssoConfig, err := s.repo.FindByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if ssoConfig == nil {
+		ssoConfig = &models.OrganizationSSOConfig{OrganizationID: orgID, AutoProvision: true}
+	}
+	autoProvision := ssoConfig.AutoProvision
+	if configIn.AutoProvision != nil {
+		autoProvision = *configIn.AutoProvision
+	}
+	// Sem domínios, qualquer conta do IdP (um Google ou Azure AD multi-tenant)
+	// viraria usuário da organização.
+	if autoProvision && len(domains) == 0 {
+		return nil, fmt.Errorf("%w: allowed_domains is required when auto_provision is enabled", ErrInvalidSSOConfig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.client.Discover(ctx, issuer); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSOConfig, err)
+	}
+	ssoConfig.Issuer = issuer
+	ssoConfig.ClientID = strings.TrimSpace(configIn.ClientID)
+	if configIn.ClientSecret != nil {
+		ssoConfig.ClientSecret = *configIn.ClientSecret
+	}
+	ssoConfig.Scopes = scopes
+	ssoConfig.AllowedDomains = strings.Join(domains, " ")
+	ssoConfig.DefaultRole = role
+	ssoConfig.AutoProvision = autoProvision
+	ssoConfig.Enabled = configIn.Enabled
+
+	if err := s.repo.Save(ssoConfig); err != nil {
+		return nil, err
+	}
+	return toSSOConfigPublic(ssoConfig), nil
+}
+
+func (s *ssoService) DeleteConfig(orgID uint) error {
+	ssoConfig, err := s.repo.FindByOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	if ssoConfig == nil {
+		return ErrSSONotConfigured
+	}
+	return s.repo.Delete(orgID)
+}
+
+func (s *ssoService) BeginLogin(orgID uint) (*schemas.SSOAuthorization, error) {
+	ssoConfig, err := s.enabledConfig(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := s.client.Discover(ctx, ssoConfig.Issuer)
+	if err != nil {
+		logging.Logger.Error("OIDC discovery failed", zap.Error(err), zap.Uint("organization_id", orgID))
+		return nil, ErrSSOLoginFailed
+	}
+
+	state, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := core.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := ssoState{OrganizationID: orgID, Nonce: nonce, CodeVerifier: verifier}
+	if err := s.cache.Set(ctx, ssoStateKey(state), pending, ssoStateTTL); err != nil {
+		return nil, err
+	}
+
+	return &schemas.SSOAuthorization{
+		AuthorizationURL: provider.AuthCodeURL(ssoConfig.ClientID, config.AppConfig.OIDC_REDIRECT_URL, ssoConfig.Scopes, state, nonce, verifier),
+	}, nil
+}
+
+func (s *ssoService) CompleteLogin(code, state string, client ClientInfo) (*schemas.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var pending ssoState
+	if err := s.cache.Get(ctx, ssoStateKey(state), &pending); err != nil {
+		return nil, ErrInvalidSSOState
+	}
+	// O state vale para um único callback, mesmo com requisições simultâneas.
+	uses, err := s.cache.Incr(ctx, ssoStateKey(state)+":used", ssoStateTTL)
+	if err != nil {
+		return nil, err
+	}
+	if uses > 1 {
+		return nil, ErrInvalidSSOState
+	}
+	if err := s.cache.Delete(ctx, ssoStateKey(state)); err != nil {
+		logging.Logger.Error("Failed to delete sso state", zap.Error(err))
+	}
+
+	ssoConfig, err := s.enabledConfig(pending.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.client.Discover(ctx, ssoConfig.Issuer)
+	if err != nil {
+		logging.Logger.Error("OIDC discovery failed", zap.Error(err), zap.Uint("organization_id", ssoConfig.OrganizationID))
+		return nil, ErrSSOLoginFailed
+	}
+	rawIDToken, err := s.client.Exchange(ctx, provider, ssoConfig.ClientID, ssoConfig.ClientSecret, config.AppConfig.OIDC_REDIRECT_URL, code, pending.CodeVerifier)
+	if err != nil {
+		logging.Logger.Warn("OIDC code exchange failed", zap.Error(err), zap.Uint("organization_id", ssoConfig.OrganizationID))
+		return nil, ErrSSOLoginFailed
+	}
+	claims, err := s.client.VerifyIDToken(ctx, provider, rawIDToken, ssoConfig.ClientID, pending.Nonce)
+	if err != nil {
+		logging.Logger.Warn("OIDC id token rejected", zap.Error(err), zap.Uint("organization_id", ssoConfig.OrganizationID))
+		return nil, ErrSSOLoginFailed
+	}
+
+	user, err := s.resolveUser(ssoConfig, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.authService.StartSSOSession(user, client)
+}
+
+// resolveUser encontra o usuário pelo e-mail ou o cria com o papel padrão da
+// organização (provisionamento just-in-time).
+func (s *ssoService) resolveUser(ssoConfig *models.OrganizationSSOConfig, claims *oidc.IDTokenClaims) (*models.User, error) {
+	// O e-mail é o que liga a conta do IdP ao usuário: sem email_verified=true
+	// qualquer um que cadastre o e-mail de outra pessoa no IdP entraria como ela.
+	email := models.NormalizeEmail(claims.Email)
+	if email == "" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		return nil, ErrSSOUserNotAllowed
+	}
+	if !emailDomainAllowed(email, ssoConfig.AllowedDomains) {
+		return nil, ErrSSOUserNotAllowed
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		// Um IdP só autentica usuários da própria organização, e nunca super admins.
+		if user.OrganizationID != ssoConfig.OrganizationID || user.Role == models.RoleSuperAdmin || !user.IsActive {
+			return nil, ErrSSOUserNotAllowed
+		}
+		return user, nil
+	}
+
+	// Configurações salvas antes da exigência de domínios não provisionam.
+	if !ssoConfig.AutoProvision || ssoConfig.AllowedDomains == "" {
+		return nil, ErrSSOUserNotAllowed
+	}
+	employeeID, err := generateEmployeeID(s.orgRepo, s.userRepo, ssoConfig.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = email
+	}
+	user = &models.User{
+		FullName:       truncate(fullName, 100),
+		Email:          email,
+		HashedPassword: ssoUnusablePassword,
+		EmployeeID:     employeeID,
+		Role:           ssoConfig.DefaultRole,
+		IsActive:       true,
+		OrganizationID: ssoConfig.OrganizationID,
+	}
+	err = s.quota.CreateWithinLimit(ssoConfig.OrganizationID, models.QuotaDrivers, func(limit int) error {
+		return s.userRepo.Create(user, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Logger.Info("User provisioned through single sign-on",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("organization_id", user.OrganizationID),
+		zap.String("role", string(user.Role)),
+	)
+	return user, nil
+}
+
+func (s *ssoService) enabledConfig(orgID uint) (*models.OrganizationSSOConfig, error) {
+	ssoConfig, err := s.repo.FindByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if ssoConfig == nil || !ssoConfig.Enabled {
+		return nil, ErrSSONotConfigured
+	}
+	return ssoConfig, nil
+}
+
+// validateIssuer exige uma URL HTTPS sem credenciais, query ou fragmento
+// (OIDC Discovery 3). Endereços internos são recusados na conexão, pelo
+// oidc.NewPublicHTTPClient.
+func validateIssuer(issuer string) error {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: issuer must be an absolute URL", ErrInvalidSSOConfig)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("%w: issuer must use https", ErrInvalidSSOConfig)
+	}
+	if parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("%w: issuer must not have credentials, query or fragment", ErrInvalidSSOConfig)
+	}
+	return nil
+}
+
+// emailDomainAllowed aceita qualquer domínio só quando a lista está vazia; o
+// provisionamento just-in-time exige a lista (veja resolveUser).
+func emailDomainAllowed(email, allowedDomains string) bool {
+	if allowedDomains == "" {
+		return true
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	return containsString(strings.Fields(allowedDomains), domain)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func ssoStateKey(state string) string {
+	return "sso:state:" + core.HashToken(state)
+}
+
+func toSSOConfigPublic(ssoConfig *models.OrganizationSSOConfig) *schemas.SSOConfigPublic {
+	return &schemas.SSOConfigPublic{
+		Issuer:          ssoConfig.Issuer,
+		ClientID:        ssoConfig.ClientID,
+		HasClientSecret: ssoConfig.ClientSecret != "",
+		Scopes:          strings.Fields(ssoConfig.Scopes),
+		AllowedDomains:  strings.Fields(ssoConfig.AllowedDomains),
+		DefaultRole:     string(ssoConfig.DefaultRole),
+		AutoProvision:   ssoConfig.AutoProvision,
+		Enabled:         ssoConfig.Enabled,
+		RedirectURI:     config.AppConfig.OIDC_REDIRECT_URL,
+		UpdatedAt:       ssoConfig.UpdatedAt,
+	}
+}
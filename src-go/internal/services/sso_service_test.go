@@ -0,0 +1,294 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"gorm.io/gorm"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/oidc"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+// mockIdP é um provedor OpenID Connect em HTTPS. O token endpoint devolve um
+// id_token assinado com as claims de claims, completadas com iss, aud, exp e o
+// nonce que o serviço mandou na URL de autorização.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	nonce  string
+	claims jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "test", "alg": "RS256", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		claims := jwt.MapClaims{"iss": idp.server.URL, "aud": "trucar", "exp": time.Now().Add(time.Minute).Unix(), "nonce": idp.nonce}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewTLSServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+type ssoFixture struct {
+	db      *gorm.DB
+	org     *models.Organization
+	idp     *mockIdP
+	service SSOService
+}
+
+// newSSOFixture configura o SSO de uma organização com o domínio a.test e
+// provisionamento de motoristas. O cliente OIDC confia no certificado do mock,
+// que escuta em 127.0.0.1.
+func newSSOFixture(t *testing.T) *ssoFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes SSO")
+	idp := newMockIdP(t)
+
+	userRepo := repositories.NewUserRepository(gormDB)
+	orgRepo := repositories.NewOrganizationRepository(gormDB)
+	loginAttempts := NewLoginAttemptService(repositories.NewMemoryCacheRepository(100), userRepo, repositories.NewAccountLockEventRepository(gormDB))
+	twoFactor := NewTwoFactorService(userRepo, orgRepo, repositories.NewTwoFactorRepository(gormDB))
+	auth := NewAuthService(userRepo, repositories.NewRefreshTokenRepository(gormDB), repositories.NewUserSessionRepository(gormDB), repositories.NewImpersonationSessionRepository(gormDB), loginAttempts, twoFactor)
+	quota := NewQuotaService(orgRepo, repositories.NewUsageRepository(gormDB), repositories.NewVehicleRepository(gormDB), userRepo, nil)
+	service := NewSSOService(repositories.NewSSOConfigRepository(gormDB), userRepo, orgRepo, repositories.NewUnboundedMemoryCacheRepository(), auth, quota, oidc.NewClient(idp.server.Client()))
+
+	_, err := service.UpdateConfig(org.ID, schemas.SSOConfigUpdate{
+		Issuer:         idp.server.URL,
+		ClientID:       "trucar",
+		AllowedDomains: []string{"a.test"},
+		DefaultRole:    string(models.RoleDriver),
+		Enabled:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ssoFixture{db: gormDB, org: org, idp: idp, service: service}
+}
+
+// login faz o fluxo completo: BeginLogin, o IdP autentica com claims e o
+// callback chega com o state da URL de autorização.
+func (f *ssoFixture) login(t *testing.T, claims jwt.MapClaims) (*schemas.Token, string, error) {
+	t.Helper()
+	authorization, err := f.service.BeginLogin(f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := url.Parse(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.idp.nonce = authURL.Query().Get("nonce")
+	f.idp.claims = claims
+	state := authURL.Query().Get("state")
+	token, err := f.service.CompleteLogin("code", state, ClientInfo{IP: "203.0.113.7"})
+	return token, state, err
+}
+
+func TestSSOLoginProvisionsVerifiedUser(t *testing.T) {
+	f := newSSOFixture(t)
+	token, _, err := f.login(t, jwt.MapClaims{"sub": "1", "email": "Motorista@A.test", "email_verified": true, "name": "Motorista"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken == "" || token.MFARequired || token.MFASetupRequired {
+		t.Fatalf("token = %+v, want a session", token)
+	}
+
+	user, err := repositories.NewUserRepository(f.db).FindByEmail("motorista@a.test")
+	if err != nil || user == nil {
+		t.Fatalf("provisioned user: %v, %v", user, err)
+	}
+	if user.OrganizationID != f.org.ID || user.Role != models.RoleDriver {
+		t.Errorf("provisioned user in org %d with role %s", user.OrganizationID, user.Role)
+	}
+}
+
+// Um cadastro antigo com maiúsculas é o mesmo usuário, não um novo
+// provisionamento.
+func TestSSOLoginMatchesEmailIgnoringCase(t *testing.T) {
+	f := newSSOFixture(t)
+	existing := createTestUser(t, f.db, f.org.ID, "Motorista@A.test", models.RoleDriver)
+
+	token, _, err := f.login(t, jwt.MapClaims{"sub": "1", "email": "motorista@a.test", "email_verified": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := core.ValidateJWT(token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != existing.ID {
+		t.Errorf("session for user %d, want %d", claims.UserID, existing.ID)
+	}
+	var count int64
+	f.db.Model(&models.User{}).Scopes(repositories.AllOrganizations).Count(&count)
+	if count != 1 {
+		t.Errorf("%d users, want the existing one only", count)
+	}
+}
+
+func TestSSOLoginRejectsUntrustedIdentities(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   error
+	}{
+		{"email not verified", jwt.MapClaims{"sub": "1", "email": "motorista@a.test", "email_verified": false}, ErrSSOUserNotAllowed},
+		{"email_verified missing", jwt.MapClaims{"sub": "1", "email": "motorista@a.test"}, ErrSSOUserNotAllowed},
+		{"email missing", jwt.MapClaims{"sub": "1", "email_verified": true}, ErrSSOUserNotAllowed},
+		{"domain not allowed", jwt.MapClaims{"sub": "1", "email": "alguem@b.test", "email_verified": true}, ErrSSOUserNotAllowed},
+		{"nonce from another login", jwt.MapClaims{"sub": "1", "email": "motorista@a.test", "email_verified": true, "nonce": "outro"}, ErrSSOLoginFailed},
+		{"audience of another client", jwt.MapClaims{"sub": "1", "email": "motorista@a.test", "email_verified": true, "aud": "outro"}, ErrSSOLoginFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newSSOFixture(t)
+			if _, _, err := f.login(t, tc.claims); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+			var count int64
+			f.db.Model(&models.User{}).Scopes(repositories.AllOrganizations).Count(&count)
+			if count != 0 {
+				t.Errorf("%d users provisioned", count)
+			}
+		})
+	}
+}
+
+func TestSSOStateIsSingleUse(t *testing.T) {
+	f := newSSOFixture(t)
+	if _, err := f.service.CompleteLogin("code", "desconhecido", ClientInfo{}); !errors.Is(err, ErrInvalidSSOState) {
+		t.Fatalf("unknown state: got %v, want ErrInvalidSSOState", err)
+	}
+
+	claims := jwt.MapClaims{"sub": "1", "email": "motorista@a.test", "email_verified": true}
+	_, state, err := f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.CompleteLogin("code", state, ClientInfo{}); !errors.Is(err, ErrInvalidSSOState) {
+		t.Fatalf("replayed state: got %v, want ErrInvalidSSOState", err)
+	}
+}
+
+// O IdP substitui a senha, não o segundo fator.
+func TestSSOLoginKeepsTwoFactorRequirements(t *testing.T) {
+	f := newSSOFixture(t)
+	manager := createTestUser(t, f.db, f.org.ID, "gestor@a.test", models.RoleClienteAtivo)
+	claims := jwt.MapClaims{"sub": "2", "email": "gestor@a.test", "email_verified": true}
+
+	f.org.RequireTwoFactorForManagers = true
+	if err := f.db.Save(f.org).Error; err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !token.MFASetupRequired || token.AccessToken != "" {
+		t.Fatalf("organization requires 2FA: token = %+v", token)
+	}
+
+	secret := "JBSWY3DPEHPK3PXP"
+	manager.TwoFactorEnabled, manager.TwoFactorSecret = true, &secret
+	if err := repositories.NewUserRepository(f.db).UpdateColumns(manager, "two_factor_enabled", "two_factor_secret"); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err = f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !token.MFARequired || token.AccessToken != "" {
+		t.Fatalf("user has 2FA: token = %+v", token)
+	}
+}
+
+func TestSSOUpdateConfigValidation(t *testing.T) {
+	f := newSSOFixture(t)
+	disabled := false
+	cases := []struct {
+		name     string
+		configIn schemas.SSOConfigUpdate
+	}{
+		{"http issuer", schemas.SSOConfigUpdate{Issuer: "http://idp.a.test", AllowedDomains: []string{"a.test"}}},
+		{"issuer with query", schemas.SSOConfigUpdate{Issuer: f.idp.server.URL + "?tenant=1", AllowedDomains: []string{"a.test"}}},
+		{"auto provision without domains", schemas.SSOConfigUpdate{Issuer: f.idp.server.URL}},
+		{"auto provision with blank domains", schemas.SSOConfigUpdate{Issuer: f.idp.server.URL, AllowedDomains: []string{" ", "@"}}},
+	}
+	for _, tc := range cases {
+		tc.configIn.ClientID, tc.configIn.DefaultRole = "trucar", string(models.RoleDriver)
+		if _, err := f.service.UpdateConfig(f.org.ID, tc.configIn); !errors.Is(err, ErrInvalidSSOConfig) {
+			t.Errorf("%s: got %v, want ErrInvalidSSOConfig", tc.name, err)
+		}
+	}
+
+	// Sem provisionamento, só usuários já cadastrados entram e os domínios
+	// podem ficar em branco.
+	if _, err := f.service.UpdateConfig(f.org.ID, schemas.SSOConfigUpdate{Issuer: f.idp.server.URL, ClientID: "trucar", DefaultRole: string(models.RoleDriver), AutoProvision: &disabled, Enabled: true}); err != nil {
+		t.Fatalf("auto provision disabled without domains: %v", err)
+	}
+	if _, _, err := f.login(t, jwt.MapClaims{"sub": "1", "email": "motorista@b.test", "email_verified": true}); !errors.Is(err, ErrSSOUserNotAllowed) {
+		t.Fatalf("login without provisioning: got %v, want ErrSSOUserNotAllowed", err)
+	}
+}
+
+// Com o cliente padrão o servidor não alcança endereços internos, como o
+// 127.0.0.1 do mock.
+func TestSSOUpdateConfigRefusesPrivateIssuer(t *testing.T) {
+	f := newSSOFixture(t)
+	gormDB := f.db
+	userRepo := repositories.NewUserRepository(gormDB)
+	service := NewSSOService(repositories.NewSSOConfigRepository(gormDB), userRepo, repositories.NewOrganizationRepository(gormDB), repositories.NewUnboundedMemoryCacheRepository(), nil, nil, oidc.NewClient(nil))
+
+	_, err := service.UpdateConfig(f.org.ID, schemas.SSOConfigUpdate{Issuer: f.idp.server.URL, ClientID: "trucar", AllowedDomains: []string{"a.test"}, DefaultRole: string(models.RoleDriver)})
+	if !errors.Is(err, ErrInvalidSSOConfig) || !strings.Contains(err.Error(), oidc.ErrPrivateAddress.Error()) {
+		t.Fatalf("got %v, want ErrInvalidSSOConfig for a private address", err)
+	}
+}
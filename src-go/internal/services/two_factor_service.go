@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+const (
+	twoFactorIssuer        = "TruCar"
+	twoFactorRecoveryCodes = 10
+)
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+var ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+var ErrTwoFactorSetupNotStarted = errors.New("two-factor setup has not been started")
+
+type TwoFactorService interface {
+	BeginSetup(user *models.User) (*schemas.TwoFactorSetup, error)
+	Activate(user *models.User, code string) ([]string, error)
+	Disable(user *models.User, code string) error
+	RegenerateRecoveryCodes(user *models.User, code string) ([]string, error)
+	Verify(user *models.User, code string) error
+	SetupRequired(user *models.User) (bool, error)
+}
+
+type twoFactorService struct {
+	userRepo      repositories.UserRepository
+	orgRepo       repositories.OrganizationRepository
+	twoFactorRepo repositories.TwoFactorRepository
+}
+
+func NewTwoFactorService(userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository, twoFactorRepo repositories.TwoFactorRepository) TwoFactorService {
+	return &twoFactorService{userRepo: userRepo, orgRepo: orgRepo, twoFactorRepo: twoFactorRepo}
+}
+
+// BeginSetup gera um novo segredo pendente. Ele só passa a valer após Activate
+// confirmar que o aplicativo autenticador foi configurado corretamente.
+func (s *twoFactorService) BeginSetup(user *models.User) (*schemas.TwoFactorSetup, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := core.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorSecret = &secret
+	user.TwoFactorLastUsedStep = 0
+	if err := s.userRepo.UpdateColumns(user, "two_factor_secret", "two_factor_last_used_step"); err != nil {
+		return nil, err
+	}
+
+	return &schemas.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: core.TOTPProvisioningURI(secret, user.Email, twoFactorIssuer),
+	}, nil
+}
+
+func (s *twoFactorService) Activate(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, ErrTwoFactorSetupNotStarted
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.userRepo.UpdateColumns(user, "two_factor_enabled"); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+func (s *twoFactorService) Disable(user *models.User, code string) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = nil
+	user.TwoFactorLastUsedStep = 0
+	if err := s.userRepo.UpdateColumns(user, "two_factor_enabled", "two_factor_secret", "two_factor_last_used_step"); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.DeleteRecoveryCodes(user.ID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Verify aceita tanto o código do aplicativo quanto um código de recuperação.
+func (s *twoFactorService) Verify(user *models.User, code string) error {
+	if !user.TwoFactorEnabled || user.TwoFactorSecret == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if strings.Contains(code, "-") {
+		used, err := s.twoFactorRepo.UseRecoveryCode(user.ID, core.HashToken(strings.ToLower(code)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	return s.verifyTOTP(user, code)
+}
+
+// SetupRequired indica se a organização obriga este gestor a cadastrar o 2FA
+// antes de concluir o login.
+func (s *twoFactorService) SetupRequired(user *models.User) (bool, error) {
+	if user.TwoFactorEnabled {
+		return false, nil
+	}
+	if user.Role != models.RoleClienteAtivo && user.Role != models.RoleClienteDemo {
+		return false, nil
+	}
+
+	org, err := s.orgRepo.FindByID(user.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	return org != nil && org.RequireTwoFactorForManagers, nil
+}
+
+// verifyTOTP recusa um passo já usado para que um código interceptado não
+// possa ser reaproveitado dentro da janela de 30s. A comparação com o último
+// passo vale no UPDATE, não só no usuário lido, para pegar requisições
+// simultâneas com o mesmo código.
+func (s *twoFactorService) verifyTOTP(user *models.User, code string) error {
+	step, ok := core.ValidateTOTP(*user.TwoFactorSecret, code, time.Now())
+	if !ok || step <= user.TwoFactorLastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.twoFactorRepo.UseTOTPStep(user, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TwoFactorLastUsedStep = step
+	return nil
+}
+
+func (s *twoFactorService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes, err := core.GenerateRecoveryCodes(twoFactorRecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models.TwoFactorRecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = models.TwoFactorRecoveryCode{UserID: userID, CodeHash: core.HashToken(code)}
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
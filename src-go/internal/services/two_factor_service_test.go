@@ -0,0 +1,205 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+// currentTOTP calcula o código do passo atual, como o aplicativo autenticador.
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+type twoFactorFixture struct {
+	db       *gorm.DB
+	userRepo repositories.UserRepository
+	service  TwoFactorService
+	user     *models.User
+}
+
+func newTwoFactorFixture(t *testing.T) *twoFactorFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes 2FA")
+	user := createTestUser(t, gormDB, org.ID, "gestor@example.com", models.RoleClienteAtivo)
+	userRepo := repositories.NewUserRepository(gormDB)
+	service := NewTwoFactorService(userRepo, repositories.NewOrganizationRepository(gormDB), repositories.NewTwoFactorRepository(gormDB))
+	return &twoFactorFixture{db: gormDB, userRepo: userRepo, service: service, user: user}
+}
+
+// reload lê o usuário de novo, como cada requisição faz.
+func (f *twoFactorFixture) reload(t *testing.T) *models.User {
+	t.Helper()
+	user, err := f.userRepo.FindByID(f.user.ID, f.user.OrganizationID)
+	if err != nil || user == nil {
+		t.Fatalf("reload user: %v, %v", user, err)
+	}
+	return user
+}
+
+// enroll cadastra o 2FA e devolve o segredo e os códigos de recuperação. O
+// passo usado na ativação é zerado para que o teste possa usar o código atual.
+func (f *twoFactorFixture) enroll(t *testing.T) (string, []string) {
+	t.Helper()
+	setup, err := f.service.BeginSetup(f.reload(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := f.service.Activate(f.reload(t), currentTOTP(t, setup.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.db.Model(&models.User{}).Scopes(repositories.AllOrganizations).Where("id = ?", f.user.ID).
+		UpdateColumn("two_factor_last_used_step", 0).Error; err != nil {
+		t.Fatal(err)
+	}
+	return setup.Secret, codes
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	f := newTwoFactorFixture(t)
+
+	if _, err := f.service.Activate(f.reload(t), "123456"); !errors.Is(err, ErrTwoFactorSetupNotStarted) {
+		t.Fatalf("activate before setup: got %v, want ErrTwoFactorSetupNotStarted", err)
+	}
+
+	setup, err := f.service.BeginSetup(f.reload(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(setup.ProvisioningURI, "otpauth://totp/TruCar:") || !strings.Contains(setup.ProvisioningURI, "secret="+setup.Secret) {
+		t.Errorf("provisioning uri = %s", setup.ProvisioningURI)
+	}
+	if f.reload(t).TwoFactorEnabled {
+		t.Fatal("2fa enabled before the first code")
+	}
+
+	codes, err := f.service.Activate(f.reload(t), currentTOTP(t, setup.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != twoFactorRecoveryCodes {
+		t.Errorf("%d recovery codes, want %d", len(codes), twoFactorRecoveryCodes)
+	}
+	user := f.reload(t)
+	if !user.TwoFactorEnabled || user.TwoFactorSecret == nil || *user.TwoFactorSecret != setup.Secret {
+		t.Fatalf("2fa not stored: enabled=%v", user.TwoFactorEnabled)
+	}
+	if _, err := f.service.BeginSetup(user); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Fatalf("setup again: got %v, want ErrTwoFactorAlreadyEnabled", err)
+	}
+}
+
+func TestTwoFactorVerify(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	if err := f.service.Verify(f.reload(t), "123456"); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("without 2fa: got %v, want ErrTwoFactorNotEnabled", err)
+	}
+
+	secret, _ := f.enroll(t)
+	if err := f.service.Verify(f.reload(t), "12345"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("short code: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := f.service.Verify(f.reload(t), currentTOTP(t, secret)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTwoFactorRejectsReplayedCode(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	secret, _ := f.enroll(t)
+	code := currentTOTP(t, secret)
+
+	// As duas requisições leram o usuário antes de qualquer uma gravar o passo.
+	first, second := f.reload(t), f.reload(t)
+	if err := f.service.Verify(first, code); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.Verify(first, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("same request: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := f.service.Verify(second, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("concurrent request: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := f.service.Verify(f.reload(t), code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("later request: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+}
+
+func TestTwoFactorRecoveryCodesAreSingleUse(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	_, codes := f.enroll(t)
+
+	if err := f.service.Verify(f.reload(t), codes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.Verify(f.reload(t), codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := f.service.Verify(f.reload(t), " "+strings.ToUpper(codes[1])+" "); err != nil {
+		t.Fatalf("recovery code typed in upper case: %v", err)
+	}
+	if err := f.service.Verify(f.reload(t), "aaaaa-aaaaa"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("unknown recovery code: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	// Códigos novos invalidam os anteriores.
+	fresh, err := f.service.RegenerateRecoveryCodes(f.reload(t), codes[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.Verify(f.reload(t), codes[3]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("code from the old set: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := f.service.Verify(f.reload(t), fresh[0]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginAsksForSecondFactor(t *testing.T) {
+	f := newAuthFixture(t)
+	twoFactor := &twoFactorFixture{db: f.db, userRepo: f.userRepo, service: f.twoFactor, user: f.user}
+	secret, _ := twoFactor.enroll(t)
+
+	challenge, err := f.service.Login(f.user.Email, "old password 123", ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !challenge.MFARequired || challenge.MFAToken == "" || challenge.AccessToken != "" {
+		t.Fatalf("token = %+v, want an mfa challenge", challenge)
+	}
+	if _, err := f.service.VerifyTwoFactor(challenge.MFAToken, "aaaaa-aaaaa", ClientInfo{IP: "10.0.0.1"}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("wrong code: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+	token, err := f.service.VerifyTwoFactor(challenge.MFAToken, currentTOTP(t, secret), ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken == "" || token.RefreshToken == "" {
+		t.Fatalf("token = %+v, want a session", token)
+	}
+}
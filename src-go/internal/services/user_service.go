@@ -2,30 +2,64 @@ package services
 
 import (
 	"errors"
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"time"
 
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
+	"go-api/internal/storage"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+var ErrEmployeeIDTaken = errors.New("employee id is already in use in this organization")
+var ErrRoleNotAllowed = errors.New("role is not allowed")
+
+// maxEmployeeIDAttempts limita quantos números da sequência são pulados por
+// já estarem em uso como matrícula informada manualmente.
+const maxEmployeeIDAttempts = 10
+
 type UserService interface {
 	GetUsers(orgID uint, skip, limit int) ([]models.User, error)
 	GetUser(userID, orgID uint) (*models.User, error)
 	CreateUser(userIn schemas.UserCreate, orgID uint) (*models.User, error)
-	UpdateUser(userID, orgID uint, userIn schemas.UserUpdate) (*models.User, error)
+	ImportUsers(orgID uint, rows []schemas.ImportRow[schemas.UserImport], report *schemas.ImportReport) error
+	ExportUsers(orgID uint) ([]schemas.UserImport, error)
+	UpdateUser(userID, orgID uint, userIn schemas.UserUpdate, ifMatch *uint) (*models.User, error)
 	DeleteUser(userID, orgID uint) error
+	UploadAvatar(userID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.User, error)
+	DeleteAvatar(userID, orgID uint, ifMatch *uint) (*models.User, error)
 	GetAllUsers(skip, limit int) ([]models.User, error)
 	GetDemoUsers() ([]models.User, error)
 	ActivateUser(userID uint) (*models.User, error)
 }
 
 type userService struct {
-	repo repositories.UserRepository
+	repo             repositories.UserRepository
+	orgRepo          repositories.OrganizationRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	sessionRepo      repositories.UserSessionRepository
+	passwordPolicy   PasswordPolicyService
+	passwordReset    PasswordResetService
+	quota            QuotaService
+	photos           photoStore
+}
+
+func NewUserService(repo repositories.UserRepository, orgRepo repositories.OrganizationRepository, refreshTokenRepo repositories.RefreshTokenRepository, sessionRepo repositories.UserSessionRepository, passwordPolicy PasswordPolicyService, passwordReset PasswordResetService, quota QuotaService, storage storage.FileStorageService) UserService {
+	return &userService{repo: repo, orgRepo: orgRepo, refreshTokenRepo: refreshTokenRepo, sessionRepo: sessionRepo, passwordPolicy: passwordPolicy, passwordReset: passwordReset, quota: quota, photos: photoStore{storage: storage}}
 }
 
-func NewUserService(repo repositories.UserRepository) UserService {
-	return &userService{repo: repo}
+// assignableRole aplica o papel padrão (motorista) e recusa papéis
+// desconhecidos e o de super admin, que o gestor da organização não concede.
+func assignableRole(role *models.UserRole) bool {
+	if *role == "" {
+		*role = models.RoleDriver
+	}
+	_, ok := models.DefaultRolePermissions[*role]
+	return ok && *role != models.RoleSuperAdmin
 }
 
 func (s *userService) GetUsers(orgID uint, skip, limit int) ([]models.User, error) {
@@ -37,20 +71,28 @@ func (s *userService) GetUser(userID, orgID uint) (*models.User, error) {
 }
 
 func (s *userService) CreateUser(userIn schemas.UserCreate, orgID uint) (*models.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userIn.Password), bcrypt.DefaultCost)
+	if !assignableRole(&userIn.Role) {
+		return nil, ErrRoleNotAllowed
+	}
+	employeeID, err := s.resolveEmployeeID(userIn.EmployeeID, orgID)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &models.User{
-		Email:          userIn.Email,
+		Email:          models.NormalizeEmail(userIn.Email),
 		FullName:       userIn.FullName,
-		HashedPassword: string(hashedPassword),
+		EmployeeID:     employeeID,
 		Role:           userIn.Role,
 		OrganizationID: orgID,
 	}
+	if err := s.passwordPolicy.SetPassword(user, userIn.Password); err != nil {
+		return nil, err
+	}
 
-	err = s.repo.Create(user)
+	err = s.quota.CreateWithinLimit(orgID, models.QuotaDrivers, func(limit int) error {
+		return s.repo.Create(user, limit)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +100,161 @@ func (s *userService) CreateUser(userIn schemas.UserCreate, orgID uint) (*models
 	return user, nil
 }
 
-func (s *userService) UpdateUser(userID, orgID uint, userIn schemas.UserUpdate) (*models.User, error) {
+// ImportUsers segue as regras do ImportVehicles. Sem papel, a linha vira
+// motorista; e-mails e matrículas repetidos no arquivo ou já cadastrados viram
+// erros da linha. Os usuários entram sem senha utilizável e recebem por e-mail
+// o link para criá-la: calcular o bcrypt de cada linha prenderia a requisição
+// por minutos, e a planilha não precisa circular com senhas.
+func (s *userService) ImportUsers(orgID uint, rows []schemas.ImportRow[schemas.UserImport], report *schemas.ImportReport) error {
+	emails := newImportUniqueness("email", "email")
+	employeeIDs := newImportUniqueness("employee_id", "employee id")
+	drivers := 0
+	for i := range rows {
+		row := &rows[i]
+		if !assignableRole(&row.Data.Role) {
+			report.Errors = append(report.Errors, schemas.ImportError{Line: row.Line, Column: "role", Message: fmt.Sprintf("role %q is not allowed", row.Data.Role)})
+		}
+		if row.Data.Role == models.RoleDriver {
+			drivers++
+		}
+		if row.Data.EmployeeID != nil {
+			employeeID := strings.TrimSpace(*row.Data.EmployeeID)
+			row.Data.EmployeeID = &employeeID
+		}
+		row.Data.Email = models.NormalizeEmail(row.Data.Email)
+		emails.add(row.Line, &row.Data.Email)
+		employeeIDs.add(row.Line, row.Data.EmployeeID)
+	}
+	if err := s.quota.CheckCapacity(orgID, models.QuotaDrivers, drivers); err != nil {
+		return err
+	}
+
+	existingEmails, err := s.repo.FindExistingEmails(emails.values())
+	if err != nil {
+		return err
+	}
+	existingEmployeeIDs, err := s.repo.FindExistingEmployeeIDs(orgID, employeeIDs.values())
+	if err != nil {
+		return err
+	}
+	report.Errors = append(report.Errors, emails.errors(existingEmails)...)
+	report.Errors = append(report.Errors, employeeIDs.errors(existingEmployeeIDs)...)
+	sortImportErrors(report.Errors)
+
+	if len(report.Errors) > 0 || report.DryRun {
+		return nil
+	}
+
+	users := make([]models.User, 0, len(rows))
+	tokens := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var employeeID string
+		if row.Data.EmployeeID != nil && *row.Data.EmployeeID != "" {
+			employeeID = *row.Data.EmployeeID
+		} else if employeeID, err = s.nextImportEmployeeID(orgID, employeeIDs); err != nil {
+			return err
+		}
+		user := models.User{
+			Email:          row.Data.Email,
+			FullName:       row.Data.FullName,
+			EmployeeID:     employeeID,
+			Role:           row.Data.Role,
+			IsActive:       true,
+			NotifyInApp:    true,
+			NotifyByEmail:  true,
+			OrganizationID: orgID,
+		}
+		token, err := s.passwordReset.PrepareSetup(&user)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+		tokens = append(tokens, token)
+	}
+	err = s.quota.CreateWithinLimit(orgID, models.QuotaDrivers, func(limit int) error {
+		return s.repo.CreateBatch(users, limit)
+	})
+	if err != nil {
+		return err
+	}
+	s.passwordReset.SendSetupLinks(users, tokens)
+	report.Created = len(users)
+	return nil
+}
+
+// nextImportEmployeeID gera a matrícula pela sequência, pulando também as
+// informadas em outras linhas do mesmo arquivo.
+func (s *userService) nextImportEmployeeID(orgID uint, fileIDs *importUniqueness) (string, error) {
+	for i := 0; i < maxEmployeeIDAttempts; i++ {
+		employeeID, err := generateEmployeeID(s.orgRepo, s.repo, orgID)
+		if err != nil {
+			return "", err
+		}
+		if !fileIDs.has(employeeID) {
+			return employeeID, nil
+		}
+	}
+	return "", ErrEmployeeIDTaken
+}
+
+// ExportUsers devolve os usuários no formato da importação.
+func (s *userService) ExportUsers(orgID uint) ([]schemas.UserImport, error) {
+	users, err := s.repo.FindByOrganization(orgID, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]schemas.UserImport, 0, len(users))
+	for _, user := range users {
+		if user.Role == models.RoleSuperAdmin {
+			continue
+		}
+		employeeID := user.EmployeeID
+		rows = append(rows, schemas.UserImport{
+			Email:      user.Email,
+			FullName:   user.FullName,
+			Role:       user.Role,
+			EmployeeID: &employeeID,
+		})
+	}
+	return rows, nil
+}
+
+// resolveEmployeeID usa a matrícula informada pelo gestor ou gera a próxima
+// da sequência da organização.
+func (s *userService) resolveEmployeeID(requested *string, orgID uint) (string, error) {
+	if requested != nil && strings.TrimSpace(*requested) != "" {
+		employeeID := strings.TrimSpace(*requested)
+		existing, err := s.repo.FindByEmployeeID(orgID, employeeID)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			return "", ErrEmployeeIDTaken
+		}
+		return employeeID, nil
+	}
+	return generateEmployeeID(s.orgRepo, s.repo, orgID)
+}
+
+func generateEmployeeID(orgRepo repositories.OrganizationRepository, userRepo repositories.UserRepository, orgID uint) (string, error) {
+	for i := 0; i < maxEmployeeIDAttempts; i++ {
+		number, err := orgRepo.NextEmployeeNumber(orgID)
+		if err != nil {
+			return "", err
+		}
+		employeeID := models.FormatEmployeeID(number)
+		existing, err := userRepo.FindByEmployeeID(orgID, employeeID)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return employeeID, nil
+		}
+	}
+	return "", ErrEmployeeIDTaken
+}
+
+func (s *userService) UpdateUser(userID, orgID uint, userIn schemas.UserUpdate, ifMatch *uint) (*models.User, error) {
 	user, err := s.repo.FindByID(userID, orgID)
 	if err != nil {
 		return nil, err
@@ -67,24 +263,54 @@ func (s *userService) UpdateUser(userID, orgID uint, userIn schemas.UserUpdate)
 		return nil, nil // Or return a not found error
 	}
 
-	if userIn.FullName != "" {
-		user.FullName = userIn.FullName
-	}
-	if userIn.Email != "" {
-		user.Email = userIn.Email
-	}
-	if userIn.IsActive != nil {
-		user.IsActive = *userIn.IsActive
+	if err := checkVersion(ifMatch, user.Version); err != nil {
+		return nil, err
 	}
+	wasActive := user.IsActive
 
-	err = s.repo.Update(user)
+	err = errors.Join(
+		patchRequired("full_name", userIn.FullName, &user.FullName),
+		patchRequired("email", userIn.Email, &user.Email),
+		patchValue("is_active", userIn.IsActive, &user.IsActive),
+	)
 	if err != nil {
 		return nil, err
 	}
+	if userIn.Email.Set {
+		user.Email = models.NormalizeEmail(user.Email)
+		if _, err := mail.ParseAddress(user.Email); err != nil {
+			return nil, fmt.Errorf("%w: invalid email", ErrInvalidPatch)
+		}
+	}
+	if userIn.Password.Set {
+		if userIn.Password.Null {
+			return nil, fmt.Errorf("%w: password cannot be null", ErrInvalidPatch)
+		}
+		if err := s.passwordPolicy.SetPassword(user, userIn.Password.Value); err != nil {
+			return nil, err
+		}
+	}
 
+	if err := s.repo.UpdateVersioned(user); err != nil {
+		return nil, versionError(err)
+	}
+	if wasActive && !user.IsActive {
+		if err := s.revokeSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
+// revokeSessions derruba todas as sessões e famílias de refresh token do
+// usuário, como no reset de senha.
+func (s *userService) revokeSessions(userID uint) error {
+	if err := s.sessionRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeAllForUser(userID)
+}
+
 func (s *userService) DeleteUser(userID, orgID uint) error {
 	user, err := s.repo.FindByID(userID, orgID)
 	if err != nil {
@@ -94,7 +320,57 @@ func (s *userService) DeleteUser(userID, orgID uint) error {
 		return nil // Or return a not found error
 	}
 
-	return s.repo.Delete(user)
+	if err := s.repo.Delete(user); err != nil {
+		return err
+	}
+	if err := s.revokeSessions(user.ID); err != nil {
+		return err
+	}
+	s.photos.remove(orgID, user.AvatarURL, user.AvatarThumbnailURL)
+	return nil
+}
+
+// UploadAvatar grava o avatar e a miniatura e só apaga os arquivos anteriores
+// depois que o usuário foi salvo com os novos.
+func (s *userService) UploadAvatar(userID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.User, error) {
+	user, err := s.repo.FindByID(userID, orgID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, user.Version); err != nil {
+		return nil, err
+	}
+
+	photo, err := s.photos.save(file, orgID, "avatars")
+	if err != nil {
+		return nil, err
+	}
+	return s.replaceAvatar(user, photo)
+}
+
+func (s *userService) DeleteAvatar(userID, orgID uint, ifMatch *uint) (*models.User, error) {
+	user, err := s.repo.FindByID(userID, orgID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, user.Version); err != nil {
+		return nil, err
+	}
+	return s.replaceAvatar(user, nil)
+}
+
+func (s *userService) replaceAvatar(user *models.User, photo *storedPhoto) (*models.User, error) {
+	oldAvatar, oldThumbnail := user.AvatarURL, user.AvatarThumbnailURL
+	user.AvatarURL, user.AvatarThumbnailURL = nil, nil
+	if photo != nil {
+		user.AvatarURL, user.AvatarThumbnailURL = photo.urls()
+	}
+	if err := s.repo.UpdateVersioned(user); err != nil {
+		s.photos.remove(user.OrganizationID, user.AvatarURL, user.AvatarThumbnailURL)
+		return nil, versionError(err)
+	}
+	s.photos.remove(user.OrganizationID, oldAvatar, oldThumbnail)
+	return user, nil
 }
 
 func (s *userService) GetAllUsers(skip, limit int) ([]models.User, error) {
@@ -118,7 +394,23 @@ func (s *userService) ActivateUser(userID uint) (*models.User, error) {
 		return nil, errors.New("user is not a demo client")
 	}
 
+	now := time.Now()
 	user.Role = models.RoleClienteAtivo
-	err = s.repo.Update(user)
-	return user, err
+	user.ActivatedAt = &now
+	if err := s.repo.UpdateVersioned(user); err != nil {
+		return nil, err
+	}
+
+	// A ativação encerra a demonstração e libera de novo as alterações.
+	org, err := s.orgRepo.FindByID(user.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org.TrialEndsAt != nil {
+		org.TrialEndsAt = nil
+		if _, err := s.orgRepo.Update(org); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
 }
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"go-api/internal/core"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+type userFixture struct {
+	db            *gorm.DB
+	org           *models.Organization
+	userRepo      repositories.UserRepository
+	service       UserService
+	passwordReset PasswordResetService
+	mailer        *testMailer
+}
+
+func newUserFixture(t *testing.T) *userFixture {
+	t.Helper()
+	gormDB := newTestDB(t)
+	org := createTestOrganization(t, gormDB, "Transportes Equipe")
+	userRepo := repositories.NewUserRepository(gormDB)
+	orgRepo := repositories.NewOrganizationRepository(gormDB)
+	refreshTokens, sessions := repositories.NewRefreshTokenRepository(gormDB), repositories.NewUserSessionRepository(gormDB)
+	policy := NewPasswordPolicyService(repositories.NewPasswordHistoryRepository(gormDB))
+	mailer := newTestMailer()
+	passwordReset := NewPasswordResetService(userRepo, refreshTokens, sessions, policy, NewEmailRequestThrottle(repositories.NewUnboundedMemoryCacheRepository()), mailer)
+	quota := NewQuotaService(orgRepo, repositories.NewUsageRepository(gormDB), repositories.NewVehicleRepository(gormDB), userRepo, nil)
+	service := NewUserService(userRepo, orgRepo, refreshTokens, sessions, policy, passwordReset, quota, nil)
+	return &userFixture{db: gormDB, org: org, userRepo: userRepo, service: service, passwordReset: passwordReset, mailer: mailer}
+}
+
+func TestCreateUserRejectsRolesTheManagerCannotGrant(t *testing.T) {
+	f := newUserFixture(t)
+	for _, role := range []models.UserRole{models.RoleSuperAdmin, "dono"} {
+		userIn := schemas.UserCreate{Email: "novo@example.com", FullName: "Novo", Password: "senha longa 2024", Role: role}
+		if _, err := f.service.CreateUser(userIn, f.org.ID); !errors.Is(err, ErrRoleNotAllowed) {
+			t.Fatalf("role %q: got %v, want ErrRoleNotAllowed", role, err)
+		}
+	}
+
+	user, err := f.service.CreateUser(schemas.UserCreate{Email: "novo@example.com", FullName: "Novo", Password: "senha longa 2024"}, f.org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleDriver {
+		t.Fatalf("role = %q, want the driver default", user.Role)
+	}
+}
+
+func TestImportUsersSendsPasswordSetupLinks(t *testing.T) {
+	f := newUserFixture(t)
+	rows := []schemas.ImportRow[schemas.UserImport]{
+		{Line: 2, Data: schemas.UserImport{Email: " Bia@Example.com ", FullName: "Bia"}},
+		{Line: 3, Data: schemas.UserImport{Email: "caio@example.com", FullName: "Caio", Role: models.RoleClienteAtivo}},
+		{Line: 4, Data: schemas.UserImport{Email: "root@example.com", FullName: "Root", Role: models.RoleSuperAdmin}},
+	}
+	report := &schemas.ImportReport{}
+	if err := f.service.ImportUsers(f.org.ID, rows, report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Line != 4 || report.Errors[0].Column != "role" || report.Created != 0 {
+		t.Fatalf("report = %+v, want only the super admin row refused", report)
+	}
+
+	report = &schemas.ImportReport{}
+	if err := f.service.ImportUsers(f.org.ID, rows[:2], report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 2 || len(report.Errors) != 0 {
+		t.Fatalf("report = %+v, want 2 users created", report)
+	}
+
+	tokens := map[string]string{}
+	for i := 0; i < 2; i++ {
+		mail := receiveMail(t, f.mailer)
+		match := resetLinkToken.FindStringSubmatch(mail.body)
+		if len(mail.to) != 1 || match == nil {
+			t.Fatalf("unexpected setup email to %v: %q", mail.to, mail.body)
+		}
+		tokens[mail.to[0]] = match[1]
+	}
+
+	bia, err := f.userRepo.FindByEmail("bia@example.com")
+	if err != nil || bia == nil {
+		t.Fatalf("imported user: %v, %v", bia, err)
+	}
+	if bia.Role != models.RoleDriver || bia.HashedPassword != "" {
+		t.Fatalf("imported user has role %q and a usable password", bia.Role)
+	}
+	if err := f.passwordReset.ResetPassword(tokens["bia@example.com"], "senha nova da bia"); err != nil {
+		t.Fatal(err)
+	}
+	bia, _ = f.userRepo.FindByEmail("bia@example.com")
+	if !core.CheckPasswordHash("senha nova da bia", bia.HashedPassword) {
+		t.Fatal("password set through the link does not match")
+	}
+	if err := f.passwordReset.ResetPassword(tokens["bia@example.com"], "outra senha da bia"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("link reused: got %v, want ErrInvalidResetToken", err)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-api/internal/logging"
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+)
+
+const vehicleCacheTTL = 10 * time.Minute
+
+// vehicleCache concentra as chaves e tags de veículos no cache. O detalhe de um
+// veículo é marcado com a tag dele e cada página da listagem com a tag da
+// frota; todo serviço que grava um veículo chama invalidate, que descarta as
+// duas, para listagem e detalhe não divergirem.
+type vehicleCache struct {
+	cache repositories.CacheRepository
+}
+
+// vehiclePage é o que fica no cache para uma página da listagem.
+type vehiclePage struct {
+	Vehicles []models.Vehicle
+	Total    int64
+}
+
+func vehicleKey(orgID, vehicleID uint) string {
+	return repositories.TenantCacheKey(orgID, "vehicle", vehicleID)
+}
+
+func vehicleListKey(orgID uint, skip, limit int, search string) string {
+	return repositories.TenantCacheKey(orgID, "vehicles", skip, limit, search)
+}
+
+func vehicleListTag(orgID uint) string {
+	return repositories.TenantCacheKey(orgID, "vehicles")
+}
+
+func (c vehicleCache) getVehicle(orgID, vehicleID uint) *models.Vehicle {
+	var vehicle models.Vehicle
+	if err := c.cache.Get(context.Background(), vehicleKey(orgID, vehicleID), &vehicle); err != nil {
+		return nil
+	}
+	return &vehicle
+}
+
+func (c vehicleCache) setVehicle(vehicle *models.Vehicle) {
+	key := vehicleKey(vehicle.OrganizationID, vehicle.ID)
+	c.cache.Set(context.Background(), key, vehicle, vehicleCacheTTL, key)
+}
+
+func (c vehicleCache) getPage(orgID uint, skip, limit int, search string) *vehiclePage {
+	var page vehiclePage
+	if err := c.cache.Get(context.Background(), vehicleListKey(orgID, skip, limit, search), &page); err != nil {
+		return nil
+	}
+	return &page
+}
+
+func (c vehicleCache) setPage(orgID uint, skip, limit int, search string, page vehiclePage) {
+	c.cache.Set(context.Background(), vehicleListKey(orgID, skip, limit, search), page, vehicleCacheTTL, vehicleListTag(orgID))
+}
+
+// invalidate descarta as listagens da organização e o detalhe dos veículos
+// informados. Falhas do cache não desfazem a gravação já feita no banco.
+func (c vehicleCache) invalidate(orgID uint, vehicleIDs ...uint) {
+	tags := []string{vehicleListTag(orgID)}
+	for _, id := range vehicleIDs {
+		tags = append(tags, vehicleKey(orgID, id))
+	}
+	if err := c.cache.InvalidateTags(context.Background(), tags...); err != nil {
+		logging.Logger.Error("Failed to invalidate vehicle cache", zap.Error(err), zap.Uint("organization_id", orgID))
+	}
+}
@@ -1,35 +1,47 @@
 package services
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"time"
+	"mime/multipart"
 
 	"go-api/internal/models"
 	"go-api/internal/repositories"
 	"go-api/internal/schemas"
+	"go-api/internal/storage"
 )
 
 type VehicleService interface {
 	GetVehicles(orgID uint, skip, limit int, search string) ([]models.Vehicle, int64, error)
 	GetVehicle(vehicleID, orgID uint) (*models.Vehicle, error)
 	CreateVehicle(vehicleIn schemas.VehicleCreate, orgID uint) (*models.Vehicle, error)
-	UpdateVehicle(vehicleID, orgID uint, vehicleIn schemas.VehicleUpdate) (*models.Vehicle, error)
+	ImportVehicles(orgID uint, rows []schemas.ImportRow[schemas.VehicleCreate], report *schemas.ImportReport) error
+	ExportVehicles(orgID uint) ([]schemas.VehicleCreate, error)
+	UpdateVehicle(vehicleID, orgID uint, vehicleIn schemas.VehicleUpdate, ifMatch *uint) (*models.Vehicle, error)
+	UploadPhoto(vehicleID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.Vehicle, error)
+	DeletePhoto(vehicleID, orgID uint, ifMatch *uint) (*models.Vehicle, error)
 	DeleteVehicle(vehicleID, orgID uint) error
+	ChangeStatus(vehicleID, orgID uint, statusIn schemas.VehicleStatusUpdate, actorID uint, ifMatch *uint) (*models.Vehicle, error)
+	GetStatusHistory(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.VehicleStatusPeriod, error)
 }
 
 type vehicleService struct {
-	repo repositories.VehicleRepository
-	cache repositories.CacheRepository
+	repo   repositories.VehicleRepository
+	cache  vehicleCache
+	quota  QuotaService
+	status VehicleStatusService
+	photos photoStore
 }
 
-func NewVehicleService(repo repositories.VehicleRepository, cache repositories.CacheRepository) VehicleService {
-	return &vehicleService{repo: repo, cache: cache}
+func NewVehicleService(repo repositories.VehicleRepository, cache repositories.CacheRepository, quota QuotaService, status VehicleStatusService, storage storage.FileStorageService) VehicleService {
+	return &vehicleService{repo: repo, cache: vehicleCache{cache: cache}, quota: quota, status: status, photos: photoStore{storage: storage}}
 }
 
 func (s *vehicleService) GetVehicles(orgID uint, skip, limit int, search string) ([]models.Vehicle, int64, error) {
-	// Caching para listas é mais complexo e pode ser implementado depois.
-	// Por enquanto, buscamos diretamente do banco.
+	if page := s.cache.getPage(orgID, skip, limit, search); page != nil {
+		return page.Vehicles, page.Total, nil
+	}
+
 	vehicles, err := s.repo.FindByOrganization(orgID, skip, limit, search)
 	if err != nil {
 		return nil, 0, err
@@ -38,21 +50,17 @@ func (s *vehicleService) GetVehicles(orgID uint, skip, limit int, search string)
 	if err != nil {
 		return nil, 0, err
 	}
+	s.cache.setPage(orgID, skip, limit, search, vehiclePage{Vehicles: vehicles, Total: total})
 	return vehicles, total, nil
 }
 
 func (s *vehicleService) GetVehicle(vehicleID, orgID uint) (*models.Vehicle, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("vehicle:%d", vehicleID)
-
-	var vehicle models.Vehicle
-	err := s.cache.Get(ctx, cacheKey, &vehicle)
-	if err == nil {
-		// Cache hit
-		return &vehicle, nil
+	// A chave inclui a organização: um veículo de outro tenant nunca é
+	// encontrado no cache e o banco filtra pela organização.
+	if vehicle := s.cache.getVehicle(orgID, vehicleID); vehicle != nil {
+		return vehicle, nil
 	}
 
-	// Cache miss
 	dbVehicle, err := s.repo.FindByID(vehicleID, orgID)
 	if err != nil {
 		return nil, err
@@ -61,33 +69,113 @@ func (s *vehicleService) GetVehicle(vehicleID, orgID uint) (*models.Vehicle, err
 		return nil, nil // Not found
 	}
 
-	// Set cache for future requests
-	s.cache.Set(ctx, cacheKey, dbVehicle, 10*time.Minute)
-
+	s.cache.setVehicle(dbVehicle)
 	return dbVehicle, nil
 }
 
 func (s *vehicleService) CreateVehicle(vehicleIn schemas.VehicleCreate, orgID uint) (*models.Vehicle, error) {
-	vehicle := &models.Vehicle{
-		Brand:              vehicleIn.Brand,
-		Model:              vehicleIn.Model,
-		Year:               vehicleIn.Year,
-		LicensePlate:       vehicleIn.LicensePlate,
-		Identifier:         vehicleIn.Identifier,
-		PhotoURL:           vehicleIn.PhotoURL,
-		CurrentKM:          vehicleIn.CurrentKM,
-		CurrentEngineHours: vehicleIn.CurrentEngineHours,
+	vehicle := newVehicle(vehicleIn, orgID)
+	err := s.quota.CreateWithinLimit(orgID, models.QuotaVehicles, func(limit int) error {
+		return s.repo.Create(vehicle, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.invalidate(orgID)
+	return vehicle, nil
+}
+
+func newVehicle(vehicleIn schemas.VehicleCreate, orgID uint) *models.Vehicle {
+	return &models.Vehicle{
+		Brand:               vehicleIn.Brand,
+		Model:               vehicleIn.Model,
+		Year:                vehicleIn.Year,
+		LicensePlate:        vehicleIn.LicensePlate,
+		Identifier:          vehicleIn.Identifier,
+		PhotoURL:            vehicleIn.PhotoURL,
+		CurrentKM:           vehicleIn.CurrentKM,
+		CurrentEngineHours:  vehicleIn.CurrentEngineHours,
 		NextMaintenanceDate: vehicleIn.NextMaintenanceDate,
-		NextMaintenanceKM:  vehicleIn.NextMaintenanceKM,
-		MaintenanceNotes:   vehicleIn.MaintenanceNotes,
-		TelemetryDeviceID:  vehicleIn.TelemetryDeviceID,
-		OrganizationID:     orgID,
+		NextMaintenanceKM:   vehicleIn.NextMaintenanceKM,
+		MaintenanceNotes:    vehicleIn.MaintenanceNotes,
+		TelemetryDeviceID:   vehicleIn.TelemetryDeviceID,
+		OrganizationID:      orgID,
+	}
+}
+
+// ImportVehicles recebe as linhas já validadas contra o schema; report chega
+// com os erros de leitura do arquivo. Placas e rastreadores repetidos no
+// arquivo ou já cadastrados viram erros da linha. Com algum erro, ou no dry
+// run, nada é gravado; caso contrário todas as linhas entram juntas.
+func (s *vehicleService) ImportVehicles(orgID uint, rows []schemas.ImportRow[schemas.VehicleCreate], report *schemas.ImportReport) error {
+	if err := s.quota.CheckCapacity(orgID, models.QuotaVehicles, report.Rows); err != nil {
+		return err
+	}
+
+	plates := newImportUniqueness("license_plate", "license plate")
+	devices := newImportUniqueness("telemetry_device_id", "telemetry device")
+	for _, row := range rows {
+		plates.add(row.Line, row.Data.LicensePlate)
+		devices.add(row.Line, row.Data.TelemetryDeviceID)
+	}
+	existingPlates, err := s.repo.FindExistingLicensePlates(plates.values())
+	if err != nil {
+		return err
+	}
+	existingDevices, err := s.repo.FindExistingTelemetryDevices(devices.values())
+	if err != nil {
+		return err
+	}
+	report.Errors = append(report.Errors, plates.errors(existingPlates)...)
+	report.Errors = append(report.Errors, devices.errors(existingDevices)...)
+	sortImportErrors(report.Errors)
+
+	if len(report.Errors) > 0 || report.DryRun {
+		return nil
 	}
-	err := s.repo.Create(vehicle)
-	return vehicle, err
+
+	vehicles := make([]models.Vehicle, 0, len(rows))
+	for _, row := range rows {
+		vehicles = append(vehicles, *newVehicle(row.Data, orgID))
+	}
+	err = s.quota.CreateWithinLimit(orgID, models.QuotaVehicles, func(limit int) error {
+		return s.repo.CreateBatch(vehicles, limit)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.invalidate(orgID)
+	report.Created = len(vehicles)
+	return nil
 }
 
-func (s *vehicleService) UpdateVehicle(vehicleID, orgID uint, vehicleIn schemas.VehicleUpdate) (*models.Vehicle, error) {
+// ExportVehicles devolve a frota no formato da importação.
+func (s *vehicleService) ExportVehicles(orgID uint) ([]schemas.VehicleCreate, error) {
+	vehicles, err := s.repo.FindByOrganization(orgID, 0, -1, "")
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]schemas.VehicleCreate, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		rows = append(rows, schemas.VehicleCreate{
+			Brand:               vehicle.Brand,
+			Model:               vehicle.Model,
+			Year:                vehicle.Year,
+			LicensePlate:        vehicle.LicensePlate,
+			Identifier:          vehicle.Identifier,
+			PhotoURL:            vehicle.PhotoURL,
+			CurrentKM:           vehicle.CurrentKM,
+			CurrentEngineHours:  vehicle.CurrentEngineHours,
+			NextMaintenanceDate: vehicle.NextMaintenanceDate,
+			NextMaintenanceKM:   vehicle.NextMaintenanceKM,
+			MaintenanceNotes:    vehicle.MaintenanceNotes,
+			TelemetryDeviceID:   vehicle.TelemetryDeviceID,
+		})
+	}
+	return rows, nil
+}
+
+func (s *vehicleService) UpdateVehicle(vehicleID, orgID uint, vehicleIn schemas.VehicleUpdate, ifMatch *uint) (*models.Vehicle, error) {
 	vehicle, err := s.repo.FindByID(vehicleID, orgID)
 	if err != nil {
 		return nil, err
@@ -95,21 +183,31 @@ func (s *vehicleService) UpdateVehicle(vehicleID, orgID uint, vehicleIn schemas.
 	if vehicle == nil {
 		return nil, nil
 	}
+	if err := checkVersion(ifMatch, vehicle.Version); err != nil {
+		// O ETag pode ter vindo do cache defasado; o próximo GET lê do banco.
+		s.cache.invalidate(orgID, vehicleID)
+		return nil, err
+	}
 
-	if vehicleIn.Brand != nil {
-		vehicle.Brand = *vehicleIn.Brand
+	oldPhoto, oldThumbnail := vehicle.PhotoURL, vehicle.PhotoThumbnailURL
+	if err := applyVehiclePatch(vehicle, vehicleIn); err != nil {
+		return nil, err
+	}
+	// Uma URL informada à mão substitui a foto enviada, e a miniatura dela deixa de valer.
+	photoChanged := vehicleIn.PhotoURL.Set && !samePhotoURL(oldPhoto, vehicle.PhotoURL)
+	if photoChanged {
+		vehicle.PhotoThumbnailURL = nil
 	}
-	// ... (outras atualizações)
 
 	err = s.repo.Update(vehicle)
 	if err != nil {
-		return nil, err
+		return nil, versionError(err)
 	}
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("vehicle:%d", vehicleID)
-	s.cache.Delete(context.Background(), cacheKey)
-
+	if photoChanged {
+		s.photos.remove(orgID, oldPhoto, oldThumbnail)
+	}
+	s.cache.invalidate(orgID, vehicleID)
 	return vehicle, nil
 }
 
@@ -127,9 +225,114 @@ func (s *vehicleService) DeleteVehicle(vehicleID, orgID uint) error {
 		return err
 	}
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("vehicle:%d", vehicleID)
-	s.cache.Delete(context.Background(), cacheKey)
+	s.photos.remove(orgID, vehicle.PhotoURL, vehicle.PhotoThumbnailURL)
+	s.cache.invalidate(orgID, vehicleID)
+	return nil
+}
+
+// UploadPhoto grava a foto e a miniatura e só apaga os arquivos anteriores
+// depois que o veículo foi salvo com os novos.
+func (s *vehicleService) UploadPhoto(vehicleID, orgID uint, file *multipart.FileHeader, ifMatch *uint) (*models.Vehicle, error) {
+	vehicle, err := s.repo.FindByID(vehicleID, orgID)
+	if err != nil || vehicle == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, vehicle.Version); err != nil {
+		return nil, err
+	}
+
+	photo, err := s.photos.save(file, orgID, "vehicles")
+	if err != nil {
+		return nil, err
+	}
+	return s.replacePhoto(vehicle, photo)
+}
+
+func (s *vehicleService) DeletePhoto(vehicleID, orgID uint, ifMatch *uint) (*models.Vehicle, error) {
+	vehicle, err := s.repo.FindByID(vehicleID, orgID)
+	if err != nil || vehicle == nil {
+		return nil, err
+	}
+	if err := checkVersion(ifMatch, vehicle.Version); err != nil {
+		return nil, err
+	}
+	return s.replacePhoto(vehicle, nil)
+}
+
+func (s *vehicleService) replacePhoto(vehicle *models.Vehicle, photo *storedPhoto) (*models.Vehicle, error) {
+	oldPhoto, oldThumbnail := vehicle.PhotoURL, vehicle.PhotoThumbnailURL
+	vehicle.PhotoURL, vehicle.PhotoThumbnailURL = nil, nil
+	if photo != nil {
+		vehicle.PhotoURL, vehicle.PhotoThumbnailURL = photo.urls()
+	}
+	if err := s.repo.Update(vehicle); err != nil {
+		s.photos.remove(vehicle.OrganizationID, vehicle.PhotoURL, vehicle.PhotoThumbnailURL)
+		return nil, versionError(err)
+	}
+	s.photos.remove(vehicle.OrganizationID, oldPhoto, oldThumbnail)
+	s.cache.invalidate(vehicle.OrganizationID, vehicle.ID)
+	return vehicle, nil
+}
+
+func (s *vehicleService) ChangeStatus(vehicleID, orgID uint, statusIn schemas.VehicleStatusUpdate, actorID uint, ifMatch *uint) (*models.Vehicle, error) {
+	vehicle, err := s.repo.FindByID(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, nil
+	}
+	if err := checkVersion(ifMatch, vehicle.Version); err != nil {
+		s.cache.invalidate(orgID, vehicleID)
+		return nil, err
+	}
+
+	err = s.status.ChangeStatus(vehicle, models.VehicleStatusChange{
+		ToStatus: models.VehicleStatus(statusIn.Status),
+		Reason:   statusIn.Reason,
+		ActorID:  &actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// O serviço de status já invalidou o cache do veículo.
+	return vehicle, nil
+}
+
+func (s *vehicleService) GetStatusHistory(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.VehicleStatusPeriod, error) {
+	vehicle, err := s.repo.FindByID(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	return s.status.GetHistory(vehicleID, orgID, dateRange)
+}
+
+func applyVehiclePatch(vehicle *models.Vehicle, vehicleIn schemas.VehicleUpdate) error {
+	err := errors.Join(
+		patchRequired("brand", vehicleIn.Brand, &vehicle.Brand),
+		patchRequired("model", vehicleIn.Model, &vehicle.Model),
+		patchRequired("year", vehicleIn.Year, &vehicle.Year),
+	)
+	if err != nil {
+		return err
+	}
+	if vehicleIn.Status.Set {
+		return fmt.Errorf("%w: status must be changed through /vehicles/:id/status", ErrInvalidPatch)
+	}
+	// Hodômetro e horímetro só mudam por leituras, que passam pela validação.
+	if vehicleIn.CurrentKM.Set || vehicleIn.CurrentEngineHours.Set {
+		return fmt.Errorf("%w: current_km and current_engine_hours must be changed through /vehicles/:id/odometer-readings", ErrInvalidPatch)
+	}
 
+	patchNullable(vehicleIn.LicensePlate, &vehicle.LicensePlate)
+	patchNullable(vehicleIn.Identifier, &vehicle.Identifier)
+	patchNullable(vehicleIn.PhotoURL, &vehicle.PhotoURL)
+	patchNullable(vehicleIn.NextMaintenanceDate, &vehicle.NextMaintenanceDate)
+	patchNullable(vehicleIn.NextMaintenanceKM, &vehicle.NextMaintenanceKM)
+	patchNullable(vehicleIn.MaintenanceNotes, &vehicle.MaintenanceNotes)
+	patchNullable(vehicleIn.TelemetryDeviceID, &vehicle.TelemetryDeviceID)
 	return nil
 }
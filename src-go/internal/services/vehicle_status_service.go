@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-api/internal/models"
+	"go-api/internal/repositories"
+	"go-api/internal/schemas"
+)
+
+var ErrInvalidVehicleStatus = errors.New("invalid vehicle status")
+var ErrInvalidStatusTransition = errors.New("vehicle status transition is not allowed")
+var ErrVehicleHeldByAnotherJourney = errors.New("vehicle is in use by another journey")
+
+type VehicleStatusService interface {
+	ChangeStatus(vehicle *models.Vehicle, change models.VehicleStatusChange) error
+	GetHistory(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.VehicleStatusPeriod, error)
+}
+
+type vehicleStatusService struct {
+	repo     repositories.VehicleRepository
+	settings OrganizationSettingsService
+	cache    vehicleCache
+}
+
+func NewVehicleStatusService(repo repositories.VehicleRepository, settings OrganizationSettingsService, cache repositories.CacheRepository) VehicleStatusService {
+	return &vehicleStatusService{repo: repo, settings: settings, cache: vehicleCache{cache: cache}}
+}
+
+// ChangeStatus é o único caminho para trocar o status de um veículo: valida a
+// transição e grava o veículo (com os demais campos já alterados) junto com o
+// histórico. Entrar em uso e ser liberado do uso ficam reservados às jornadas.
+func (s *vehicleStatusService) ChangeStatus(vehicle *models.Vehicle, change models.VehicleStatusChange) error {
+	from, to := vehicle.Status, change.ToStatus
+	if !models.IsValidVehicleStatus(to) {
+		return fmt.Errorf("%w: %q", ErrInvalidVehicleStatus, to)
+	}
+	journeyOnly := to == models.StatusInUse || (from == models.StatusInUse && to == models.StatusAvailable)
+	if !models.CanTransitionVehicleStatus(from, to) || (journeyOnly && change.JourneyID == nil) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+	}
+	// Só a jornada que colocou o veículo em uso pode liberá-lo; uma jornada
+	// antiga encerrada depois não solta o veículo de outra.
+	if from == models.StatusInUse && to == models.StatusAvailable {
+		entered, err := s.repo.FindLastStatusChange(vehicle.ID, vehicle.OrganizationID, models.StatusInUse)
+		if err != nil {
+			return err
+		}
+		if entered == nil || entered.JourneyID == nil || *entered.JourneyID != *change.JourneyID {
+			return ErrVehicleHeldByAnotherJourney
+		}
+	}
+
+	change.VehicleID = vehicle.ID
+	change.OrganizationID = vehicle.OrganizationID
+	change.FromStatus = from
+	change.ChangedAt = time.Now()
+	vehicle.Status = to
+	if err := s.repo.UpdateStatus(vehicle, &change); err != nil {
+		vehicle.Status = from
+		return versionError(err)
+	}
+	s.cache.invalidate(vehicle.OrganizationID, vehicle.ID)
+	return nil
+}
+
+// GetHistory devolve os períodos de status que se sobrepõem ao período no
+// fuso da organização, do mais antigo ao atual.
+func (s *vehicleStatusService) GetHistory(vehicleID, orgID uint, dateRange schemas.DateRange) ([]schemas.VehicleStatusPeriod, error) {
+	settings, err := s.settings.Settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	loc := settings.Location()
+	dateFrom, dateTo := dateWindow(settings, dateRange)
+
+	changes, err := s.repo.FindStatusHistory(vehicleID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := []schemas.VehicleStatusPeriod{}
+	for i, change := range changes {
+		period := schemas.VehicleStatusPeriod{
+			Status:     string(change.ToStatus),
+			FromStatus: string(change.FromStatus),
+			Since:      change.ChangedAt.In(loc),
+			Reason:     change.Reason,
+			ActorID:    change.ActorID,
+			JourneyID:  change.JourneyID,
+		}
+		if i+1 < len(changes) {
+			until := changes[i+1].ChangedAt.In(loc)
+			period.Until = &until
+		}
+		if change.Actor != nil {
+			period.ActorName = &change.Actor.FullName
+		}
+
+		if dateTo != nil && !period.Since.Before(*dateTo) {
+			continue
+		}
+		if dateFrom != nil && period.Until != nil && !period.Until.After(*dateFrom) {
+			continue
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
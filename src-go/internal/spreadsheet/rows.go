@@ -0,0 +1,268 @@
+package spreadsheet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// As colunas da importação e da exportação são as tags json do schema, na
+// ordem dos campos: um arquivo exportado pode ser reimportado sem ajustes.
+
+var ErrUnknownColumn = errors.New("unknown column")
+var ErrDuplicateColumn = errors.New("duplicate column")
+
+// CellError é o erro de conversão de uma célula.
+type CellError struct {
+	Column string
+	Err    error
+}
+
+func (e CellError) Error() string {
+	return e.Column + ": " + e.Err.Error()
+}
+
+func (e CellError) Unwrap() error {
+	return e.Err
+}
+
+type column struct {
+	name  string
+	index int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// excelEpoch é o dia zero das datas seriais do Excel.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func structColumns(t reflect.Type) []column {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, column{name: name, index: i})
+	}
+	return columns
+}
+
+// Columns devolve o cabeçalho do schema.
+func Columns(v any) []string {
+	var names []string
+	for _, col := range structColumns(reflect.TypeOf(v)) {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// ColumnOf devolve a coluna do campo Go informado, para relatar erros de
+// validação com o nome que aparece no arquivo.
+func ColumnOf(v any, fieldName string) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, col := range structColumns(t) {
+		if t.Field(col.index).Name == fieldName {
+			return col.name
+		}
+	}
+	return fieldName
+}
+
+// MarshalRow converte o schema em uma linha na ordem de Columns.
+func MarshalRow(v any) []string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	columns := structColumns(rv.Type())
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = formatValue(rv.Field(col.index))
+	}
+	return row
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		if t.Equal(t.Truncate(24 * time.Hour)) {
+			return t.UTC().Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Decoder associa as colunas do arquivo aos campos do schema. A ordem das
+// colunas é livre e as ausentes ficam com o valor zero.
+type Decoder struct {
+	fields []column
+}
+
+// NewDecoder recusa colunas que não existem no schema ou que se repetem, para
+// um erro de digitação no cabeçalho não virar um campo vazio em todas as linhas.
+func NewDecoder(header []string, v any) (*Decoder, error) {
+	known := map[string]column{}
+	for _, col := range structColumns(reflect.TypeOf(v)) {
+		known[col.name] = col
+	}
+	seen := map[string]bool{}
+	fields := make([]column, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			fields[i] = column{index: -1}
+			continue
+		}
+		col, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateColumn, name)
+		}
+		seen[name] = true
+		fields[i] = col
+	}
+	return &Decoder{fields: fields}, nil
+}
+
+// Decode preenche dst (ponteiro para o schema) com a linha. Células que não
+// puderam ser convertidas voltam como CellError e o campo fica com o valor zero.
+func (d *Decoder) Decode(row []string, dst any) []CellError {
+	rv := reflect.ValueOf(dst).Elem()
+	var errs []CellError
+	for i, value := range row {
+		value = strings.TrimSpace(value)
+		if i >= len(d.fields) || d.fields[i].index < 0 {
+			if value != "" {
+				errs = append(errs, CellError{Column: columnName(i), Err: errors.New("value in a column without header")})
+			}
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		if err := parseValue(rv.Field(d.fields[i].index), value); err != nil {
+			errs = append(errs, CellError{Column: d.fields[i].name, Err: err})
+		}
+	}
+	return errs
+}
+
+func parseValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := parseValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if field.Type() == timeType {
+		t, err := parseTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			// Planilhas às vezes guardam inteiros como "2020.0".
+			f, ferr := parseFloat(value)
+			if ferr != nil || f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+			n = int64(f)
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("%q is out of range", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || field.OverflowUint(n) {
+			return fmt.Errorf("%q is not a valid positive integer", value)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseFloat(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported column type %s", field.Type())
+	}
+	return nil
+}
+
+// parseFloat aceita a vírgula decimal usada nas planilhas em português.
+func parseFloat(value string) (float64, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.New("invalid number")
+	}
+	return f, nil
+}
+
+// parseTime aceita RFC 3339, AAAA-MM-DD e a data serial que o XLSX grava nas
+// células formatadas como data.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	// 2958465 é 31/12/9999, a última data que o Excel representa.
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial <= 2958465 {
+		return excelEpoch.Add(time.Duration(math.Round(serial*86400)) * time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (use YYYY-MM-DD)", value)
+}
@@ -0,0 +1,184 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format é o formato de arquivo aceito nas importações e gerado nas exportações.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, use csv or xlsx")
+var ErrInvalidFile = errors.New("invalid spreadsheet file")
+var ErrTooManyRows = errors.New("too many rows")
+var ErrTooManyColumns = errors.New("too many columns")
+
+// MaxColumns limita as colunas lidas: os schemas importados têm poucas, e uma
+// referência como XFD1 não pode fazer cada linha alocar 16 mil células.
+const MaxColumns = 256
+
+// ParseFormat aceita o valor do parâmetro format (vazio vale CSV).
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// FormatFromFilename identifica o formato pela extensão do arquivo enviado.
+func FormatFromFilename(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Read devolve as linhas da primeira planilha. A posição no slice corresponde
+// à linha do arquivo (índice 0 é a linha 1), inclusive linhas vazias. Arquivos
+// com mais de maxLines linhas (contando as vazias) ou de MaxColumns colunas
+// são recusados durante a leitura, antes de as linhas serem alocadas.
+func Read(r io.ReaderAt, size int64, format Format, maxLines int) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(io.NewSectionReader(r, 0, size), maxLines)
+	case FormatXLSX:
+		return readXLSX(r, size, maxLines)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// Write grava as linhas no formato pedido; sheet só é usado no XLSX.
+func Write(w io.Writer, format Format, sheet string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, rows)
+	case FormatXLSX:
+		return writeXLSX(w, sheet, rows)
+	}
+	return ErrUnsupportedFormat
+}
+
+// WriteCSV grava as linhas protegendo as células que o Excel executaria como
+// fórmula (veja EscapeFormula). No XLSX as células são sempre texto.
+func WriteCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		escaped := make([]string, len(row))
+		for i, cell := range row {
+			escaped[i] = EscapeFormula(cell)
+		}
+		if err := cw.Write(escaped); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// EscapeFormula prefixa com "'" as células que começam com =, +, -, @, tab ou
+// CR, para o Excel mostrá-las como texto. Números negativos não são tocados.
+func EscapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+const formulaPrefixes = "=+-@\t\r"
+
+// unescapeFormula desfaz EscapeFormula, para um CSV exportado voltar igual
+// na importação.
+func unescapeFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// readCSV aceita o BOM e o separador ";" que o Excel em português gera.
+func readCSV(r io.Reader, maxLines int) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		// O leitor de CSV pula linhas em branco; elas voltam vazias para a
+		// numeração continuar igual à do arquivo.
+		line, _ := reader.FieldPos(0)
+		if err := checkSize(line, len(record), maxLines); err != nil {
+			return nil, err
+		}
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		for i, cell := range record {
+			record[i] = unescapeFormula(cell)
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+func checkSize(line, columns, maxLines int) error {
+	if line > maxLines {
+		return fmt.Errorf("%w: the file must have at most %d lines", ErrTooManyRows, maxLines)
+	}
+	if columns > MaxColumns {
+		return fmt.Errorf("%w: the file must have at most %d columns", ErrTooManyColumns, MaxColumns)
+	}
+	return nil
+}
+
+// IsBlank indica uma linha sem nenhum valor preenchido.
+func IsBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
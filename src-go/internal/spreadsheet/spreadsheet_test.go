@@ -0,0 +1,115 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	cases := map[string]string{
+		"":                   "",
+		"Transportes Silva":  "Transportes Silva",
+		"=HYPERLINK(\"x\")":  "'=HYPERLINK(\"x\")",
+		"+55 11 99999-0000":  "'+55 11 99999-0000",
+		"-cmd|' /C calc'!A0": "'-cmd|' /C calc'!A0",
+		"@SUM(A1)":           "'@SUM(A1)",
+		"\tvalor":            "'\tvalor",
+		"\rvalor":            "'\rvalor",
+		"-12.5":              "-12.5",
+		"+3":                 "+3",
+	}
+	for in, want := range cases {
+		if got := EscapeFormula(in); got != want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteCSVEscapesFormulasAndRoundTrips(t *testing.T) {
+	rows := [][]string{{"name", "value"}, {"=1+1", "-3"}, {"'quoted", "@x"}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "name,value\n'=1+1,-3\n'quoted,'@x\n"
+	if buf.String() != want {
+		t.Fatalf("WriteCSV = %q, want %q", buf.String(), want)
+	}
+
+	read, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), FormatCSV, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, rows) {
+		t.Fatalf("round trip = %q, want %q", read, rows)
+	}
+}
+
+func readCSVString(data string, maxLines int) ([][]string, error) {
+	return Read(strings.NewReader(data), int64(len(data)), FormatCSV, maxLines)
+}
+
+func TestReadCSV(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{"comma", "a,b\n1,2\n", [][]string{{"a", "b"}, {"1", "2"}}},
+		{"excel semicolon with BOM", "\xef\xbb\xbfplaca;marca\nABC;\"Volvo; FH\"\n", [][]string{{"placa", "marca"}, {"ABC", "Volvo; FH"}}},
+		{"blank lines keep numbering", "a\n\n\nb\n", [][]string{{"a"}, nil, nil, {"b"}}},
+		{"ragged rows", "a,b,c\n1\n", [][]string{{"a", "b", "c"}, {"1"}}},
+		// O índice segue a linha do arquivo, que é a usada nos erros da importação.
+		{"quoted newline", "a\n\"x\ny\"\nz\n", [][]string{{"a"}, {"x\ny"}, nil, {"z"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := readCSVString(tc.data, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("rows = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVRejectsMalformedAndOversizedInput(t *testing.T) {
+	if _, err := readCSVString("a,b\n\"unterminated,1\n", 100); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("unterminated quote: err = %v, want ErrInvalidFile", err)
+	}
+	if _, err := readCSVString(strings.Repeat("x\n", 11), 10); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("too many lines: err = %v, want ErrTooManyRows", err)
+	}
+	if _, err := readCSVString("a\n"+strings.Repeat("\n", 50)+"b\n", 10); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("line beyond the limit after blank lines: err = %v, want ErrTooManyRows", err)
+	}
+	if _, err := readCSVString(strings.Repeat("x,", MaxColumns)+"x\n", 10); !errors.Is(err, ErrTooManyColumns) {
+		t.Errorf("too many columns: err = %v, want ErrTooManyColumns", err)
+	}
+	if _, err := readCSVString(strings.Repeat("x\n", 10), 10); err != nil {
+		t.Errorf("file at the limit: %v", err)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	if f, err := ParseFormat(" XLSX "); err != nil || f != FormatXLSX {
+		t.Errorf("ParseFormat(XLSX) = %q, %v", f, err)
+	}
+	if f, err := ParseFormat(""); err != nil || f != FormatCSV {
+		t.Errorf("ParseFormat(\"\") = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("ods"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("ParseFormat(ods) err = %v", err)
+	}
+	if f, err := FormatFromFilename("frota.CSV"); err != nil || f != FormatCSV {
+		t.Errorf("FormatFromFilename(frota.CSV) = %q, %v", f, err)
+	}
+	if _, err := FormatFromFilename("frota.xls"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("FormatFromFilename(frota.xls) err = %v", err)
+	}
+}
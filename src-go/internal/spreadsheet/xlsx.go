@@ -0,0 +1,332 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Leitura e escrita do mínimo do formato XLSX (Office Open XML) que a
+// importação precisa: a primeira planilha, com textos compartilhados,
+// textos inline e números. Fórmulas valem pelo último valor calculado.
+
+// maxPartSize limita o XML descompactado de cada parte do arquivo, para um
+// arquivo pequeno não se expandir em centenas de megabytes.
+const maxPartSize = 50 << 20
+
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relTypeWorksheet      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
+	nsSpreadsheet         = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels         = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(r io.ReaderAt, size int64, maxLines int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: worksheet %s not found", ErrInvalidFile, sheetPath)
+	}
+	var sheet xlsxWorksheet
+	if err := decodePart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		index := row.Index
+		if index <= 0 {
+			index = len(rows) + 1
+		}
+		if index < len(rows)+1 {
+			return nil, fmt.Errorf("%w: rows out of order", ErrInvalidFile)
+		}
+		// O índice vem do arquivo: é conferido antes de preencher as lacunas.
+		if err := checkSize(index, len(row.Cells), maxLines); err != nil {
+			return nil, err
+		}
+		for len(rows) < index-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if col, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if err := checkSize(index, col+1, maxLines); err != nil {
+				return nil, err
+			}
+			var value string
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: invalid shared string in %s", ErrInvalidFile, cell.Ref)
+				}
+				value = shared.Items[n].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			default:
+				value = cell.Value
+			}
+			if col >= len(values) {
+				values = append(values, make([]string, col-len(values)+1)...)
+			}
+			values[col] = value
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// firstSheetPath segue as relações do pacote até a primeira planilha do
+// workbook, que nem sempre se chama sheet1.xml.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	workbookPath := "xl/workbook.xml"
+	if f, ok := files["_rels/.rels"]; ok {
+		var rels xlsxRelationships
+		if err := decodePart(f, &rels); err != nil {
+			return "", err
+		}
+		for _, rel := range rels.Relationships {
+			if strings.HasSuffix(rel.Type, "/officeDocument") {
+				workbookPath = strings.TrimPrefix(path.Clean("/"+rel.Target), "/")
+			}
+		}
+	}
+	f, ok := files[workbookPath]
+	if !ok {
+		return "", fmt.Errorf("%w: workbook not found", ErrInvalidFile)
+	}
+	var workbook xlsxWorkbook
+	if err := decodePart(f, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no sheets", ErrInvalidFile)
+	}
+
+	dir, name := path.Split(workbookPath)
+	f, ok = files[dir+"_rels/"+name+".rels"]
+	if !ok {
+		return "", fmt.Errorf("%w: workbook relationships not found", ErrInvalidFile)
+	}
+	var rels xlsxRelationships
+	if err := decodePart(f, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		// O sufixo cobre também os namespaces do formato Strict.
+		if rel.ID != workbook.Sheets[0].RelID || !strings.HasSuffix(rel.Type, "/worksheet") {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(path.Clean(rel.Target), "/"), nil
+		}
+		return path.Clean(dir + rel.Target), nil
+	}
+	return "", fmt.Errorf("%w: first sheet not found", ErrInvalidFile)
+}
+
+func decodePart(f *zip.File, v any) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidFile, f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFile, f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex converte a referência da célula ("C12") no índice da coluna (2).
+func columnIndex(ref string) (int, error) {
+	col := 0
+	letters := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		letters++
+		if col > 16384 {
+			break
+		}
+	}
+	// O XLSX vai até a coluna XFD (16384).
+	if letters == 0 || col > 16384 {
+		return 0, fmt.Errorf("%w: invalid cell reference %q", ErrInvalidFile, ref)
+	}
+	return col - 1, nil
+}
+
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// writeXLSX grava todas as células como texto inline, o que mantém valores
+// como placas e matrículas exatamente como no CSV.
+func writeXLSX(w io.Writer, sheet string, rows [][]string) error {
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	// O Excel limita o nome da aba a 31 caracteres.
+	if runes := []rune(sheet); len(runes) > 31 {
+		sheet = string(runes[:31])
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content func(io.Writer) error
+	}{
+		{"[Content_Types].xml", staticPart(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`)},
+		{"_rels/.rels", staticPart(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="` + nsPackageRels + `"><Relationship Id="rId1" Type="` + relTypeOfficeDocument + `" Target="xl/workbook.xml"/></Relationships>`)},
+		{"xl/workbook.xml", func(w io.Writer) error {
+			if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="`+nsSpreadsheet+`" xmlns:r="`+nsRelationships+`"><sheets><sheet name="`); err != nil {
+				return err
+			}
+			if err := xml.EscapeText(w, []byte(sheet)); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, `" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+			return err
+		}},
+		{"xl/_rels/workbook.xml.rels", staticPart(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="` + nsPackageRels + `"><Relationship Id="rId1" Type="` + relTypeWorksheet + `" Target="worksheets/sheet1.xml"/></Relationships>`)},
+		{"xl/worksheets/sheet1.xml", func(w io.Writer) error { return writeWorksheet(w, rows) }},
+	}
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if err := part.content(pw); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func staticPart(content string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+}
+
+func writeWorksheet(w io.Writer, rows [][]string) error {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="` + nsSpreadsheet + `"><sheetData>`)
+	for i, row := range rows {
+		line := strconv.Itoa(i + 1)
+		b.WriteString(`<row r="` + line + `">`)
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			b.WriteString(`<c r="` + columnName(j) + line + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&b, []byte(value))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+		if b.Len() > 64<<10 {
+			if _, err := io.WriteString(w, b.String()); err != nil {
+				return err
+			}
+			b.Reset()
+		}
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,156 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildXLSX monta um pacote mínimo com a planilha e os textos compartilhados
+// informados; parts sobrescreve ou remove (conteúdo vazio) partes do pacote.
+func buildXLSX(t *testing.T, sheetData, sharedStrings string, parts map[string]string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"_rels/.rels":                `<Relationships xmlns="` + nsPackageRels + `"><Relationship Id="rId1" Type="` + relTypeOfficeDocument + `" Target="xl/workbook.xml"/></Relationships>`,
+		"xl/workbook.xml":            `<workbook xmlns="` + nsSpreadsheet + `" xmlns:r="` + nsRelationships + `"><sheets><sheet name="Dados" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="` + nsPackageRels + `"><Relationship Id="rId7" Type="` + relTypeWorksheet + `" Target="worksheets/dados.xml"/></Relationships>`,
+		"xl/worksheets/dados.xml":    `<worksheet xmlns="` + nsSpreadsheet + `"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if sharedStrings != "" {
+		files["xl/sharedStrings.xml"] = `<sst xmlns="` + nsSpreadsheet + `">` + sharedStrings + `</sst>`
+	}
+	for name, content := range parts {
+		if content == "" {
+			delete(files, name)
+		} else {
+			files[name] = content
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readXLSXBytes(data []byte, maxLines int) ([][]string, error) {
+	return Read(bytes.NewReader(data), int64(len(data)), FormatXLSX, maxLines)
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"license_plate", "brand", "year"},
+		{"ABC1D23", "Volvo & Cia <SA>", "2022"},
+		{},
+		{"", "  espaços  ", "", "", "última"},
+		{"=1+1", "0012", "ação"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, "Veículos com um nome de aba bem comprido", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readXLSXBytes(buf.Bytes(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{rows[0], rows[1], nil, rows[3], rows[4]}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXCellTypes(t *testing.T) {
+	data := buildXLSX(t,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>`+
+			`<row r="3"><c r="B3"><v>42.5</v></c><c r="A3" t="inlineStr"><is><t>inline</t></is></c><c r="D3" t="str"><f>A3</f><v>calc</v></c></row>`,
+		`<si><t>placa</t></si><si><r><t>mar</t></r><r><t>ca</t></r></si>`,
+		nil)
+
+	got, err := readXLSXBytes(data, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"placa", "", "marca"}, nil, {"inline", "42.5", "", "calc"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXRejectsMalformedFiles(t *testing.T) {
+	cases := map[string][]byte{
+		"not a zip":            []byte("PK\x03\x04 definitely not a zip"),
+		"missing workbook":     buildXLSX(t, ``, ``, map[string]string{"xl/workbook.xml": ""}),
+		"missing sheet":        buildXLSX(t, ``, ``, map[string]string{"xl/worksheets/dados.xml": ""}),
+		"no sheets":            buildXLSX(t, ``, ``, map[string]string{"xl/workbook.xml": `<workbook xmlns="` + nsSpreadsheet + `"><sheets/></workbook>`}),
+		"broken xml":           buildXLSX(t, `<row r="1"><c r="A1"><v>1</v></row>`, ``, nil),
+		"bad shared string":    buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>5</v></c></row>`, `<si><t>x</t></si>`, nil),
+		"negative shared":      buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>-1</v></c></row>`, `<si><t>x</t></si>`, nil),
+		"rows out of order":    buildXLSX(t, `<row r="2"><c r="A2"><v>1</v></c></row><row r="1"><c r="A1"><v>1</v></c></row>`, ``, nil),
+		"bad cell reference":   buildXLSX(t, `<row r="1"><c r="12"><v>1</v></c></row>`, ``, nil),
+		"column beyond XFD":    buildXLSX(t, `<row r="1"><c r="XFE1"><v>1</v></c></row>`, ``, nil),
+		"huge column overflow": buildXLSX(t, `<row r="1"><c r="ZZZZZZZZZZZZZZ1"><v>1</v></c></row>`, ``, nil),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readXLSXBytes(data, 100); !errors.Is(err, ErrInvalidFile) {
+				t.Fatalf("err = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+}
+
+func TestReadXLSXRejectsOversizedSheets(t *testing.T) {
+	cases := []struct {
+		name  string
+		sheet string
+		want  error
+	}{
+		{"huge row index", `<row r="2000000000"><c r="A2000000000"><v>1</v></c></row>`, ErrTooManyRows},
+		{"row just above the limit", `<row r="11"><c r="A11"><v>1</v></c></row>`, ErrTooManyRows},
+		{"implicit rows above the limit", strings.Repeat(`<row><c><v>1</v></c></row>`, 11), ErrTooManyRows},
+		{"wide cell reference", `<row r="1"><c r="XFD1"><v>1</v></c></row>`, ErrTooManyColumns},
+		{"too many cells", `<row r="1">` + strings.Repeat(`<c><v>1</v></c>`, MaxColumns+1) + `</row>`, ErrTooManyColumns},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := readXLSXBytes(buildXLSX(t, tc.sheet, ``, nil), 10); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	if _, err := readXLSXBytes(buildXLSX(t, `<row r="10"><c r="IV10"><v>1</v></c></row>`, ``, nil), 10); err != nil {
+		t.Fatalf("sheet at the limits: %v", err)
+	}
+}
+
+func TestReadXLSXRejectsOversizedParts(t *testing.T) {
+	huge := `<row r="1"><c r="A1" t="inlineStr"><is><t>` + strings.Repeat("a", maxPartSize) + `</t></is></c></row>`
+	if _, err := readXLSXBytes(buildXLSX(t, huge, ``, nil), 10); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("err = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestColumnNames(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA", 16383: "XFD"} {
+		if got := columnName(index); got != name {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, name)
+		}
+		if got, err := columnIndex(name + "7"); err != nil || got != index {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", name+"7", got, err, index)
+		}
+	}
+}
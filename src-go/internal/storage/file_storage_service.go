@@ -6,13 +6,18 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
 type FileStorageService interface {
 	Save(file *multipart.FileHeader, subpath string) (string, error)
+	// SaveContent grava conteúdo gerado pela API (ou já validado por ela) com a
+	// extensão informada, em vez da extensão do nome enviado pelo cliente.
+	SaveContent(content io.Reader, subpath, ext string) (string, error)
 	Delete(filePath string) error
+	Size(filePath string) (int64, error)
 }
 
 type localStorageService struct {
@@ -24,8 +29,18 @@ func NewLocalStorageService(basePath string) FileStorageService {
 }
 
 func (s *localStorageService) Save(file *multipart.FileHeader, subpath string) (string, error) {
+	// Open the uploaded file
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	return s.SaveContent(src, subpath, filepath.Ext(file.Filename))
+}
+
+func (s *localStorageService) SaveContent(content io.Reader, subpath, ext string) (string, error) {
 	// Generate a unique filename
-	ext := filepath.Ext(file.Filename)
 	uniqueFilename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
 
 	// Create the full path
@@ -35,13 +50,6 @@ func (s *localStorageService) Save(file *multipart.FileHeader, subpath string) (
 	}
 	fullPath := filepath.Join(dir, uniqueFilename)
 
-	// Open the uploaded file
-	src, err := file.Open()
-	if err != nil {
-		return "", err
-	}
-	defer src.Close()
-
 	// Create the destination file
 	dst, err := os.Create(fullPath)
 	if err != nil {
@@ -50,7 +58,8 @@ func (s *localStorageService) Save(file *multipart.FileHeader, subpath string) (
 	defer dst.Close()
 
 	// Copy the file content
-	if _, err = io.Copy(dst, src); err != nil {
+	if _, err = io.Copy(dst, content); err != nil {
+		os.Remove(fullPath)
 		return "", err
 	}
 
@@ -60,9 +69,7 @@ func (s *localStorageService) Save(file *multipart.FileHeader, subpath string) (
 }
 
 func (s *localStorageService) Delete(filePath string) error {
-	// Construct the full path from the web path
-	// This is a simplification and might need to be more robust
-	fullPath := filepath.Join(s.basePath, filepath.Clean(filePath))
+	fullPath := s.fullPath(filePath)
 
 	// Check if the file exists
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
@@ -71,3 +78,20 @@ func (s *localStorageService) Delete(filePath string) error {
 
 	return os.Remove(fullPath)
 }
+
+// Size devolve o tamanho do arquivo em bytes; arquivos que não existem mais contam 0.
+func (s *localStorageService) Size(filePath string) (int64, error) {
+	info, err := os.Stat(s.fullPath(filePath))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// fullPath converte a URL pública devolvida por Save no caminho em disco.
+func (s *localStorageService) fullPath(filePath string) string {
+	return filepath.Join(s.basePath, strings.TrimPrefix(filepath.Clean("/"+filePath), "/static"))
+}